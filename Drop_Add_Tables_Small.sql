@@ -1,23 +1,56 @@
 drop table keys cascade;
 drop table groups cascade;
 drop table partners cascade;
+drop table partner_status_changes cascade;
 drop table groups_to_keys cascade;
 drop table partner_mappings cascade;
+drop table templates cascade;
+drop table template_mappings cascade;
+drop table change_sets cascade;
+drop table change_set_partners cascade;
+drop table change_set_attributes cascade;
+drop table approval_policies cascade;
+drop table approval_requests cascade;
+drop table audit_log cascade;
+drop table idempotency_keys cascade;
+drop table access_log cascade;
+drop table api_keys cascade;
+drop table schema_version cascade;
 
 CREATE TABLE keys (
     id serial primary key,
-    name varchar
+    name varchar,
+    identifier boolean NOT NULL DEFAULT false,
+    sensitive boolean NOT NULL DEFAULT false,
+    deleted_at timestamptz
 );
 
+-- A name can be reused only once the key that had it is deleted, so restoring a key fails while a live one has its name.
+CREATE UNIQUE INDEX keys_live_name ON keys (name) WHERE deleted_at IS NULL;
+
 CREATE TABLE groups (
     id serial primary key,
-    name varchar
+    name varchar,
+    deleted_at timestamptz
 );
 
 CREATE TABLE partners (
     id serial primary key,
     name varchar,
-    code varchar
+    code varchar,
+    status varchar NOT NULL DEFAULT 'active',
+    status_changed_at timestamptz NOT NULL DEFAULT now(),
+    revision int NOT NULL DEFAULT 1,
+    deleted_at timestamptz
+);
+
+CREATE TABLE partner_status_changes (
+    id serial primary key,
+    partner_id int,
+    from_status varchar,
+    to_status varchar,
+    changed_at timestamptz NOT NULL DEFAULT now(),
+    FOREIGN KEY(partner_id) REFERENCES partners(id)
 );
 
 CREATE TABLE groups_to_keys (
@@ -32,15 +65,129 @@ CREATE TABLE partner_mappings (
     id serial primary key,
     partner_id int,
     key_id int,
-    FOREIGN KEY(partner_id) REFERENCES keys(id),
+    FOREIGN KEY(partner_id) REFERENCES partners(id),
+    FOREIGN KEY(key_id) REFERENCES keys(id),
+    value varchar,
+    deleted_at timestamptz
+);
+
+CREATE TABLE templates (
+    id serial primary key,
+    name varchar UNIQUE
+);
+
+CREATE TABLE template_mappings (
+    id serial primary key,
+    template_id int,
+    key_id int,
+    FOREIGN KEY(template_id) REFERENCES templates(id),
     FOREIGN KEY(key_id) REFERENCES keys(id),
     value varchar
 );
 
+CREATE TABLE change_sets (
+    id serial primary key,
+    name varchar,
+    status varchar NOT NULL DEFAULT 'draft',
+    created_at timestamptz NOT NULL DEFAULT now(),
+    closed_at timestamptz
+);
+
+CREATE TABLE change_set_partners (
+    id serial primary key,
+    change_set_id int,
+    code varchar,
+    name varchar,
+    expected_revision int,
+    FOREIGN KEY(change_set_id) REFERENCES change_sets(id),
+    UNIQUE(change_set_id, code)
+);
+
+CREATE TABLE change_set_attributes (
+    id serial primary key,
+    change_set_partner_id int,
+    key varchar,
+    value varchar,
+    FOREIGN KEY(change_set_partner_id) REFERENCES change_set_partners(id),
+    UNIQUE(change_set_partner_id, key)
+);
+
+CREATE TABLE approval_policies (
+    id serial primary key,
+    group_id int,
+    approver varchar,
+    FOREIGN KEY(group_id) REFERENCES groups(id),
+    UNIQUE(group_id, approver)
+);
+
+CREATE TABLE approval_requests (
+    id serial primary key,
+    change_set_id int,
+    groups varchar[],
+    status varchar NOT NULL DEFAULT 'pending',
+    requested_by varchar,
+    requested_at timestamptz NOT NULL DEFAULT now(),
+    decided_by varchar,
+    decided_at timestamptz,
+    reason varchar,
+    FOREIGN KEY(change_set_id) REFERENCES change_sets(id)
+);
+
+CREATE TABLE audit_log (
+    id serial primary key,
+    action varchar,
+    actor varchar,
+    subject varchar,
+    detail varchar,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE idempotency_keys (
+    id serial primary key,
+    idempotency_key varchar NOT NULL,
+    method varchar NOT NULL,
+    request_hash varchar,
+    response varchar,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    UNIQUE(idempotency_key, method)
+);
+
+CREATE TABLE access_log (
+    id serial primary key,
+    caller varchar NOT NULL,
+    partner_code varchar NOT NULL,
+    keys varchar[] NOT NULL,
+    request_id varchar,
+    read_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- The access log is append-only, so rows cannot be changed or removed.
+CREATE RULE access_log_no_update AS ON UPDATE TO access_log DO INSTEAD NOTHING;
+CREATE RULE access_log_no_delete AS ON DELETE TO access_log DO INSTEAD NOTHING;
+
+CREATE TABLE api_keys (
+    id serial primary key,
+    prefix varchar NOT NULL UNIQUE,
+    hash varchar NOT NULL UNIQUE,
+    owner varchar NOT NULL,
+    scope varchar[] NOT NULL,
+    issued_by varchar NOT NULL,
+    expires_at timestamptz,
+    last_used_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    revoked_at timestamptz
+);
+
+-- Each change to the schema adds a row, and the service is only ready when the latest matches db.SchemaVersion.
+CREATE TABLE schema_version (
+    version int NOT NULL,
+    applied_at timestamptz NOT NULL DEFAULT now()
+);
+
 INSERT INTO keys (name) VALUES ('Currency');
-INSERT INTO keys (name) VALUES ('ISAID');
+INSERT INTO keys (name, identifier) VALUES ('ISAID', true);
 INSERT INTO keys (name) VALUES ('Qualifier');
-INSERT INTO keys (name) VALUES ('DM_VENDOR_CODE');
+INSERT INTO keys (name, identifier) VALUES ('DM_VENDOR_CODE', true);
 
 
 INSERT INTO groups (name) VALUES ('EDI');
@@ -55,6 +202,10 @@ INSERT INTO groups_to_keys (group_id, key_id) VALUES (1, 3);
 INSERT INTO groups_to_keys (group_id, key_id) VALUES (2, 1);
 INSERT INTO groups_to_keys (group_id, key_id) VALUES (1, 4);
 
+INSERT INTO approval_policies (group_id, approver) VALUES (1, 'edi-lead');
+INSERT INTO approval_policies (group_id, approver) VALUES (2, 'finance-lead');
+INSERT INTO approval_policies (group_id, approver) VALUES (2, 'controller');
+
 
 INSERT INTO partner_mappings (partner_id, key_id, value) VALUES (2, 1, 'USD');
 INSERT INTO partner_mappings (partner_id, key_id, value) VALUES (1, 1, 'CAD');
@@ -67,3 +218,12 @@ INSERT INTO partner_mappings (partner_id, key_id, value) VALUES (3, 3, 'ZZ');
 INSERT INTO partner_mappings (partner_id, key_id, value) VALUES (1, 4, 'MUS');
 INSERT INTO partner_mappings (partner_id, key_id, value) VALUES (2, 4, 'BAR');
 INSERT INTO partner_mappings (partner_id, key_id, value) VALUES (3, 4, 'FAN');
+
+INSERT INTO templates (name) VALUES ('Standard US EDI retailer');
+
+INSERT INTO template_mappings (template_id, key_id, value) VALUES (1, 1, 'USD');
+INSERT INTO template_mappings (template_id, key_id, value) VALUES (1, 3, 'ZZ');
+
+INSERT INTO schema_version (version) VALUES (1);
+INSERT INTO schema_version (version) VALUES (2);
+INSERT INTO schema_version (version) VALUES (3);
@@ -0,0 +1,39 @@
+// Command openapi makes the OpenAPI 3 spec of the http gateway from the Swagger 2.0 file protoc-gen-swagger
+// generates. pkg/pb/compile.sh runs it after protoc.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/openapi"
+)
+
+func main() {
+	in := flag.String("in", "pkg/pb/partner_service.swagger.json", "Swagger 2.0 file to convert")
+	out := flag.String("out", "pkg/pb/partner_service.openapi.json", "OpenAPI 3 file to write")
+	title := flag.String("title", "Partner Service", "title of the API")
+	description := flag.String("description", "Preferences wholesale partners have for EDI, currency and the like.", "description of the API")
+	version := flag.String("version", "v1", "version of the API")
+	flag.Parse()
+
+	if err := run(*in, *out, openapi.Info{Title: *title, Description: *description, Version: *version}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(in, out string, info openapi.Info) error {
+	swagger, err := ioutil.ReadFile(in)
+	if err != nil {
+		return errors.Wrap(err, "failed to read swagger file")
+	}
+	spec, err := openapi.Convert(swagger, info)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(ioutil.WriteFile(out, spec, 0644), "failed to write openapi file")
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/pb"
+)
+
+// runCompare asks a running service to compare partners and prints the result as a diff.
+func runCompare(args []string) error {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	codes := fs.String("codes", "", "comma separated codes of the partners to compare, at least two")
+	group := fs.String("group", "", "only compare keys in this group")
+	format := fs.String("format", "text", "output format: text or json")
+	all := fs.Bool("all", false, "also show keys that are equal for every partner")
+	d := addDialFlags(fs)
+	fs.Parse(args)
+
+	conn, err := dial(d)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	req := &pb.CompareRequest{Group: *group}
+	if *codes != "" {
+		req.PartnerCodes = strings.Split(*codes, ",")
+	}
+	reply, err := pb.NewPartnerServiceClient(conn).ComparePartners(context.Background(), req)
+	if err != nil {
+		return errors.Wrap(err, "failed to compare partners")
+	}
+	if reply.Error != "" {
+		return errors.New(reply.Error)
+	}
+
+	var keys []*pb.KeyComparison
+	for _, k := range reply.Keys {
+		if *all || k.Status != pb.KeyStatus_EQUAL {
+			keys = append(keys, k)
+		}
+	}
+
+	switch *format {
+	case "text":
+		return writeCompareText(os.Stdout, reply.PartnerCodes, keys)
+	case "json":
+		//jsonpb writes statuses by name, the way the http gateway does.
+		m := jsonpb.Marshaler{Indent: "  "}
+		if err = m.Marshal(os.Stdout, &pb.CompareReply{PartnerCodes: reply.PartnerCodes, Keys: keys}); err != nil {
+			return errors.Wrap(err, "failed to write json comparison")
+		}
+		fmt.Println()
+		return nil
+	}
+	return errors.New(fmt.Sprintf("unknown compare format: %s", *format))
+}
+
+// writeCompareText prints one row per key with a column per partner. Rows are marked like a diff:
+// ~ where the values differ, ! where some partner has no value and a blank where they are all equal.
+func writeCompareText(w io.Writer, partnerCodes []string, keys []*pb.KeyComparison) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "  key\t%s\n", strings.Join(partnerCodes, "\t"))
+	for _, k := range keys {
+		mark := " "
+		switch k.Status {
+		case pb.KeyStatus_DIFFERENT:
+			mark = "~"
+		case pb.KeyStatus_MISSING:
+			mark = "!"
+		}
+		values := make([]string, 0, len(partnerCodes))
+		for _, code := range partnerCodes {
+			value, ok := k.Values[code]
+			if !ok {
+				value = "<missing>"
+			}
+			values = append(values, value)
+		}
+		fmt.Fprintf(tw, "%s %s\t%s\n", mark, k.Key, strings.Join(values, "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/jackc/pgx"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/config"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/export"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/pb"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/secrets"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/service"
+)
+
+// runExport streams partners from a running service through ExportPartners and writes them out.
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", export.FormatCSV, "output format: csv, json or yaml; csv cannot hold empty values")
+	group := fs.String("group", "", "only export keys in this group")
+	codes := fs.String("codes", "", "comma separated partner codes to export, all partners if empty")
+	out := fs.String("out", "", "file to write to, stdout if empty")
+	d := addDialFlags(fs)
+	fs.Parse(args)
+
+	conn, err := dial(d)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	req := &pb.ExportRequest{Group: *group}
+	if *codes != "" {
+		req.PartnerCodes = strings.Split(*codes, ",")
+	}
+	stream, err := pb.NewPartnerServiceClient(conn).ExportPartners(context.Background(), req)
+	if err != nil {
+		return errors.Wrap(err, "failed to start export")
+	}
+	var partners []*pb.Partner
+	for {
+		p, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to receive partner")
+		}
+		partners = append(partners, p)
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return errors.Wrap(err, "failed to create output file")
+		}
+		defer f.Close()
+		w = f
+	}
+	return export.Write(w, *format, partners)
+}
+
+// dialFlags are the flags export and compare connect to a running partner service with.
+type dialFlags struct {
+	addr       string
+	certPath   string
+	clientCert string
+	clientKey  string
+	token      string
+	apiKey     string
+}
+
+// addDialFlags registers the flags for connecting to a partner service on fs.
+func addDialFlags(fs *flag.FlagSet) *dialFlags {
+	d := &dialFlags{}
+	fs.StringVar(&d.addr, "addr", "localhost:8081", "gRPC address of the partner service")
+	fs.StringVar(&d.certPath, "certPath", "", "path to ssl cert file the server is verified with, plaintext if empty")
+	fs.StringVar(&d.clientCert, "clientCert", "", "path to the client cert file, for servers that verify clients")
+	fs.StringVar(&d.clientKey, "clientKey", "", "path to the key file of the client cert")
+	fs.StringVar(&d.token, "token", "", "bearer token to call with")
+	fs.StringVar(&d.apiKey, "apiKey", "", "api key to call with")
+	return d
+}
+
+// dial connects to a running partner service, over tls when a cert is given, with the credentials in d.
+func dial(d *dialFlags) (*grpc.ClientConn, error) {
+	var dopts []grpc.DialOption
+	if d.certPath != "" || d.clientCert != "" {
+		tlsConfig, err := d.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		dopts = append(dopts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		dopts = append(dopts, grpc.WithInsecure())
+	}
+	if d.token != "" || d.apiKey != "" {
+		dopts = append(dopts, grpc.WithPerRPCCredentials(callCredentials{token: d.token, apiKey: d.apiKey}))
+	}
+	conn, err := grpc.Dial(d.addr, dopts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to dial partner service")
+	}
+	return conn, nil
+}
+
+// tlsConfig verifies the server with the cert at certPath, or the system roots if there is none, and presents the
+// client cert when one is given.
+func (d *dialFlags) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+	if d.certPath != "" {
+		pem, err := ioutil.ReadFile(d.certPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read server tls cert")
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, errors.New("failed to parse server tls cert")
+		}
+		tlsConfig.RootCAs = roots
+	}
+	if d.clientCert != "" || d.clientKey != "" {
+		cert, err := tls.LoadX509KeyPair(d.clientCert, d.clientKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load client tls cert")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// callCredentials send a bearer token and an api key with every call, the way AuthMiddleware and
+// APIKeyMiddleware read them.
+type callCredentials struct {
+	token  string
+	apiKey string
+}
+
+func (c callCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	md := map[string]string{}
+	if c.token != "" {
+		md["authorization"] = "Bearer " + c.token
+	}
+	if c.apiKey != "" {
+		md["x-api-key"] = c.apiKey
+	}
+	return md, nil
+}
+
+// RequireTransportSecurity is false so a service running plaintext, like one on localhost, can still be called.
+func (c callCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// runImport reads an export and saves it straight to the database in one transaction. Like apply it is an operator
+// tool, so keys in groups with an approval policy are saved without asking for approval.
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", export.FormatCSV, "input format: csv, json or yaml")
+	in := fs.String("in", "", "file to read from, stdin if empty")
+	keyfilePath := fs.String("keyfilePath", "", "path to the keyfile that sensitive values are sealed with")
+	dbConfig, err := config.LoadDB(fs, args, os.Getenv)
+	if err != nil {
+		return err
+	}
+
+	r := io.Reader(os.Stdin)
+	if *in != "" {
+		f, err := os.Open(*in)
+		if err != nil {
+			return errors.Wrap(err, "failed to open input file")
+		}
+		defer f.Close()
+		r = f
+	}
+	partners, err := export.Read(r, *format)
+	if err != nil {
+		return err
+	}
+
+	conn, err := pgx.Connect(dbConfig.ConnConfig())
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to database")
+	}
+	defer conn.Close()
+
+	querier, err := newQuerier(conn, *keyfilePath)
+	if err != nil {
+		return err
+	}
+	//an export by a caller who could not see sensitive values has them redacted, SavePartners leaves what it is not
+	//given as it is
+	partnerModels, err := toModels(withoutRedacted(partners))
+	if err != nil {
+		return err
+	}
+	if err = querier.SavePartners(partnerModels); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "imported %d partners\n", len(partnerModels))
+	return nil
+}
+
+// withoutRedacted returns copies of the partners without the values that are secrets.Redacted.
+func withoutRedacted(partners []*pb.Partner) []*pb.Partner {
+	kept := make([]*pb.Partner, 0, len(partners))
+	for _, p := range partners {
+		attrs := make(map[string]string, len(p.Attributes))
+		for key, value := range p.Attributes {
+			if value != secrets.Redacted {
+				attrs[key] = value
+			}
+		}
+		kept = append(kept, &pb.Partner{Name: p.Name, Code: p.Code, Status: p.Status, Attributes: attrs})
+	}
+	return kept
+}
+
+// toModels converts partners read from files into the models the querier saves. A partner without a status is
+// created onboarding.
+func toModels(partners []*pb.Partner) ([]models.Partner, error) {
+	partnerModels := make([]models.Partner, 0, len(partners))
+	for _, p := range partners {
+		if p.Status != "" && !service.IsStatus(p.Status) {
+			return nil, errors.New(fmt.Sprintf("partner %s has unknown status %s", p.Code, p.Status))
+		}
+		partnerModels = append(partnerModels, models.Partner{
+			Name:       pgx.NullString{String: p.Name, Valid: true},
+			Code:       pgx.NullString{String: p.Code, Valid: true},
+			Status:     pgx.NullString{String: p.Status, Valid: p.Status != ""},
+			Attributes: p.Attributes,
+		})
+	}
+	return partnerModels, nil
+}
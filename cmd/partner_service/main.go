@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"flag"
@@ -13,32 +14,75 @@ import (
 	"syscall"
 	"time"
 
+	stdjwt "github.com/dgrijalva/jwt-go"
+	"github.com/go-kit/kit/endpoint"
 	"github.com/go-kit/kit/log"
-	"github.com/jackc/pgx"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
 	"github.com/pkg/errors"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/accesslog"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/authz"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/config"
 	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db"
-	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/dbconfig"
 	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/endpoints"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/health"
 	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/pb"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/ratelimit"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/secrets"
 	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/service"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/tracing"
 	tg "jaxf-github.fanatics.corp/apparel/partner-service/pkg/transport_grpc"
 	th "jaxf-github.fanatics.corp/apparel/partner-service/pkg/transport_http"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/version"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/watch"
 )
 
+// commands are run instead of the server when named as the first argument
+var commands = map[string]func([]string) error{
+	"export":  runExport,
+	"import":  runImport,
+	"plan":    runPlan,
+	"apply":   runApply,
+	"compare": runCompare,
+	"purge":   runPurge,
+}
+
 func main() {
-	// command line flags
-	grpcAddr := flag.String("grpcAddr", ":8081", "gRPC listen address")
-	httpAddr := flag.String("httpAddr", ":8080", "http listen address")
-	dom := flag.String("domain", "localhost", "domain name of service")
-	certPath := flag.String("certPath", "./tls/test/test.cert.pem", "path to ssl cert file")
-	keyPath := flag.String("keyPath", "./tls/test/test.key.pem", "path to ssl key file")
-	sec := flag.Bool("sec", false, "use ssl cert")
-	flag.Parse()
-
-	var config *tls.Config
-	host := fmt.Sprintf("%v%v", *dom, *grpcAddr)
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			if err := cmd(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	// settings, from flags, env and the -config file
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:], os.Getenv)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if cfg.PrintConfig {
+		if err := cfg.Redacted().Write(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	var tlsConfig *tls.Config
 
 	// set up logger
 	var logger log.Logger
@@ -48,33 +92,78 @@ func main() {
 		logger = log.With(logger, "caller", log.DefaultCaller)
 	}
 
-	// set up tls
-	if *sec {
+	// the secret files and the ssl cert are watched for changes until shutdown
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	failed := func(setting string) func(error) {
+		return func(err error) { logger.Log("watch", setting, "err", err) }
+	}
 
-		cert, err := tls.LoadX509KeyPair(*certPath, *keyPath)
+	// set up auth, with bearer tokens checked against either a JWKS file or an HMAC secret
+	var keys stdjwt.Keyfunc
+	if cfg.JWKSPath != "" {
+		var err error
+		if keys, err = endpoints.LoadJWKS(cfg.JWKSPath); err != nil {
+			logger.Log("err", err)
+			panic(err)
+		}
+	} else if cfg.JWTHMACSecretFile != "" {
+		secret, err := watch.LoadSecret(cfg.JWTHMACSecretFile)
 		if err != nil {
-			err = errors.Wrap(err, "failed to create certificate")
 			logger.Log("err", err)
 			panic(err)
 		}
+		keys = func(token *stdjwt.Token) (interface{}, error) {
+			return endpoints.HMACKeys([]byte(secret.Value()))(token)
+		}
+		go secret.Watch(watchCtx, cfg.WatchInterval, func(string) {
+			logger.Log("watch", "jwtHMACSecretFile", "msg", "secret changed")
+		}, failed("jwtHMACSecretFile"))
+	} else if cfg.JWTHMACSecret != "" {
+		keys = endpoints.HMACKeys([]byte(cfg.JWTHMACSecret))
+	}
 
-		pem, err := ioutil.ReadFile(*certPath)
+	// set up tracing
+	if cfg.TracePath != "" {
+		stopTracing, err := tracing.Start(cfg.TracePath)
 		if err != nil {
-			err = errors.Wrap(err, "failed to create pem")
 			logger.Log("err", err)
 			panic(err)
 		}
+		defer stopTracing(context.Background())
+	}
+
+	// set up authorization
+	policy, err := authz.LoadPolicy(cfg.PolicyPath)
+	if err != nil {
+		logger.Log("err", err)
+		panic(err)
+	}
+
+	// set up rate limits
+	var rateLimits ratelimit.Config
+	if cfg.RateLimitPath != "" {
+		if rateLimits, err = ratelimit.LoadConfig(cfg.RateLimitPath); err != nil {
+			logger.Log("err", err)
+			panic(err)
+		}
+	}
+	limiter := ratelimit.NewLimiter(rateLimits)
+
+	// set up tls
+	var grpcOptions []grpctransport.ServerOption
+	if cfg.Sec {
 
-		certPool := x509.NewCertPool()
-		ok := certPool.AppendCertsFromPEM(pem)
-		if !ok {
-			err = errors.New("failed to append cert from pem")
+		// the cert and key are loaded again when they change, for each new connection to be served the current pair
+		keyPair, err := watch.LoadKeyPair(cfg.CertPath, cfg.KeyPath)
+		if err != nil {
 			logger.Log("err", err)
 			panic(err)
 		}
+		go keyPair.Watch(watchCtx, cfg.WatchInterval, func(leaf *x509.Certificate) {
+			logger.Log("watch", "certPath", "msg", "certificate changed", "expires", leaf.NotAfter)
+		}, failed("certPath"))
 
-		config = &tls.Config{
-			ServerName:               host,
+		tlsConfig = &tls.Config{
 			PreferServerCipherSuites: true,
 			MinVersion:               tls.VersionTLS12,
 			CurvePreferences: []tls.CurveID{
@@ -89,25 +178,194 @@ func main() {
 				tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
 				tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
 			},
-			Certificates: []tls.Certificate{cert},
-			RootCAs:      certPool,
+			GetCertificate: keyPair.GetCertificate,
+		}
+
+		// with mutual tls a client certificate names the caller, and callers without one can still send a token
+		if cfg.ClientCAPath != "" {
+			caPem, err := ioutil.ReadFile(cfg.ClientCAPath)
+			if err != nil {
+				err = errors.Wrap(err, "failed to read client CA pem")
+				logger.Log("err", err)
+				panic(err)
+			}
+			clientCAs := x509.NewCertPool()
+			if !clientCAs.AppendCertsFromPEM(caPem) {
+				err = errors.New("failed to append client CA certs from pem")
+				logger.Log("err", err)
+				panic(err)
+			}
+			tlsConfig.ClientCAs = clientCAs
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+
+			// the http gateway passes on the subject of the http client's cert
+			grpcOptions = append(grpcOptions, grpctransport.ServerBefore(tg.CallerFromPeer))
 		}
 	}
 
-	// set up db
-	conn, err := pgx.Connect(dbconfig.ExtractConfig())
+	// set up db, with a pool that is reconnected when the password in dbPasswordFile changes
+	var password *watch.Secret
+	if cfg.DB.PasswordFile != "" {
+		if password, err = watch.LoadSecret(cfg.DB.PasswordFile); err != nil {
+			logger.Log("err", err)
+			panic(err)
+		}
+		cfg.DB.Password = password.Value()
+	}
+	conn, err := db.NewPool(cfg.DB.ConnConfig(), cfg.DB.MaxConcurrent)
+	if err != nil {
+		logger.Log("err", err)
+		panic(err)
+	}
+	if password != nil {
+		go password.Watch(watchCtx, cfg.WatchInterval, func(value string) {
+			settings := cfg.DB
+			settings.Password = value
+			if err := conn.Recycle(settings.ConnConfig()); err != nil {
+				logger.Log("watch", "dbPasswordFile", "err", err)
+				return
+			}
+			logger.Log("watch", "dbPasswordFile", "msg", "reconnected to database")
+		}, failed("dbPasswordFile"))
+	}
+
+	// set up metrics, served at /metrics
+	fieldKeys := []string{"method", "transport", "code", "error"}
+	requests := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "partner_service",
+		Name:      "requests_total",
+		Help:      "Number of requests received.",
+	}, fieldKeys)
+	duration := kitprometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		Namespace: "partner_service",
+		Name:      "request_duration_seconds",
+		Help:      "Time spent serving requests.",
+	}, fieldKeys)
+	queryDuration := kitprometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		Namespace: "partner_service",
+		Name:      "db_query_duration_seconds",
+		Help:      "Time spent on each database query.",
+	}, []string{"query", "error"})
+	gauges := db.BreakerGauges{
+		InFlight: kitprometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: "partner_service",
+			Name:      "db_calls_in_flight",
+			Help:      "Number of calls using the database.",
+		}, []string{}),
+		Waiting: kitprometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: "partner_service",
+			Name:      "db_calls_waiting",
+			Help:      "Number of calls waiting to use the database.",
+		}, []string{}),
+		State: kitprometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: "partner_service",
+			Name:      "db_breaker_state",
+			Help:      "State of the database circuit breaker: 0 closed, 1 open, 2 half open.",
+		}, []string{}),
+	}
+	cacheLookups := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "partner_service",
+		Name:      "cache_lookups_total",
+		Help:      "Number of cache lookups, by whether they were hits or misses.",
+	}, []string{"cache", "result"})
+
+	// calls fail fast with Unavailable while the database is unhealthy
+	breaker := cfg.DB.BreakerConfig()
+	querier, err := newQuerier(conn, cfg.KeyfilePath,
+		func(q db.PartnerServiceQuerier) db.PartnerServiceQuerier {
+			return db.NewInstrumentingQuerier(q, queryDuration)
+		},
+		func(q db.PartnerServiceQuerier) db.PartnerServiceQuerier {
+			return db.NewBreakerQuerier(q, breaker, gauges)
+		},
+	)
 	if err != nil {
-		err = errors.Wrap(err, "failed to connect to database")
+		logger.Log("err", err)
 		panic(err)
 	}
-	defer conn.Close()
 
-	// Make service and endpoints
-	svc := service.New(logger, db.NewPartnerServiceQuerier(conn))
-	eps := endpoints.New(svc, logger)
+	// Make service and endpoints, finding which keys are sensitive at most once a minute
+	sensitive := secrets.NewSensitiveKeys(querier, time.Minute).Instrument(cacheLookups.With("cache", "sensitive_keys"))
+	recorder := accesslog.New(querier, cfg.AccessLogBuffer, logger)
+	svc := service.New(logger, querier, sensitive)
+	// callers who send an API key are named by it before the token or certificate is checked
+	auth := endpoint.Chain(endpoints.APIKeyMiddleware(querier), endpoints.AuthMiddleware(keys))
+	eps := endpoints.New(svc, logger, sensitive, auth, endpoints.AuthorizationMiddleware(policy, querier, sensitive),
+		endpoints.AccessLogMiddleware(recorder, sensitive), limiter, querier, cfg.IdempotencyWindow, requests, duration)
 
-	// Mechanical domain.
-	errc := make(chan error)
+	// the grpc health service reports whether the database answers with the schema this code works with
+	healthServer := grpchealth.NewServer()
+	checker := health.NewChecker(querier, db.SchemaVersion)
+	go checker.Watch(watchCtx, healthServer, cfg.ReadyInterval)
+
+	// gRPC servers, with every call traced, as part of the http request's trace for calls from the gateway. The gateway
+	// calls a server of its own in process, without tls.
+	newGRPCServer := func(opts ...grpc.ServerOption) *grpc.Server {
+		s := grpc.NewServer(append(opts,
+			grpc.UnaryInterceptor(tg.TracingUnaryInterceptor),
+			grpc.StreamInterceptor(tg.TracingStreamInterceptor),
+		)...)
+		pb.RegisterPartnerServiceServer(s, tg.MakeGRPCServer(eps, logger, grpcOptions...))
+		return s
+	}
+	var creds []grpc.ServerOption
+	if cfg.Sec {
+		creds = append(creds, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	grpcServer := newGRPCServer(creds...)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	gatewayServer := newGRPCServer()
+	inProcess := tg.NewInProcessListener()
+	gatewayConn, err := grpc.Dial(inProcess.Addr().String(), grpc.WithInsecure(), grpc.WithDialer(inProcess.Dial))
+	if err != nil {
+		err = errors.Wrap(err, "failed to dial in-process gRPC server")
+		logger.Log("err", err)
+		panic(err)
+	}
+
+	// HTTP server, the gateway to the gRPC server along with the routes for operating the service
+	h, err := th.MakeHTTPHandler(gatewayConn, log.With(logger, "transport", "HTTP"))
+	if err != nil {
+		err = errors.Wrap(err, "failed to create new http handler")
+		logger.Log("err", err)
+		panic(err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/healthz", health.LivenessHandler())
+	mux.Handle("/readyz", health.ReadinessHandler(checker))
+	mux.Handle("/api/v1/version", version.Handler())
+	mux.Handle("/", h)
+	httpServer := &http.Server{
+		Addr:         cfg.HTTPAddr,
+		Handler:      mux,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		IdleTimeout:  120 * time.Second,
+		TLSConfig:    tlsConfig,
+	}
+	// with one address for both, gRPC calls are told apart from http requests and served on the same port
+	sharedPort := cfg.GRPCAddr == cfg.HTTPAddr
+	var grpcHandler *th.GRPCHandler
+	if sharedPort {
+		grpcHandler = th.GRPC(grpcServer, mux)
+		httpServer.Handler = grpcHandler
+	}
+
+	// the operator routes are served on an address of their own, kept off the public network. The counts of calls each
+	// client made, and how many were over the rate limit, are at /debug/ratelimit
+	adminMux := http.NewServeMux()
+	adminMux.Handle("/debug/ratelimit", ratelimit.CountsHandler(limiter))
+	adminServer := &http.Server{
+		Addr:         cfg.AdminAddr,
+		Handler:      adminMux,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		IdleTimeout:  120 * time.Second,
+	}
+
+	// Mechanical domain, with room for every goroutine to report why it stopped without blocking.
+	errc := make(chan error, 5)
 
 	// Interrupt handler.
 	go func() {
@@ -117,63 +375,122 @@ func main() {
 	}()
 
 	go func() {
-		ln, err := net.Listen("tcp", *grpcAddr)
-		if err != nil {
-			errc <- err
-			return
-		}
+		errc <- gatewayServer.Serve(inProcess)
+	}()
 
-		srv := tg.MakeGRPCServer(eps, logger)
-		var opts []grpc.ServerOption
-		if *sec {
-			creds := credentials.NewTLS(config)
-			fmt.Printf("%+v", &creds)
+	if !sharedPort {
+		go func() {
+			ln, err := net.Listen("tcp", cfg.GRPCAddr)
 			if err != nil {
-				err = errors.Wrap(err, "failed to create new server tls from file")
-				logger.Log("err", err)
-				panic(err)
+				errc <- err
+				return
 			}
-			opts = append(opts, grpc.Creds(creds))
-		}
-		s := grpc.NewServer(opts...)
-		pb.RegisterPartnerServiceServer(s, srv)
-		logger.Log("success", "it works")
-		logger.Log("addr", *grpcAddr)
-		errc <- s.Serve(ln)
-	}()
+			logger.Log("transport", "gRPC", "addr", cfg.GRPCAddr)
+			errc <- grpcServer.Serve(ln)
+		}()
+	}
 
-	// HTTP transport.
 	go func() {
-		var dopts []grpc.DialOption
-		if *sec {
-			dcreds := credentials.NewTLS(config)
-			dopts = append(dopts, grpc.WithTransportCredentials(dcreds))
-		} else {
-			dopts = append(dopts, grpc.WithInsecure())
-		}
-		logger := log.With(logger, "transport", "HTTP")
-
-		h, err := th.MakeHTTPHandler(host, dopts, logger)
-		if err != nil {
-			err = errors.Wrap(err, "failed to create new http handler")
-			logger.Log("err", err)
-			panic(err)
-		}
-		httpServer := &http.Server{
-			Addr:         *httpAddr,
-			Handler:      h,
-			ReadTimeout:  5 * time.Second,
-			WriteTimeout: 10 * time.Second,
-			IdleTimeout:  120 * time.Second,
-		}
-		if *sec {
-			httpServer.TLSConfig = config
+		logger.Log("transport", "HTTP", "addr", cfg.HTTPAddr)
+		if cfg.Sec {
 			errc <- httpServer.ListenAndServeTLS("", "")
 		} else {
 			errc <- httpServer.ListenAndServe()
 		}
 	}()
 
+	if cfg.AdminAddr != "" {
+		go func() {
+			logger.Log("transport", "admin", "addr", cfg.AdminAddr)
+			errc <- adminServer.ListenAndServe()
+		}()
+	}
+
 	// Run!
 	logger.Log("exit", <-errc)
+
+	// Shut down in order: stop taking new calls, let the ones in flight finish, then close what they were using.
+	stopWatching()
+	checker.Shutdown(healthServer)
+	logger.Log("shutdown", "not ready", "delay", cfg.ShutdownDelay)
+	time.Sleep(cfg.ShutdownDelay)
+
+	if sharedPort {
+		// the gRPC calls came through the http server, so they are drained with it and the server is only stopped after
+		if !drainShared(httpServer, grpcHandler, cfg.HTTPDrainTimeout, cfg.GRPCDrainTimeout, logger) {
+			logger.Log("shutdown", "gRPC", "err", "calls still in flight were cut off")
+		}
+		grpcServer.Stop()
+	} else {
+		if !stopGRPC(grpcServer, cfg.GRPCDrainTimeout) {
+			logger.Log("shutdown", "gRPC", "err", "calls still in flight were cut off")
+		}
+		shutdownHTTP(httpServer, cfg.HTTPDrainTimeout, logger)
+	}
+	if cfg.AdminAddr != "" {
+		shutdownHTTP(adminServer, cfg.HTTPDrainTimeout, logger)
+	}
+	// the gateway's calls are over once its requests are
+	gatewayConn.Close()
+	gatewayServer.Stop()
+
+	recorder.Close()
+	conn.Close()
+	logger.Log("shutdown", "done")
+}
+
+// shutdownHTTP stops the server from taking new requests and waits for the ones in flight to finish, for up to
+// timeout, before cutting them off.
+func shutdownHTTP(s *http.Server, timeout time.Duration, logger log.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		logger.Log("shutdown", "HTTP", "err", errors.Wrap(err, "requests still in flight were cut off"))
+		s.Close()
+	}
+}
+
+// drainShared shuts down a server that takes gRPC calls on the same port as http requests. Shutdown does not wait for
+// the gRPC calls, so they are drained through the handler, for up to grpcTimeout, before the connections they are on
+// are closed. It returns whether they all finished.
+func drainShared(s *http.Server, h *th.GRPCHandler, httpTimeout, grpcTimeout time.Duration, logger log.Logger) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), grpcTimeout)
+	defer cancel()
+	drained := h.Drain(ctx) == nil
+	shutdownHTTP(s, httpTimeout, logger)
+	return drained
+}
+
+// stopGRPC stops the server from taking new calls and waits for the ones in flight to finish, for up to timeout,
+// before cutting them off. It returns whether they all finished.
+func stopGRPC(s *grpc.Server, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		s.Stop()
+		return false
+	}
+}
+
+// newQuerier returns a querier for the database that seals the values of sensitive keys with the keyfile, if one is given.
+// The wrappers go between the two, innermost first.
+func newQuerier(conn db.Conn, keyfilePath string, wrappers ...func(db.PartnerServiceQuerier) db.PartnerServiceQuerier) (db.PartnerServiceQuerier, error) {
+	var keyring *secrets.Keyring
+	if keyfilePath != "" {
+		var err error
+		if keyring, err = secrets.LoadKeyfile(keyfilePath); err != nil {
+			return nil, err
+		}
+	}
+	querier := db.NewPartnerServiceQuerier(conn)
+	for _, wrap := range wrappers {
+		querier = wrap(querier)
+	}
+	return db.NewSealingQuerier(querier, keyring), nil
 }
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jackc/pgx"
+	"github.com/pkg/errors"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/config"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/pb"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/plan"
+)
+
+// runPlan prints what apply would change without touching the database.
+func runPlan(args []string) error {
+	fs := flag.NewFlagSet("plan", flag.ExitOnError)
+	dir := fs.String("dir", "./partners", "directory of partner yaml files, one partner per file")
+	prune := fs.Bool("prune", false, "delete partners that do not have a file")
+	keyfilePath := fs.String("keyfilePath", "", "path to the keyfile that sensitive values are sealed with")
+	dbConfig, err := config.LoadDB(fs, args, os.Getenv)
+	if err != nil {
+		return err
+	}
+
+	conn, err := pgx.Connect(dbConfig.ConnConfig())
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to database")
+	}
+	defer conn.Close()
+
+	querier, err := newQuerier(conn, *keyfilePath)
+	if err != nil {
+		return err
+	}
+	p, _, err := makePlan(querier, *dir, *prune)
+	if err != nil {
+		return err
+	}
+	return p.Write(os.Stdout)
+}
+
+// runApply prints the plan, asks for confirmation and applies it in one transaction. Partners changed since the plan
+// was read fail the apply rather than being overwritten. Approval policies do not apply, as with import.
+func runApply(args []string) error {
+	fs := flag.NewFlagSet("apply", flag.ExitOnError)
+	dir := fs.String("dir", "./partners", "directory of partner yaml files, one partner per file")
+	prune := fs.Bool("prune", false, "delete partners that do not have a file")
+	autoApprove := fs.Bool("auto-approve", false, "apply without asking for confirmation")
+	keyfilePath := fs.String("keyfilePath", "", "path to the keyfile that sensitive values are sealed with")
+	dbConfig, err := config.LoadDB(fs, args, os.Getenv)
+	if err != nil {
+		return err
+	}
+
+	conn, err := pgx.Connect(dbConfig.ConnConfig())
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to database")
+	}
+	defer conn.Close()
+	querier, err := newQuerier(conn, *keyfilePath)
+	if err != nil {
+		return err
+	}
+
+	p, revisions, err := makePlan(querier, *dir, *prune)
+	if err != nil {
+		return err
+	}
+	if err = p.Write(os.Stdout); err != nil {
+		return err
+	}
+	if p.Empty() {
+		return nil
+	}
+
+	if !*autoApprove {
+		fmt.Print("\nOnly 'yes' will be accepted to apply these changes: ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.TrimSpace(answer) != "yes" {
+			return errors.New("apply cancelled")
+		}
+	}
+
+	saves, err := toModels(p.Saves())
+	if err != nil {
+		return err
+	}
+	//a partner the plan creates is at revision 0, so the apply fails if it has been created since
+	for i := range saves {
+		saves[i].Revision = pgx.NullInt32{Int32: 0, Valid: true}
+		if revision, ok := revisions[saves[i].Code.String]; ok {
+			saves[i].Revision = revision
+		}
+	}
+	deletes := make([]models.Partner, 0, len(p.Deletes()))
+	for _, code := range p.Deletes() {
+		deletes = append(deletes, models.Partner{Code: pgx.NullString{String: code, Valid: true}, Revision: revisions[code]})
+	}
+	if err = querier.ApplyPartners(saves, deletes); err != nil {
+		return err
+	}
+	fmt.Println("Apply complete.")
+	return nil
+}
+
+// makePlan diffs the partner files in dir against every partner in the database. It also returns the revision each
+// live partner was read at, by code, for the apply to check.
+func makePlan(querier db.PartnerServiceQuerier, dir string, prune bool) (plan.Plan, map[string]pgx.NullInt32, error) {
+	desired, err := plan.LoadDir(dir)
+	if err != nil {
+		return plan.Plan{}, nil, err
+	}
+	liveModels, err := querier.FindPartners(nil, "")
+	if err != nil {
+		return plan.Plan{}, nil, err
+	}
+	live := make([]*pb.Partner, 0, len(liveModels))
+	revisions := make(map[string]pgx.NullInt32, len(liveModels))
+	for _, m := range liveModels {
+		live = append(live, m.Gen(m.Attributes))
+		revisions[m.Code.String] = m.Revision
+	}
+	p, err := plan.Diff(desired, live, prune)
+	return p, revisions, err
+}
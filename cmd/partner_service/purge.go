@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jackc/pgx"
+	"github.com/pkg/errors"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/config"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db"
+)
+
+// runPurge permanently removes partners, keys, groups and attributes that were deleted longer ago than the retention window.
+// Until then they can be brought back with the Restore methods. Idempotency keys older than their window are removed too.
+func runPurge(args []string) error {
+	fs := flag.NewFlagSet("purge", flag.ExitOnError)
+	retention := fs.Duration("retention", 90*24*time.Hour, "how long deleted rows are kept before they are purged")
+	idempotencyWindow := fs.Duration("idempotencyWindow", 24*time.Hour, "how long idempotency keys are kept before they are purged")
+	dbConfig, err := config.LoadDB(fs, args, os.Getenv)
+	if err != nil {
+		return err
+	}
+
+	if *retention < 0 {
+		return errors.New("retention cannot be negative")
+	}
+	if *idempotencyWindow < 0 {
+		return errors.New("idempotencyWindow cannot be negative")
+	}
+
+	conn, err := pgx.Connect(dbConfig.ConnConfig())
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to database")
+	}
+	defer conn.Close()
+
+	querier := db.NewPartnerServiceQuerier(conn)
+	before := time.Now().Add(-*retention)
+	purged, err := querier.PurgeDeleted(before)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Purged %d rows deleted before %s.\n", purged, before.Format(time.RFC3339))
+
+	keysBefore := time.Now().Add(-*idempotencyWindow)
+	purged, err = querier.PurgeIdempotencyKeys(keysBefore)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Purged %d idempotency keys used before %s.\n", purged, keysBefore.Format(time.RFC3339))
+	return nil
+}
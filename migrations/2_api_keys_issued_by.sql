@@ -0,0 +1,13 @@
+-- Brings a database at schema version 1 up to 2: API keys record the caller who issued them.
+-- Keys issued before this were not recorded, so they are marked as issued by 'unknown'. Their scope was not checked
+-- against the issuer's roles either, so review them with GET /ws/v1/api-keys and revoke any that grant too much.
+
+BEGIN;
+
+ALTER TABLE api_keys ADD COLUMN issued_by varchar;
+UPDATE api_keys SET issued_by = 'unknown' WHERE issued_by IS NULL;
+ALTER TABLE api_keys ALTER COLUMN issued_by SET NOT NULL;
+
+INSERT INTO schema_version (version) VALUES (2);
+
+COMMIT;
@@ -0,0 +1,11 @@
+-- Brings a database at schema version 2 up to 3: a key name can only be in use by one live key.
+-- Live keys that share a name have to be merged or deleted first, or the index cannot be made. They are listed by:
+--   SELECT name, count(*) FROM keys WHERE deleted_at IS NULL GROUP BY name HAVING count(*) > 1;
+
+BEGIN;
+
+CREATE UNIQUE INDEX keys_live_name ON keys (name) WHERE deleted_at IS NULL;
+
+INSERT INTO schema_version (version) VALUES (3);
+
+COMMIT;
@@ -0,0 +1,88 @@
+package accesslog
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+)
+
+// maxBatch is the most records written to the store at once.
+const maxBatch = 100
+
+// Store appends records to the access log. The db querier is one.
+type Store interface {
+	RecordAccess([]models.AccessRecord) error
+}
+
+// Recorder writes access records to the store in the background, so recording a read does not wait on the database.
+// Records wait in a buffer of a fixed size. When the buffer is full, or the store fails, records are logged instead
+// of being lost without a trace.
+type Recorder struct {
+	store   Store
+	logger  log.Logger
+	records chan models.AccessRecord
+	done    chan struct{}
+	once    sync.Once
+}
+
+// New starts a recorder with a buffer for size records. Close it to write the records still in the buffer.
+func New(store Store, size int, logger log.Logger) *Recorder {
+	r := &Recorder{
+		store:   store,
+		logger:  logger,
+		records: make(chan models.AccessRecord, size),
+		done:    make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// Record queues the record to be written, without waiting. The time of the read is set if it is not already.
+func (r *Recorder) Record(record models.AccessRecord) {
+	if !record.ReadAt.Valid {
+		record.ReadAt.Time, record.ReadAt.Valid = time.Now(), true
+	}
+	select {
+	case r.records <- record:
+	default:
+		r.logDropped("access log buffer is full", record)
+	}
+}
+
+// Close stops taking records and returns once the ones in the buffer are written. Record must not be called after.
+func (r *Recorder) Close() {
+	r.once.Do(func() { close(r.records) })
+	<-r.done
+}
+
+func (r *Recorder) run() {
+	defer close(r.done)
+	for record := range r.records {
+		batch := []models.AccessRecord{record}
+	fill:
+		for len(batch) < maxBatch {
+			select {
+			case next, ok := <-r.records:
+				if !ok {
+					break fill
+				}
+				batch = append(batch, next)
+			default:
+				break fill
+			}
+		}
+		if err := r.store.RecordAccess(batch); err != nil {
+			for _, dropped := range batch {
+				r.logDropped(err.Error(), dropped)
+			}
+		}
+	}
+}
+
+func (r *Recorder) logDropped(reason string, record models.AccessRecord) {
+	r.logger.Log("err", "access record not written: "+reason, "caller", record.Caller.String, "partner", record.PartnerCode.String,
+		"keys", strings.Join(record.Keys, ","), "requestId", record.RequestId.String, "readAt", record.ReadAt.Time)
+}
@@ -0,0 +1,110 @@
+package accesslog
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/jackc/pgx"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+)
+
+//memoryStore keeps the batches it is given, or fails with err. Writes wait for block to close, if there is one.
+type memoryStore struct {
+	mu      sync.Mutex
+	batches [][]models.AccessRecord
+	err     error
+	block   chan struct{}
+}
+
+func (s *memoryStore) RecordAccess(records []models.AccessRecord) error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.batches = append(s.batches, records)
+	return nil
+}
+
+func (s *memoryStore) records() []models.AccessRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var records []models.AccessRecord
+	for _, batch := range s.batches {
+		records = append(records, batch...)
+	}
+	return records
+}
+
+func record(code string) models.AccessRecord {
+	return models.AccessRecord{
+		Caller:      pgx.NullString{String: "jdoe", Valid: true},
+		PartnerCode: pgx.NullString{String: code, Valid: true},
+		Keys:        []string{"AS2 Password", "Bank Account"},
+	}
+}
+
+func TestRecorderWritesOnClose(t *testing.T) {
+	a := assert.New(t)
+	store := &memoryStore{}
+	r := New(store, 10, log.NewNopLogger())
+
+	r.Record(record("KOH"))
+	r.Record(record("JCP"))
+	r.Close()
+
+	records := store.records()
+	a.Equal(2, len(records))
+	a.Equal("KOH", records[0].PartnerCode.String)
+	a.Equal("JCP", records[1].PartnerCode.String)
+	a.True(records[0].ReadAt.Valid)
+}
+
+func TestRecorderLogsWhenFull(t *testing.T) {
+	a := assert.New(t)
+	var buf bytes.Buffer
+	store := &memoryStore{block: make(chan struct{})}
+	r := New(store, 1, log.NewLogfmtLogger(&buf))
+
+	// the writer takes the first record and waits on the store, the second fills the buffer and the third is dropped
+	r.Record(record("KOH"))
+	for len(r.records) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	r.Record(record("JCP"))
+	r.Record(record("DIC"))
+	close(store.block)
+	r.Close()
+
+	a.Equal(2, len(store.records()))
+	a.Contains(buf.String(), "access log buffer is full")
+	a.Contains(buf.String(), "partner=DIC")
+	a.Contains(buf.String(), `keys="AS2 Password,Bank Account"`)
+}
+
+func TestRecorderLogsStoreErrors(t *testing.T) {
+	a := assert.New(t)
+	var buf bytes.Buffer
+	store := &memoryStore{err: errors.New("connection refused")}
+	r := New(store, 10, log.NewLogfmtLogger(&buf))
+
+	r.Record(record("KOH"))
+	r.Close()
+
+	a.Contains(buf.String(), "connection refused")
+	a.Contains(buf.String(), "partner=KOH")
+}
+
+func TestRecorderCloseTwice(t *testing.T) {
+	r := New(&memoryStore{}, 10, log.NewNopLogger())
+	r.Close()
+	r.Close()
+}
@@ -0,0 +1,43 @@
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Prefix starts every key, so keys are easy to spot in config files and in text they leak into.
+const Prefix = "psk_"
+
+// Generate makes a new key. Only its hash is stored, along with its prefix, which names the key in lists without
+// giving it away. The key itself is shown once, to whoever it is issued to.
+func Generate() (key, prefix, hash string, err error) {
+	id := make([]byte, 4)
+	secret := make([]byte, 32)
+	if _, err = io.ReadFull(rand.Reader, id); err != nil {
+		return "", "", "", errors.Wrap(err, "failed to make key id")
+	}
+	if _, err = io.ReadFull(rand.Reader, secret); err != nil {
+		return "", "", "", errors.Wrap(err, "failed to make key secret")
+	}
+	prefix = Prefix + hex.EncodeToString(id)
+	key = prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return key, prefix, Hash(key), nil
+}
+
+// Hash returns the hash a key is stored and found by. Keys are random, so a fast hash is enough to keep a stolen
+// table of hashes from being turned back into keys.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// LooksLikeKey reports whether the value has the shape of a key, to reject junk before it is looked up.
+func LooksLikeKey(value string) bool {
+	return strings.HasPrefix(value, Prefix) && strings.Count(value, "_") >= 2
+}
@@ -0,0 +1,37 @@
+package apikeys
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	a := assert.New(t)
+
+	key, prefix, hash, err := Generate()
+
+	a.Nil(err)
+	a.True(strings.HasPrefix(key, prefix+"_"))
+	a.True(strings.HasPrefix(prefix, Prefix))
+	a.Equal(Hash(key), hash)
+	a.True(LooksLikeKey(key))
+	a.False(strings.Contains(hash, key))
+}
+
+func TestGenerateUnique(t *testing.T) {
+	first, firstPrefix, _, _ := Generate()
+	second, secondPrefix, _, _ := Generate()
+
+	assert.NotEqual(t, first, second)
+	assert.NotEqual(t, firstPrefix, secondPrefix)
+}
+
+func TestLooksLikeKey(t *testing.T) {
+	a := assert.New(t)
+
+	a.False(LooksLikeKey(""))
+	a.False(LooksLikeKey("hunter2"))
+	a.False(LooksLikeKey("psk_abcdef12"))
+}
@@ -0,0 +1,165 @@
+package authz
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// AllGroups stands for every group in a grant, including keys that are in no group.
+const AllGroups = "*"
+
+// Grant is the groups a role can read and write, and the groups whose sensitive values it can see. Writing a group
+// implies reading it, but not seeing its sensitive values. Audit lets the role read the access log.
+type Grant struct {
+	Read      []string `yaml:"read"`
+	Write     []string `yaml:"write"`
+	Sensitive []string `yaml:"sensitive"`
+	Audit     bool     `yaml:"audit"`
+}
+
+// Policy maps roles to the groups they can read and write. Callers get their roles from their token, or else from
+// Callers, which is how callers with a client certificate get theirs, or else DefaultRoles.
+type Policy struct {
+	Roles        map[string]Grant    `yaml:"roles"`
+	Callers      map[string][]string `yaml:"callers"`
+	DefaultRoles []string            `yaml:"defaultRoles"`
+}
+
+// LoadPolicy reads a policy from a YAML file. Every role a caller can be given must be defined.
+func LoadPolicy(path string) (Policy, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Policy{}, errors.Wrap(err, fmt.Sprintf("failed to read policy file: %s", path))
+	}
+	var policy Policy
+	if err = yaml.Unmarshal(b, &policy); err != nil {
+		return Policy{}, errors.Wrap(err, fmt.Sprintf("failed to parse policy file: %s", path))
+	}
+	for _, role := range policy.DefaultRoles {
+		if _, ok := policy.Roles[role]; !ok {
+			return Policy{}, errors.New(fmt.Sprintf("default role %s is not defined in policy file: %s", role, path))
+		}
+	}
+	for caller, roles := range policy.Callers {
+		for _, role := range roles {
+			if _, ok := policy.Roles[role]; !ok {
+				return Policy{}, errors.New(fmt.Sprintf("role %s of caller %s is not defined in policy file: %s", role, caller, path))
+			}
+		}
+	}
+	return policy, nil
+}
+
+// CallerRoles returns the roles of a caller, which are the roles from their token if it gave them any.
+func (p Policy) CallerRoles(caller string, roles []string) []string {
+	if len(roles) == 0 {
+		roles = p.Callers[caller]
+	}
+	if len(roles) == 0 {
+		roles = p.DefaultRoles
+	}
+	return roles
+}
+
+// Access returns what a caller with the roles from their token can read and write. Roles the policy does not define
+// grant nothing.
+func (p Policy) Access(caller string, roles []string) Access {
+	roles = p.CallerRoles(caller, roles)
+
+	access := Access{read: make(map[string]bool), write: make(map[string]bool), sensitive: make(map[string]bool)}
+	for _, role := range roles {
+		grant := p.Roles[role]
+		for _, group := range grant.Read {
+			access.read[group] = true
+		}
+		for _, group := range grant.Write {
+			access.read[group] = true
+			access.write[group] = true
+		}
+		for _, group := range grant.Sensitive {
+			access.sensitive[group] = true
+		}
+		access.audit = access.audit || grant.Audit
+	}
+	return access
+}
+
+// Access is the groups one caller can read and write.
+type Access struct {
+	read      map[string]bool
+	write     map[string]bool
+	sensitive map[string]bool
+	audit     bool
+}
+
+func (a Access) CanRead(group string) bool {
+	return a.read[AllGroups] || a.read[group]
+}
+
+func (a Access) CanWrite(group string) bool {
+	return a.write[AllGroups] || a.write[group]
+}
+
+func (a Access) CanReadAny() bool {
+	return len(a.read) > 0
+}
+
+func (a Access) CanWriteAny() bool {
+	return len(a.write) > 0
+}
+
+func (a Access) CanWriteAll() bool {
+	return a.write[AllGroups]
+}
+
+// CanAudit reports whether the access log, which says who saw which sensitive values, can be read.
+func (a Access) CanAudit() bool {
+	return a.audit
+}
+
+// CanReadKey reports whether a key in the groups can be read, which it can if any of its groups can be.
+func (a Access) CanReadKey(groups []string) bool {
+	if a.read[AllGroups] {
+		return true
+	}
+	for _, group := range groups {
+		if a.read[group] {
+			return true
+		}
+	}
+	return false
+}
+
+// CanWriteKey reports whether a key in the groups can be written, which it can only if all of its groups can be,
+// since writing it changes every group it is in.
+func (a Access) CanWriteKey(groups []string) bool {
+	if a.write[AllGroups] {
+		return true
+	}
+	for _, group := range groups {
+		if !a.write[group] {
+			return false
+		}
+	}
+	return len(groups) > 0
+}
+
+// CanSeeSensitive reports whether the sensitive value of a key in the groups can be seen, which it can if the key can
+// be read and any of its groups grants seeing sensitive values.
+func (a Access) CanSeeSensitive(groups []string) bool {
+	if !a.CanReadKey(groups) {
+		return false
+	}
+	if a.sensitive[AllGroups] {
+		return true
+	}
+	for _, group := range groups {
+		if a.sensitive[group] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package authz
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testPolicy() Policy {
+	return Policy{
+		Roles: map[string]Grant{
+			"admin":   {Write: []string{AllGroups}},
+			"edi":     {Write: []string{"EDI"}},
+			"finance": {Read: []string{"EDI"}, Write: []string{"Money"}},
+			"reader":  {Read: []string{AllGroups}},
+		},
+		Callers:      map[string][]string{"onboarding-pipeline": {"edi"}},
+		DefaultRoles: []string{"reader"},
+	}
+}
+
+func writePolicy(t *testing.T, contents string) string {
+	file, err := ioutil.TempFile("", "policy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(contents)
+	file.Close()
+	return file.Name()
+}
+
+func TestLoadPolicy(t *testing.T) {
+	a := assert.New(t)
+	path := writePolicy(t, "roles:\n  edi:\n    write: [EDI]\n  reader:\n    read: ['*']\ncallers:\n  onboarding-pipeline: [edi]\ndefaultRoles: [reader]\n")
+	defer os.Remove(path)
+
+	policy, err := LoadPolicy(path)
+
+	a.Nil(err)
+	a.Equal(Grant{Write: []string{"EDI"}}, policy.Roles["edi"])
+	a.Equal([]string{"edi"}, policy.Callers["onboarding-pipeline"])
+	a.Equal([]string{"reader"}, policy.DefaultRoles)
+}
+
+func TestLoadPolicyUndefinedRole(t *testing.T) {
+	path := writePolicy(t, "roles:\n  edi:\n    write: [EDI]\ncallers:\n  onboarding-pipeline: [finance]\n")
+	defer os.Remove(path)
+
+	_, err := LoadPolicy(path)
+
+	assert.NotNil(t, err)
+}
+
+func TestLoadPolicyMissing(t *testing.T) {
+	_, err := LoadPolicy("./does-not-exist.yaml")
+
+	assert.NotNil(t, err)
+}
+
+func TestAccessFromTokenRoles(t *testing.T) {
+	a := assert.New(t)
+
+	access := testPolicy().Access("jdoe", []string{"finance"})
+
+	a.True(access.CanRead("EDI"))
+	a.True(access.CanRead("Money"))
+	a.False(access.CanRead("Style"))
+	a.True(access.CanWrite("Money"))
+	a.False(access.CanWrite("EDI"))
+	a.False(access.CanWriteAll())
+}
+
+func TestAccessFromCaller(t *testing.T) {
+	a := assert.New(t)
+
+	access := testPolicy().Access("onboarding-pipeline", nil)
+
+	a.True(access.CanWrite("EDI"))
+	a.True(access.CanRead("EDI"))
+	a.False(access.CanRead("Money"))
+}
+
+func TestAccessDefault(t *testing.T) {
+	a := assert.New(t)
+
+	access := testPolicy().Access("jdoe", nil)
+
+	a.True(access.CanRead("Money"))
+	a.True(access.CanReadAny())
+	a.False(access.CanWriteAny())
+}
+
+func TestCallerRoles(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal([]string{"finance"}, testPolicy().CallerRoles("onboarding-pipeline", []string{"finance"}))
+	a.Equal([]string{"edi"}, testPolicy().CallerRoles("onboarding-pipeline", nil))
+	a.Equal([]string{"reader"}, testPolicy().CallerRoles("jdoe", nil))
+}
+
+func TestAccessUndefinedRole(t *testing.T) {
+	a := assert.New(t)
+
+	access := testPolicy().Access("jdoe", []string{"owner"})
+
+	a.False(access.CanReadAny())
+	a.False(access.CanWriteAny())
+}
+
+func TestCanReadKey(t *testing.T) {
+	a := assert.New(t)
+	access := testPolicy().Access("onboarding-pipeline", nil)
+
+	a.True(access.CanReadKey([]string{"EDI", "Money"}))
+	a.False(access.CanReadKey([]string{"Money"}))
+	a.False(access.CanReadKey(nil))
+	a.True(testPolicy().Access("jdoe", nil).CanReadKey(nil))
+}
+
+func TestCanWriteKey(t *testing.T) {
+	a := assert.New(t)
+	access := testPolicy().Access("onboarding-pipeline", nil)
+
+	a.True(access.CanWriteKey([]string{"EDI"}))
+	a.False(access.CanWriteKey([]string{"EDI", "Money"}))
+	a.False(access.CanWriteKey(nil))
+	a.True(testPolicy().Access("jdoe", []string{"admin"}).CanWriteKey(nil))
+}
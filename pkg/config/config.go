@@ -0,0 +1,419 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/dbconfig"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/secrets"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/watch"
+)
+
+// Config is every setting of the server. Each one comes from, in order of precedence, its flag, its env var, the
+// yaml file given with -config and its default. Keys in the yaml file are the flag names. The secrets have no flags,
+// so that they are not in the process list, and come only from the file or env; or they are read from a file of their
+// own, named like their env var with _FILE on the end, which is watched for changes.
+type Config struct {
+	Path        string `yaml:"-"`
+	PrintConfig bool   `yaml:"-"`
+
+	GRPCAddr          string        `yaml:"grpcAddr"`
+	HTTPAddr          string        `yaml:"httpAddr"`
+	AdminAddr         string        `yaml:"adminAddr"`
+	Sec               bool          `yaml:"sec"`
+	CertPath          string        `yaml:"certPath"`
+	KeyPath           string        `yaml:"keyPath"`
+	ClientCAPath      string        `yaml:"clientCAPath"`
+	PolicyPath        string        `yaml:"policyPath"`
+	JWKSPath          string        `yaml:"jwksPath"`
+	JWTHMACSecret     string        `yaml:"jwtHMACSecret"`
+	JWTHMACSecretFile string        `yaml:"jwtHMACSecretFile"`
+	KeyfilePath       string        `yaml:"keyfilePath"`
+	RateLimitPath     string        `yaml:"rateLimitPath"`
+	AccessLogBuffer   int           `yaml:"accessLogBuffer"`
+	IdempotencyWindow time.Duration `yaml:"idempotencyWindow"`
+	TracePath         string        `yaml:"tracePath"`
+	ReadyInterval     time.Duration `yaml:"readyInterval"`
+	ShutdownDelay     time.Duration `yaml:"shutdownDelay"`
+	GRPCDrainTimeout  time.Duration `yaml:"grpcDrainTimeout"`
+	HTTPDrainTimeout  time.Duration `yaml:"httpDrainTimeout"`
+	WatchInterval     time.Duration `yaml:"watchInterval"`
+	DB                DB            `yaml:",inline"`
+}
+
+// DB is how to reach the database and how hard it can be used. It is all the CLI commands need.
+type DB struct {
+	Host               string        `yaml:"dbHost"`
+	Port               int           `yaml:"dbPort"`
+	User               string        `yaml:"dbUser"`
+	Password           string        `yaml:"dbPassword"`
+	PasswordFile       string        `yaml:"dbPasswordFile"`
+	Database           string        `yaml:"dbDatabase"`
+	MaxConcurrent      int           `yaml:"dbMaxConcurrent"`
+	MaxWait            time.Duration `yaml:"dbMaxWait"`
+	BreakerFailures    int           `yaml:"breakerFailures"`
+	BreakerOpenTimeout time.Duration `yaml:"breakerOpenTimeout"`
+	BreakerSlowCall    time.Duration `yaml:"breakerSlowCall"`
+}
+
+// envVars are the env vars each flag can be given in instead.
+var envVars = []struct{ flag, env string }{
+	{"grpcAddr", "PARTNER_SERVICE_GRPC_ADDR"},
+	{"httpAddr", "PARTNER_SERVICE_HTTP_ADDR"},
+	{"adminAddr", "PARTNER_SERVICE_ADMIN_ADDR"},
+	{"sec", "PARTNER_SERVICE_SEC"},
+	{"certPath", "PARTNER_SERVICE_CERT_PATH"},
+	{"keyPath", "PARTNER_SERVICE_KEY_PATH"},
+	{"clientCAPath", "PARTNER_SERVICE_CLIENT_CA_PATH"},
+	{"policyPath", "PARTNER_SERVICE_POLICY_PATH"},
+	{"jwksPath", "PARTNER_SERVICE_JWKS_PATH"},
+	{"jwtHMACSecretFile", "JWT_HMAC_SECRET_FILE"},
+	{"keyfilePath", "PARTNER_SERVICE_KEYFILE_PATH"},
+	{"rateLimitPath", "PARTNER_SERVICE_RATE_LIMIT_PATH"},
+	{"accessLogBuffer", "PARTNER_SERVICE_ACCESS_LOG_BUFFER"},
+	{"idempotencyWindow", "PARTNER_SERVICE_IDEMPOTENCY_WINDOW"},
+	{"tracePath", "PARTNER_SERVICE_TRACE_PATH"},
+	{"readyInterval", "PARTNER_SERVICE_READY_INTERVAL"},
+	{"shutdownDelay", "PARTNER_SERVICE_SHUTDOWN_DELAY"},
+	{"grpcDrainTimeout", "PARTNER_SERVICE_GRPC_DRAIN_TIMEOUT"},
+	{"httpDrainTimeout", "PARTNER_SERVICE_HTTP_DRAIN_TIMEOUT"},
+	{"watchInterval", "PARTNER_SERVICE_WATCH_INTERVAL"},
+	{"dbHost", "DB_HOST"},
+	{"dbPort", "DB_PORT"},
+	{"dbUser", "DB_USER"},
+	{"dbPasswordFile", "DB_PASSWORD_FILE"},
+	{"dbDatabase", "DB_DATABASE"},
+	{"dbMaxConcurrent", "PARTNER_SERVICE_DB_MAX_CONCURRENT"},
+	{"dbMaxWait", "PARTNER_SERVICE_DB_MAX_WAIT"},
+	{"breakerFailures", "PARTNER_SERVICE_BREAKER_FAILURES"},
+	{"breakerOpenTimeout", "PARTNER_SERVICE_BREAKER_OPEN_TIMEOUT"},
+	{"breakerSlowCall", "PARTNER_SERVICE_BREAKER_SLOW_CALL"},
+}
+
+// the env vars the config file and the secrets are given in
+const (
+	configEnv        = "PARTNER_SERVICE_CONFIG"
+	dbPasswordEnv    = "DB_PASSWORD"
+	jwtHMACSecretEnv = "JWT_HMAC_SECRET"
+)
+
+// Default returns the settings used when nothing else is given. It has no database password or token secret.
+func Default() Config {
+	conn := dbconfig.Default()
+	return Config{
+		GRPCAddr:          ":8081",
+		HTTPAddr:          ":8080",
+		AdminAddr:         "localhost:8082",
+		CertPath:          "./tls/test/test.cert.pem",
+		KeyPath:           "./tls/test/test.key.pem",
+		PolicyPath:        "./policy.yaml",
+		AccessLogBuffer:   1000,
+		IdempotencyWindow: 24 * time.Hour,
+		ReadyInterval:     10 * time.Second,
+		ShutdownDelay:     5 * time.Second,
+		GRPCDrainTimeout:  15 * time.Second,
+		HTTPDrainTimeout:  15 * time.Second,
+		WatchInterval:     30 * time.Second,
+		DB: DB{
+			Host:               conn.Host,
+			Port:               int(conn.Port),
+			User:               conn.User,
+			Database:           conn.Database,
+			MaxConcurrent:      db.DefaultBreakerConfig.MaxConcurrent,
+			MaxWait:            db.DefaultBreakerConfig.MaxWait,
+			BreakerFailures:    db.DefaultBreakerConfig.FailureThreshold,
+			BreakerOpenTimeout: db.DefaultBreakerConfig.OpenTimeout,
+			BreakerSlowCall:    db.DefaultBreakerConfig.SlowCall,
+		},
+	}
+}
+
+// Load parses the server's flags from args into fs and merges them over env from getenv, the -config file and the
+// defaults. It does not validate the result, so that -print-config can show settings that are wrong.
+func Load(fs *flag.FlagSet, args []string, getenv func(string) string) (Config, error) {
+	return load(fs, args, getenv, (*Config).bind)
+}
+
+// LoadDB is Load for the CLI commands, which only take the database flags alongside their own. The result is validated.
+func LoadDB(fs *flag.FlagSet, args []string, getenv func(string) string) (DB, error) {
+	c, err := load(fs, args, getenv, (*Config).bindDB)
+	if err != nil {
+		return DB{}, err
+	}
+	if problems := c.DB.problems(); len(problems) > 0 {
+		return DB{}, invalid(problems)
+	}
+	if c.DB.PasswordFile != "" {
+		password, err := watch.LoadSecret(c.DB.PasswordFile)
+		if err != nil {
+			return DB{}, err
+		}
+		c.DB.Password = password.Value()
+	}
+	return c.DB, nil
+}
+
+// load parses args with the flags bind adds to fs, to find the -config file and which flags were given. The settings
+// are then built up from the defaults, the file and env, and the flags that were given are set over them.
+func load(fs *flag.FlagSet, args []string, getenv func(string) string, bind func(*Config, *flag.FlagSet)) (Config, error) {
+	given := Default()
+	bind(&given, fs)
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	c := Default()
+	c.Path = given.Path
+	if c.Path == "" {
+		c.Path = getenv(configEnv)
+	}
+	if c.Path != "" {
+		if err := c.read(c.Path); err != nil {
+			return Config{}, err
+		}
+	}
+
+	merged := flag.NewFlagSet(fs.Name(), flag.ContinueOnError)
+	bind(&c, merged)
+	for _, v := range envVars {
+		value := getenv(v.env)
+		if value == "" || merged.Lookup(v.flag) == nil {
+			continue
+		}
+		if err := merged.Set(v.flag, value); err != nil {
+			return Config{}, errors.Wrap(err, fmt.Sprintf("invalid %s", v.env))
+		}
+	}
+	if secret := getenv(dbPasswordEnv); secret != "" {
+		c.DB.Password = secret
+	}
+	if secret := getenv(jwtHMACSecretEnv); secret != "" {
+		c.JWTHMACSecret = secret
+	}
+
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		//the commands' own flags are not settings
+		if err == nil && merged.Lookup(f.Name) != nil {
+			err = merged.Set(f.Name, f.Value.String())
+		}
+	})
+	return c, err
+}
+
+// read merges the yaml file at path over c, failing on keys that are not settings.
+func (c *Config) read(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to read config file")
+	}
+	if err := yaml.UnmarshalStrict(b, c); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to parse config file %s", path))
+	}
+	return nil
+}
+
+// bind adds the server's flags to fs, set into c.
+func (c *Config) bind(fs *flag.FlagSet) {
+	c.bindConfig(fs)
+	fs.BoolVar(&c.PrintConfig, "print-config", false, "print the settings, with secrets redacted, and exit")
+	fs.StringVar(&c.GRPCAddr, "grpcAddr", c.GRPCAddr, "gRPC listen address; the same as httpAddr to serve both on one port")
+	fs.StringVar(&c.HTTPAddr, "httpAddr", c.HTTPAddr, "http listen address")
+	fs.StringVar(&c.AdminAddr, "adminAddr", c.AdminAddr, "http listen address for the operator routes, such as /debug/ratelimit, kept off the public network; not served if empty")
+	fs.BoolVar(&c.Sec, "sec", c.Sec, "use ssl cert")
+	fs.StringVar(&c.CertPath, "certPath", c.CertPath, "path to ssl cert file")
+	fs.StringVar(&c.KeyPath, "keyPath", c.KeyPath, "path to ssl key file")
+	fs.StringVar(&c.ClientCAPath, "clientCAPath", c.ClientCAPath, "path to the CA certs for client certificates, which turns on mutual tls; needs -sec")
+	fs.StringVar(&c.PolicyPath, "policyPath", c.PolicyPath, "path to a yaml file of roles and the groups each can read and write")
+	fs.StringVar(&c.JWKSPath, "jwksPath", c.JWKSPath, "path to a JWKS file with the keys for bearer tokens; the JWT_HMAC_SECRET env var can be set instead")
+	fs.StringVar(&c.JWTHMACSecretFile, "jwtHMACSecretFile", c.JWTHMACSecretFile, "path to a file with the secret bearer tokens are signed with, instead of JWT_HMAC_SECRET; read again when it changes")
+	fs.StringVar(&c.KeyfilePath, "keyfilePath", c.KeyfilePath, "path to the keyfile that sensitive values are sealed with; needed once any key is sensitive")
+	fs.StringVar(&c.RateLimitPath, "rateLimitPath", c.RateLimitPath, "path to a yaml file of the rate each caller can call each method at; no limits if empty")
+	fs.IntVar(&c.AccessLogBuffer, "accessLogBuffer", c.AccessLogBuffer, "how many access records can wait to be written before they are logged instead")
+	fs.DurationVar(&c.IdempotencyWindow, "idempotencyWindow", c.IdempotencyWindow, "how long the reply to a write sent with an idempotency key is replayed for")
+	fs.StringVar(&c.TracePath, "tracePath", c.TracePath, "path to a file that trace spans are written to as JSON, or - for stdout; no tracing if empty")
+	fs.DurationVar(&c.ReadyInterval, "readyInterval", c.ReadyInterval, "how often the grpc health service checks that the database is ready")
+	fs.DurationVar(&c.ShutdownDelay, "shutdownDelay", c.ShutdownDelay, "how long to keep serving after reporting not ready on shutdown, so load balancers stop sending calls")
+	fs.DurationVar(&c.GRPCDrainTimeout, "grpcDrainTimeout", c.GRPCDrainTimeout, "how long to wait on shutdown for gRPC calls in flight to finish before cutting them off")
+	fs.DurationVar(&c.HTTPDrainTimeout, "httpDrainTimeout", c.HTTPDrainTimeout, "how long to wait on shutdown for http requests in flight to finish before cutting them off")
+	fs.DurationVar(&c.WatchInterval, "watchInterval", c.WatchInterval, "how often the secret files and the ssl cert and key are checked for changes")
+	c.DB.bind(fs)
+	fs.IntVar(&c.DB.MaxConcurrent, "dbMaxConcurrent", c.DB.MaxConcurrent, "how many calls can use the database at once, which is how many connections the pool has")
+	fs.DurationVar(&c.DB.MaxWait, "dbMaxWait", c.DB.MaxWait, "how long a call waits to use the database before failing with Unavailable")
+	fs.IntVar(&c.DB.BreakerFailures, "breakerFailures", c.DB.BreakerFailures, "how many database failures in a row open the circuit breaker")
+	fs.DurationVar(&c.DB.BreakerOpenTimeout, "breakerOpenTimeout", c.DB.BreakerOpenTimeout, "how long the circuit breaker fails calls before trying the database again")
+	fs.DurationVar(&c.DB.BreakerSlowCall, "breakerSlowCall", c.DB.BreakerSlowCall, "database calls slower than this count as failures; 0 if none do")
+}
+
+// bindDB adds the flags the CLI commands take to fs, set into c.
+func (c *Config) bindDB(fs *flag.FlagSet) {
+	c.bindConfig(fs)
+	c.DB.bind(fs)
+}
+
+func (c *Config) bindConfig(fs *flag.FlagSet) {
+	fs.StringVar(&c.Path, "config", c.Path, "path to a yaml file of settings, keyed by flag name; flags and env vars go over it")
+}
+
+func (d *DB) bind(fs *flag.FlagSet) {
+	fs.StringVar(&d.Host, "dbHost", d.Host, "database host")
+	fs.IntVar(&d.Port, "dbPort", d.Port, "database port")
+	fs.StringVar(&d.User, "dbUser", d.User, "database user; the password is given in the DB_PASSWORD env var or the config file")
+	fs.StringVar(&d.PasswordFile, "dbPasswordFile", d.PasswordFile, "path to a file with the database password, instead of DB_PASSWORD; the server reconnects when it changes")
+	fs.StringVar(&d.Database, "dbDatabase", d.Database, "database name")
+}
+
+// Validate returns every problem with the settings in one error, or nil if there are none.
+func (c Config) Validate() error {
+	var problems []string
+	if c.GRPCAddr == "" {
+		problems = append(problems, "grpcAddr is required")
+	}
+	if c.HTTPAddr == "" {
+		problems = append(problems, "httpAddr is required")
+	}
+	if c.AdminAddr != "" && (c.AdminAddr == c.GRPCAddr || c.AdminAddr == c.HTTPAddr) {
+		problems = append(problems, "adminAddr cannot be the same as grpcAddr or httpAddr")
+	}
+	if c.PolicyPath == "" {
+		problems = append(problems, "policyPath is required")
+	}
+	if c.JWTHMACSecret != "" && c.JWTHMACSecretFile != "" {
+		problems = append(problems, "set either JWT_HMAC_SECRET or jwtHMACSecretFile, not both")
+	}
+	hmac := c.JWTHMACSecret != "" || c.JWTHMACSecretFile != ""
+	if c.JWKSPath != "" && hmac {
+		problems = append(problems, "set either jwksPath or JWT_HMAC_SECRET, not both")
+	}
+	if c.JWKSPath == "" && !hmac && c.ClientCAPath == "" {
+		problems = append(problems, "no way to authenticate callers: set jwksPath, JWT_HMAC_SECRET or clientCAPath")
+	}
+	if c.ClientCAPath != "" && !c.Sec {
+		problems = append(problems, "clientCAPath needs sec")
+	}
+	if c.Sec && (c.CertPath == "" || c.KeyPath == "") {
+		problems = append(problems, "sec needs certPath and keyPath")
+	}
+	if c.AccessLogBuffer < 0 {
+		problems = append(problems, "accessLogBuffer cannot be negative")
+	}
+	if c.IdempotencyWindow <= 0 {
+		problems = append(problems, "idempotencyWindow must be positive")
+	}
+	if c.ReadyInterval <= 0 {
+		problems = append(problems, "readyInterval must be positive")
+	}
+	if c.WatchInterval <= 0 {
+		problems = append(problems, "watchInterval must be positive")
+	}
+	if c.ShutdownDelay < 0 || c.GRPCDrainTimeout < 0 || c.HTTPDrainTimeout < 0 {
+		problems = append(problems, "shutdownDelay, grpcDrainTimeout and httpDrainTimeout cannot be negative")
+	}
+	problems = append(problems, c.DB.problems()...)
+	if len(problems) > 0 {
+		return invalid(problems)
+	}
+	return nil
+}
+
+func (d DB) problems() []string {
+	var problems []string
+	if d.Host == "" {
+		problems = append(problems, "dbHost is required")
+	}
+	if d.Password != "" && d.PasswordFile != "" {
+		problems = append(problems, "set either DB_PASSWORD or dbPasswordFile, not both")
+	}
+	if d.Port <= 0 || d.Port > 65535 {
+		problems = append(problems, fmt.Sprintf("dbPort %d is not a port", d.Port))
+	}
+	if d.User == "" {
+		problems = append(problems, "dbUser is required")
+	}
+	if d.Database == "" {
+		problems = append(problems, "dbDatabase is required")
+	}
+	if d.MaxConcurrent <= 0 {
+		problems = append(problems, "dbMaxConcurrent must be positive")
+	}
+	if d.BreakerFailures <= 0 {
+		problems = append(problems, "breakerFailures must be positive")
+	}
+	if d.MaxWait < 0 || d.BreakerOpenTimeout < 0 || d.BreakerSlowCall < 0 {
+		problems = append(problems, "dbMaxWait, breakerOpenTimeout and breakerSlowCall cannot be negative")
+	}
+	return problems
+}
+
+func invalid(problems []string) error {
+	return errors.New("invalid config: " + strings.Join(problems, "; "))
+}
+
+// ConnConfig returns the settings pgx connects to the database with.
+func (d DB) ConnConfig() pgx.ConnConfig {
+	conn := dbconfig.Default()
+	conn.Host = d.Host
+	conn.Port = uint16(d.Port)
+	conn.User = d.User
+	conn.Password = d.Password
+	conn.Database = d.Database
+	return conn
+}
+
+// BreakerConfig returns the settings of the circuit breaker around the database.
+func (d DB) BreakerConfig() db.BreakerConfig {
+	return db.BreakerConfig{
+		MaxConcurrent:    d.MaxConcurrent,
+		MaxWait:          d.MaxWait,
+		FailureThreshold: d.BreakerFailures,
+		OpenTimeout:      d.BreakerOpenTimeout,
+		SlowCall:         d.BreakerSlowCall,
+	}
+}
+
+// Redacted returns a copy of c with the secrets that are set replaced with secrets.Redacted.
+func (c Config) Redacted() Config {
+	if c.DB.Password != "" {
+		c.DB.Password = secrets.Redacted
+	}
+	if c.JWTHMACSecret != "" {
+		c.JWTHMACSecret = secrets.Redacted
+	}
+	return c
+}
+
+// Write writes c as a yaml config file, keyed by flag name, with durations as strings like 1m30s. Secrets are
+// written as they are, so redact them first if they are not meant to be seen.
+func (c Config) Write(w io.Writer) error {
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	c.bind(fs)
+	var settings yaml.MapSlice
+	fs.VisitAll(func(f *flag.Flag) {
+		if f.Name == "config" || f.Name == "print-config" {
+			return
+		}
+		value := f.Value.(flag.Getter).Get()
+		if d, ok := value.(time.Duration); ok {
+			value = d.String()
+		}
+		settings = append(settings, yaml.MapItem{Key: f.Name, Value: value})
+	})
+	settings = append(settings,
+		yaml.MapItem{Key: "dbPassword", Value: c.DB.Password},
+		yaml.MapItem{Key: "jwtHMACSecret", Value: c.JWTHMACSecret},
+	)
+	b, err := yaml.Marshal(settings)
+	if err != nil {
+		return errors.Wrap(err, "failed to write config")
+	}
+	_, err = w.Write(b)
+	return err
+}
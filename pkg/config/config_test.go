@@ -0,0 +1,153 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//writeFile writes a config file into a temp dir and returns its path and a func that removes it
+func writeFile(t *testing.T, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func env(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	a := assert.New(t)
+	path, cleanup := writeFile(t, "grpcAddr: :9001\nhttpAddr: :9002\ntracePath: file.json\ndbHost: file-db\ndbPassword: from-file\nshutdownDelay: 1s\n")
+	defer cleanup()
+
+	c, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-config", path, "-grpcAddr", ":7001"}, env(map[string]string{
+		"PARTNER_SERVICE_GRPC_ADDR": ":8001",
+		"PARTNER_SERVICE_HTTP_ADDR": ":8002",
+		"DB_PASSWORD":               "from-env",
+	}))
+
+	a.Nil(err)
+	a.Equal(":7001", c.GRPCAddr)
+	a.Equal(":8002", c.HTTPAddr)
+	a.Equal("file.json", c.TracePath)
+	a.Equal("file-db", c.DB.Host)
+	a.Equal("from-env", c.DB.Password)
+	a.Equal(time.Second, c.ShutdownDelay)
+	a.Equal(Default().PolicyPath, c.PolicyPath)
+	a.Equal(path, c.Path)
+}
+
+func TestLoadConfigFromEnv(t *testing.T) {
+	a := assert.New(t)
+	path, cleanup := writeFile(t, "tracePath: file.json\n")
+	defer cleanup()
+
+	c, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), nil, env(map[string]string{"PARTNER_SERVICE_CONFIG": path}))
+
+	a.Nil(err)
+	a.Equal("file.json", c.TracePath)
+}
+
+func TestLoadErrors(t *testing.T) {
+	a := assert.New(t)
+	path, cleanup := writeFile(t, "grpcAdr: :9001\n")
+	defer cleanup()
+
+	_, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-config", path}, env(nil))
+	a.NotNil(err)
+
+	_, err = Load(flag.NewFlagSet("test", flag.ContinueOnError), nil, env(map[string]string{"PARTNER_SERVICE_READY_INTERVAL": "soon"}))
+	a.Contains(err.Error(), "invalid PARTNER_SERVICE_READY_INTERVAL")
+}
+
+func TestLoadDB(t *testing.T) {
+	a := assert.New(t)
+	fs := flag.NewFlagSet("plan", flag.ContinueOnError)
+	dir := fs.String("dir", "./partners", "")
+
+	d, err := LoadDB(fs, []string{"-dir", "./other", "-dbHost", "flag-db"}, env(map[string]string{"DB_USER": "settings"}))
+
+	a.Nil(err)
+	a.Equal("./other", *dir)
+	a.Equal("flag-db", d.Host)
+	a.Equal("settings", d.User)
+	a.Nil(fs.Lookup("grpcAddr"))
+
+	_, err = LoadDB(flag.NewFlagSet("plan", flag.ContinueOnError), []string{"-dbHost", ""}, env(nil))
+	a.EqualError(err, "invalid config: dbHost is required")
+}
+
+func TestValidate(t *testing.T) {
+	a := assert.New(t)
+	c := Default()
+	c.JWTHMACSecret = "secret"
+	a.Nil(c.Validate())
+
+	c.JWKSPath = "keys.json"
+	c.ClientCAPath = "ca.pem"
+	c.DB.Port = 0
+	a.EqualError(c.Validate(), "invalid config: set either jwksPath or JWT_HMAC_SECRET, not both; clientCAPath needs sec; dbPort 0 is not a port")
+
+	a.EqualError(Default().Validate(), "invalid config: no way to authenticate callers: set jwksPath, JWT_HMAC_SECRET or clientCAPath")
+
+	c = Default()
+	c.JWTHMACSecret = "secret"
+	c.AdminAddr = c.HTTPAddr
+	a.EqualError(c.Validate(), "invalid config: adminAddr cannot be the same as grpcAddr or httpAddr")
+}
+
+func TestWriteRedacted(t *testing.T) {
+	a := assert.New(t)
+	c := Default()
+	c.DB.Password = "hunter2"
+	c.JWTHMACSecret = "secret"
+	var b bytes.Buffer
+
+	a.Nil(c.Redacted().Write(&b))
+
+	a.NotContains(b.String(), "hunter2")
+	a.NotContains(b.String(), "secret\n")
+	a.Contains(b.String(), "dbPassword: '[REDACTED]'")
+	a.Contains(b.String(), "shutdownDelay: 5s")
+
+	//what is written can be read back
+	path, cleanup := writeFile(t, b.String())
+	defer cleanup()
+	read, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-config", path}, env(nil))
+	a.Nil(err)
+	read.Path = ""
+	a.Equal(c.Redacted(), read)
+}
+
+func TestSecretFiles(t *testing.T) {
+	a := assert.New(t)
+	path, cleanup := writeFile(t, "hunter2\n")
+	defer cleanup()
+
+	d, err := LoadDB(flag.NewFlagSet("plan", flag.ContinueOnError), nil, env(map[string]string{"DB_PASSWORD_FILE": path}))
+	a.Nil(err)
+	a.Equal("hunter2", d.Password)
+	a.Equal(path, d.PasswordFile)
+
+	c := Default()
+	c.JWTHMACSecretFile = path
+	a.Nil(c.Validate())
+	c.JWTHMACSecret = "secret"
+	c.DB.Password = "hunter2"
+	c.DB.PasswordFile = path
+	a.EqualError(c.Validate(), "invalid config: set either JWT_HMAC_SECRET or jwtHMACSecretFile, not both; set either DB_PASSWORD or dbPasswordFile, not both")
+}
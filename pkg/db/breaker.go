@@ -0,0 +1,594 @@
+package db
+
+import (
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"github.com/jackc/pgx"
+	"github.com/pkg/errors"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+)
+
+//ErrUnavailable is the cause of errors from calls the breaker turned away without trying the database, either because
+//the database has been failing or because too many calls were already waiting on it.
+var ErrUnavailable = errors.New("database unavailable")
+
+//IsUnavailable reports whether err is from a call that was turned away by the breaker or that failed because the
+//database could not be reached. A caching layer can serve what it has for these instead.
+func IsUnavailable(err error) bool {
+	return errors.Cause(err) == ErrUnavailable || isUnhealthy(err)
+}
+
+//isUnhealthy reports whether err says the database is down or overloaded, as opposed to the call being wrong.
+func isUnhealthy(err error) bool {
+	cause := errors.Cause(err)
+	switch cause {
+	case pgx.ErrDeadConn, pgx.ErrAcquireTimeout, io.EOF, io.ErrUnexpectedEOF:
+		return true
+	}
+	switch cause := cause.(type) {
+	case pgx.PgError:
+		//connection exceptions, insufficient resources and operator intervention such as a shutdown
+		return strings.HasPrefix(cause.Code, "08") || strings.HasPrefix(cause.Code, "53") || strings.HasPrefix(cause.Code, "57P")
+	case net.Error:
+		return true
+	}
+	return false
+}
+
+//BreakerConfig is how many calls the breaker lets through to the database and when it stops trying it.
+type BreakerConfig struct {
+	MaxConcurrent    int           //calls in flight at once; the server's pool has a connection for each
+	MaxWait          time.Duration //how long a call waits for one of those before it is turned away
+	FailureThreshold int           //failures in a row that open the breaker
+	OpenTimeout      time.Duration //how long the breaker stays open before letting a trial call through
+	SlowCall         time.Duration //calls that take longer count as failures; zero if none do
+}
+
+//DefaultBreakerConfig suits a small pool of connections.
+var DefaultBreakerConfig = BreakerConfig{
+	MaxConcurrent:    4,
+	MaxWait:          time.Second,
+	FailureThreshold: 5,
+	OpenTimeout:      10 * time.Second,
+}
+
+//BreakerGauges are set to the calls using the database, the calls waiting to, and the state of the breaker: 0 when
+//closed, 1 when open and 2 when half open. Any can be nil.
+type BreakerGauges struct {
+	InFlight metrics.Gauge
+	Waiting  metrics.Gauge
+	State    metrics.Gauge
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+//breaker lets calls through while the database is healthy. After FailureThreshold failures in a row it opens and
+//turns every call away for OpenTimeout, then lets a single trial call through: if that succeeds the breaker closes,
+//otherwise it opens again. Only failures that say the database is unhealthy count, not errors such as a missing
+//partner or a revision conflict.
+type breaker struct {
+	config   BreakerConfig
+	gauges   BreakerGauges
+	slots    chan struct{}
+	now      func() time.Time
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+	trial    bool
+	inFlight int
+	waiting  int
+}
+
+func newBreaker(config BreakerConfig, gauges BreakerGauges) *breaker {
+	if config.MaxConcurrent < 1 {
+		config.MaxConcurrent = 1
+	}
+	if config.FailureThreshold < 1 {
+		config.FailureThreshold = 1
+	}
+	if gauges.InFlight == nil {
+		gauges.InFlight = discard.NewGauge()
+	}
+	if gauges.Waiting == nil {
+		gauges.Waiting = discard.NewGauge()
+	}
+	if gauges.State == nil {
+		gauges.State = discard.NewGauge()
+	}
+	return &breaker{
+		config: config,
+		gauges: gauges,
+		slots:  make(chan struct{}, config.MaxConcurrent),
+		now:    time.Now,
+	}
+}
+
+//allow reports whether a call can go through, and whether it is the trial call of a half open breaker.
+func (b *breaker) allow() (bool, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.config.OpenTimeout {
+			return false, false
+		}
+		b.setState(breakerHalfOpen)
+		fallthrough
+	case breakerHalfOpen:
+		if b.trial {
+			return false, false
+		}
+		b.trial = true
+		return true, true
+	}
+	return true, false
+}
+
+//done records how a call went.
+func (b *breaker) done(trial, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if trial {
+		b.trial = false
+	}
+	if !failed {
+		b.setState(breakerClosed)
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if trial || b.failures >= b.config.FailureThreshold {
+		b.setState(breakerOpen)
+		b.openedAt = b.now()
+	}
+}
+
+//setState is called with mu held.
+func (b *breaker) setState(state breakerState) {
+	b.state = state
+	b.gauges.State.Set(float64(state))
+}
+
+//count adds to the calls using the database and waiting to, and sets the gauges to them.
+func (b *breaker) count(inFlight, waiting int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.inFlight += inFlight
+	b.waiting += waiting
+	b.gauges.InFlight.Set(float64(b.inFlight))
+	b.gauges.Waiting.Set(float64(b.waiting))
+}
+
+//abandon gives up the trial of a call that never reached the database.
+func (b *breaker) abandon(trial bool) {
+	if !trial {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+//acquire waits up to MaxWait for a slot to call the database in.
+func (b *breaker) acquire() bool {
+	select {
+	case b.slots <- struct{}{}:
+		return true
+	default:
+	}
+	if b.config.MaxWait <= 0 {
+		return false
+	}
+	b.count(0, 1)
+	defer b.count(0, -1)
+	timer := time.NewTimer(b.config.MaxWait)
+	defer timer.Stop()
+	select {
+	case b.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+func (b *breaker) call(f func() error) error {
+	ok, trial := b.allow()
+	if !ok {
+		return errors.Wrap(ErrUnavailable, "circuit breaker is open")
+	}
+	if !b.acquire() {
+		b.abandon(trial)
+		return errors.Wrap(ErrUnavailable, "too many calls waiting on the database")
+	}
+	b.count(1, 0)
+	defer func() {
+		<-b.slots
+		b.count(-1, 0)
+	}()
+
+	start := b.now()
+	err := f()
+	slow := b.config.SlowCall > 0 && b.now().Sub(start) > b.config.SlowCall
+	b.done(trial, slow || isUnhealthy(err))
+	return err
+}
+
+//NewBreakerQuerier returns a querier that stops calling the database while it is unhealthy, failing fast with
+//ErrUnavailable instead, and that holds calls to at most MaxConcurrent at once.
+func NewBreakerQuerier(next PartnerServiceQuerier, config BreakerConfig, gauges BreakerGauges) PartnerServiceQuerier {
+	return breakerQuerier{
+		next:    next,
+		breaker: newBreaker(config, gauges),
+	}
+}
+
+//breakerQuerier does not embed the querier it wraps, so that every method goes through the breaker.
+type breakerQuerier struct {
+	next PartnerServiceQuerier
+	*breaker
+}
+
+func (q breakerQuerier) FindPartnerDataFromKeyValue(key, value string) (partnerId int32, code string, err error) {
+	err = q.call(func() error {
+		partnerId, code, err = q.next.FindPartnerDataFromKeyValue(key, value)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) FindAllAttributesForPartner(id int32) (attributes map[string]string, err error) {
+	err = q.call(func() error {
+		attributes, err = q.next.FindAllAttributesForPartner(id)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) FindPartnerAttribute(id int32, group string) (attributes map[string]string, err error) {
+	err = q.call(func() error {
+		attributes, err = q.next.FindPartnerAttribute(id, group)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) FindPartnerDataByID(partnerId int32, code string) (id int32, partnerCode string, err error) {
+	err = q.call(func() error {
+		id, partnerCode, err = q.next.FindPartnerDataByID(partnerId, code)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) CheckPartnerIDEqualsPartnerCode(partnerId int32, code string) (equal bool, err error) {
+	err = q.call(func() error {
+		equal, err = q.next.CheckPartnerIDEqualsPartnerCode(partnerId, code)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) FindPartners(codes []string, group string) (partners []models.Partner, err error) {
+	err = q.call(func() error {
+		partners, err = q.next.FindPartners(codes, group)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) SavePartners(partners []models.Partner) error {
+	return q.call(func() error {
+		return q.next.SavePartners(partners)
+	})
+}
+
+func (q breakerQuerier) ApplyPartners(partners, deletes []models.Partner) error {
+	return q.call(func() error {
+		return q.next.ApplyPartners(partners, deletes)
+	})
+}
+
+func (q breakerQuerier) FindIdentifierKeys() (keys []string, err error) {
+	err = q.call(func() error {
+		keys, err = q.next.FindIdentifierKeys()
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) FindKeyGroups(keys []string) (groups map[string][]string, err error) {
+	err = q.call(func() error {
+		groups, err = q.next.FindKeyGroups(keys)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) FindSensitiveKeys() (keys []string, err error) {
+	err = q.call(func() error {
+		keys, err = q.next.FindSensitiveKeys()
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) FindTemplateAttributes(name, group string) (attributes map[string]string, err error) {
+	err = q.call(func() error {
+		attributes, err = q.next.FindTemplateAttributes(name, group)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) CreatePartner(p models.Partner) (partnerId int32, err error) {
+	err = q.call(func() error {
+		partnerId, err = q.next.CreatePartner(p)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) FindPartnerStatus(id int32) (status string, err error) {
+	err = q.call(func() error {
+		status, err = q.next.FindPartnerStatus(id)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) FindPartnerRevision(id int32) (revision int32, err error) {
+	err = q.call(func() error {
+		revision, err = q.next.FindPartnerRevision(id)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) UpdatePartnerStatus(id int32, from, to string, expectedRevision int32) (changedAt time.Time, err error) {
+	err = q.call(func() error {
+		changedAt, err = q.next.UpdatePartnerStatus(id, from, to, expectedRevision)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) RestorePartner(code string) (n int64, err error) {
+	err = q.call(func() error {
+		n, err = q.next.RestorePartner(code)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) RestoreKey(name string) (n int64, err error) {
+	err = q.call(func() error {
+		n, err = q.next.RestoreKey(name)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) RestoreGroup(name string) (n int64, err error) {
+	err = q.call(func() error {
+		n, err = q.next.RestoreGroup(name)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) RestorePartnerAttribute(code, key string, expectedRevision int32) (n int64, err error) {
+	err = q.call(func() error {
+		n, err = q.next.RestorePartnerAttribute(code, key, expectedRevision)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) PurgeDeleted(before time.Time) (n int64, err error) {
+	err = q.call(func() error {
+		n, err = q.next.PurgeDeleted(before)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) CreateChangeSet(name string) (changeSetId int32, err error) {
+	err = q.call(func() error {
+		changeSetId, err = q.next.CreateChangeSet(name)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) FindChangeSet(id int32) (changeSet models.ChangeSet, err error) {
+	err = q.call(func() error {
+		changeSet, err = q.next.FindChangeSet(id)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) StageChange(id int32, partner models.Partner) error {
+	return q.call(func() error {
+		return q.next.StageChange(id, partner)
+	})
+}
+
+func (q breakerQuerier) PublishChangeSet(id int32) error {
+	return q.call(func() error {
+		return q.next.PublishChangeSet(id)
+	})
+}
+
+func (q breakerQuerier) DiscardChangeSet(id int32) error {
+	return q.call(func() error {
+		return q.next.DiscardChangeSet(id)
+	})
+}
+
+func (q breakerQuerier) FindApprovalPolicies(keys []string) (approvers map[string][]string, err error) {
+	err = q.call(func() error {
+		approvers, err = q.next.FindApprovalPolicies(keys)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) FindRestoreApprovalPolicies(table, name string) (approvers map[string][]string, err error) {
+	err = q.call(func() error {
+		approvers, err = q.next.FindRestoreApprovalPolicies(table, name)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) RequestApproval(changeSetId int32, requestedBy string, groups []string) (requestId int32, err error) {
+	err = q.call(func() error {
+		requestId, err = q.next.RequestApproval(changeSetId, requestedBy, groups)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) FindApprovalRequests(status string) (requests []models.ApprovalRequest, err error) {
+	err = q.call(func() error {
+		requests, err = q.next.FindApprovalRequests(status)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) FindApprovalRequest(id int32) (request models.ApprovalRequest, err error) {
+	err = q.call(func() error {
+		request, err = q.next.FindApprovalRequest(id)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) ApproveRequest(id int32, approver string) error {
+	return q.call(func() error {
+		return q.next.ApproveRequest(id, approver)
+	})
+}
+
+func (q breakerQuerier) RejectRequest(id int32, approver, reason string) error {
+	return q.call(func() error {
+		return q.next.RejectRequest(id, approver, reason)
+	})
+}
+
+func (q breakerQuerier) ClaimIdempotencyKey(key, method, requestHash string, since time.Time) (response models.IdempotentResponse, claimed bool, err error) {
+	err = q.call(func() error {
+		response, claimed, err = q.next.ClaimIdempotencyKey(key, method, requestHash, since)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) SaveIdempotentResponse(key, method, response string) error {
+	return q.call(func() error {
+		return q.next.SaveIdempotentResponse(key, method, response)
+	})
+}
+
+func (q breakerQuerier) ReleaseIdempotencyKey(key, method string) error {
+	return q.call(func() error {
+		return q.next.ReleaseIdempotencyKey(key, method)
+	})
+}
+
+func (q breakerQuerier) PurgeIdempotencyKeys(before time.Time) (n int64, err error) {
+	err = q.call(func() error {
+		n, err = q.next.PurgeIdempotencyKeys(before)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) RecordAccess(records []models.AccessRecord) error {
+	return q.call(func() error {
+		return q.next.RecordAccess(records)
+	})
+}
+
+func (q breakerQuerier) FindAccessRecords(partnerCode string, from, to time.Time) (records []models.AccessRecord, err error) {
+	err = q.call(func() error {
+		records, err = q.next.FindAccessRecords(partnerCode, from, to)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) CreateAPIKey(key models.APIKey, hash string) (created models.APIKey, err error) {
+	err = q.call(func() error {
+		created, err = q.next.CreateAPIKey(key, hash)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) FindAPIKeys(owner string) (apiKeys []models.APIKey, err error) {
+	err = q.call(func() error {
+		apiKeys, err = q.next.FindAPIKeys(owner)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) FindAPIKeyByHash(hash string) (apiKey models.APIKey, err error) {
+	err = q.call(func() error {
+		apiKey, err = q.next.FindAPIKeyByHash(hash)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) RotateAPIKey(id int32, prefix, hash string) (apiKey models.APIKey, err error) {
+	err = q.call(func() error {
+		apiKey, err = q.next.RotateAPIKey(id, prefix, hash)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) RevokeAPIKey(id int32) (apiKey models.APIKey, err error) {
+	err = q.call(func() error {
+		apiKey, err = q.next.RevokeAPIKey(id)
+		return err
+	})
+	return
+}
+
+func (q breakerQuerier) TouchAPIKey(id int32, usedAt time.Time) error {
+	return q.call(func() error {
+		return q.next.TouchAPIKey(id, usedAt)
+	})
+}
+
+func (q breakerQuerier) Ping() error {
+	return q.call(func() error {
+		return q.next.Ping()
+	})
+}
+
+func (q breakerQuerier) FindSchemaVersion() (version int32, err error) {
+	err = q.call(func() error {
+		version, err = q.next.FindSchemaVersion()
+		return err
+	})
+	return
+}
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics/generic"
+	"github.com/jackc/pgx"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// statusQuerier answers FindPartnerStatus with err, blocking on block if it is set, and counts the calls that reach it
+type statusQuerier struct {
+	PartnerServiceQuerier
+	err   error
+	block chan struct{}
+	calls int
+}
+
+func (q *statusQuerier) FindPartnerStatus(id int32) (string, error) {
+	q.calls++
+	if q.block != nil {
+		<-q.block
+	}
+	return "active", q.err
+}
+
+func newTestBreakerQuerier(next PartnerServiceQuerier, config BreakerConfig) (breakerQuerier, *time.Time) {
+	now := time.Date(2017, 8, 1, 12, 0, 0, 0, time.UTC)
+	q := NewBreakerQuerier(next, config, BreakerGauges{}).(breakerQuerier)
+	q.now = func() time.Time { return now }
+	return q, &now
+}
+
+func TestBreakerOpensAfterFailures(t *testing.T) {
+	a := assert.New(t)
+	next := &statusQuerier{err: errors.Wrap(pgx.ErrDeadConn, "error finding status")}
+	q, _ := newTestBreakerQuerier(next, BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute})
+	state := generic.NewGauge("breaker_state")
+	q.gauges.State = state
+
+	for i := 0; i < 2; i++ {
+		_, err := q.FindPartnerStatus(1)
+		a.True(IsUnavailable(err))
+		a.NotEqual(ErrUnavailable, errors.Cause(err))
+	}
+	_, err := q.FindPartnerStatus(1)
+
+	a.Equal(ErrUnavailable, errors.Cause(err))
+	a.Equal(2, next.calls)
+	a.Equal(float64(breakerOpen), state.Value())
+}
+
+func TestBreakerIgnoresLogicalErrors(t *testing.T) {
+	a := assert.New(t)
+	next := &statusQuerier{err: errors.New("no rows returned from id: 1")}
+	q, _ := newTestBreakerQuerier(next, BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		_, err := q.FindPartnerStatus(1)
+		a.False(IsUnavailable(err))
+	}
+	a.Equal(3, next.calls)
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	a := assert.New(t)
+	next := &statusQuerier{err: pgx.PgError{Code: "57P01", Message: "terminating connection due to administrator command"}}
+	q, now := newTestBreakerQuerier(next, BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute})
+
+	q.FindPartnerStatus(1)
+	*now = now.Add(time.Minute)
+
+	//the trial call fails, which opens the breaker again
+	_, err := q.FindPartnerStatus(1)
+	a.NotEqual(ErrUnavailable, errors.Cause(err))
+	_, err = q.FindPartnerStatus(1)
+	a.Equal(ErrUnavailable, errors.Cause(err))
+	a.Equal(2, next.calls)
+
+	//the next trial call succeeds, which closes it
+	*now = now.Add(time.Minute)
+	next.err = nil
+	for i := 0; i < 2; i++ {
+		status, err := q.FindPartnerStatus(1)
+		a.Nil(err)
+		a.Equal("active", status)
+	}
+	a.Equal(4, next.calls)
+}
+
+func TestBreakerSlowCalls(t *testing.T) {
+	a := assert.New(t)
+	next := &statusQuerier{}
+	q, now := newTestBreakerQuerier(next, BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute, SlowCall: time.Second})
+	start := *now
+	calls := 0
+	q.now = func() time.Time {
+		//each call to the database takes two seconds
+		calls++
+		return start.Add(time.Duration(calls) * 2 * time.Second)
+	}
+
+	_, err := q.FindPartnerStatus(1)
+	a.Nil(err)
+	_, err = q.FindPartnerStatus(1)
+
+	a.Equal(ErrUnavailable, errors.Cause(err))
+}
+
+func TestBreakerBulkhead(t *testing.T) {
+	a := assert.New(t)
+	next := &statusQuerier{block: make(chan struct{})}
+	q := NewBreakerQuerier(next, BreakerConfig{MaxConcurrent: 1, MaxWait: 10 * time.Millisecond, FailureThreshold: 1}, BreakerGauges{})
+
+	done := make(chan error)
+	go func() {
+		_, err := q.FindPartnerStatus(1)
+		done <- err
+	}()
+	//wait for the first call to take the only slot
+	for len(q.(breakerQuerier).slots) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	_, err := q.FindPartnerStatus(1)
+	a.Equal(ErrUnavailable, errors.Cause(err))
+
+	close(next.block)
+	a.Nil(<-done)
+
+	//being turned away does not count as a failure
+	_, err = q.FindPartnerStatus(1)
+	a.Nil(err)
+}
+
+func TestIsUnavailable(t *testing.T) {
+	a := assert.New(t)
+
+	a.True(IsUnavailable(errors.Wrap(ErrUnavailable, "circuit breaker is open")))
+	a.True(IsUnavailable(errors.Wrap(pgx.ErrAcquireTimeout, "error finding status")))
+	a.True(IsUnavailable(pgx.PgError{Code: "53300", Message: "too many connections"}))
+	a.False(IsUnavailable(pgx.PgError{Code: "23505", Message: "duplicate key value"}))
+	a.False(IsUnavailable(ErrRevisionConflict))
+	a.False(IsUnavailable(nil))
+}
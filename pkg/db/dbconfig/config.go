@@ -2,38 +2,38 @@ package dbconfig
 
 import (
 	"os"
+	"strconv"
 
 	"github.com/jackc/pgx"
 )
 
-func ExtractConfig() pgx.ConnConfig {
-	var config pgx.ConnConfig
-	environment := os.Getenv("PARTNER_SERVICE_ENVIRONMENT")
-
-	var environment = os.Getenv("PARTNER_SERVICE_ENVIRONMENT") //true
-
-	if environment == "" {
-		config.Host = "partner-service.cclyw00l55b3.us-east-1.rds.amazonaws.com"
-		config.User = "spam"
-		config.Password = "mapsmaps"
-		config.Database = "settings"
-	} else {
-		config.Host = os.Getenv("DB_HOST")
-		if config.Host == "" {
-			config.Host = "localhost"
-		}
-
-		config.User = os.Getenv("DB_USER")
-		if config.User == "" {
-			config.User = os.Getenv("postgres")
-		}
-
-		config.Password = os.Getenv("DB_PASSWORD")
+//Default is the database the service connects to when nothing else is given, a local one with no password.
+//The server's settings start from it; see the config package.
+func Default() pgx.ConnConfig {
+	return pgx.ConnConfig{
+		Host:     "localhost",
+		Port:     5432,
+		User:     "postgres",
+		Database: "partner_service",
+	}
+}
 
-		config.Database = os.Getenv("DB_DATABASE")
-		if config.Database == "" {
-			config.Database = "partner_service"
-		}
+//ExtractConfig returns Default with DB_HOST, DB_PORT, DB_USER, DB_PASSWORD and DB_DATABASE from env over it, for the
+//tests that need a database.
+func ExtractConfig() pgx.ConnConfig {
+	config := Default()
+	if host := os.Getenv("DB_HOST"); host != "" {
+		config.Host = host
+	}
+	if port, err := strconv.ParseUint(os.Getenv("DB_PORT"), 10, 16); err == nil {
+		config.Port = uint16(port)
+	}
+	if user := os.Getenv("DB_USER"); user != "" {
+		config.User = user
+	}
+	config.Password = os.Getenv("DB_PASSWORD")
+	if database := os.Getenv("DB_DATABASE"); database != "" {
+		config.Database = database
 	}
 	return config
 }
@@ -0,0 +1,264 @@
+package db
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+)
+
+//NewInstrumentingQuerier returns a querier that observes how many seconds each query took, by query and whether it
+//failed.
+func NewInstrumentingQuerier(next PartnerServiceQuerier, duration metrics.Histogram) PartnerServiceQuerier {
+	return instrumentingQuerier{
+		next:     next,
+		duration: duration,
+	}
+}
+
+//instrumentingQuerier does not embed the querier it wraps, so that every query is observed.
+type instrumentingQuerier struct {
+	next     PartnerServiceQuerier
+	duration metrics.Histogram
+}
+
+//observe is deferred with the error the query returns, which is set by the time it runs.
+func (q instrumentingQuerier) observe(query string, begin time.Time, err *error) {
+	q.duration.With("query", query, "error", strconv.FormatBool(*err != nil)).Observe(time.Since(begin).Seconds())
+}
+
+func (q instrumentingQuerier) FindPartnerDataFromKeyValue(key, value string) (partnerId int32, code string, err error) {
+	defer q.observe("FindPartnerDataFromKeyValue", time.Now(), &err)
+	return q.next.FindPartnerDataFromKeyValue(key, value)
+}
+
+func (q instrumentingQuerier) FindAllAttributesForPartner(id int32) (attributes map[string]string, err error) {
+	defer q.observe("FindAllAttributesForPartner", time.Now(), &err)
+	return q.next.FindAllAttributesForPartner(id)
+}
+
+func (q instrumentingQuerier) FindPartnerAttribute(id int32, group string) (attributes map[string]string, err error) {
+	defer q.observe("FindPartnerAttribute", time.Now(), &err)
+	return q.next.FindPartnerAttribute(id, group)
+}
+
+func (q instrumentingQuerier) FindPartnerDataByID(partnerId int32, code string) (id int32, partnerCode string, err error) {
+	defer q.observe("FindPartnerDataByID", time.Now(), &err)
+	return q.next.FindPartnerDataByID(partnerId, code)
+}
+
+func (q instrumentingQuerier) CheckPartnerIDEqualsPartnerCode(partnerId int32, code string) (equal bool, err error) {
+	defer q.observe("CheckPartnerIDEqualsPartnerCode", time.Now(), &err)
+	return q.next.CheckPartnerIDEqualsPartnerCode(partnerId, code)
+}
+
+func (q instrumentingQuerier) FindPartners(codes []string, group string) (partners []models.Partner, err error) {
+	defer q.observe("FindPartners", time.Now(), &err)
+	return q.next.FindPartners(codes, group)
+}
+
+func (q instrumentingQuerier) SavePartners(partners []models.Partner) (err error) {
+	defer q.observe("SavePartners", time.Now(), &err)
+	return q.next.SavePartners(partners)
+}
+
+func (q instrumentingQuerier) ApplyPartners(partners, deletes []models.Partner) (err error) {
+	defer q.observe("ApplyPartners", time.Now(), &err)
+	return q.next.ApplyPartners(partners, deletes)
+}
+
+func (q instrumentingQuerier) FindIdentifierKeys() (keys []string, err error) {
+	defer q.observe("FindIdentifierKeys", time.Now(), &err)
+	return q.next.FindIdentifierKeys()
+}
+
+func (q instrumentingQuerier) FindKeyGroups(keys []string) (groups map[string][]string, err error) {
+	defer q.observe("FindKeyGroups", time.Now(), &err)
+	return q.next.FindKeyGroups(keys)
+}
+
+func (q instrumentingQuerier) FindSensitiveKeys() (keys []string, err error) {
+	defer q.observe("FindSensitiveKeys", time.Now(), &err)
+	return q.next.FindSensitiveKeys()
+}
+
+func (q instrumentingQuerier) FindTemplateAttributes(name, group string) (attributes map[string]string, err error) {
+	defer q.observe("FindTemplateAttributes", time.Now(), &err)
+	return q.next.FindTemplateAttributes(name, group)
+}
+
+func (q instrumentingQuerier) CreatePartner(p models.Partner) (partnerId int32, err error) {
+	defer q.observe("CreatePartner", time.Now(), &err)
+	return q.next.CreatePartner(p)
+}
+
+func (q instrumentingQuerier) FindPartnerStatus(id int32) (status string, err error) {
+	defer q.observe("FindPartnerStatus", time.Now(), &err)
+	return q.next.FindPartnerStatus(id)
+}
+
+func (q instrumentingQuerier) FindPartnerRevision(id int32) (revision int32, err error) {
+	defer q.observe("FindPartnerRevision", time.Now(), &err)
+	return q.next.FindPartnerRevision(id)
+}
+
+func (q instrumentingQuerier) UpdatePartnerStatus(id int32, from, to string, expectedRevision int32) (changedAt time.Time, err error) {
+	defer q.observe("UpdatePartnerStatus", time.Now(), &err)
+	return q.next.UpdatePartnerStatus(id, from, to, expectedRevision)
+}
+
+func (q instrumentingQuerier) RestorePartner(code string) (n int64, err error) {
+	defer q.observe("RestorePartner", time.Now(), &err)
+	return q.next.RestorePartner(code)
+}
+
+func (q instrumentingQuerier) RestoreKey(name string) (n int64, err error) {
+	defer q.observe("RestoreKey", time.Now(), &err)
+	return q.next.RestoreKey(name)
+}
+
+func (q instrumentingQuerier) RestoreGroup(name string) (n int64, err error) {
+	defer q.observe("RestoreGroup", time.Now(), &err)
+	return q.next.RestoreGroup(name)
+}
+
+func (q instrumentingQuerier) RestorePartnerAttribute(code, key string, expectedRevision int32) (n int64, err error) {
+	defer q.observe("RestorePartnerAttribute", time.Now(), &err)
+	return q.next.RestorePartnerAttribute(code, key, expectedRevision)
+}
+
+func (q instrumentingQuerier) PurgeDeleted(before time.Time) (n int64, err error) {
+	defer q.observe("PurgeDeleted", time.Now(), &err)
+	return q.next.PurgeDeleted(before)
+}
+
+func (q instrumentingQuerier) CreateChangeSet(name string) (changeSetId int32, err error) {
+	defer q.observe("CreateChangeSet", time.Now(), &err)
+	return q.next.CreateChangeSet(name)
+}
+
+func (q instrumentingQuerier) FindChangeSet(id int32) (changeSet models.ChangeSet, err error) {
+	defer q.observe("FindChangeSet", time.Now(), &err)
+	return q.next.FindChangeSet(id)
+}
+
+func (q instrumentingQuerier) StageChange(id int32, partner models.Partner) (err error) {
+	defer q.observe("StageChange", time.Now(), &err)
+	return q.next.StageChange(id, partner)
+}
+
+func (q instrumentingQuerier) PublishChangeSet(id int32) (err error) {
+	defer q.observe("PublishChangeSet", time.Now(), &err)
+	return q.next.PublishChangeSet(id)
+}
+
+func (q instrumentingQuerier) DiscardChangeSet(id int32) (err error) {
+	defer q.observe("DiscardChangeSet", time.Now(), &err)
+	return q.next.DiscardChangeSet(id)
+}
+
+func (q instrumentingQuerier) FindApprovalPolicies(keys []string) (approvers map[string][]string, err error) {
+	defer q.observe("FindApprovalPolicies", time.Now(), &err)
+	return q.next.FindApprovalPolicies(keys)
+}
+
+func (q instrumentingQuerier) FindRestoreApprovalPolicies(table, name string) (approvers map[string][]string, err error) {
+	defer q.observe("FindRestoreApprovalPolicies", time.Now(), &err)
+	return q.next.FindRestoreApprovalPolicies(table, name)
+}
+
+func (q instrumentingQuerier) RequestApproval(changeSetId int32, requestedBy string, groups []string) (requestId int32, err error) {
+	defer q.observe("RequestApproval", time.Now(), &err)
+	return q.next.RequestApproval(changeSetId, requestedBy, groups)
+}
+
+func (q instrumentingQuerier) FindApprovalRequests(status string) (requests []models.ApprovalRequest, err error) {
+	defer q.observe("FindApprovalRequests", time.Now(), &err)
+	return q.next.FindApprovalRequests(status)
+}
+
+func (q instrumentingQuerier) FindApprovalRequest(id int32) (request models.ApprovalRequest, err error) {
+	defer q.observe("FindApprovalRequest", time.Now(), &err)
+	return q.next.FindApprovalRequest(id)
+}
+
+func (q instrumentingQuerier) ApproveRequest(id int32, approver string) (err error) {
+	defer q.observe("ApproveRequest", time.Now(), &err)
+	return q.next.ApproveRequest(id, approver)
+}
+
+func (q instrumentingQuerier) RejectRequest(id int32, approver, reason string) (err error) {
+	defer q.observe("RejectRequest", time.Now(), &err)
+	return q.next.RejectRequest(id, approver, reason)
+}
+
+func (q instrumentingQuerier) ClaimIdempotencyKey(key, method, requestHash string, since time.Time) (response models.IdempotentResponse, claimed bool, err error) {
+	defer q.observe("ClaimIdempotencyKey", time.Now(), &err)
+	return q.next.ClaimIdempotencyKey(key, method, requestHash, since)
+}
+
+func (q instrumentingQuerier) SaveIdempotentResponse(key, method, response string) (err error) {
+	defer q.observe("SaveIdempotentResponse", time.Now(), &err)
+	return q.next.SaveIdempotentResponse(key, method, response)
+}
+
+func (q instrumentingQuerier) ReleaseIdempotencyKey(key, method string) (err error) {
+	defer q.observe("ReleaseIdempotencyKey", time.Now(), &err)
+	return q.next.ReleaseIdempotencyKey(key, method)
+}
+
+func (q instrumentingQuerier) PurgeIdempotencyKeys(before time.Time) (n int64, err error) {
+	defer q.observe("PurgeIdempotencyKeys", time.Now(), &err)
+	return q.next.PurgeIdempotencyKeys(before)
+}
+
+func (q instrumentingQuerier) RecordAccess(records []models.AccessRecord) (err error) {
+	defer q.observe("RecordAccess", time.Now(), &err)
+	return q.next.RecordAccess(records)
+}
+
+func (q instrumentingQuerier) FindAccessRecords(partnerCode string, from, to time.Time) (records []models.AccessRecord, err error) {
+	defer q.observe("FindAccessRecords", time.Now(), &err)
+	return q.next.FindAccessRecords(partnerCode, from, to)
+}
+
+func (q instrumentingQuerier) CreateAPIKey(key models.APIKey, hash string) (created models.APIKey, err error) {
+	defer q.observe("CreateAPIKey", time.Now(), &err)
+	return q.next.CreateAPIKey(key, hash)
+}
+
+func (q instrumentingQuerier) FindAPIKeys(owner string) (apiKeys []models.APIKey, err error) {
+	defer q.observe("FindAPIKeys", time.Now(), &err)
+	return q.next.FindAPIKeys(owner)
+}
+
+func (q instrumentingQuerier) FindAPIKeyByHash(hash string) (apiKey models.APIKey, err error) {
+	defer q.observe("FindAPIKeyByHash", time.Now(), &err)
+	return q.next.FindAPIKeyByHash(hash)
+}
+
+func (q instrumentingQuerier) RotateAPIKey(id int32, prefix, hash string) (apiKey models.APIKey, err error) {
+	defer q.observe("RotateAPIKey", time.Now(), &err)
+	return q.next.RotateAPIKey(id, prefix, hash)
+}
+
+func (q instrumentingQuerier) RevokeAPIKey(id int32) (apiKey models.APIKey, err error) {
+	defer q.observe("RevokeAPIKey", time.Now(), &err)
+	return q.next.RevokeAPIKey(id)
+}
+
+func (q instrumentingQuerier) TouchAPIKey(id int32, usedAt time.Time) (err error) {
+	defer q.observe("TouchAPIKey", time.Now(), &err)
+	return q.next.TouchAPIKey(id, usedAt)
+}
+
+func (q instrumentingQuerier) Ping() (err error) {
+	defer q.observe("Ping", time.Now(), &err)
+	return q.next.Ping()
+}
+
+func (q instrumentingQuerier) FindSchemaVersion() (version int32, err error) {
+	defer q.observe("FindSchemaVersion", time.Now(), &err)
+	return q.next.FindSchemaVersion()
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// queryHistogram keeps what it observes by its label values, joined with spaces
+type queryHistogram struct {
+	observed map[string][]float64
+	labels   string
+}
+
+func (h queryHistogram) With(labelValues ...string) metrics.Histogram {
+	return queryHistogram{observed: h.observed, labels: strings.Join(labelValues, " ")}
+}
+
+func (h queryHistogram) Observe(value float64) {
+	h.observed[h.labels] = append(h.observed[h.labels], value)
+}
+
+func TestInstrumentingQuerier(t *testing.T) {
+	a := assert.New(t)
+	next := &statusQuerier{}
+	duration := queryHistogram{observed: make(map[string][]float64)}
+	q := NewInstrumentingQuerier(next, duration)
+
+	q.FindPartnerStatus(1)
+	next.err = errors.New("no rows returned from id: 1")
+	_, err := q.FindPartnerStatus(1)
+
+	a.NotNil(err)
+	a.Equal(2, next.calls)
+	a.Len(duration.observed["query FindPartnerStatus error false"], 1)
+	a.Len(duration.observed["query FindPartnerStatus error true"], 1)
+}
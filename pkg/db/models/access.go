@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jackc/pgx"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/pb"
+)
+
+// AccessRecord is one read of sensitive values: who read which keys of a partner, and in which request.
+type AccessRecord struct {
+	Id          pgx.NullInt32
+	Caller      pgx.NullString
+	PartnerCode pgx.NullString
+	Keys        []string
+	RequestId   pgx.NullString
+	ReadAt      pgx.NullTime
+}
+
+func (r AccessRecord) Gen() *pb.AccessRecord {
+	rep := &pb.AccessRecord{
+		Id:          r.Id.Int32,
+		Caller:      r.Caller.String,
+		PartnerCode: r.PartnerCode.String,
+		Keys:        r.Keys,
+		RequestId:   r.RequestId.String,
+	}
+	if r.ReadAt.Valid {
+		rep.ReadAt = r.ReadAt.Time.Format(time.RFC3339)
+	}
+	return rep
+}
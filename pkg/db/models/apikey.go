@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jackc/pgx"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/pb"
+)
+
+// APIKey lets a caller who cannot get a token authenticate on behalf of the owner, with the roles in its scope. Only
+// the hash of the key is stored, and the prefix names the key in lists. IssuedBy is the caller who issued it.
+type APIKey struct {
+	Id         pgx.NullInt32
+	Prefix     pgx.NullString
+	Owner      pgx.NullString
+	Scope      []string
+	IssuedBy   pgx.NullString
+	ExpiresAt  pgx.NullTime
+	LastUsedAt pgx.NullTime
+	CreatedAt  pgx.NullTime
+	RevokedAt  pgx.NullTime
+}
+
+// Usable reports whether the key can still authenticate callers at the time.
+func (k APIKey) Usable(at time.Time) bool {
+	return k.Id.Valid && !k.RevokedAt.Valid && (!k.ExpiresAt.Valid || at.Before(k.ExpiresAt.Time))
+}
+
+func (k APIKey) Gen() *pb.ApiKey {
+	rep := &pb.ApiKey{
+		Id:     k.Id.Int32,
+		Prefix: k.Prefix.String,
+		Owner:  k.Owner.String,
+		Scope:  k.Scope,
+	}
+	if k.ExpiresAt.Valid {
+		rep.ExpiresAt = k.ExpiresAt.Time.Format(time.RFC3339)
+	}
+	if k.LastUsedAt.Valid {
+		rep.LastUsedAt = k.LastUsedAt.Time.Format(time.RFC3339)
+	}
+	if k.CreatedAt.Valid {
+		rep.CreatedAt = k.CreatedAt.Time.Format(time.RFC3339)
+	}
+	if k.RevokedAt.Valid {
+		rep.RevokedAt = k.RevokedAt.Time.Format(time.RFC3339)
+	}
+	return rep
+}
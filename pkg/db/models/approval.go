@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jackc/pgx"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/pb"
+)
+
+// Approval request statuses.
+const (
+	ApprovalPending  = "pending"
+	ApprovalApproved = "approved"
+	ApprovalRejected = "rejected"
+)
+
+// ApprovalRequest asks for a change set that touches protected groups to be published. Groups are the protected
+// groups the change set touched when it was submitted.
+type ApprovalRequest struct {
+	Id          pgx.NullInt32
+	ChangeSetId pgx.NullInt32
+	Groups      []string
+	Status      pgx.NullString
+	RequestedBy pgx.NullString
+	RequestedAt pgx.NullTime
+	DecidedBy   pgx.NullString
+	DecidedAt   pgx.NullTime
+	Reason      pgx.NullString
+}
+
+func (r ApprovalRequest) Gen() *pb.ApprovalRequest {
+	rep := &pb.ApprovalRequest{
+		Id:          r.Id.Int32,
+		ChangeSetId: r.ChangeSetId.Int32,
+		Groups:      r.Groups,
+		Status:      r.Status.String,
+		RequestedBy: r.RequestedBy.String,
+		DecidedBy:   r.DecidedBy.String,
+		Reason:      r.Reason.String,
+	}
+	if r.RequestedAt.Valid {
+		rep.RequestedAt = r.RequestedAt.Time.Format(time.RFC3339)
+	}
+	if r.DecidedAt.Valid {
+		rep.DecidedAt = r.DecidedAt.Time.Format(time.RFC3339)
+	}
+	return rep
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"github.com/jackc/pgx"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/pb"
+)
+
+// Change set statuses. Only a draft can be staged to, published or discarded. A pending change set is waiting
+// for approval and goes back to draft if it is rejected.
+const (
+	ChangeSetDraft     = "draft"
+	ChangeSetPending   = "pending"
+	ChangeSetPublished = "published"
+	ChangeSetDiscarded = "discarded"
+)
+
+// ChangeSet is a batch of partner edits that readers do not see until it is published. Each staged partner
+// holds only the attributes the change set sets, and has no name if the change set leaves the name as it is.
+type ChangeSet struct {
+	Id       pgx.NullInt32
+	Name     pgx.NullString
+	Status   pgx.NullString
+	Partners []Partner
+}
+
+func (c ChangeSet) Gen() *pb.ChangeSetReply {
+	partners := make([]*pb.Partner, 0, len(c.Partners))
+	for _, p := range c.Partners {
+		partners = append(partners, p.Gen(p.Attributes))
+	}
+	return &pb.ChangeSetReply{
+		ChangeSetId: c.Id.Int32,
+		Name:        c.Name.String,
+		Status:      c.Status.String,
+		Partners:    partners,
+	}
+}
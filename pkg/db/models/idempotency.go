@@ -0,0 +1,15 @@
+package models
+
+import (
+	"github.com/jackc/pgx"
+)
+
+// IdempotentResponse is the stored reply to a write sent with an idempotency key. Response is not valid while the
+// write is still being served. RequestHash identifies the request the key was first used with.
+type IdempotentResponse struct {
+	Key         pgx.NullString
+	Method      pgx.NullString
+	RequestHash pgx.NullString
+	Response    pgx.NullString
+	CreatedAt   pgx.NullTime
+}
@@ -9,6 +9,8 @@ type Partner struct {
 	Name       pgx.NullString
 	Code       pgx.NullString
 	Id         pgx.NullInt32
+	Revision   pgx.NullInt32  //the revision the partner was read at, or for a staged partner the one the change was made against
+	Status     pgx.NullString //the status the partner is at, or for an import or apply the one it is created in
 	Attributes map[string]string
 }
 
@@ -23,5 +25,6 @@ func (p Partner) Gen(attrs map[string]string) *pb.Partner {
 		Code:       p.Code.String,
 		Id:         p.Id.Int32,
 		Attributes: attrs,
+		Status:     p.Status.String,
 	}
 }
@@ -0,0 +1,89 @@
+package db
+
+import (
+	"sync"
+
+	"github.com/jackc/pgx"
+	"github.com/pkg/errors"
+)
+
+//connPool is the part of *pgx.ConnPool a Pool uses
+type connPool interface {
+	Conn
+	Close()
+}
+
+//Pool is a pool of connections to the database that can be swapped for a new one, such as when the password changes.
+type Pool struct {
+	connect func(pgx.ConnPoolConfig) (connPool, error)
+
+	recycling sync.Mutex //held while a new pool connects, so that queries can go on using the old one
+	config    pgx.ConnPoolConfig
+
+	mtx  sync.RWMutex
+	pool connPool
+}
+
+//NewPool connects a pool of up to maxConnections connections to the database.
+func NewPool(conn pgx.ConnConfig, maxConnections int) (*Pool, error) {
+	return newPool(pgx.ConnPoolConfig{ConnConfig: conn, MaxConnections: maxConnections}, func(config pgx.ConnPoolConfig) (connPool, error) {
+		return pgx.NewConnPool(config)
+	})
+}
+
+func newPool(config pgx.ConnPoolConfig, connect func(pgx.ConnPoolConfig) (connPool, error)) (*Pool, error) {
+	pool, err := connect(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to database")
+	}
+	return &Pool{config: config, connect: connect, pool: pool}, nil
+}
+
+//Recycle connects a new pool with conn and swaps it in, then closes the old one. Connections the old pool has lent out
+//are closed when they are given back, so calls using them finish first. If the new pool cannot connect, the old one
+//is kept.
+func (p *Pool) Recycle(conn pgx.ConnConfig) error {
+	p.recycling.Lock()
+	defer p.recycling.Unlock()
+	config := p.config
+	config.ConnConfig = conn
+	pool, err := p.connect(config)
+	if err != nil {
+		return errors.Wrap(err, "failed to reconnect to database")
+	}
+	p.config = config
+
+	p.mtx.Lock()
+	old := p.pool
+	p.pool = pool
+	p.mtx.Unlock()
+	old.Close()
+	return nil
+}
+
+//Close closes every connection.
+func (p *Pool) Close() {
+	p.current().Close()
+}
+
+func (p *Pool) current() connPool {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	return p.pool
+}
+
+func (p *Pool) Exec(sql string, arguments ...interface{}) (pgx.CommandTag, error) {
+	return p.current().Exec(sql, arguments...)
+}
+
+func (p *Pool) Query(sql string, args ...interface{}) (*pgx.Rows, error) {
+	return p.current().Query(sql, args...)
+}
+
+func (p *Pool) QueryRow(sql string, args ...interface{}) *pgx.Row {
+	return p.current().QueryRow(sql, args...)
+}
+
+func (p *Pool) Begin() (*pgx.Tx, error) {
+	return p.current().Begin()
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePool records the password it was connected with and whether it was closed
+type fakePool struct {
+	Conn
+	password string
+	closed   bool
+}
+
+func (p *fakePool) Close() {
+	p.closed = true
+}
+
+func TestPoolRecycle(t *testing.T) {
+	a := assert.New(t)
+	var pools []*fakePool
+	connect := func(config pgx.ConnPoolConfig) (connPool, error) {
+		if config.Password == "wrong" {
+			return nil, errors.New("password authentication failed")
+		}
+		a.Equal(4, config.MaxConnections)
+		pool := &fakePool{password: config.Password}
+		pools = append(pools, pool)
+		return pool, nil
+	}
+	p, err := newPool(pgx.ConnPoolConfig{ConnConfig: pgx.ConnConfig{Password: "old"}, MaxConnections: 4}, connect)
+	a.Nil(err)
+
+	a.Nil(p.Recycle(pgx.ConnConfig{Password: "new"}))
+
+	a.Equal(2, len(pools))
+	a.True(pools[0].closed)
+	a.False(pools[1].closed)
+	a.Equal("new", p.current().(*fakePool).password)
+
+	//a password that does not work leaves the pool that does
+	a.NotNil(p.Recycle(pgx.ConnConfig{Password: "wrong"}))
+	a.Equal("new", p.current().(*fakePool).password)
+	a.False(pools[1].closed)
+}
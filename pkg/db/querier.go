@@ -4,28 +4,86 @@ import (
 	//"fmt"
 
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx"
 	"github.com/pkg/errors"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
 	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/queries"
 )
 
+//SchemaVersion is the version of the schema this code works with. It goes up with every change to the schema, along
+//with the row inserted into schema_version by the sql files.
+const SchemaVersion = 3
+
+//ErrRevisionConflict is the cause of errors from writes that expected a revision the partner is no longer at.
+var ErrRevisionConflict = queries.ErrRevisionConflict
+
 type PartnerServiceQuerier interface {
-	FindPartnerDataFromKeyValue(string, string) (int32, string, error) //KeyValue
-	FindAllAttributesForPartner(int32) (map[string]string, error)      //Used by KeyValue and Id/code
-	FindPartnerAttribute(int32, string) (map[string]string, error)     //Used by KeyValue and Id/code
-	FindPartnerDataByID(int32, string) (int32, string, error)          //Id or code
-	CheckPartnerIDEqualsPartnerCode(int32, string) (bool, error)       //check that the id and code correspond to same data
+	FindPartnerDataFromKeyValue(string, string) (int32, string, error)                              //KeyValue
+	FindAllAttributesForPartner(int32) (map[string]string, error)                                   //Used by KeyValue and Id/code
+	FindPartnerAttribute(int32, string) (map[string]string, error)                                  //Used by KeyValue and Id/code
+	FindPartnerDataByID(int32, string) (int32, string, error)                                       //Id or code
+	CheckPartnerIDEqualsPartnerCode(int32, string) (bool, error)                                    //check that the id and code correspond to same data
+	FindPartners([]string, string) ([]models.Partner, error)                                        //Export, by codes and group
+	SavePartners([]models.Partner) error                                                            //Import, all or nothing
+	ApplyPartners([]models.Partner, []models.Partner) error                                         //Plan apply, replaces attributes and deletes by code at the revisions read, 0 for creates, all or nothing
+	FindIdentifierKeys() ([]string, error)                                                          //Clone, keys that are never copied
+	FindTemplateAttributes(string, string) (map[string]string, error)                               //Clone, by template name and group
+	FindKeyGroups([]string) (map[string][]string, error)                                            //Authorization, the groups each of the keys is in
+	FindSensitiveKeys() ([]string, error)                                                           //Sealing and redaction, keys whose values are sensitive
+	CreatePartner(models.Partner) (int32, error)                                                    //Clone, fails if the code is taken
+	FindPartnerStatus(int32) (string, error)                                                        //Lookups skip partners that are not active
+	FindPartnerRevision(int32) (int32, error)                                                       //Lookups, for writes to send back as the revision they expect
+	UpdatePartnerStatus(int32, string, string, int32) (time.Time, error)                            //SetPartnerStatus, from one status to another at the expected revision
+	RestorePartner(string) (int64, error)                                                           //Restore, by code along with the attributes deleted with it
+	RestoreKey(string) (int64, error)                                                               //Restore, by name
+	RestoreGroup(string) (int64, error)                                                             //Restore, by name
+	RestorePartnerAttribute(string, string, int32) (int64, error)                                   //Restore, by partner code and key at the expected revision
+	PurgeDeleted(time.Time) (int64, error)                                                          //Purge, rows deleted before the time
+	CreateChangeSet(string) (int32, error)                                                          //OpenChangeSet, by name
+	FindChangeSet(int32) (models.ChangeSet, error)                                                  //ChangeSet with its staged partners
+	StageChange(int32, models.Partner) error                                                        //StageChange, into a draft change set
+	PublishChangeSet(int32) error                                                                   //PublishChangeSet, applies the staged partners
+	DiscardChangeSet(int32) error                                                                   //DiscardChangeSet, drops the staged partners
+	FindApprovalPolicies([]string) (map[string][]string, error)                                     //Approvers by protected group, for groups holding any of the keys
+	FindRestoreApprovalPolicies(string, string) (map[string][]string, error)                        //Restore, approvers by protected group, for groups a restore by name in partners, keys or groups brings values back into
+	RequestApproval(int32, string, []string) (int32, error)                                         //PublishChangeSet, when the change set touches protected groups
+	FindApprovalRequests(string) ([]models.ApprovalRequest, error)                                  //ListApprovalRequests, by status
+	FindApprovalRequest(int32) (models.ApprovalRequest, error)                                      //ApprovalRequest, by id
+	ApproveRequest(int32, string) error                                                             //ApproveRequest, publishes the change set
+	RejectRequest(int32, string, string) error                                                      //RejectRequest, returns the change set to draft
+	ClaimIdempotencyKey(string, string, string, time.Time) (models.IdempotentResponse, bool, error) //Idempotent writes, by key and method, taking over keys used before the time
+	SaveIdempotentResponse(string, string, string) error                                            //Idempotent writes, the reply to replay for the key
+	ReleaseIdempotencyKey(string, string) error                                                     //Idempotent writes, when the write failed and can be sent again
+	PurgeIdempotencyKeys(time.Time) (int64, error)                                                  //Purge, keys used before the time
+	RecordAccess([]models.AccessRecord) error                                                       //Access log, reads of sensitive values, all or nothing
+	FindAccessRecords(string, time.Time, time.Time) ([]models.AccessRecord, error)                  //ListAccessRecords, by partner code from one time up to another
+	CreateAPIKey(models.APIKey, string) (models.APIKey, error)                                      //IssueAPIKey, with the hash of the key
+	FindAPIKeys(string) ([]models.APIKey, error)                                                    //ListAPIKeys, by owner
+	FindAPIKeyByHash(string) (models.APIKey, error)                                                 //API key auth, without an id when there is no such key
+	RotateAPIKey(int32, string, string) (models.APIKey, error)                                      //RotateAPIKey, a new prefix and hash for a key that is not revoked
+	RevokeAPIKey(int32) (models.APIKey, error)                                                      //RevokeAPIKey, by id
+	TouchAPIKey(int32, time.Time) error                                                             //API key auth, when the key was last used
+	Ping() error                                                                                    //Readiness, the database answers
+	FindSchemaVersion() (int32, error)                                                              //Readiness, the version the schema was brought up to
 }
 
-func NewPartnerServiceQuerier(c *pgx.Conn) PartnerServiceQuerier {
+//Conn is what the querier runs its queries on, such as a *pgx.Conn or a *Pool.
+type Conn interface {
+	queries.Queryer
+	Begin() (*pgx.Tx, error)
+}
+
+func NewPartnerServiceQuerier(c Conn) PartnerServiceQuerier {
 	return querier{
 		conn: c,
 	}
 }
 
 type querier struct {
-	conn *pgx.Conn
+	conn Conn
 }
 
 //DB query for PartnerID from partners table
@@ -46,7 +104,7 @@ func (q querier) FindPartnerDataFromKeyValue(key, value string) (int32, string,
 func (q querier) FindAllAttributesForPartner(id int32) (map[string]string, error) { //DB query for PartnerAttribute from partner_mappings table
 	attribute, err := queries.GetAllAttributesForPartner(id, q.conn)
 	if err != nil {
-		err = errors.Wrap(errors.New(""), fmt.Sprintf("error finding attributes in FindAllAttributes"))
+		err = errors.Wrap(err, fmt.Sprintf("error finding attributes in FindAllAttributes"))
 		return make(map[string]string), err
 	}
 	return attribute, nil
@@ -54,7 +112,7 @@ func (q querier) FindAllAttributesForPartner(id int32) (map[string]string, error
 func (q querier) FindPartnerAttribute(id int32, group string) (map[string]string, error) { //DB query for PartnerAttribute from partner_mappings table
 	attribute, err := queries.GetGroupAttributesForPartner(id, group, q.conn)
 	if err != nil {
-		err = errors.Wrap(errors.New(""), fmt.Sprintf("error finding attributes in FindPartnerAttributes (by group)"))
+		err = errors.Wrap(err, fmt.Sprintf("error finding attributes in FindPartnerAttributes (by group)"))
 		return make(map[string]string), err
 	}
 	return attribute, nil
@@ -79,3 +137,690 @@ func (q querier) CheckPartnerIDEqualsPartnerCode(partnerId int32, code string) (
 	}
 	return areEqual, nil
 }
+
+//FindPartners returns the partners with the given codes (all partners if none are given) along with their attributes,
+//limited to the given group when one is set.
+func (q querier) FindPartners(codes []string, group string) ([]models.Partner, error) {
+	partners, err := queries.GetPartners(codes, q.conn)
+	if err != nil {
+		err = errors.Wrap(err, "error finding partners in FindPartners")
+		return nil, err
+	}
+	attrMaps, err := queries.GetAttributesForPartners(group, q.conn)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("error finding attributes for group: %s in FindPartners", group))
+		return nil, err
+	}
+	for i := range partners {
+		partners[i].Attributes = attrMaps[partners[i].Id.Int32]
+		if partners[i].Attributes == nil {
+			partners[i].Attributes = make(map[string]string)
+		}
+	}
+	return partners, nil
+}
+
+//SavePartners creates or updates each partner by code and sets the given attributes in a single transaction.
+//Attributes that are not given are left as they are. New partners are created in their status, or onboarding if they
+//have none.
+func (q querier) SavePartners(partners []models.Partner) error {
+	tx, err := q.conn.Begin()
+	if err != nil {
+		return errors.Wrap(err, "error starting transaction in SavePartners")
+	}
+	defer tx.Rollback()
+
+	for _, p := range partners {
+		id, err := queries.SavePartner(p.Name.String, p.Code.String, p.Status.String, tx)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("error saving partner: %s in SavePartners", p.Code.String))
+		}
+		if _, err = queries.BumpPartnerRevision(id, 0, tx); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("error saving partner: %s in SavePartners", p.Code.String))
+		}
+		for key, value := range p.Attributes {
+			if err = queries.SavePartnerAttribute(id, key, value, tx); err != nil {
+				return errors.Wrap(err, fmt.Sprintf("error saving attributes for partner: %s in SavePartners", p.Code.String))
+			}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "error committing transaction in SavePartners")
+	}
+	return nil
+}
+
+//ApplyPartners saves each partner with exactly the given attributes, removing any others it has, and deletes the
+//partners with the codes of the deletes, all in a single transaction. A partner or delete with a revision fails the
+//whole apply if the partner is no longer at it, so changes made since the plan was read are not overwritten. A partner
+//at revision 0 did not exist when the plan was read, and fails the apply if it has been created since.
+func (q querier) ApplyPartners(partners, deletes []models.Partner) error {
+	tx, err := q.conn.Begin()
+	if err != nil {
+		return errors.Wrap(err, "error starting transaction in ApplyPartners")
+	}
+	defer tx.Rollback()
+
+	for _, p := range partners {
+		if p.Revision.Valid && p.Revision.Int32 == 0 {
+			_, err = queries.GetPartnerID(p.Code.String, tx)
+			if err == nil {
+				err = errors.Wrap(ErrRevisionConflict, fmt.Sprintf("partner: %s was created since it was read in ApplyPartners", p.Code.String))
+				return err
+			}
+			if errors.Cause(err) != pgx.ErrNoRows {
+				return errors.Wrap(err, fmt.Sprintf("error saving partner: %s in ApplyPartners", p.Code.String))
+			}
+		}
+		id, err := queries.SavePartner(p.Name.String, p.Code.String, p.Status.String, tx)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("error saving partner: %s in ApplyPartners", p.Code.String))
+		}
+		if _, err = queries.BumpPartnerRevision(id, p.Revision.Int32, tx); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("error saving partner: %s in ApplyPartners", p.Code.String))
+		}
+		keys := make([]string, 0, len(p.Attributes))
+		for key, value := range p.Attributes {
+			if err = queries.SavePartnerAttribute(id, key, value, tx); err != nil {
+				return errors.Wrap(err, fmt.Sprintf("error saving attributes for partner: %s in ApplyPartners", p.Code.String))
+			}
+			keys = append(keys, key)
+		}
+		if err = queries.DeletePartnerAttributes(id, keys, tx); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("error removing attributes for partner: %s in ApplyPartners", p.Code.String))
+		}
+	}
+	for _, d := range deletes {
+		id, err := queries.GetPartnerID(d.Code.String, tx)
+		if err == nil {
+			_, err = queries.BumpPartnerRevision(id, d.Revision.Int32, tx)
+		}
+		if err == nil {
+			err = queries.DeletePartner(d.Code.String, tx)
+		}
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("error deleting partner: %s in ApplyPartners", d.Code.String))
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "error committing transaction in ApplyPartners")
+	}
+	return nil
+}
+
+func (q querier) FindIdentifierKeys() ([]string, error) {
+	keys, err := queries.GetIdentifierKeys(q.conn)
+	if err != nil {
+		err = errors.Wrap(err, "error finding identifier keys in FindIdentifierKeys")
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (q querier) FindKeyGroups(keys []string) (map[string][]string, error) {
+	keyGroups, err := queries.GetKeyGroups(keys, q.conn)
+	if err != nil {
+		err = errors.Wrap(err, "error finding key groups in FindKeyGroups")
+		return nil, err
+	}
+	return keyGroups, nil
+}
+
+func (q querier) FindSensitiveKeys() ([]string, error) {
+	keys, err := queries.GetSensitiveKeys(q.conn)
+	if err != nil {
+		err = errors.Wrap(err, "error finding sensitive keys in FindSensitiveKeys")
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (q querier) FindTemplateAttributes(name, group string) (map[string]string, error) {
+	attributes, err := queries.GetTemplateAttributes(name, group, q.conn)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("error finding attributes for template: %s in FindTemplateAttributes", name))
+		return nil, err
+	}
+	return attributes, nil
+}
+
+//CreatePartner inserts a new partner with its attributes in a single transaction. New partners start out onboarding.
+func (q querier) CreatePartner(p models.Partner) (int32, error) {
+	tx, err := q.conn.Begin()
+	if err != nil {
+		return 0, errors.Wrap(err, "error starting transaction in CreatePartner")
+	}
+	defer tx.Rollback()
+
+	id, err := queries.InsertPartner(p.Name.String, p.Code.String, tx)
+	if err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("error creating partner: %s in CreatePartner", p.Code.String))
+	}
+	for key, value := range p.Attributes {
+		if err = queries.SavePartnerAttribute(id, key, value, tx); err != nil {
+			return 0, errors.Wrap(err, fmt.Sprintf("error saving attributes for partner: %s in CreatePartner", p.Code.String))
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "error committing transaction in CreatePartner")
+	}
+	return id, nil
+}
+
+func (q querier) FindPartnerStatus(id int32) (string, error) {
+	status, err := queries.GetPartnerStatus(id, q.conn)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("error finding status for partnerId: %d in FindPartnerStatus", id))
+		return "", err
+	}
+	return status, nil
+}
+
+func (q querier) FindPartnerRevision(id int32) (int32, error) {
+	revision, err := queries.GetPartnerRevision(id, q.conn)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("error finding revision for partnerId: %d in FindPartnerRevision", id))
+		return 0, err
+	}
+	return revision, nil
+}
+
+//UpdatePartnerStatus moves a partner from one status to another and records the change, in a single transaction.
+//It fails if the partner's status is no longer from, or if it is not at the expected revision when one is given.
+func (q querier) UpdatePartnerStatus(id int32, from, to string, expectedRevision int32) (time.Time, error) {
+	tx, err := q.conn.Begin()
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "error starting transaction in UpdatePartnerStatus")
+	}
+	defer tx.Rollback()
+
+	if _, err = queries.BumpPartnerRevision(id, expectedRevision, tx); err != nil {
+		return time.Time{}, errors.Wrap(err, fmt.Sprintf("error updating status for partnerId: %d in UpdatePartnerStatus", id))
+	}
+	changedAt, err := queries.UpdatePartnerStatus(id, from, to, tx)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, fmt.Sprintf("error updating status for partnerId: %d in UpdatePartnerStatus", id))
+	}
+
+	if err = tx.Commit(); err != nil {
+		return time.Time{}, errors.Wrap(err, "error committing transaction in UpdatePartnerStatus")
+	}
+	return changedAt, nil
+}
+
+//RestorePartner brings back a deleted partner and the attributes that were deleted with it, in a single transaction.
+func (q querier) RestorePartner(code string) (int64, error) {
+	tx, err := q.conn.Begin()
+	if err != nil {
+		return 0, errors.Wrap(err, "error starting transaction in RestorePartner")
+	}
+	defer tx.Rollback()
+
+	restored, err := queries.RestorePartner(code, tx)
+	if err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("error restoring partner: %s in RestorePartner", code))
+	}
+	id, err := queries.GetPartnerID(code, tx)
+	if err == nil {
+		_, err = queries.BumpPartnerRevision(id, 0, tx)
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("error restoring partner: %s in RestorePartner", code))
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "error committing transaction in RestorePartner")
+	}
+	return restored, nil
+}
+
+func (q querier) RestoreKey(name string) (int64, error) {
+	restored, err := queries.RestoreKey(name, q.conn)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("error restoring key: %s in RestoreKey", name))
+		return 0, err
+	}
+	return restored, nil
+}
+
+func (q querier) RestoreGroup(name string) (int64, error) {
+	restored, err := queries.RestoreGroup(name, q.conn)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("error restoring group: %s in RestoreGroup", name))
+		return 0, err
+	}
+	return restored, nil
+}
+
+//RestorePartnerAttribute brings back a deleted value of the key for the partner, in a single transaction. It fails if
+//the partner is not at the expected revision when one is given.
+func (q querier) RestorePartnerAttribute(code, key string, expectedRevision int32) (int64, error) {
+	tx, err := q.conn.Begin()
+	if err != nil {
+		return 0, errors.Wrap(err, "error starting transaction in RestorePartnerAttribute")
+	}
+	defer tx.Rollback()
+
+	id, err := queries.GetPartnerID(code, tx)
+	if err == nil {
+		_, err = queries.BumpPartnerRevision(id, expectedRevision, tx)
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("error restoring key: %s for partner: %s in RestorePartnerAttribute", key, code))
+	}
+	restored, err := queries.RestorePartnerAttribute(code, key, tx)
+	if err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("error restoring key: %s for partner: %s in RestorePartnerAttribute", key, code))
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "error committing transaction in RestorePartnerAttribute")
+	}
+	return restored, nil
+}
+
+//PurgeDeleted permanently removes every row deleted before the given time, in a single transaction.
+func (q querier) PurgeDeleted(before time.Time) (int64, error) {
+	tx, err := q.conn.Begin()
+	if err != nil {
+		return 0, errors.Wrap(err, "error starting transaction in PurgeDeleted")
+	}
+	defer tx.Rollback()
+
+	purged, err := queries.PurgeDeleted(before, tx)
+	if err != nil {
+		return 0, errors.Wrap(err, "error purging deleted rows in PurgeDeleted")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "error committing transaction in PurgeDeleted")
+	}
+	return purged, nil
+}
+
+func (q querier) CreateChangeSet(name string) (int32, error) {
+	id, err := queries.InsertChangeSet(name, q.conn)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("error creating change set: %s in CreateChangeSet", name))
+		return 0, err
+	}
+	return id, nil
+}
+
+func (q querier) FindChangeSet(id int32) (models.ChangeSet, error) {
+	changeSet, err := queries.GetChangeSet(id, q.conn)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("error finding change set: %d in FindChangeSet", id))
+		return models.ChangeSet{}, err
+	}
+	changeSet.Partners, err = queries.GetChangeSetPartners(id, q.conn)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("error finding staged partners for change set: %d in FindChangeSet", id))
+		return models.ChangeSet{}, err
+	}
+	return changeSet, nil
+}
+
+//StageChange adds a partner to a draft change set, in a single transaction so the change set cannot be
+//published or discarded halfway through.
+func (q querier) StageChange(id int32, partner models.Partner) error {
+	tx, err := q.conn.Begin()
+	if err != nil {
+		return errors.Wrap(err, "error starting transaction in StageChange")
+	}
+	defer tx.Rollback()
+
+	if err = queries.LockChangeSet(id, models.ChangeSetDraft, tx); err != nil {
+		return errors.Wrap(err, "error staging change in StageChange")
+	}
+	if err = queries.StageChangeSetPartner(id, partner, tx); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("error staging partner: %s in StageChange", partner.Code.String))
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "error committing transaction in StageChange")
+	}
+	return nil
+}
+
+//PublishChangeSet saves every staged partner and marks the change set published in a single transaction.
+//Staged attributes are set on the partner and attributes that are not staged are left as they are.
+func (q querier) PublishChangeSet(id int32) error {
+	tx, err := q.conn.Begin()
+	if err != nil {
+		return errors.Wrap(err, "error starting transaction in PublishChangeSet")
+	}
+	defer tx.Rollback()
+
+	if err = queries.LockChangeSet(id, models.ChangeSetDraft, tx); err != nil {
+		return errors.Wrap(err, "error publishing change set in PublishChangeSet")
+	}
+	if err = publishStaged(id, tx); err != nil {
+		return errors.Wrap(err, "error publishing change set in PublishChangeSet")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "error committing transaction in PublishChangeSet")
+	}
+	return nil
+}
+
+func (q querier) DiscardChangeSet(id int32) error {
+	tx, err := q.conn.Begin()
+	if err != nil {
+		return errors.Wrap(err, "error starting transaction in DiscardChangeSet")
+	}
+	defer tx.Rollback()
+
+	if err = queries.LockChangeSet(id, models.ChangeSetDraft, tx); err != nil {
+		return errors.Wrap(err, "error discarding change set in DiscardChangeSet")
+	}
+	if err = queries.CloseChangeSet(id, models.ChangeSetDiscarded, tx); err != nil {
+		return errors.Wrap(err, "error closing change set in DiscardChangeSet")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "error committing transaction in DiscardChangeSet")
+	}
+	return nil
+}
+
+//publishStaged saves every partner staged in the change set and closes it as published. A partner staged against a
+//revision it is no longer at fails the whole change set.
+func publishStaged(id int32, conn queries.Queryer) error {
+	partners, err := queries.GetChangeSetPartners(id, conn)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("error finding staged partners for change set: %d", id))
+	}
+	for _, p := range partners {
+		var partnerId int32
+		if p.Name.Valid {
+			partnerId, err = queries.SavePartner(p.Name.String, p.Code.String, "", conn)
+		} else {
+			partnerId, err = queries.GetPartnerID(p.Code.String, conn)
+		}
+		if err == nil {
+			_, err = queries.BumpPartnerRevision(partnerId, p.Revision.Int32, conn)
+		}
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("error saving partner: %s", p.Code.String))
+		}
+		for key, value := range p.Attributes {
+			if err = queries.SavePartnerAttribute(partnerId, key, value, conn); err != nil {
+				return errors.Wrap(err, fmt.Sprintf("error saving attributes for partner: %s", p.Code.String))
+			}
+		}
+	}
+	if err = queries.CloseChangeSet(id, models.ChangeSetPublished, conn); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("error closing change set: %d", id))
+	}
+	return nil
+}
+
+func (q querier) FindApprovalPolicies(keys []string) (map[string][]string, error) {
+	policies, err := queries.GetApprovalPolicies(keys, q.conn)
+	if err != nil {
+		err = errors.Wrap(err, "error finding approval policies in FindApprovalPolicies")
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (q querier) FindRestoreApprovalPolicies(table, name string) (map[string][]string, error) {
+	policies, err := queries.GetRestoreApprovalPolicies(table, name, q.conn)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("error finding approval policies to restore %s: %s in FindRestoreApprovalPolicies", table, name))
+		return nil, err
+	}
+	return policies, nil
+}
+
+//RequestApproval moves a draft change set to pending and records who asked for it to be published, in a single
+//transaction.
+func (q querier) RequestApproval(changeSetId int32, requestedBy string, groups []string) (int32, error) {
+	tx, err := q.conn.Begin()
+	if err != nil {
+		return 0, errors.Wrap(err, "error starting transaction in RequestApproval")
+	}
+	defer tx.Rollback()
+
+	if err = queries.LockChangeSet(changeSetId, models.ChangeSetDraft, tx); err != nil {
+		return 0, errors.Wrap(err, "error requesting approval in RequestApproval")
+	}
+	if err = queries.UpdateChangeSetStatus(changeSetId, models.ChangeSetPending, tx); err != nil {
+		return 0, errors.Wrap(err, "error requesting approval in RequestApproval")
+	}
+	id, err := queries.InsertApprovalRequest(changeSetId, requestedBy, groups, tx)
+	if err != nil {
+		return 0, errors.Wrap(err, "error requesting approval in RequestApproval")
+	}
+	err = queries.InsertAudit("approval requested", requestedBy, fmt.Sprintf("approval request %d", id), fmt.Sprintf("change set %d touches %s", changeSetId, strings.Join(groups, ", ")), tx)
+	if err != nil {
+		return 0, errors.Wrap(err, "error recording approval request in RequestApproval")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "error committing transaction in RequestApproval")
+	}
+	return id, nil
+}
+
+func (q querier) FindApprovalRequests(status string) ([]models.ApprovalRequest, error) {
+	requests, err := queries.GetApprovalRequests(status, q.conn)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("error finding approval requests with status: %s in FindApprovalRequests", status))
+		return nil, err
+	}
+	return requests, nil
+}
+
+func (q querier) FindApprovalRequest(id int32) (models.ApprovalRequest, error) {
+	request, err := queries.GetApprovalRequest(id, q.conn)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("error finding approval request: %d in FindApprovalRequest", id))
+		return models.ApprovalRequest{}, err
+	}
+	return request, nil
+}
+
+//ApproveRequest publishes the change set behind a pending request and records the approval, in a single transaction.
+func (q querier) ApproveRequest(id int32, approver string) error {
+	tx, err := q.conn.Begin()
+	if err != nil {
+		return errors.Wrap(err, "error starting transaction in ApproveRequest")
+	}
+	defer tx.Rollback()
+
+	request, err := queries.LockPendingApprovalRequest(id, tx)
+	if err != nil {
+		return errors.Wrap(err, "error approving request in ApproveRequest")
+	}
+	changeSetId := request.ChangeSetId.Int32
+	if err = queries.LockChangeSet(changeSetId, models.ChangeSetPending, tx); err != nil {
+		return errors.Wrap(err, "error approving request in ApproveRequest")
+	}
+	if err = publishStaged(changeSetId, tx); err != nil {
+		return errors.Wrap(err, "error publishing change set in ApproveRequest")
+	}
+	if err = queries.DecideApprovalRequest(id, models.ApprovalApproved, approver, "", tx); err != nil {
+		return errors.Wrap(err, "error approving request in ApproveRequest")
+	}
+	err = queries.InsertAudit("approval approved", approver, fmt.Sprintf("approval request %d", id), fmt.Sprintf("change set %d published", changeSetId), tx)
+	if err != nil {
+		return errors.Wrap(err, "error recording approval in ApproveRequest")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "error committing transaction in ApproveRequest")
+	}
+	return nil
+}
+
+//RejectRequest returns the change set behind a pending request to draft and records the rejection, in a single
+//transaction.
+func (q querier) RejectRequest(id int32, approver, reason string) error {
+	tx, err := q.conn.Begin()
+	if err != nil {
+		return errors.Wrap(err, "error starting transaction in RejectRequest")
+	}
+	defer tx.Rollback()
+
+	request, err := queries.LockPendingApprovalRequest(id, tx)
+	if err != nil {
+		return errors.Wrap(err, "error rejecting request in RejectRequest")
+	}
+	changeSetId := request.ChangeSetId.Int32
+	if err = queries.LockChangeSet(changeSetId, models.ChangeSetPending, tx); err != nil {
+		return errors.Wrap(err, "error rejecting request in RejectRequest")
+	}
+	if err = queries.UpdateChangeSetStatus(changeSetId, models.ChangeSetDraft, tx); err != nil {
+		return errors.Wrap(err, "error rejecting request in RejectRequest")
+	}
+	if err = queries.DecideApprovalRequest(id, models.ApprovalRejected, approver, reason, tx); err != nil {
+		return errors.Wrap(err, "error rejecting request in RejectRequest")
+	}
+	err = queries.InsertAudit("approval rejected", approver, fmt.Sprintf("approval request %d", id), fmt.Sprintf("change set %d returned to draft: %s", changeSetId, reason), tx)
+	if err != nil {
+		return errors.Wrap(err, "error recording rejection in RejectRequest")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "error committing transaction in RejectRequest")
+	}
+	return nil
+}
+
+//ClaimIdempotencyKey takes the key for a write, in a single transaction. When the key is already taken, the write
+//that took it is returned instead, with false.
+func (q querier) ClaimIdempotencyKey(key, method, requestHash string, since time.Time) (models.IdempotentResponse, bool, error) {
+	tx, err := q.conn.Begin()
+	if err != nil {
+		return models.IdempotentResponse{}, false, errors.Wrap(err, "error starting transaction in ClaimIdempotencyKey")
+	}
+	defer tx.Rollback()
+
+	claimed, err := queries.InsertIdempotencyKey(key, method, requestHash, since, tx)
+	if err != nil {
+		return models.IdempotentResponse{}, false, errors.Wrap(err, "error claiming key in ClaimIdempotencyKey")
+	}
+	var stored models.IdempotentResponse
+	if !claimed {
+		stored, err = queries.GetIdempotentResponse(key, method, tx)
+		if err != nil {
+			return models.IdempotentResponse{}, false, errors.Wrap(err, "error finding response in ClaimIdempotencyKey")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.IdempotentResponse{}, false, errors.Wrap(err, "error committing transaction in ClaimIdempotencyKey")
+	}
+	return stored, claimed, nil
+}
+
+func (q querier) SaveIdempotentResponse(key, method, response string) error {
+	return queries.UpdateIdempotentResponse(key, method, response, q.conn)
+}
+
+func (q querier) ReleaseIdempotencyKey(key, method string) error {
+	return queries.DeleteIdempotencyKey(key, method, q.conn)
+}
+
+func (q querier) PurgeIdempotencyKeys(before time.Time) (int64, error) {
+	return queries.DeleteIdempotencyKeys(before, q.conn)
+}
+
+//RecordAccess appends the records to the access log in a single transaction.
+func (q querier) RecordAccess(records []models.AccessRecord) error {
+	tx, err := q.conn.Begin()
+	if err != nil {
+		return errors.Wrap(err, "error starting transaction in RecordAccess")
+	}
+	defer tx.Rollback()
+
+	for _, record := range records {
+		if err = queries.InsertAccessRecord(record, tx); err != nil {
+			return errors.Wrap(err, "error recording access in RecordAccess")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "error committing transaction in RecordAccess")
+	}
+	return nil
+}
+
+func (q querier) FindAccessRecords(partnerCode string, from, to time.Time) ([]models.AccessRecord, error) {
+	records, err := queries.GetAccessRecords(partnerCode, from, to, q.conn)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("error finding access records for partner: %s in FindAccessRecords", partnerCode))
+		return nil, err
+	}
+	return records, nil
+}
+
+func (q querier) CreateAPIKey(key models.APIKey, hash string) (models.APIKey, error) {
+	created, err := queries.InsertAPIKey(key, hash, q.conn)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("error creating api key for owner: %s in CreateAPIKey", key.Owner.String))
+		return models.APIKey{}, err
+	}
+	return created, nil
+}
+
+func (q querier) FindAPIKeys(owner string) ([]models.APIKey, error) {
+	keys, err := queries.GetAPIKeys(owner, q.conn)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("error finding api keys of owner: %s in FindAPIKeys", owner))
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (q querier) FindAPIKeyByHash(hash string) (models.APIKey, error) {
+	key, err := queries.GetAPIKeyByHash(hash, q.conn)
+	if err != nil {
+		err = errors.Wrap(err, "error finding api key in FindAPIKeyByHash")
+		return models.APIKey{}, err
+	}
+	return key, nil
+}
+
+func (q querier) RotateAPIKey(id int32, prefix, hash string) (models.APIKey, error) {
+	key, err := queries.UpdateAPIKeyHash(id, prefix, hash, q.conn)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("error rotating api key: %d in RotateAPIKey", id))
+		return models.APIKey{}, err
+	}
+	return key, nil
+}
+
+func (q querier) RevokeAPIKey(id int32) (models.APIKey, error) {
+	key, err := queries.UpdateAPIKeyRevoked(id, q.conn)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("error revoking api key: %d in RevokeAPIKey", id))
+		return models.APIKey{}, err
+	}
+	return key, nil
+}
+
+func (q querier) TouchAPIKey(id int32, usedAt time.Time) error {
+	if err := queries.UpdateAPIKeyLastUsed(id, usedAt, q.conn); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("error touching api key: %d in TouchAPIKey", id))
+	}
+	return nil
+}
+
+func (q querier) Ping() error {
+	return queries.Ping(q.conn)
+}
+
+func (q querier) FindSchemaVersion() (int32, error) {
+	version, err := queries.GetSchemaVersion(q.conn)
+	if err != nil {
+		return 0, errors.Wrap(err, "error finding schema version in FindSchemaVersion")
+	}
+	return version, nil
+}
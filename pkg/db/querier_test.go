@@ -1,13 +1,19 @@
 package db
 
 import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgx"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/dbconfig"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/secrets"
 )
 
 var testQuerier PartnerServiceQuerier
@@ -33,29 +39,79 @@ func (suite *QuerierMethodsSuite) SetupTest() {
 	}
 
 	testConn.Exec("DROP TABLE keys cascade;")
-	testConn.Exec("CREATE TABLE keys (id serial primary key,name varchar);")
+	testConn.Exec("CREATE TABLE keys (id serial primary key,name varchar, identifier boolean NOT NULL DEFAULT false, sensitive boolean NOT NULL DEFAULT false, deleted_at timestamptz);")
+	testConn.Exec("CREATE UNIQUE INDEX keys_live_name ON keys (name) WHERE deleted_at IS NULL;")
 	testConn.Exec("INSERT INTO keys (name) VALUES ('Currency');")
 	testConn.Exec("INSERT INTO keys (name) VALUES ('Type of Payment');")
+	testConn.Exec("INSERT INTO keys (name, identifier) VALUES ('ISAID', true);")
 
 	testConn.Exec("DROP TABLE groups cascade;")
-	testConn.Exec("CREATE TABLE groups (id serial primary key, name varchar);")
+	testConn.Exec("CREATE TABLE groups (id serial primary key, name varchar, deleted_at timestamptz);")
 	testConn.Exec("INSERT INTO groups (name) VALUES ('EDI');")
 	testConn.Exec("INSERT INTO groups (name) VALUES ('Style');")
 	testConn.Exec("INSERT INTO groups (name) VALUES ('Money');")
 
 	testConn.Exec("DROP TABLE partners cascade;")
-	testConn.Exec("CREATE TABLE partners (id serial primary key, name varchar, code varchar);")
+	testConn.Exec("CREATE TABLE partners (id serial primary key, name varchar, code varchar, status varchar NOT NULL DEFAULT 'active', status_changed_at timestamptz NOT NULL DEFAULT now(), revision int NOT NULL DEFAULT 1, deleted_at timestamptz);")
 	testConn.Exec("INSERT INTO partners (name, code) VALUES ('Kohls', 'KOH');")
 
+	testConn.Exec("DROP TABLE partner_status_changes cascade;")
+	testConn.Exec("CREATE TABLE partner_status_changes (id serial primary key, partner_id int, from_status varchar, to_status varchar, changed_at timestamptz NOT NULL DEFAULT now(), FOREIGN KEY(partner_id) REFERENCES partners(id));")
+
 	testConn.Exec("DROP TABLE groups_to_keys cascade;")
 	testConn.Exec("CREATE TABLE groups_to_keys (id serial primary key, group_id int, key_id int, FOREIGN KEY(group_id) REFERENCES groups(id), FOREIGN KEY(key_id) REFERENCES keys(id));")
 	testConn.Exec("INSERT INTO groups_to_keys (group_id, key_id) VALUES (3, 1);")
 	testConn.Exec("INSERT INTO groups_to_keys (group_id, key_id) VALUES (3, 2);")
 
 	testConn.Exec("DROP TABLE partner_mappings cascade;")
-	testConn.Exec("CREATE TABLE partner_mappings (id serial primary key, partner_id int, key_id int, FOREIGN KEY(partner_id) REFERENCES keys(id), FOREIGN KEY(key_id) REFERENCES keys(id), value varchar);")
+	testConn.Exec("CREATE TABLE partner_mappings (id serial primary key, partner_id int, key_id int, FOREIGN KEY(partner_id) REFERENCES partners(id), FOREIGN KEY(key_id) REFERENCES keys(id), value varchar, deleted_at timestamptz);")
 	testConn.Exec("INSERT INTO partner_mappings (partner_id, key_id, value) VALUES (1, 1, 'USD');")
 	testConn.Exec("INSERT INTO partner_mappings (partner_id, key_id, value) VALUES (1, 2, 'Credit');")
+
+	testConn.Exec("DROP TABLE templates cascade;")
+	testConn.Exec("CREATE TABLE templates (id serial primary key, name varchar UNIQUE);")
+	testConn.Exec("INSERT INTO templates (name) VALUES ('Standard US EDI retailer');")
+
+	testConn.Exec("DROP TABLE template_mappings cascade;")
+	testConn.Exec("CREATE TABLE template_mappings (id serial primary key, template_id int, key_id int, FOREIGN KEY(template_id) REFERENCES templates(id), FOREIGN KEY(key_id) REFERENCES keys(id), value varchar);")
+	testConn.Exec("INSERT INTO template_mappings (template_id, key_id, value) VALUES (1, 1, 'USD');")
+	testConn.Exec("INSERT INTO template_mappings (template_id, key_id, value) VALUES (1, 2, 'Credit');")
+
+	testConn.Exec("DROP TABLE change_sets cascade;")
+	testConn.Exec("CREATE TABLE change_sets (id serial primary key, name varchar, status varchar NOT NULL DEFAULT 'draft', created_at timestamptz NOT NULL DEFAULT now(), closed_at timestamptz);")
+
+	testConn.Exec("DROP TABLE change_set_partners cascade;")
+	testConn.Exec("CREATE TABLE change_set_partners (id serial primary key, change_set_id int, code varchar, name varchar, expected_revision int, FOREIGN KEY(change_set_id) REFERENCES change_sets(id), UNIQUE(change_set_id, code));")
+
+	testConn.Exec("DROP TABLE change_set_attributes cascade;")
+	testConn.Exec("CREATE TABLE change_set_attributes (id serial primary key, change_set_partner_id int, key varchar, value varchar, FOREIGN KEY(change_set_partner_id) REFERENCES change_set_partners(id), UNIQUE(change_set_partner_id, key));")
+
+	testConn.Exec("DROP TABLE approval_policies cascade;")
+	testConn.Exec("CREATE TABLE approval_policies (id serial primary key, group_id int, approver varchar, FOREIGN KEY(group_id) REFERENCES groups(id), UNIQUE(group_id, approver));")
+	testConn.Exec("INSERT INTO approval_policies (group_id, approver) VALUES (3, 'controller');")
+	testConn.Exec("INSERT INTO approval_policies (group_id, approver) VALUES (3, 'finance-lead');")
+
+	testConn.Exec("DROP TABLE approval_requests cascade;")
+	testConn.Exec("CREATE TABLE approval_requests (id serial primary key, change_set_id int, groups varchar[], status varchar NOT NULL DEFAULT 'pending', requested_by varchar, requested_at timestamptz NOT NULL DEFAULT now(), decided_by varchar, decided_at timestamptz, reason varchar, FOREIGN KEY(change_set_id) REFERENCES change_sets(id));")
+
+	testConn.Exec("DROP TABLE audit_log cascade;")
+	testConn.Exec("CREATE TABLE audit_log (id serial primary key, action varchar, actor varchar, subject varchar, detail varchar, created_at timestamptz NOT NULL DEFAULT now());")
+
+	testConn.Exec("DROP TABLE idempotency_keys cascade;")
+	testConn.Exec("CREATE TABLE idempotency_keys (id serial primary key, idempotency_key varchar NOT NULL, method varchar NOT NULL, request_hash varchar, response varchar, created_at timestamptz NOT NULL DEFAULT now(), UNIQUE(idempotency_key, method));")
+
+	testConn.Exec("DROP TABLE access_log cascade;")
+	testConn.Exec("CREATE TABLE access_log (id serial primary key, caller varchar NOT NULL, partner_code varchar NOT NULL, keys varchar[] NOT NULL, request_id varchar, read_at timestamptz NOT NULL DEFAULT now());")
+	testConn.Exec("CREATE RULE access_log_no_update AS ON UPDATE TO access_log DO INSTEAD NOTHING;")
+	testConn.Exec("CREATE RULE access_log_no_delete AS ON DELETE TO access_log DO INSTEAD NOTHING;")
+	testConn.Exec("DROP TABLE api_keys cascade;")
+	testConn.Exec("CREATE TABLE api_keys (id serial primary key, prefix varchar NOT NULL UNIQUE, hash varchar NOT NULL UNIQUE, owner varchar NOT NULL, scope varchar[] NOT NULL, issued_by varchar NOT NULL, expires_at timestamptz, last_used_at timestamptz, created_at timestamptz NOT NULL DEFAULT now(), revoked_at timestamptz);")
+
+	testConn.Exec("DROP TABLE schema_version cascade;")
+	testConn.Exec("CREATE TABLE schema_version (version int NOT NULL, applied_at timestamptz NOT NULL DEFAULT now());")
+	testConn.Exec("INSERT INTO schema_version (version) VALUES (1);")
+	testConn.Exec("INSERT INTO schema_version (version) VALUES (2);")
+	testConn.Exec("INSERT INTO schema_version (version) VALUES (3);")
 	testQuerier = NewPartnerServiceQuerier(testConn)
 }
 
@@ -254,3 +310,902 @@ func (suite *QuerierMethodsSuite) TestFindPartnerAttributeNilGroup() {
 	a.Equal(make(map[string]string), attributes)
 	a.NotNil(err)
 }
+
+//tests for ApplyPartners
+//deletes are the partners with the codes, to delete whatever revision they are at.
+func deletes(codes ...string) []models.Partner {
+	partners := make([]models.Partner, 0, len(codes))
+	for _, code := range codes {
+		partners = append(partners, models.Partner{Code: pgx.NullString{String: code, Valid: true}})
+	}
+	return partners
+}
+
+func (suite *QuerierMethodsSuite) TestApplyPartnersReplacesAttributes() {
+	a := assert.New(suite.T())
+	partners := []models.Partner{{
+		Name:       pgx.NullString{String: "Kohls", Valid: true},
+		Code:       pgx.NullString{String: "KOH", Valid: true},
+		Attributes: map[string]string{"Currency": "CAD"},
+	}}
+
+	err := testQuerier.ApplyPartners(partners, nil)
+	a.Nil(err)
+
+	attributes, err := testQuerier.FindAllAttributesForPartner(int32(1))
+	a.Nil(err)
+	a.Equal(map[string]string{"Currency": "CAD"}, attributes)
+}
+
+func (suite *QuerierMethodsSuite) TestApplyPartnersDelete() {
+	a := assert.New(suite.T())
+
+	err := testQuerier.ApplyPartners(nil, deletes("KOH"))
+	a.Nil(err)
+
+	partners, err := testQuerier.FindPartners(nil, "")
+	a.Nil(err)
+	a.Equal(0, len(partners))
+}
+
+func (suite *QuerierMethodsSuite) TestApplyPartnersBadDeleteRollsBack() {
+	a := assert.New(suite.T())
+	partners := []models.Partner{{
+		Name:       pgx.NullString{String: "Kohls", Valid: true},
+		Code:       pgx.NullString{String: "KOH", Valid: true},
+		Attributes: map[string]string{"Currency": "CAD"},
+	}}
+
+	err := testQuerier.ApplyPartners(partners, deletes("lkjhg"))
+	a.NotNil(err)
+
+	attributes, err := testQuerier.FindAllAttributesForPartner(int32(1))
+	a.Nil(err)
+	a.Equal("USD", attributes["Currency"])
+}
+
+func (suite *QuerierMethodsSuite) TestApplyPartnersChangedSinceRead() {
+	a := assert.New(suite.T())
+	read, err := testQuerier.FindPartners([]string{"KOH"}, "")
+	a.Nil(err)
+	a.Equal(int32(1), read[0].Revision.Int32)
+
+	//someone else changes the partner between the plan and the apply
+	_, err = testQuerier.UpdatePartnerStatus(int32(1), "active", "suspended", int32(0))
+	a.Nil(err)
+
+	read[0].Attributes = map[string]string{"Currency": "CAD"}
+	err = testQuerier.ApplyPartners(read, nil)
+	a.Equal(ErrRevisionConflict, errors.Cause(err))
+	err = testQuerier.ApplyPartners(nil, []models.Partner{{Code: read[0].Code, Revision: read[0].Revision}})
+	a.Equal(ErrRevisionConflict, errors.Cause(err))
+
+	attributes, err := testQuerier.FindAllAttributesForPartner(int32(1))
+	a.Nil(err)
+	a.Equal("USD", attributes["Currency"])
+}
+
+func (suite *QuerierMethodsSuite) TestApplyPartnersCreatedSinceRead() {
+	a := assert.New(suite.T())
+	partners := []models.Partner{{
+		Name:       pgx.NullString{String: "Kohls", Valid: true},
+		Code:       pgx.NullString{String: "KOH", Valid: true},
+		Revision:   pgx.NullInt32{Int32: 0, Valid: true},
+		Attributes: map[string]string{"Currency": "CAD"},
+	}}
+
+	err := testQuerier.ApplyPartners(partners, nil)
+	a.Equal(ErrRevisionConflict, errors.Cause(err))
+
+	attributes, err := testQuerier.FindAllAttributesForPartner(int32(1))
+	a.Nil(err)
+	a.Equal("USD", attributes["Currency"])
+}
+
+//tests for FindIdentifierKeys
+func (suite *QuerierMethodsSuite) TestFindIdentifierKeysHappy() {
+	a := assert.New(suite.T())
+
+	keys, err := testQuerier.FindIdentifierKeys()
+	a.Nil(err)
+	a.Equal([]string{"ISAID"}, keys)
+}
+
+//tests for FindTemplateAttributes
+func (suite *QuerierMethodsSuite) TestFindTemplateAttributesHappy() {
+	a := assert.New(suite.T())
+
+	attributes, err := testQuerier.FindTemplateAttributes("Standard US EDI retailer", "")
+	a.Nil(err)
+	a.Equal(map[string]string{"Currency": "USD", "Type of Payment": "Credit"}, attributes)
+}
+
+func (suite *QuerierMethodsSuite) TestFindTemplateAttributesBadName() {
+	a := assert.New(suite.T())
+
+	attributes, err := testQuerier.FindTemplateAttributes("lkjhg", "")
+	a.Nil(attributes)
+	a.NotNil(err)
+}
+
+//tests for CreatePartner
+func (suite *QuerierMethodsSuite) TestCreatePartnerHappy() {
+	a := assert.New(suite.T())
+	partner := models.Partner{
+		Name:       pgx.NullString{String: "Dicks", Valid: true},
+		Code:       pgx.NullString{String: "DIC", Valid: true},
+		Attributes: map[string]string{"Currency": "USD"},
+	}
+
+	id, err := testQuerier.CreatePartner(partner)
+	a.Nil(err)
+	a.Equal(int32(2), id)
+
+	attributes, err := testQuerier.FindAllAttributesForPartner(id)
+	a.Nil(err)
+	a.Equal(map[string]string{"Currency": "USD"}, attributes)
+}
+
+func (suite *QuerierMethodsSuite) TestCreatePartnerCodeTaken() {
+	a := assert.New(suite.T())
+	partner := models.Partner{
+		Name:       pgx.NullString{String: "Kohls Again", Valid: true},
+		Code:       pgx.NullString{String: "KOH", Valid: true},
+		Attributes: map[string]string{"Currency": "CAD"},
+	}
+
+	id, err := testQuerier.CreatePartner(partner)
+	a.Equal(int32(0), id)
+	a.NotNil(err)
+}
+
+//tests for FindPartnerStatus and UpdatePartnerStatus
+func (suite *QuerierMethodsSuite) TestFindPartnerStatusHappy() {
+	a := assert.New(suite.T())
+
+	status, err := testQuerier.FindPartnerStatus(int32(1))
+	a.Nil(err)
+	a.Equal("active", status)
+}
+
+func (suite *QuerierMethodsSuite) TestFindPartnerStatusBadId() {
+	a := assert.New(suite.T())
+
+	status, err := testQuerier.FindPartnerStatus(int32(-1))
+	a.Equal("", status)
+	a.NotNil(err)
+}
+
+func (suite *QuerierMethodsSuite) TestUpdatePartnerStatusHappy() {
+	a := assert.New(suite.T())
+
+	changedAt, err := testQuerier.UpdatePartnerStatus(int32(1), "active", "suspended", int32(0))
+	a.Nil(err)
+	a.False(changedAt.IsZero())
+
+	status, err := testQuerier.FindPartnerStatus(int32(1))
+	a.Nil(err)
+	a.Equal("suspended", status)
+
+	revision, err := testQuerier.FindPartnerRevision(int32(1))
+	a.Nil(err)
+	a.Equal(int32(2), revision)
+}
+
+func (suite *QuerierMethodsSuite) TestUpdatePartnerStatusStale() {
+	a := assert.New(suite.T())
+
+	changedAt, err := testQuerier.UpdatePartnerStatus(int32(1), "onboarding", "active", int32(0))
+	a.True(changedAt.IsZero())
+	a.NotNil(err)
+}
+
+//tests for revisions
+func (suite *QuerierMethodsSuite) TestUpdatePartnerStatusExpectedRevision() {
+	a := assert.New(suite.T())
+
+	_, err := testQuerier.UpdatePartnerStatus(int32(1), "active", "suspended", int32(1))
+	a.Nil(err)
+
+	_, err = testQuerier.UpdatePartnerStatus(int32(1), "suspended", "active", int32(1))
+	a.Equal(ErrRevisionConflict, errors.Cause(err))
+
+	status, err := testQuerier.FindPartnerStatus(int32(1))
+	a.Nil(err)
+	a.Equal("suspended", status)
+}
+
+func (suite *QuerierMethodsSuite) TestSavePartnersBumpsRevision() {
+	a := assert.New(suite.T())
+
+	err := testQuerier.SavePartners([]models.Partner{{
+		Name:       pgx.NullString{String: "Kohls", Valid: true},
+		Code:       pgx.NullString{String: "KOH", Valid: true},
+		Attributes: map[string]string{"Currency": "CAD"},
+	}})
+	a.Nil(err)
+
+	revision, err := testQuerier.FindPartnerRevision(int32(1))
+	a.Nil(err)
+	a.Equal(int32(2), revision)
+}
+
+func (suite *QuerierMethodsSuite) TestSavePartnersWithStatus() {
+	a := assert.New(suite.T())
+
+	err := testQuerier.SavePartners([]models.Partner{{
+		Name:   pgx.NullString{String: "Mustang", Valid: true},
+		Code:   pgx.NullString{String: "MUS", Valid: true},
+		Status: pgx.NullString{String: "active", Valid: true},
+	}})
+	a.Nil(err)
+
+	partners, err := testQuerier.FindPartners([]string{"MUS"}, "")
+	a.Nil(err)
+	a.Equal(1, len(partners))
+	a.Equal("active", partners[0].Status.String)
+}
+
+func (suite *QuerierMethodsSuite) TestSavePartnersDeletedKey() {
+	a := assert.New(suite.T())
+	testConn.Exec("UPDATE keys SET deleted_at = now() WHERE name = 'Type of Payment';")
+
+	err := testQuerier.SavePartners([]models.Partner{{
+		Name:       pgx.NullString{String: "Kohls", Valid: true},
+		Code:       pgx.NullString{String: "KOH", Valid: true},
+		Attributes: map[string]string{"Type of Payment": "Check"},
+	}})
+	a.NotNil(err)
+
+	restored, err := testQuerier.RestoreKey("Type of Payment")
+	a.Nil(err)
+	a.Equal(int64(1), restored)
+}
+
+func (suite *QuerierMethodsSuite) TestPublishChangeSetStaleRevisionRollsBack() {
+	a := assert.New(suite.T())
+	id, err := testQuerier.CreateChangeSet("Kohls to CAD")
+	a.Nil(err)
+	err = testQuerier.StageChange(id, models.Partner{
+		Code:       pgx.NullString{String: "KOH", Valid: true},
+		Revision:   pgx.NullInt32{Int32: 1, Valid: true},
+		Attributes: map[string]string{"Currency": "CAD"},
+	})
+	a.Nil(err)
+
+	//someone else changes the partner after the change was staged
+	_, err = testQuerier.UpdatePartnerStatus(int32(1), "active", "suspended", int32(0))
+	a.Nil(err)
+
+	err = testQuerier.PublishChangeSet(id)
+	a.Equal(ErrRevisionConflict, errors.Cause(err))
+
+	attributes, err := testQuerier.FindAllAttributesForPartner(int32(1))
+	a.Nil(err)
+	a.Equal("USD", attributes["Currency"])
+	changeSet, err := testQuerier.FindChangeSet(id)
+	a.Nil(err)
+	a.Equal(models.ChangeSetDraft, changeSet.Status.String)
+	a.Equal(pgx.NullInt32{Int32: 1, Valid: true}, changeSet.Partners[0].Revision)
+}
+
+func (suite *QuerierMethodsSuite) TestRestorePartnerAttributeStaleRevision() {
+	a := assert.New(suite.T())
+	testConn.Exec("UPDATE partner_mappings SET deleted_at = now() WHERE partner_id = 1 AND key_id = 2;")
+
+	restored, err := testQuerier.RestorePartnerAttribute("KOH", "Type of Payment", int32(2))
+	a.Equal(int64(0), restored)
+	a.Equal(ErrRevisionConflict, errors.Cause(err))
+
+	restored, err = testQuerier.RestorePartnerAttribute("KOH", "Type of Payment", int32(1))
+	a.Equal(int64(1), restored)
+	a.Nil(err)
+}
+
+func (suite *QuerierMethodsSuite) TestCreatePartnerOnboarding() {
+	a := assert.New(suite.T())
+	partner := models.Partner{
+		Name: pgx.NullString{String: "Dicks", Valid: true},
+		Code: pgx.NullString{String: "DIC", Valid: true},
+	}
+
+	id, err := testQuerier.CreatePartner(partner)
+	a.Nil(err)
+
+	status, err := testQuerier.FindPartnerStatus(id)
+	a.Nil(err)
+	a.Equal("onboarding", status)
+}
+
+func (suite *QuerierMethodsSuite) TestNewPartnersStartOnboardingHoweverCreated() {
+	a := assert.New(suite.T())
+	newPartner := func(name, code string) models.Partner {
+		return models.Partner{
+			Name:       pgx.NullString{String: name, Valid: true},
+			Code:       pgx.NullString{String: code, Valid: true},
+			Attributes: map[string]string{"Currency": "USD"},
+		}
+	}
+
+	//import
+	err := testQuerier.SavePartners([]models.Partner{newPartner("Dicks", "DIC"), newPartner("Kohls", "KOH")})
+	a.Nil(err)
+	//apply
+	err = testQuerier.ApplyPartners([]models.Partner{newPartner("Target", "TAR")}, nil)
+	a.Nil(err)
+	//change set
+	changeSetId, err := testQuerier.CreateChangeSet("Mustang")
+	a.Nil(err)
+	a.Nil(testQuerier.StageChange(changeSetId, newPartner("Mustang", "MUS")))
+	a.Nil(testQuerier.PublishChangeSet(changeSetId))
+
+	for id, expected := range map[int32]string{1: "active", 2: "onboarding", 3: "onboarding", 4: "onboarding"} {
+		status, err := testQuerier.FindPartnerStatus(id)
+		a.Nil(err)
+		a.Equal(expected, status, "partnerId: %d", id)
+	}
+}
+
+//tests for the Restore methods and PurgeDeleted
+func (suite *QuerierMethodsSuite) TestRestorePartnerHappy() {
+	a := assert.New(suite.T())
+
+	err := testQuerier.ApplyPartners(nil, deletes("KOH"))
+	a.Nil(err)
+
+	restored, err := testQuerier.RestorePartner("KOH")
+	a.Nil(err)
+	a.Equal(int64(3), restored)
+
+	attributes, err := testQuerier.FindAllAttributesForPartner(int32(1))
+	a.Nil(err)
+	a.Equal(map[string]string{"Currency": "USD", "Type of Payment": "Credit"}, attributes)
+}
+
+func (suite *QuerierMethodsSuite) TestRestorePartnerNotDeleted() {
+	a := assert.New(suite.T())
+
+	restored, err := testQuerier.RestorePartner("KOH")
+	a.Equal(int64(0), restored)
+	a.NotNil(err)
+}
+
+func (suite *QuerierMethodsSuite) TestRestoreKeyNotDeleted() {
+	a := assert.New(suite.T())
+
+	restored, err := testQuerier.RestoreKey("Currency")
+	a.Equal(int64(0), restored)
+	a.NotNil(err)
+}
+
+func (suite *QuerierMethodsSuite) TestRestoreGroupHappy() {
+	a := assert.New(suite.T())
+	testConn.Exec("UPDATE groups SET deleted_at = now() WHERE name = 'Money';")
+
+	attributes, err := testQuerier.FindPartnerAttribute(int32(1), "Money")
+	a.Equal(0, len(attributes))
+
+	restored, err := testQuerier.RestoreGroup("Money")
+	a.Nil(err)
+	a.Equal(int64(1), restored)
+
+	attributes, err = testQuerier.FindPartnerAttribute(int32(1), "Money")
+	a.Nil(err)
+	a.Equal("USD", attributes["Currency"])
+}
+
+func (suite *QuerierMethodsSuite) TestRestorePartnerAttributeHappy() {
+	a := assert.New(suite.T())
+	partners := []models.Partner{{
+		Name:       pgx.NullString{String: "Kohls", Valid: true},
+		Code:       pgx.NullString{String: "KOH", Valid: true},
+		Attributes: map[string]string{"Currency": "USD"},
+	}}
+	err := testQuerier.ApplyPartners(partners, nil)
+	a.Nil(err)
+
+	restored, err := testQuerier.RestorePartnerAttribute("KOH", "Type of Payment", int32(0))
+	a.Nil(err)
+	a.Equal(int64(1), restored)
+
+	attributes, err := testQuerier.FindAllAttributesForPartner(int32(1))
+	a.Nil(err)
+	a.Equal("Credit", attributes["Type of Payment"])
+}
+
+func (suite *QuerierMethodsSuite) TestRestorePartnerAttributeAlreadySet() {
+	a := assert.New(suite.T())
+
+	restored, err := testQuerier.RestorePartnerAttribute("KOH", "Currency", int32(0))
+	a.Equal(int64(0), restored)
+	a.NotNil(err)
+}
+
+func (suite *QuerierMethodsSuite) TestPurgeDeleted() {
+	a := assert.New(suite.T())
+	err := testQuerier.ApplyPartners(nil, deletes("KOH"))
+	a.Nil(err)
+
+	purged, err := testQuerier.PurgeDeleted(time.Now().Add(-time.Hour))
+	a.Nil(err)
+	a.Equal(int64(0), purged)
+
+	purged, err = testQuerier.PurgeDeleted(time.Now().Add(time.Hour))
+	a.Nil(err)
+	a.Equal(int64(3), purged)
+
+	restored, err := testQuerier.RestorePartner("KOH")
+	a.Equal(int64(0), restored)
+	a.NotNil(err)
+}
+
+func (suite *QuerierMethodsSuite) TestPurgeDeletedGroupWithApprovalPolicy() {
+	a := assert.New(suite.T())
+	testConn.Exec("UPDATE groups SET deleted_at = now() WHERE name = 'Money';")
+
+	//two keys in the group, its two approvers and the group
+	purged, err := testQuerier.PurgeDeleted(time.Now().Add(time.Hour))
+	a.Nil(err)
+	a.Equal(int64(5), purged)
+
+	var policies int64
+	err = testConn.QueryRow("SELECT count(*) FROM approval_policies").Scan(&policies)
+	a.Nil(err)
+	a.Equal(int64(0), policies)
+
+	restored, err := testQuerier.RestoreGroup("Money")
+	a.Equal(int64(0), restored)
+	a.NotNil(err)
+}
+
+//tests for change sets
+func (suite *QuerierMethodsSuite) TestStageChangeNotVisibleUntilPublished() {
+	a := assert.New(suite.T())
+	id, err := testQuerier.CreateChangeSet("Kohls to CAD")
+	a.Nil(err)
+
+	err = testQuerier.StageChange(id, models.Partner{
+		Code:       pgx.NullString{String: "KOH", Valid: true},
+		Attributes: map[string]string{"Currency": "CAD"},
+	})
+	a.Nil(err)
+
+	attributes, err := testQuerier.FindAllAttributesForPartner(int32(1))
+	a.Nil(err)
+	a.Equal("USD", attributes["Currency"])
+
+	err = testQuerier.PublishChangeSet(id)
+	a.Nil(err)
+
+	attributes, err = testQuerier.FindAllAttributesForPartner(int32(1))
+	a.Nil(err)
+	a.Equal(map[string]string{"Currency": "CAD", "Type of Payment": "Credit"}, attributes)
+}
+
+func (suite *QuerierMethodsSuite) TestFindChangeSetMergesStagedPartners() {
+	a := assert.New(suite.T())
+	id, err := testQuerier.CreateChangeSet("Dicks")
+	a.Nil(err)
+
+	err = testQuerier.StageChange(id, models.Partner{
+		Name:       pgx.NullString{String: "Dicks", Valid: true},
+		Code:       pgx.NullString{String: "DIC", Valid: true},
+		Attributes: map[string]string{"Currency": "USD"},
+	})
+	a.Nil(err)
+	err = testQuerier.StageChange(id, models.Partner{
+		Code:       pgx.NullString{String: "DIC", Valid: true},
+		Attributes: map[string]string{"Currency": "CAD", "ISAID": "DICKS"},
+	})
+	a.Nil(err)
+
+	changeSet, err := testQuerier.FindChangeSet(id)
+	a.Nil(err)
+	a.Equal("draft", changeSet.Status.String)
+	a.Equal([]models.Partner{{
+		Name:       pgx.NullString{String: "Dicks", Valid: true},
+		Code:       pgx.NullString{String: "DIC", Valid: true},
+		Attributes: map[string]string{"Currency": "CAD", "ISAID": "DICKS"},
+	}}, changeSet.Partners)
+}
+
+func (suite *QuerierMethodsSuite) TestPublishChangeSetNewPartnerWithoutNameRollsBack() {
+	a := assert.New(suite.T())
+	id, err := testQuerier.CreateChangeSet("Mustang")
+	a.Nil(err)
+	testQuerier.StageChange(id, models.Partner{
+		Code:       pgx.NullString{String: "KOH", Valid: true},
+		Attributes: map[string]string{"Currency": "CAD"},
+	})
+	testQuerier.StageChange(id, models.Partner{
+		Code:       pgx.NullString{String: "MUS", Valid: true},
+		Attributes: map[string]string{"Currency": "USD"},
+	})
+
+	err = testQuerier.PublishChangeSet(id)
+	a.NotNil(err)
+
+	attributes, err := testQuerier.FindAllAttributesForPartner(int32(1))
+	a.Nil(err)
+	a.Equal("USD", attributes["Currency"])
+}
+
+func (suite *QuerierMethodsSuite) TestDiscardChangeSet() {
+	a := assert.New(suite.T())
+	id, err := testQuerier.CreateChangeSet("Never mind")
+	a.Nil(err)
+
+	err = testQuerier.DiscardChangeSet(id)
+	a.Nil(err)
+
+	err = testQuerier.PublishChangeSet(id)
+	a.NotNil(err)
+	err = testQuerier.StageChange(id, models.Partner{Code: pgx.NullString{String: "KOH", Valid: true}})
+	a.NotNil(err)
+}
+
+//tests for approvals
+func (suite *QuerierMethodsSuite) TestFindApprovalPolicies() {
+	a := assert.New(suite.T())
+
+	policies, err := testQuerier.FindApprovalPolicies([]string{"Currency", "ISAID"})
+
+	a.Nil(err)
+	a.Equal(map[string][]string{"Money": {"controller", "finance-lead"}}, policies)
+}
+
+func (suite *QuerierMethodsSuite) TestFindApprovalPoliciesUnprotected() {
+	a := assert.New(suite.T())
+
+	policies, err := testQuerier.FindApprovalPolicies([]string{"ISAID"})
+
+	a.Nil(err)
+	a.Equal(0, len(policies))
+}
+
+func (suite *QuerierMethodsSuite) TestFindRestoreApprovalPoliciesPartner() {
+	a := assert.New(suite.T())
+	err := testQuerier.ApplyPartners(nil, deletes("KOH"))
+	a.Nil(err)
+
+	policies, err := testQuerier.FindRestoreApprovalPolicies("partners", "KOH")
+
+	a.Nil(err)
+	a.Equal(map[string][]string{"Money": {"controller", "finance-lead"}}, policies)
+}
+
+func (suite *QuerierMethodsSuite) TestFindRestoreApprovalPoliciesGroup() {
+	a := assert.New(suite.T())
+	testConn.Exec("UPDATE groups SET deleted_at = now() WHERE name = 'Money';")
+
+	policies, err := testQuerier.FindRestoreApprovalPolicies("groups", "Money")
+
+	a.Nil(err)
+	a.Equal(map[string][]string{"Money": {"controller", "finance-lead"}}, policies)
+}
+
+func (suite *QuerierMethodsSuite) TestApproveRequestPublishes() {
+	a := assert.New(suite.T())
+	id, err := testQuerier.CreateChangeSet("Kohls to CAD")
+	a.Nil(err)
+	testQuerier.StageChange(id, models.Partner{
+		Code:       pgx.NullString{String: "KOH", Valid: true},
+		Attributes: map[string]string{"Currency": "CAD"},
+	})
+
+	requestId, err := testQuerier.RequestApproval(id, "jdoe", []string{"Money"})
+	a.Nil(err)
+
+	changeSet, err := testQuerier.FindChangeSet(id)
+	a.Nil(err)
+	a.Equal(models.ChangeSetPending, changeSet.Status.String)
+	err = testQuerier.StageChange(id, models.Partner{Code: pgx.NullString{String: "KOH", Valid: true}})
+	a.NotNil(err)
+
+	requests, err := testQuerier.FindApprovalRequests(models.ApprovalPending)
+	a.Nil(err)
+	a.Equal(1, len(requests))
+	a.Equal([]string{"Money"}, requests[0].Groups)
+
+	err = testQuerier.ApproveRequest(requestId, "controller")
+	a.Nil(err)
+
+	attributes, err := testQuerier.FindAllAttributesForPartner(int32(1))
+	a.Nil(err)
+	a.Equal("CAD", attributes["Currency"])
+
+	request, err := testQuerier.FindApprovalRequest(requestId)
+	a.Nil(err)
+	a.Equal(models.ApprovalApproved, request.Status.String)
+	a.Equal("controller", request.DecidedBy.String)
+
+	err = testQuerier.ApproveRequest(requestId, "controller")
+	a.NotNil(err)
+}
+
+func (suite *QuerierMethodsSuite) TestRejectRequestReturnsToDraft() {
+	a := assert.New(suite.T())
+	id, err := testQuerier.CreateChangeSet("Kohls to CAD")
+	a.Nil(err)
+	testQuerier.StageChange(id, models.Partner{
+		Code:       pgx.NullString{String: "KOH", Valid: true},
+		Attributes: map[string]string{"Currency": "CAD"},
+	})
+	requestId, err := testQuerier.RequestApproval(id, "jdoe", []string{"Money"})
+	a.Nil(err)
+
+	err = testQuerier.RejectRequest(requestId, "controller", "wrong currency")
+	a.Nil(err)
+
+	changeSet, err := testQuerier.FindChangeSet(id)
+	a.Nil(err)
+	a.Equal(models.ChangeSetDraft, changeSet.Status.String)
+
+	attributes, err := testQuerier.FindAllAttributesForPartner(int32(1))
+	a.Nil(err)
+	a.Equal("USD", attributes["Currency"])
+
+	request, err := testQuerier.FindApprovalRequest(requestId)
+	a.Nil(err)
+	a.Equal(models.ApprovalRejected, request.Status.String)
+	a.Equal("wrong currency", request.Reason.String)
+}
+
+//tests for idempotency keys
+func (suite *QuerierMethodsSuite) TestClaimIdempotencyKeyOnce() {
+	a := assert.New(suite.T())
+	since := time.Now().Add(-time.Hour)
+
+	_, claimed, err := testQuerier.ClaimIdempotencyKey("abc", "ClonePartner", "hash", since)
+	a.Nil(err)
+	a.True(claimed)
+
+	stored, claimed, err := testQuerier.ClaimIdempotencyKey("abc", "ClonePartner", "hash", since)
+	a.Nil(err)
+	a.False(claimed)
+	a.Equal("hash", stored.RequestHash.String)
+	a.False(stored.Response.Valid)
+
+	err = testQuerier.SaveIdempotentResponse("abc", "ClonePartner", `{"PartnerId":7}`)
+	a.Nil(err)
+	stored, claimed, err = testQuerier.ClaimIdempotencyKey("abc", "ClonePartner", "hash", since)
+	a.Nil(err)
+	a.False(claimed)
+	a.Equal(`{"PartnerId":7}`, stored.Response.String)
+
+	//the same key can be used for another method
+	_, claimed, err = testQuerier.ClaimIdempotencyKey("abc", "SetPartnerStatus", "hash", since)
+	a.Nil(err)
+	a.True(claimed)
+}
+
+func (suite *QuerierMethodsSuite) TestClaimIdempotencyKeyExpired() {
+	a := assert.New(suite.T())
+
+	_, claimed, err := testQuerier.ClaimIdempotencyKey("abc", "ClonePartner", "hash", time.Now().Add(-time.Hour))
+	a.Nil(err)
+	a.True(claimed)
+
+	stored, claimed, err := testQuerier.ClaimIdempotencyKey("abc", "ClonePartner", "other", time.Now().Add(time.Minute))
+	a.Nil(err)
+	a.True(claimed)
+	a.False(stored.Response.Valid)
+}
+
+func (suite *QuerierMethodsSuite) TestReleaseAndPurgeIdempotencyKeys() {
+	a := assert.New(suite.T())
+	since := time.Now().Add(-time.Hour)
+
+	_, _, err := testQuerier.ClaimIdempotencyKey("abc", "ClonePartner", "hash", since)
+	a.Nil(err)
+	err = testQuerier.ReleaseIdempotencyKey("abc", "ClonePartner")
+	a.Nil(err)
+	_, claimed, err := testQuerier.ClaimIdempotencyKey("abc", "ClonePartner", "hash", since)
+	a.Nil(err)
+	a.True(claimed)
+
+	purged, err := testQuerier.PurgeIdempotencyKeys(time.Now().Add(time.Minute))
+	a.Nil(err)
+	a.Equal(int64(1), purged)
+}
+
+//tests for FindKeyGroups
+func (suite *QuerierMethodsSuite) TestFindKeyGroups() {
+	a := assert.New(suite.T())
+
+	keyGroups, err := testQuerier.FindKeyGroups([]string{"Currency", "ISAID", "asdfjkl"})
+
+	a.Nil(err)
+	a.Equal(map[string][]string{"Currency": {"Money"}}, keyGroups)
+}
+
+func (suite *QuerierMethodsSuite) TestFindKeyGroupsDeletedGroup() {
+	a := assert.New(suite.T())
+	testConn.Exec("UPDATE groups SET deleted_at = now() WHERE name = 'Money';")
+
+	keyGroups, err := testQuerier.FindKeyGroups([]string{"Currency"})
+
+	a.Nil(err)
+	a.Equal(map[string][]string{}, keyGroups)
+}
+
+//tests for sensitive keys and the sealing querier
+func (suite *QuerierMethodsSuite) TestFindSensitiveKeys() {
+	a := assert.New(suite.T())
+	testConn.Exec("UPDATE keys SET sensitive = true WHERE name = 'Type of Payment';")
+
+	keys, err := testQuerier.FindSensitiveKeys()
+
+	a.Nil(err)
+	a.Equal([]string{"Type of Payment"}, keys)
+}
+
+func testKeyring(t *testing.T) *secrets.Keyring {
+	file, err := ioutil.TempFile("", "keyfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString(base64.StdEncoding.EncodeToString(make([]byte, 32)))
+	file.Close()
+	keyring, err := secrets.LoadKeyfile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+func (suite *QuerierMethodsSuite) TestSealingQuerierSealsSensitiveValues() {
+	a := assert.New(suite.T())
+	testConn.Exec("UPDATE keys SET sensitive = true WHERE name = 'Type of Payment';")
+	sealing := NewSealingQuerier(testQuerier, testKeyring(suite.T()))
+
+	err := sealing.SavePartners([]models.Partner{{
+		Name:       pgx.NullString{String: "Kohls", Valid: true},
+		Code:       pgx.NullString{String: "KOH", Valid: true},
+		Attributes: map[string]string{"Currency": "CAD", "Type of Payment": "Wire"},
+	}})
+	a.Nil(err)
+
+	stored, err := testQuerier.FindAllAttributesForPartner(int32(1))
+	a.Nil(err)
+	a.Equal("CAD", stored["Currency"])
+	a.True(secrets.IsSealed(stored["Type of Payment"]))
+
+	opened, err := sealing.FindAllAttributesForPartner(int32(1))
+	a.Nil(err)
+	a.Equal("Wire", opened["Type of Payment"])
+
+	_, _, err = sealing.FindPartnerDataFromKeyValue("Type of Payment", "Wire")
+	a.NotNil(err)
+}
+
+func (suite *QuerierMethodsSuite) TestSealingQuerierNoKeyring() {
+	a := assert.New(suite.T())
+	testConn.Exec("UPDATE keys SET sensitive = true WHERE name = 'Type of Payment';")
+
+	err := NewSealingQuerier(testQuerier, nil).SavePartners([]models.Partner{{
+		Code:       pgx.NullString{String: "KOH", Valid: true},
+		Attributes: map[string]string{"Type of Payment": "Wire"},
+	}})
+
+	a.Equal(secrets.ErrNoKeyfile, errors.Cause(err))
+}
+
+func (suite *QuerierMethodsSuite) TestRecordAccess() {
+	a := assert.New(suite.T())
+	readAt := time.Date(2017, 8, 1, 12, 0, 0, 0, time.UTC)
+	records := []models.AccessRecord{
+		{
+			Caller:      pgx.NullString{String: "jdoe", Valid: true},
+			PartnerCode: pgx.NullString{String: "KOH", Valid: true},
+			Keys:        []string{"Type of Payment"},
+			RequestId:   pgx.NullString{String: "abc123", Valid: true},
+			ReadAt:      pgx.NullTime{Time: readAt, Valid: true},
+		},
+		{
+			Caller:      pgx.NullString{String: "jdoe", Valid: true},
+			PartnerCode: pgx.NullString{String: "JCP", Valid: true},
+			Keys:        []string{"Type of Payment"},
+			ReadAt:      pgx.NullTime{Time: readAt, Valid: true},
+		},
+	}
+
+	err := testQuerier.RecordAccess(records)
+	a.Nil(err)
+
+	found, err := testQuerier.FindAccessRecords("KOH", readAt.Add(-time.Hour), readAt.Add(time.Hour))
+	a.Nil(err)
+	a.Equal(1, len(found))
+	a.Equal("jdoe", found[0].Caller.String)
+	a.Equal([]string{"Type of Payment"}, found[0].Keys)
+	a.Equal("abc123", found[0].RequestId.String)
+	a.True(readAt.Equal(found[0].ReadAt.Time))
+
+	found, err = testQuerier.FindAccessRecords("KOH", readAt.Add(time.Hour), readAt.Add(2*time.Hour))
+	a.Nil(err)
+	a.Equal(0, len(found))
+}
+
+func (suite *QuerierMethodsSuite) TestAccessLogIsAppendOnly() {
+	a := assert.New(suite.T())
+	readAt := time.Date(2017, 8, 1, 12, 0, 0, 0, time.UTC)
+	err := testQuerier.RecordAccess([]models.AccessRecord{{
+		Caller:      pgx.NullString{String: "jdoe", Valid: true},
+		PartnerCode: pgx.NullString{String: "KOH", Valid: true},
+		Keys:        []string{"Type of Payment"},
+		ReadAt:      pgx.NullTime{Time: readAt, Valid: true},
+	}})
+	a.Nil(err)
+
+	testConn.Exec("UPDATE access_log SET caller = 'someone else';")
+	testConn.Exec("DELETE FROM access_log;")
+
+	found, err := testQuerier.FindAccessRecords("KOH", readAt.Add(-time.Hour), readAt.Add(time.Hour))
+	a.Nil(err)
+	a.Equal(1, len(found))
+	a.Equal("jdoe", found[0].Caller.String)
+}
+
+func (suite *QuerierMethodsSuite) TestAPIKeyLifecycle() {
+	a := assert.New(suite.T())
+	expires := time.Now().Add(time.Hour)
+
+	created, err := testQuerier.CreateAPIKey(models.APIKey{
+		Prefix:    pgx.NullString{String: "psk_0a1b2c3d", Valid: true},
+		Owner:     pgx.NullString{String: "onboarding-pipeline", Valid: true},
+		Scope:     []string{"edi"},
+		IssuedBy:  pgx.NullString{String: "jdoe", Valid: true},
+		ExpiresAt: pgx.NullTime{Time: expires, Valid: true},
+	}, "hash1")
+	a.Nil(err)
+	a.True(created.Id.Valid)
+	a.Equal("jdoe", created.IssuedBy.String)
+	a.True(created.CreatedAt.Valid)
+
+	found, err := testQuerier.FindAPIKeyByHash("hash1")
+	a.Nil(err)
+	a.Equal(created.Id, found.Id)
+	a.Equal([]string{"edi"}, found.Scope)
+	a.True(found.Usable(time.Now()))
+
+	usedAt := time.Date(2017, 8, 1, 12, 0, 0, 0, time.UTC)
+	a.Nil(testQuerier.TouchAPIKey(created.Id.Int32, usedAt))
+
+	rotated, err := testQuerier.RotateAPIKey(created.Id.Int32, "psk_4e5f6a7b", "hash2")
+	a.Nil(err)
+	a.Equal("psk_4e5f6a7b", rotated.Prefix.String)
+	a.True(usedAt.Equal(rotated.LastUsedAt.Time))
+
+	found, err = testQuerier.FindAPIKeyByHash("hash1")
+	a.Nil(err)
+	a.False(found.Id.Valid)
+
+	revoked, err := testQuerier.RevokeAPIKey(created.Id.Int32)
+	a.Nil(err)
+	a.True(revoked.RevokedAt.Valid)
+	a.False(revoked.Usable(time.Now()))
+
+	_, err = testQuerier.RotateAPIKey(created.Id.Int32, "psk_8c9d0e1f", "hash3")
+	a.NotNil(err)
+	_, err = testQuerier.RevokeAPIKey(created.Id.Int32)
+	a.NotNil(err)
+
+	keys, err := testQuerier.FindAPIKeys("onboarding-pipeline")
+	a.Nil(err)
+	a.Equal(1, len(keys))
+	keys, err = testQuerier.FindAPIKeys("someone else")
+	a.Nil(err)
+	a.Equal(0, len(keys))
+}
+
+//tests for Ping and FindSchemaVersion
+func (suite *QuerierMethodsSuite) TestPingAndFindSchemaVersion() {
+	a := assert.New(suite.T())
+
+	a.Nil(testQuerier.Ping())
+
+	version, err := testQuerier.FindSchemaVersion()
+	a.Nil(err)
+	a.Equal(int32(SchemaVersion), version)
+}
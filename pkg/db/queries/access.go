@@ -0,0 +1,48 @@
+package queries
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+)
+
+func InsertAccessRecord(record models.AccessRecord, conn Queryer) error {
+
+	//The time of the read is kept when it is known, since records are written some time after the reads.
+	statement := "INSERT INTO access_log (caller, partner_code, keys, request_id, read_at) VALUES ($1, $2, $3, $4, COALESCE($5, now()))"
+	_, err := conn.Exec(statement, record.Caller, record.PartnerCode, record.Keys, record.RequestId, record.ReadAt)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to record read of partner: %s by %s", record.PartnerCode.String, record.Caller.String))
+		return err
+	}
+	return nil
+}
+
+func GetAccessRecords(partnerCode string, from, to time.Time, conn Queryer) ([]models.AccessRecord, error) {
+
+	statement := "SELECT id, caller, partner_code, keys, request_id, read_at FROM access_log WHERE partner_code = $1 AND read_at >= $2 AND read_at < $3 ORDER BY read_at, id"
+	rows, err := conn.Query(statement, partnerCode, from, to)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to query access log for partner: %s", partnerCode))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []models.AccessRecord
+	for rows.Next() {
+		var record models.AccessRecord
+		err = rows.Scan(&record.Id, &record.Caller, &record.PartnerCode, &record.Keys, &record.RequestId, &record.ReadAt)
+		if err != nil {
+			err = errors.Wrap(err, "Failed to scan AccessRecord into access log")
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	if rows.Err() != nil {
+		err = errors.Wrap(rows.Err(), fmt.Sprintf("failed to read access log for partner: %s", partnerCode))
+		return nil, err
+	}
+	return records, nil
+}
@@ -0,0 +1,115 @@
+package queries
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx"
+	"github.com/pkg/errors"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+)
+
+const apiKeyColumns = "id, prefix, owner, scope, issued_by, expires_at, last_used_at, created_at, revoked_at"
+
+//rowScanner is a row from either QueryRow or Query.
+type rowScanner interface {
+	Scan(...interface{}) error
+}
+
+func scanAPIKey(row rowScanner) (models.APIKey, error) {
+	var key models.APIKey
+	err := row.Scan(&key.Id, &key.Prefix, &key.Owner, &key.Scope, &key.IssuedBy, &key.ExpiresAt, &key.LastUsedAt, &key.CreatedAt, &key.RevokedAt)
+	return key, err
+}
+
+func InsertAPIKey(key models.APIKey, hash string, conn Queryer) (models.APIKey, error) {
+
+	statement := fmt.Sprintf("INSERT INTO api_keys (prefix, hash, owner, scope, issued_by, expires_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING %s", apiKeyColumns)
+	inserted, err := scanAPIKey(conn.QueryRow(statement, key.Prefix, hash, key.Owner, key.Scope, key.IssuedBy, key.ExpiresAt))
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to insert api key for owner: %s", key.Owner.String))
+		return models.APIKey{}, err
+	}
+	return inserted, nil
+}
+
+func GetAPIKeys(owner string, conn Queryer) ([]models.APIKey, error) {
+
+	//An empty owner means every owner's keys are returned.
+	statement := fmt.Sprintf("SELECT %s FROM api_keys WHERE ($1 = '' OR owner = $1) ORDER BY id", apiKeyColumns)
+	rows, err := conn.Query(statement, owner)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to query api keys of owner: %s", owner))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []models.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			err = errors.Wrap(err, "Failed to scan api key")
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if rows.Err() != nil {
+		err = errors.Wrap(rows.Err(), fmt.Sprintf("failed to read api keys of owner: %s", owner))
+		return nil, err
+	}
+	return keys, nil
+}
+
+func GetAPIKeyByHash(hash string, conn Queryer) (models.APIKey, error) {
+
+	//A key that is not found comes back without an id, rather than as an error.
+	statement := fmt.Sprintf("SELECT %s FROM api_keys WHERE hash = $1", apiKeyColumns)
+	key, err := scanAPIKey(conn.QueryRow(statement, hash))
+	if err == pgx.ErrNoRows {
+		return models.APIKey{}, nil
+	}
+	if err != nil {
+		err = errors.Wrap(err, "failed to query api key by hash")
+		return models.APIKey{}, err
+	}
+	return key, nil
+}
+
+func UpdateAPIKeyHash(id int32, prefix, hash string, conn Queryer) (models.APIKey, error) {
+
+	//Revoked keys cannot be rotated back into use.
+	statement := fmt.Sprintf("UPDATE api_keys SET prefix = $1, hash = $2 WHERE id = $3 AND revoked_at IS NULL RETURNING %s", apiKeyColumns)
+	key, err := scanAPIKey(conn.QueryRow(statement, prefix, hash, id))
+	if err == pgx.ErrNoRows {
+		return models.APIKey{}, errors.New(fmt.Sprintf("no api key with id: %d that is not revoked", id))
+	}
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to rotate api key: %d", id))
+		return models.APIKey{}, err
+	}
+	return key, nil
+}
+
+func UpdateAPIKeyRevoked(id int32, conn Queryer) (models.APIKey, error) {
+
+	statement := fmt.Sprintf("UPDATE api_keys SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL RETURNING %s", apiKeyColumns)
+	key, err := scanAPIKey(conn.QueryRow(statement, id))
+	if err == pgx.ErrNoRows {
+		return models.APIKey{}, errors.New(fmt.Sprintf("no api key with id: %d that is not revoked", id))
+	}
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to revoke api key: %d", id))
+		return models.APIKey{}, err
+	}
+	return key, nil
+}
+
+func UpdateAPIKeyLastUsed(id int32, usedAt time.Time, conn Queryer) error {
+
+	_, err := conn.Exec("UPDATE api_keys SET last_used_at = $1 WHERE id = $2", usedAt, id)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to update last use of api key: %d", id))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package queries
+
+import (
+	"fmt"
+
+	"github.com/jackc/pgx"
+	"github.com/pkg/errors"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+)
+
+const approvalRequestColumns = "id, change_set_id, groups, status, requested_by, requested_at, decided_by, decided_at, reason"
+
+func GetApprovalPolicies(keys []string, conn Queryer) (map[string][]string, error) {
+
+	//A group is protected when it has at least one approver, and a key is protected when it is in a protected group.
+	statement := "SELECT groups.name, approval_policies.approver FROM approval_policies INNER JOIN groups ON groups.id = approval_policies.group_id WHERE groups.deleted_at IS NULL AND approval_policies.group_id IN (SELECT groups_to_keys.group_id FROM groups_to_keys INNER JOIN keys ON keys.id = groups_to_keys.key_id WHERE keys.name = ANY($1) AND keys.deleted_at IS NULL) ORDER BY groups.name, approval_policies.approver"
+	if keys == nil {
+		keys = []string{}
+	}
+	return queryApprovalPolicies(statement, keys, fmt.Sprintf("keys: %v", keys), conn)
+}
+
+//GetRestoreApprovalPolicies finds the approvers by protected group for the groups that a restore by name in
+//partners, keys or groups would bring values back into.
+func GetRestoreApprovalPolicies(table, name string, conn Queryer) (map[string][]string, error) {
+
+	//A restore brings back the latest deleted row with the name, so the groups are looked up through that row even
+	//though it is deleted. A restored group is protected by its own approvers from then on.
+	var statement string
+	switch table {
+	case "partners":
+		statement = "SELECT groups.name, approval_policies.approver FROM approval_policies INNER JOIN groups ON groups.id = approval_policies.group_id WHERE groups.deleted_at IS NULL AND approval_policies.group_id IN (SELECT groups_to_keys.group_id FROM groups_to_keys INNER JOIN partner_mappings ON partner_mappings.key_id = groups_to_keys.key_id INNER JOIN partners ON partners.id = partner_mappings.partner_id AND partners.deleted_at = partner_mappings.deleted_at WHERE partners.id = (SELECT id FROM partners WHERE code = $1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC LIMIT 1)) ORDER BY groups.name, approval_policies.approver"
+	case "keys":
+		statement = "SELECT groups.name, approval_policies.approver FROM approval_policies INNER JOIN groups ON groups.id = approval_policies.group_id WHERE groups.deleted_at IS NULL AND approval_policies.group_id IN (SELECT group_id FROM groups_to_keys WHERE key_id = (SELECT id FROM keys WHERE name = $1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC LIMIT 1)) ORDER BY groups.name, approval_policies.approver"
+	case "groups":
+		statement = "SELECT groups.name, approval_policies.approver FROM approval_policies INNER JOIN groups ON groups.id = approval_policies.group_id WHERE groups.id = (SELECT id FROM groups WHERE name = $1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC LIMIT 1) ORDER BY groups.name, approval_policies.approver"
+	default:
+		return nil, errors.New(fmt.Sprintf("cannot restore from table: %s", table))
+	}
+	return queryApprovalPolicies(statement, name, fmt.Sprintf("restore of %s with name: %s", table, name), conn)
+}
+
+//queryApprovalPolicies runs a statement that selects group names and approvers, and groups the approvers by name.
+func queryApprovalPolicies(statement string, arg interface{}, description string, conn Queryer) (map[string][]string, error) {
+	rows, err := conn.Query(statement, arg)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to query approval policies for %s", description))
+		return nil, err
+	}
+	defer rows.Close()
+
+	policies := make(map[string][]string)
+	for rows.Next() {
+		var group, approver pgx.NullString
+		err = rows.Scan(&group, &approver)
+		if err != nil {
+			err = errors.Wrap(err, "Failed to scan Group and Approver into approval policies")
+			return nil, err
+		}
+		policies[group.String] = append(policies[group.String], approver.String)
+	}
+	if rows.Err() != nil {
+		err = errors.Wrap(rows.Err(), fmt.Sprintf("failed to read approval policies for %s", description))
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+func InsertApprovalRequest(changeSetId int32, requestedBy string, groups []string, conn Queryer) (int32, error) {
+
+	var id pgx.NullInt32
+	err := conn.QueryRow("INSERT INTO approval_requests (change_set_id, groups, requested_by) VALUES ($1, $2, $3) RETURNING id", changeSetId, groups, requestedBy).Scan(&id)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to insert approval request for change set: %d", changeSetId))
+		return 0, err
+	}
+	return id.Int32, nil
+}
+
+func GetApprovalRequests(status string, conn Queryer) ([]models.ApprovalRequest, error) {
+
+	//An empty status means requests in every status are returned.
+	statement := fmt.Sprintf("SELECT %s FROM approval_requests WHERE ($1 = '' OR status = $1) ORDER BY id", approvalRequestColumns)
+	rows, err := conn.Query(statement, status)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to query approval requests with status: %s", status))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []models.ApprovalRequest
+	for rows.Next() {
+		request := models.ApprovalRequest{}
+		err = rows.Scan(&request.Id, &request.ChangeSetId, &request.Groups, &request.Status, &request.RequestedBy, &request.RequestedAt, &request.DecidedBy, &request.DecidedAt, &request.Reason)
+		if err != nil {
+			err = errors.Wrap(err, "Failed to scan approval request")
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+	if rows.Err() != nil {
+		err = errors.Wrap(rows.Err(), fmt.Sprintf("failed to read approval requests with status: %s", status))
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+func GetApprovalRequest(id int32, conn Queryer) (models.ApprovalRequest, error) {
+
+	request := models.ApprovalRequest{}
+	statement := fmt.Sprintf("SELECT %s FROM approval_requests WHERE id = $1", approvalRequestColumns)
+	err := conn.QueryRow(statement, id).Scan(&request.Id, &request.ChangeSetId, &request.Groups, &request.Status, &request.RequestedBy, &request.RequestedAt, &request.DecidedBy, &request.DecidedAt, &request.Reason)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to query approval request: %d", id))
+		return models.ApprovalRequest{}, err
+	}
+	return request, nil
+}
+
+func LockPendingApprovalRequest(id int32, conn Queryer) (models.ApprovalRequest, error) {
+
+	//Locking the row means two approvers acting at once cannot both decide the same request.
+	request := models.ApprovalRequest{}
+	statement := fmt.Sprintf("SELECT %s FROM approval_requests WHERE id = $1 FOR UPDATE", approvalRequestColumns)
+	err := conn.QueryRow(statement, id).Scan(&request.Id, &request.ChangeSetId, &request.Groups, &request.Status, &request.RequestedBy, &request.RequestedAt, &request.DecidedBy, &request.DecidedAt, &request.Reason)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to query approval request: %d", id))
+		return models.ApprovalRequest{}, err
+	}
+	if request.Status.String != models.ApprovalPending {
+		err = errors.New(fmt.Sprintf("approval request: %d is already %s", id, request.Status.String))
+		return models.ApprovalRequest{}, err
+	}
+	return request, nil
+}
+
+func DecideApprovalRequest(id int32, status, decidedBy, reason string, conn Queryer) error {
+
+	_, err := conn.Exec("UPDATE approval_requests SET status = $2, decided_by = $3, decided_at = now(), reason = $4 WHERE id = $1", id, status, decidedBy, reason)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to set approval request: %d to %s", id, status))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package queries
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+func InsertAudit(action, actor, subject, detail string, conn Queryer) error {
+
+	_, err := conn.Exec("INSERT INTO audit_log (action, actor, subject, detail) VALUES ($1, $2, $3, $4)", action, actor, subject, detail)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to record %s of %s by %s", action, subject, actor))
+		return err
+	}
+	return nil
+}
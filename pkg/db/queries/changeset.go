@@ -0,0 +1,143 @@
+package queries
+
+import (
+	"fmt"
+
+	"github.com/jackc/pgx"
+	"github.com/pkg/errors"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+)
+
+func InsertChangeSet(name string, conn Queryer) (int32, error) {
+
+	var id pgx.NullInt32
+	err := conn.QueryRow("INSERT INTO change_sets (name) VALUES ($1) RETURNING id", name).Scan(&id)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to insert change set: %s", name))
+		return 0, err
+	}
+	return id.Int32, nil
+}
+
+func GetChangeSet(id int32, conn Queryer) (models.ChangeSet, error) {
+
+	changeSet := models.ChangeSet{}
+	err := conn.QueryRow("SELECT id, name, status FROM change_sets WHERE id = $1", id).Scan(&changeSet.Id, &changeSet.Name, &changeSet.Status)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to query change set: %d", id))
+		return models.ChangeSet{}, err
+	}
+	return changeSet, nil
+}
+
+func GetChangeSetPartners(id int32, conn Queryer) ([]models.Partner, error) {
+
+	statement := "SELECT change_set_partners.code, change_set_partners.name, change_set_partners.expected_revision, change_set_attributes.key, change_set_attributes.value FROM change_set_partners LEFT JOIN change_set_attributes ON change_set_attributes.change_set_partner_id = change_set_partners.id WHERE change_set_partners.change_set_id = $1 ORDER BY change_set_partners.code"
+	rows, err := conn.Query(statement, id)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to query staged partners for change set: %d", id))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var partners []models.Partner
+	for rows.Next() {
+		var code, name pgx.NullString
+		var revision pgx.NullInt32
+		attr := &models.Attribute{}
+		err = rows.Scan(&code, &name, &revision, &attr.Name, &attr.Value)
+		if err != nil {
+			err = errors.Wrap(err, "Failed to scan Code, Name, Revision, Key and Value into staged Partner")
+			return nil, err
+		}
+		//Rows come ordered by code, so a new code starts a new partner.
+		if len(partners) == 0 || partners[len(partners)-1].Code.String != code.String {
+			partners = append(partners, models.Partner{Code: code, Name: name, Revision: revision, Attributes: make(map[string]string)})
+		}
+		if attr.Name.Valid {
+			partners[len(partners)-1].Attributes[attr.Name.String] = attr.Value.String
+		}
+	}
+	if rows.Err() != nil {
+		err = errors.Wrap(rows.Err(), fmt.Sprintf("failed to read staged partners for change set: %d", id))
+		return nil, err
+	}
+
+	return partners, nil
+}
+
+func StageChangeSetPartner(id int32, p models.Partner, conn Queryer) error {
+
+	//Staging the same partner again adds to what is already staged for it, and a name or expected revision replaces
+	//the staged one.
+	var stagedId pgx.NullInt32
+	err := conn.QueryRow("SELECT id FROM change_set_partners WHERE change_set_id = $1 AND code = $2", id, p.Code.String).Scan(&stagedId)
+	if err == pgx.ErrNoRows {
+		err = conn.QueryRow("INSERT INTO change_set_partners (change_set_id, code, name, expected_revision) VALUES ($1, $2, $3, $4) RETURNING id", id, p.Code.String, p.Name, p.Revision).Scan(&stagedId)
+	} else if err == nil && (p.Name.Valid || p.Revision.Valid) {
+		_, err = conn.Exec("UPDATE change_set_partners SET name = COALESCE($1, name), expected_revision = COALESCE($2, expected_revision) WHERE id = $3", p.Name, p.Revision, stagedId.Int32)
+	}
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to stage partner with code: %s in change set: %d", p.Code.String, id))
+		return err
+	}
+
+	for key, value := range p.Attributes {
+		tag, err := conn.Exec("UPDATE change_set_attributes SET value = $1 WHERE change_set_partner_id = $2 AND key = $3", value, stagedId.Int32, key)
+		if err == nil && tag.RowsAffected() == 0 {
+			_, err = conn.Exec("INSERT INTO change_set_attributes (change_set_partner_id, key, value) VALUES ($1, $2, $3)", stagedId.Int32, key, value)
+		}
+		if err != nil {
+			err = errors.Wrap(err, fmt.Sprintf("failed to stage key: %s for partner with code: %s in change set: %d", key, p.Code.String, id))
+			return err
+		}
+	}
+	return nil
+}
+
+func LockChangeSet(id int32, want string, conn Queryer) error {
+
+	//Locking the row keeps a concurrent publish, discard, stage or approval from acting on the same change set.
+	var status pgx.NullString
+	err := conn.QueryRow("SELECT status FROM change_sets WHERE id = $1 FOR UPDATE", id).Scan(&status)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to query change set: %d", id))
+		return err
+	}
+	if status.String != want {
+		err = errors.New(fmt.Sprintf("change set: %d is %s, not %s", id, status.String, want))
+		return err
+	}
+	return nil
+}
+
+func UpdateChangeSetStatus(id int32, status string, conn Queryer) error {
+
+	_, err := conn.Exec("UPDATE change_sets SET status = $2 WHERE id = $1", id, status)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to set change set: %d to %s", id, status))
+		return err
+	}
+	return nil
+}
+
+func CloseChangeSet(id int32, status string, conn Queryer) error {
+
+	_, err := conn.Exec("UPDATE change_sets SET status = $2, closed_at = now() WHERE id = $1", id, status)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to set change set: %d to %s", id, status))
+		return err
+	}
+	return nil
+}
+
+func GetPartnerID(code string, conn Queryer) (int32, error) {
+
+	var id pgx.NullInt32
+	err := conn.QueryRow("SELECT id FROM partners WHERE code = $1 AND deleted_at IS NULL", code).Scan(&id)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to query partner with code: %s", code))
+		return 0, err
+	}
+	return id.Int32, nil
+}
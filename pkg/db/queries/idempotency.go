@@ -0,0 +1,68 @@
+package queries
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx"
+	"github.com/pkg/errors"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+)
+
+func InsertIdempotencyKey(key, method, requestHash string, since time.Time, conn Queryer) (bool, error) {
+
+	//A key used before since has expired, so it is taken over as if it were new. Otherwise nothing is returned and
+	//the key stays with the request that first used it.
+	var id pgx.NullInt32
+	statement := "INSERT INTO idempotency_keys (idempotency_key, method, request_hash) VALUES ($1, $2, $3) ON CONFLICT (idempotency_key, method) DO UPDATE SET request_hash = EXCLUDED.request_hash, response = NULL, created_at = now() WHERE idempotency_keys.created_at < $4 RETURNING id"
+	err := conn.QueryRow(statement, key, method, requestHash, since).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to insert idempotency key: %s for %s", key, method))
+		return false, err
+	}
+	return true, nil
+}
+
+func GetIdempotentResponse(key, method string, conn Queryer) (models.IdempotentResponse, error) {
+
+	var stored models.IdempotentResponse
+	err := conn.QueryRow("SELECT idempotency_key, method, request_hash, response, created_at FROM idempotency_keys WHERE idempotency_key = $1 AND method = $2", key, method).Scan(&stored.Key, &stored.Method, &stored.RequestHash, &stored.Response, &stored.CreatedAt)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to query response for idempotency key: %s for %s", key, method))
+		return models.IdempotentResponse{}, err
+	}
+	return stored, nil
+}
+
+func UpdateIdempotentResponse(key, method, response string, conn Queryer) error {
+
+	_, err := conn.Exec("UPDATE idempotency_keys SET response = $3 WHERE idempotency_key = $1 AND method = $2", key, method, response)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to store response for idempotency key: %s for %s", key, method))
+		return err
+	}
+	return nil
+}
+
+func DeleteIdempotencyKey(key, method string, conn Queryer) error {
+
+	_, err := conn.Exec("DELETE FROM idempotency_keys WHERE idempotency_key = $1 AND method = $2", key, method)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to delete idempotency key: %s for %s", key, method))
+		return err
+	}
+	return nil
+}
+
+func DeleteIdempotencyKeys(before time.Time, conn Queryer) (int64, error) {
+
+	tag, err := conn.Exec("DELETE FROM idempotency_keys WHERE created_at < $1", before)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to delete idempotency keys used before %s", before.Format(time.RFC3339)))
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -2,17 +2,18 @@ package queries
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx"
 	"github.com/pkg/errors"
 	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
 )
 
-func GetPartnerDataFromKeyValue(key, value string, conn *pgx.Conn) (int32, string, error) {
+func GetPartnerDataFromKeyValue(key, value string, conn Queryer) (int32, string, error) {
 
 	partnerModel := new(models.Partner)
 	//Statement to find the id and code that correspond to the given key and value.
-	statement := "SELECT partners.Id, partners.Code FROM partner_mappings INNER JOIN partners on partners.Id = partner_mappings.partner_id WHERE key_id = (select id from keys where name = $1) and value =$2;"
+	statement := "SELECT partners.Id, partners.Code FROM partner_mappings INNER JOIN partners on partners.Id = partner_mappings.partner_id WHERE key_id = (select id from keys where name = $1 and deleted_at IS NULL) and value =$2 and partner_mappings.deleted_at IS NULL and partners.deleted_at IS NULL;"
 
 	rows, err := conn.Query(statement, key, value)
 	//hasRows is needed because err will return nil even when there are no rows to return from the above statement.
@@ -29,7 +30,7 @@ func GetPartnerDataFromKeyValue(key, value string, conn *pgx.Conn) (int32, strin
 	}
 
 	if err != nil {
-		err = errors.Wrap(errors.New(""), fmt.Sprintf("Failed to query PartnerId from key: %s and value: %s in the database", key, value))
+		err = errors.Wrap(err, fmt.Sprintf("Failed to query PartnerId from key: %s and value: %s in the database", key, value))
 		return 0, "", err
 	}
 	//If hasRows was not reset to true, we want to return an error as this means there was no corresponding row for the entered key value pair.
@@ -42,12 +43,12 @@ func GetPartnerDataFromKeyValue(key, value string, conn *pgx.Conn) (int32, strin
 	return partner.Id, partner.Code, err
 }
 
-func GetPartnerDataByIDOrCode(id int32, code string, conn *pgx.Conn) (int32, string, error) {
+func GetPartnerDataByIDOrCode(id int32, code string, conn Queryer) (int32, string, error) {
 
 	partnerModel := new(models.Partner)
 	//For GetDataById in service.go, the partner data can be found using either the partnerId or partnerCode, as long as one entry is a valid entry (eg, non-negative, non-bad)
 	//Thus the data can be selected using id or code.
-	statement := "SELECT Id, Code FROM partners WHERE id = $1 or code = $2"
+	statement := "SELECT Id, Code FROM partners WHERE (id = $1 or code = $2) AND deleted_at IS NULL"
 
 	err := conn.QueryRow(statement, id, code).Scan(&partnerModel.Id, &partnerModel.Code)
 
@@ -66,9 +67,9 @@ func GetPartnerDataByIDOrCode(id int32, code string, conn *pgx.Conn) (int32, str
 	return partner.Id, partner.Code, nil
 }
 
-func GetAllAttributesForPartner(id int32, conn *pgx.Conn) (map[string]string, error) {
+func GetAllAttributesForPartner(id int32, conn Queryer) (map[string]string, error) {
 
-	statement := "SELECT keys.name, partner_mappings.value FROM partner_mappings INNER JOIN keys on keys.id = partner_mappings.key_id WHERE partner_id = $1"
+	statement := "SELECT keys.name, partner_mappings.value FROM partner_mappings INNER JOIN keys on keys.id = partner_mappings.key_id WHERE partner_id = $1 AND partner_mappings.deleted_at IS NULL AND keys.deleted_at IS NULL"
 	rows, err := conn.Query(statement, id)
 
 	attrMap := make(map[string]string)
@@ -84,7 +85,7 @@ func GetAllAttributesForPartner(id int32, conn *pgx.Conn) (map[string]string, er
 		attr := &models.Attribute{}
 		err = rows.Scan(&attr.Name, &attr.Value)
 		if err != nil {
-			err = errors.Wrap(errors.New(""), fmt.Sprintf("Failed to scan Name and Value into Attributes"))
+			err = errors.Wrap(err, fmt.Sprintf("Failed to scan Name and Value into Attributes"))
 			return attrMap, err
 		}
 		attrMap[attr.Name.String] = attr.Value.String
@@ -97,9 +98,9 @@ func GetAllAttributesForPartner(id int32, conn *pgx.Conn) (map[string]string, er
 	return attrMap, err
 }
 
-func GetGroupAttributesForPartner(id int32, group string, conn *pgx.Conn) (map[string]string, error) {
+func GetGroupAttributesForPartner(id int32, group string, conn Queryer) (map[string]string, error) {
 
-	statement := "SELECT keys.name, partner_mappings.value FROM partner_mappings INNER JOIN keys ON keys.id = partner_mappings.key_id WHERE partner_id = $1 AND key_id = ANY(SELECT key_id FROM groups_to_keys WHERE group_id = (SELECT id FROM groups WHERE name = $2 LIMIT 1));"
+	statement := "SELECT keys.name, partner_mappings.value FROM partner_mappings INNER JOIN keys ON keys.id = partner_mappings.key_id WHERE partner_id = $1 AND partner_mappings.deleted_at IS NULL AND keys.deleted_at IS NULL AND key_id = ANY(SELECT key_id FROM groups_to_keys WHERE group_id = (SELECT id FROM groups WHERE name = $2 AND deleted_at IS NULL LIMIT 1));"
 	rows, err := conn.Query(statement, id, group)
 
 	if err != nil {
@@ -114,7 +115,7 @@ func GetGroupAttributesForPartner(id int32, group string, conn *pgx.Conn) (map[s
 		attr := &models.Attribute{}
 		err = rows.Scan(&attr.Name, &attr.Value)
 		if err != nil {
-			err = errors.Wrap(errors.New(""), fmt.Sprintf("Failed to scan Name and Value into Attributes"))
+			err = errors.Wrap(err, fmt.Sprintf("Failed to scan Name and Value into Attributes"))
 			return nil, err
 		}
 		attrMap[attr.Name.String] = attr.Value.String
@@ -127,12 +128,12 @@ func GetGroupAttributesForPartner(id int32, group string, conn *pgx.Conn) (map[s
 	return attrMap, nil
 }
 
-func GetCheckPartnerIDEqualsPartnerCode(id int32, code string, conn *pgx.Conn) (bool, error) {
+func GetCheckPartnerIDEqualsPartnerCode(id int32, code string, conn Queryer) (bool, error) {
 
 	partnerModel := new(models.Partner)
 	//Before entering GetCheckPartnerIDEqualsPartnerCode, id and code are found to be non-nil. Check that the non-nil inputs correspond
 	//to the same row in the DB.
-	statement := "SELECT id, code FROM partners WHERE id = $1 and code = $2"
+	statement := "SELECT id, code FROM partners WHERE id = $1 and code = $2 and deleted_at IS NULL"
 
 	rows, err := conn.Query(statement, id, code)
 	hasRows := false
@@ -150,3 +151,477 @@ func GetCheckPartnerIDEqualsPartnerCode(id int32, code string, conn *pgx.Conn) (
 	}
 	return hasRows, err
 }
+
+//Queryer is satisfied by *pgx.Conn, *pgx.ConnPool and *pgx.Tx, so the write queries below can run inside a transaction.
+type Queryer interface {
+	Exec(sql string, arguments ...interface{}) (pgx.CommandTag, error)
+	Query(sql string, args ...interface{}) (*pgx.Rows, error)
+	QueryRow(sql string, args ...interface{}) *pgx.Row
+}
+
+func GetPartners(codes []string, conn Queryer) ([]models.Partner, error) {
+
+	//An empty list of codes means every partner is returned.
+	statement := "SELECT id, name, code, revision, status FROM partners WHERE (cardinality($1::varchar[]) = 0 OR code = ANY($1)) AND deleted_at IS NULL ORDER BY code"
+	if codes == nil {
+		codes = []string{}
+	}
+	rows, err := conn.Query(statement, codes)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to query partners for codes: %v", codes))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var partners []models.Partner
+	for rows.Next() {
+		partnerModel := models.Partner{}
+		err = rows.Scan(&partnerModel.Id, &partnerModel.Name, &partnerModel.Code, &partnerModel.Revision, &partnerModel.Status)
+		if err != nil {
+			err = errors.Wrap(err, "Failed to scan Id, Name, Code, Revision and Status into Partner")
+			return nil, err
+		}
+		partners = append(partners, partnerModel)
+	}
+	if rows.Err() != nil {
+		err = errors.Wrap(rows.Err(), fmt.Sprintf("failed to read partners for codes: %v", codes))
+		return nil, err
+	}
+
+	return partners, nil
+}
+
+func GetAttributesForPartners(group string, conn Queryer) (map[int32]map[string]string, error) {
+
+	//Unlike GetAllAttributesForPartner this returns every partner's attributes in one round trip, and an empty group means all keys.
+	statement := "SELECT partner_mappings.partner_id, keys.name, partner_mappings.value FROM partner_mappings INNER JOIN keys ON keys.id = partner_mappings.key_id WHERE partner_mappings.deleted_at IS NULL AND keys.deleted_at IS NULL AND ($1 = '' OR key_id = ANY(SELECT key_id FROM groups_to_keys WHERE group_id = (SELECT id FROM groups WHERE name = $1 AND deleted_at IS NULL LIMIT 1)))"
+	rows, err := conn.Query(statement, group)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to query attributes for group: %s", group))
+		return nil, err
+	}
+	defer rows.Close()
+
+	attrMaps := make(map[int32]map[string]string)
+	for rows.Next() {
+		var partnerId pgx.NullInt32
+		attr := &models.Attribute{}
+		err = rows.Scan(&partnerId, &attr.Name, &attr.Value)
+		if err != nil {
+			err = errors.Wrap(err, "Failed to scan PartnerId, Name and Value into Attributes")
+			return nil, err
+		}
+		if attrMaps[partnerId.Int32] == nil {
+			attrMaps[partnerId.Int32] = make(map[string]string)
+		}
+		attrMaps[partnerId.Int32][attr.Name.String] = attr.Value.String
+	}
+	if rows.Err() != nil {
+		err = errors.Wrap(rows.Err(), fmt.Sprintf("failed to read attributes for group: %s", group))
+		return nil, err
+	}
+
+	return attrMaps, nil
+}
+
+//NewPartnerStatus is the status a partner starts out in, whether it is created by import, apply, a change set or a
+//clone, unless an import or apply gives it one. The column defaults to active only so that partners from before
+//statuses were added stay visible.
+const NewPartnerStatus = "onboarding"
+
+func SavePartner(name, code, status string, conn Queryer) (int32, error) {
+
+	//Partners are matched on code, so saving an existing code renames it rather than adding a second row. The status
+	//is only used for a new partner; an existing one changes status through SetPartnerStatus, which records it.
+	if status == "" {
+		status = NewPartnerStatus
+	}
+	var id pgx.NullInt32
+	err := conn.QueryRow("SELECT id FROM partners WHERE code = $1 AND deleted_at IS NULL", code).Scan(&id)
+	if err == pgx.ErrNoRows {
+		err = conn.QueryRow("INSERT INTO partners (name, code, status) VALUES ($1, $2, $3) RETURNING id", name, code, status).Scan(&id)
+		if err != nil {
+			err = errors.Wrap(err, fmt.Sprintf("failed to insert partner with code: %s", code))
+			return 0, err
+		}
+		return id.Int32, nil
+	}
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to query partner with code: %s", code))
+		return 0, err
+	}
+
+	_, err = conn.Exec("UPDATE partners SET name = $1 WHERE id = $2", name, id.Int32)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to update partner with code: %s", code))
+		return 0, err
+	}
+	return id.Int32, nil
+}
+
+func SavePartnerAttribute(id int32, key, value string, conn Queryer) error {
+
+	//Keys are created on first use so an import can introduce new settings. A deleted key is not created again, since
+	//the new one would have none of its groups and would not be sealed if it was sensitive; it has to be restored.
+	var keyId pgx.NullInt32
+	var deletedAt pgx.NullTime
+	err := conn.QueryRow("SELECT id, deleted_at FROM keys WHERE name = $1 ORDER BY deleted_at IS NULL DESC, deleted_at DESC LIMIT 1", key).Scan(&keyId, &deletedAt)
+	if err == nil && deletedAt.Valid {
+		err = errors.New(fmt.Sprintf("key: %s is deleted, restore it before setting values for it", key))
+		return err
+	}
+	if err == pgx.ErrNoRows {
+		err = conn.QueryRow("INSERT INTO keys (name) VALUES ($1) RETURNING id", key).Scan(&keyId)
+	}
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to find or create key: %s", key))
+		return err
+	}
+
+	tag, err := conn.Exec("UPDATE partner_mappings SET value = $1 WHERE partner_id = $2 AND key_id = $3 AND deleted_at IS NULL", value, id, keyId.Int32)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to update key: %s for partnerId: %d", key, id))
+		return err
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	_, err = conn.Exec("INSERT INTO partner_mappings (partner_id, key_id, value) VALUES ($1, $2, $3)", id, keyId.Int32, value)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to insert key: %s for partnerId: %d", key, id))
+		return err
+	}
+	return nil
+}
+
+func DeletePartnerAttributes(id int32, keep []string, conn Queryer) error {
+
+	//Tombstones every value the partner has for a key that is not in keep, so the partner ends up with exactly the kept keys.
+	if keep == nil {
+		keep = []string{}
+	}
+	statement := "UPDATE partner_mappings SET deleted_at = now() WHERE partner_id = $1 AND deleted_at IS NULL AND key_id NOT IN (SELECT id FROM keys WHERE name = ANY($2::varchar[]) AND deleted_at IS NULL)"
+	_, err := conn.Exec(statement, id, keep)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to delete attributes for partnerId: %d", id))
+		return err
+	}
+	return nil
+}
+
+func DeletePartner(code string, conn Queryer) error {
+
+	//The partner and its mappings are tombstoned rather than deleted. now() is the same for every statement in a
+	//transaction, so RestorePartner can tell which mappings went with the partner.
+	_, err := conn.Exec("UPDATE partner_mappings SET deleted_at = now() WHERE partner_id = (SELECT id FROM partners WHERE code = $1 AND deleted_at IS NULL) AND deleted_at IS NULL", code)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to delete attributes for partner with code: %s", code))
+		return err
+	}
+	tag, err := conn.Exec("UPDATE partners SET deleted_at = now() WHERE code = $1 AND deleted_at IS NULL", code)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to delete partner with code: %s", code))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		err = errors.New(fmt.Sprintf("No partner with code: %s to delete", code))
+		return err
+	}
+	return nil
+}
+
+func GetIdentifierKeys(conn Queryer) ([]string, error) {
+
+	//Identifier keys, like ISAID, hold values that belong to one partner and must never be copied to another.
+	rows, err := conn.Query("SELECT name FROM keys WHERE identifier AND deleted_at IS NULL ORDER BY name")
+	if err != nil {
+		err = errors.Wrap(err, "failed to query identifier keys")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var name pgx.NullString
+		if err = rows.Scan(&name); err != nil {
+			err = errors.Wrap(err, "Failed to scan Name into identifier keys")
+			return nil, err
+		}
+		keys = append(keys, name.String)
+	}
+	if rows.Err() != nil {
+		err = errors.Wrap(rows.Err(), "failed to read identifier keys")
+		return nil, err
+	}
+	return keys, nil
+}
+
+func GetSensitiveKeys(conn Queryer) ([]string, error) {
+
+	//Sensitive keys, like AS2 passwords, hold values that are stored sealed and kept out of logs.
+	rows, err := conn.Query("SELECT name FROM keys WHERE sensitive AND deleted_at IS NULL ORDER BY name")
+	if err != nil {
+		err = errors.Wrap(err, "failed to query sensitive keys")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var name pgx.NullString
+		if err = rows.Scan(&name); err != nil {
+			err = errors.Wrap(err, "Failed to scan Name into sensitive keys")
+			return nil, err
+		}
+		keys = append(keys, name.String)
+	}
+	if rows.Err() != nil {
+		err = errors.Wrap(rows.Err(), "failed to read sensitive keys")
+		return nil, err
+	}
+	return keys, nil
+}
+
+func GetKeyGroups(keys []string, conn Queryer) (map[string][]string, error) {
+
+	//Keys in no group that is still there are left out of the map.
+	statement := "SELECT keys.name, groups.name FROM keys INNER JOIN groups_to_keys ON groups_to_keys.key_id = keys.id INNER JOIN groups ON groups.id = groups_to_keys.group_id WHERE keys.name = ANY($1) AND keys.deleted_at IS NULL AND groups.deleted_at IS NULL ORDER BY keys.name, groups.name"
+	if keys == nil {
+		keys = []string{}
+	}
+	rows, err := conn.Query(statement, keys)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to query groups for keys: %v", keys))
+		return nil, err
+	}
+	defer rows.Close()
+
+	keyGroups := make(map[string][]string)
+	for rows.Next() {
+		var key, group pgx.NullString
+		if err = rows.Scan(&key, &group); err != nil {
+			err = errors.Wrap(err, "Failed to scan Key and Group into key groups")
+			return nil, err
+		}
+		keyGroups[key.String] = append(keyGroups[key.String], group.String)
+	}
+	if rows.Err() != nil {
+		err = errors.Wrap(rows.Err(), fmt.Sprintf("failed to read groups for keys: %v", keys))
+		return nil, err
+	}
+	return keyGroups, nil
+}
+
+func GetTemplateAttributes(name, group string, conn Queryer) (map[string]string, error) {
+
+	var templateId pgx.NullInt32
+	err := conn.QueryRow("SELECT id FROM templates WHERE name = $1", name).Scan(&templateId)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to query template with name: %s", name))
+		return nil, err
+	}
+
+	//An empty group means every key in the template.
+	statement := "SELECT keys.name, template_mappings.value FROM template_mappings INNER JOIN keys ON keys.id = template_mappings.key_id WHERE template_id = $1 AND keys.deleted_at IS NULL AND ($2 = '' OR key_id = ANY(SELECT key_id FROM groups_to_keys WHERE group_id = (SELECT id FROM groups WHERE name = $2 AND deleted_at IS NULL LIMIT 1)))"
+	rows, err := conn.Query(statement, templateId.Int32, group)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to query attributes for template: %s and group: %s", name, group))
+		return nil, err
+	}
+	defer rows.Close()
+
+	attrMap := make(map[string]string)
+	for rows.Next() {
+		attr := &models.Attribute{}
+		if err = rows.Scan(&attr.Name, &attr.Value); err != nil {
+			err = errors.Wrap(err, "Failed to scan Name and Value into Attributes")
+			return nil, err
+		}
+		attrMap[attr.Name.String] = attr.Value.String
+	}
+	if rows.Err() != nil {
+		err = errors.Wrap(rows.Err(), fmt.Sprintf("failed to read attributes for template: %s and group: %s", name, group))
+		return nil, err
+	}
+	return attrMap, nil
+}
+
+func InsertPartner(name, code string, conn Queryer) (int32, error) {
+
+	//Unlike SavePartner this never touches an existing partner, so a taken code is an error.
+	var id pgx.NullInt32
+	err := conn.QueryRow("SELECT id FROM partners WHERE code = $1 AND deleted_at IS NULL", code).Scan(&id)
+	if err == nil {
+		err = errors.New(fmt.Sprintf("partner with code: %s already exists", code))
+		return 0, err
+	}
+	if err != pgx.ErrNoRows {
+		err = errors.Wrap(err, fmt.Sprintf("failed to query partner with code: %s", code))
+		return 0, err
+	}
+
+	err = conn.QueryRow("INSERT INTO partners (name, code, status) VALUES ($1, $2, $3) RETURNING id", name, code, NewPartnerStatus).Scan(&id)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to insert partner with code: %s", code))
+		return 0, err
+	}
+	return id.Int32, nil
+}
+
+func GetPartnerStatus(id int32, conn Queryer) (string, error) {
+
+	var status pgx.NullString
+	err := conn.QueryRow("SELECT status FROM partners WHERE id = $1 AND deleted_at IS NULL", id).Scan(&status)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to query status for partnerId: %d", id))
+		return "", err
+	}
+	return status.String, nil
+}
+
+func UpdatePartnerStatus(id int32, from, to string, conn Queryer) (time.Time, error) {
+
+	//Checking the old status in the WHERE clause means a concurrent change to the status makes this one fail
+	//rather than skip a transition check.
+	var changedAt time.Time
+	statement := "UPDATE partners SET status = $3, status_changed_at = now() WHERE id = $1 AND status = $2 AND deleted_at IS NULL RETURNING status_changed_at"
+	err := conn.QueryRow(statement, id, from, to).Scan(&changedAt)
+	if err == pgx.ErrNoRows {
+		err = errors.New(fmt.Sprintf("partnerId: %d is no longer %s", id, from))
+		return time.Time{}, err
+	}
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to update status for partnerId: %d", id))
+		return time.Time{}, err
+	}
+
+	_, err = conn.Exec("INSERT INTO partner_status_changes (partner_id, from_status, to_status, changed_at) VALUES ($1, $2, $3, $4)", id, from, to, changedAt)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to record status change for partnerId: %d", id))
+		return time.Time{}, err
+	}
+	return changedAt, nil
+}
+
+func RestorePartner(code string, conn Queryer) (int64, error) {
+
+	var id pgx.NullInt32
+	err := conn.QueryRow("SELECT id FROM partners WHERE code = $1 AND deleted_at IS NULL", code).Scan(&id)
+	if err == nil {
+		err = errors.New(fmt.Sprintf("partner with code: %s already exists", code))
+		return 0, err
+	}
+	if err != pgx.ErrNoRows {
+		err = errors.Wrap(err, fmt.Sprintf("failed to query partner with code: %s", code))
+		return 0, err
+	}
+
+	//Only the latest deleted partner with the code comes back, along with the mappings deleted at the same time.
+	var deletedAt time.Time
+	err = conn.QueryRow("SELECT id, deleted_at FROM partners WHERE code = $1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC LIMIT 1", code).Scan(&id, &deletedAt)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to find deleted partner with code: %s", code))
+		return 0, err
+	}
+	tag, err := conn.Exec("UPDATE partner_mappings SET deleted_at = NULL WHERE partner_id = $1 AND deleted_at = $2", id.Int32, deletedAt)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to restore attributes for partner with code: %s", code))
+		return 0, err
+	}
+	_, err = conn.Exec("UPDATE partners SET deleted_at = NULL WHERE id = $1", id.Int32)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to restore partner with code: %s", code))
+		return 0, err
+	}
+	return tag.RowsAffected() + 1, nil
+}
+
+func RestoreKey(name string, conn Queryer) (int64, error) {
+	return restoreByName("keys", name, conn)
+}
+
+func RestoreGroup(name string, conn Queryer) (int64, error) {
+	return restoreByName("groups", name, conn)
+}
+
+//restoreByName brings back the latest deleted row with the name in keys or groups, unless a live one has the name.
+func restoreByName(table, name string, conn Queryer) (int64, error) {
+
+	var id pgx.NullInt32
+	err := conn.QueryRow(fmt.Sprintf("SELECT id FROM %s WHERE name = $1 AND deleted_at IS NULL", table), name).Scan(&id)
+	if err == nil {
+		err = errors.New(fmt.Sprintf("%s with name: %s already exists", table, name))
+		return 0, err
+	}
+	if err != pgx.ErrNoRows {
+		err = errors.Wrap(err, fmt.Sprintf("failed to query %s with name: %s", table, name))
+		return 0, err
+	}
+
+	statement := fmt.Sprintf("UPDATE %[1]s SET deleted_at = NULL WHERE id = (SELECT id FROM %[1]s WHERE name = $1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC LIMIT 1)", table)
+	tag, err := conn.Exec(statement, name)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to restore %s with name: %s", table, name))
+		return 0, err
+	}
+	if tag.RowsAffected() == 0 {
+		err = errors.New(fmt.Sprintf("No deleted %s with name: %s to restore", table, name))
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func RestorePartnerAttribute(code, key string, conn Queryer) (int64, error) {
+
+	var id, keyId pgx.NullInt32
+	err := conn.QueryRow("SELECT id FROM partners WHERE code = $1 AND deleted_at IS NULL", code).Scan(&id)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to query partner with code: %s", code))
+		return 0, err
+	}
+	err = conn.QueryRow("SELECT id FROM keys WHERE name = $1 AND deleted_at IS NULL", key).Scan(&keyId)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to query key: %s", key))
+		return 0, err
+	}
+
+	//A partner has one live value per key, so a deleted value can only come back if the key has not been set since.
+	statement := "UPDATE partner_mappings SET deleted_at = NULL WHERE id = (SELECT id FROM partner_mappings WHERE partner_id = $1 AND key_id = $2 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC LIMIT 1) AND NOT EXISTS (SELECT 1 FROM partner_mappings WHERE partner_id = $1 AND key_id = $2 AND deleted_at IS NULL)"
+	tag, err := conn.Exec(statement, id.Int32, keyId.Int32)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to restore key: %s for partner with code: %s", key, code))
+		return 0, err
+	}
+	if tag.RowsAffected() == 0 {
+		err = errors.New(fmt.Sprintf("No deleted value of key: %s for partner with code: %s to restore, or it has a value already", key, code))
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func PurgeDeleted(before time.Time, conn Queryer) (int64, error) {
+
+	//Rows that point at a purged partner, key or group go first because of the foreign keys, whether or not they
+	//were deleted themselves.
+	statements := []string{
+		"DELETE FROM partner_mappings WHERE deleted_at < $1 OR partner_id IN (SELECT id FROM partners WHERE deleted_at < $1) OR key_id IN (SELECT id FROM keys WHERE deleted_at < $1)",
+		"DELETE FROM template_mappings WHERE key_id IN (SELECT id FROM keys WHERE deleted_at < $1)",
+		"DELETE FROM groups_to_keys WHERE key_id IN (SELECT id FROM keys WHERE deleted_at < $1) OR group_id IN (SELECT id FROM groups WHERE deleted_at < $1)",
+		"DELETE FROM partner_status_changes WHERE partner_id IN (SELECT id FROM partners WHERE deleted_at < $1)",
+		"DELETE FROM approval_policies WHERE group_id IN (SELECT id FROM groups WHERE deleted_at < $1)",
+		"DELETE FROM partners WHERE deleted_at < $1",
+		"DELETE FROM keys WHERE deleted_at < $1",
+		"DELETE FROM groups WHERE deleted_at < $1",
+	}
+	var purged int64
+	for _, statement := range statements {
+		tag, err := conn.Exec(statement, before)
+		if err != nil {
+			err = errors.Wrap(err, fmt.Sprintf("failed to purge rows deleted before: %s", before))
+			return 0, err
+		}
+		purged += tag.RowsAffected()
+	}
+	return purged, nil
+}
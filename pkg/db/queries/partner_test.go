@@ -22,10 +22,10 @@ func CreateDB() {
   conn.Exec("DROP TABLE keys;")
   conn.Exec("DROP TABLE groups;")
 
-  conn.Exec("CREATE TABLE keys (id serial primary key, name varchar(255) not null);")
-  conn.Exec("CREATE TABLE groups (id serial primary key, name varchar(255) not null);")
-  conn.Exec("CREATE TABLE partners (id serial primary key, name varchar(255) not null, code varchar(255) not null);")
-  conn.Exec("CREATE TABLE partner_mappings (id serial primary key, partner_id int not null, key_id int not null, FOREIGN KEY(partner_id) REFERENCES keys(id), FOREIGN KEY(key_id) REFERENCES keys(id), value varchar(255) not null);")
+  conn.Exec("CREATE TABLE keys (id serial primary key, name varchar(255) not null, deleted_at timestamptz);")
+  conn.Exec("CREATE TABLE groups (id serial primary key, name varchar(255) not null, deleted_at timestamptz);")
+  conn.Exec("CREATE TABLE partners (id serial primary key, name varchar(255) not null, code varchar(255) not null, deleted_at timestamptz);")
+  conn.Exec("CREATE TABLE partner_mappings (id serial primary key, partner_id int not null, key_id int not null, FOREIGN KEY(partner_id) REFERENCES partners(id), FOREIGN KEY(key_id) REFERENCES keys(id), value varchar(255) not null, deleted_at timestamptz);")
   conn.Exec("CREATE TABLE groups_to_keys (id serial primary key, group_id int not null, key_id int not null, FOREIGN KEY(group_id) REFERENCES groups(id), FOREIGN KEY(key_id) REFERENCES keys(id));")
 
   conn.Exec("INSERT INTO keys (name) VALUES ('Currency');")
@@ -0,0 +1,39 @@
+package queries
+
+import (
+	"fmt"
+
+	"github.com/jackc/pgx"
+	"github.com/pkg/errors"
+)
+
+//ErrRevisionConflict is the cause of the error returned when a partner is no longer at the revision a write expected.
+var ErrRevisionConflict = errors.New("partner was changed since it was read")
+
+func GetPartnerRevision(id int32, conn Queryer) (int32, error) {
+
+	var revision pgx.NullInt32
+	err := conn.QueryRow("SELECT revision FROM partners WHERE id = $1 AND deleted_at IS NULL", id).Scan(&revision)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to query revision for partnerId: %d", id))
+		return 0, err
+	}
+	return revision.Int32, nil
+}
+
+func BumpPartnerRevision(id, expected int32, conn Queryer) (int32, error) {
+
+	//An expected revision of 0 means the write does not care what it overwrites. Otherwise the row lock taken by the
+	//update makes a concurrent write wait and then see the revision it bumped.
+	var revision pgx.NullInt32
+	err := conn.QueryRow("UPDATE partners SET revision = revision + 1 WHERE id = $1 AND deleted_at IS NULL AND ($2 = 0 OR revision = $2) RETURNING revision", id, expected).Scan(&revision)
+	if err == pgx.ErrNoRows && expected != 0 {
+		err = errors.Wrap(ErrRevisionConflict, fmt.Sprintf("partnerId: %d is not at revision %d", id, expected))
+		return 0, err
+	}
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("failed to bump revision for partnerId: %d", id))
+		return 0, err
+	}
+	return revision.Int32, nil
+}
@@ -0,0 +1,28 @@
+package queries
+
+import (
+	"github.com/pkg/errors"
+)
+
+//Ping checks that the database answers.
+func Ping(conn Queryer) error {
+
+	_, err := conn.Exec("SELECT 1")
+	if err != nil {
+		err = errors.Wrap(err, "failed to ping the database")
+		return err
+	}
+	return nil
+}
+
+//GetSchemaVersion returns the version the schema was last brought up to, or 0 if it has none.
+func GetSchemaVersion(conn Queryer) (int32, error) {
+
+	var version int32
+	err := conn.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&version)
+	if err != nil {
+		err = errors.Wrap(err, "failed to query the schema version")
+		return 0, err
+	}
+	return version, nil
+}
@@ -0,0 +1,208 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/secrets"
+)
+
+//NewSealingQuerier returns a querier that stores the values of sensitive keys sealed with the keyring and opens them
+//again when they are read. The replies kept for idempotent writes are sealed too, since they can hold sensitive values.
+//Without a keyring, writing a sensitive key fails.
+func NewSealingQuerier(next PartnerServiceQuerier, keyring *secrets.Keyring) PartnerServiceQuerier {
+	return sealingQuerier{
+		PartnerServiceQuerier: next,
+		keyring:               keyring,
+	}
+}
+
+type sealingQuerier struct {
+	PartnerServiceQuerier
+	keyring *secrets.Keyring
+}
+
+func (q sealingQuerier) sensitiveKeys() (map[string]bool, error) {
+	keys, err := q.FindSensitiveKeys()
+	if err != nil {
+		return nil, err
+	}
+	sensitive := make(map[string]bool)
+	for _, key := range keys {
+		sensitive[key] = true
+	}
+	return sensitive, nil
+}
+
+//sealAttributes returns a copy of the attributes with the values of sensitive keys sealed.
+func (q sealingQuerier) sealAttributes(attributes map[string]string, sensitive map[string]bool) (map[string]string, error) {
+	if attributes == nil {
+		return nil, nil
+	}
+	sealed := make(map[string]string)
+	for key, value := range attributes {
+		if sensitive[key] && !secrets.IsSealed(value) {
+			var err error
+			if value, err = q.keyring.Seal(key, value); err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("error sealing value of key: %s", key))
+			}
+		}
+		sealed[key] = value
+	}
+	return sealed, nil
+}
+
+func (q sealingQuerier) sealPartners(partners []models.Partner) ([]models.Partner, error) {
+	sensitive, err := q.sensitiveKeys()
+	if err != nil {
+		return nil, err
+	}
+	sealed := make([]models.Partner, len(partners))
+	for i, p := range partners {
+		if p.Attributes, err = q.sealAttributes(p.Attributes, sensitive); err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("error sealing attributes for partner: %s", p.Code.String))
+		}
+		sealed[i] = p
+	}
+	return sealed, nil
+}
+
+//openAttributes opens the sealed values in the attributes in place.
+func (q sealingQuerier) openAttributes(attributes map[string]string) error {
+	for key, value := range attributes {
+		if !secrets.IsSealed(value) {
+			continue
+		}
+		opened, err := q.keyring.Open(key, value)
+		if err != nil {
+			return err
+		}
+		attributes[key] = opened
+	}
+	return nil
+}
+
+func (q sealingQuerier) openPartners(partners []models.Partner) error {
+	for _, p := range partners {
+		if err := q.openAttributes(p.Attributes); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("error opening attributes for partner: %s", p.Code.String))
+		}
+	}
+	return nil
+}
+
+//FindPartnerDataFromKeyValue refuses sensitive keys, since their values are sealed and cannot be matched.
+func (q sealingQuerier) FindPartnerDataFromKeyValue(key, value string) (int32, string, error) {
+	sensitive, err := q.sensitiveKeys()
+	if err != nil {
+		return 0, "", err
+	}
+	if sensitive[key] {
+		return 0, "", errors.New(fmt.Sprintf("partners cannot be found by the value of sensitive key: %s", key))
+	}
+	return q.PartnerServiceQuerier.FindPartnerDataFromKeyValue(key, value)
+}
+
+func (q sealingQuerier) FindAllAttributesForPartner(id int32) (map[string]string, error) {
+	attributes, err := q.PartnerServiceQuerier.FindAllAttributesForPartner(id)
+	if err != nil {
+		return attributes, err
+	}
+	return attributes, q.openAttributes(attributes)
+}
+
+func (q sealingQuerier) FindPartnerAttribute(id int32, group string) (map[string]string, error) {
+	attributes, err := q.PartnerServiceQuerier.FindPartnerAttribute(id, group)
+	if err != nil {
+		return attributes, err
+	}
+	return attributes, q.openAttributes(attributes)
+}
+
+func (q sealingQuerier) FindPartners(codes []string, group string) ([]models.Partner, error) {
+	partners, err := q.PartnerServiceQuerier.FindPartners(codes, group)
+	if err != nil {
+		return nil, err
+	}
+	if err = q.openPartners(partners); err != nil {
+		return nil, err
+	}
+	return partners, nil
+}
+
+func (q sealingQuerier) SavePartners(partners []models.Partner) error {
+	sealed, err := q.sealPartners(partners)
+	if err != nil {
+		return errors.Wrap(err, "error saving partners in SavePartners")
+	}
+	return q.PartnerServiceQuerier.SavePartners(sealed)
+}
+
+func (q sealingQuerier) ApplyPartners(partners, deletes []models.Partner) error {
+	sealed, err := q.sealPartners(partners)
+	if err != nil {
+		return errors.Wrap(err, "error saving partners in ApplyPartners")
+	}
+	return q.PartnerServiceQuerier.ApplyPartners(sealed, deletes)
+}
+
+func (q sealingQuerier) FindTemplateAttributes(name, group string) (map[string]string, error) {
+	attributes, err := q.PartnerServiceQuerier.FindTemplateAttributes(name, group)
+	if err != nil {
+		return nil, err
+	}
+	if err = q.openAttributes(attributes); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("error opening attributes for template: %s", name))
+	}
+	return attributes, nil
+}
+
+func (q sealingQuerier) CreatePartner(p models.Partner) (int32, error) {
+	sealed, err := q.sealPartners([]models.Partner{p})
+	if err != nil {
+		return 0, errors.Wrap(err, "error creating partner in CreatePartner")
+	}
+	return q.PartnerServiceQuerier.CreatePartner(sealed[0])
+}
+
+func (q sealingQuerier) FindChangeSet(id int32) (models.ChangeSet, error) {
+	changeSet, err := q.PartnerServiceQuerier.FindChangeSet(id)
+	if err != nil {
+		return changeSet, err
+	}
+	if err = q.openPartners(changeSet.Partners); err != nil {
+		return models.ChangeSet{}, errors.Wrap(err, fmt.Sprintf("error opening staged partners for change set: %d", id))
+	}
+	return changeSet, nil
+}
+
+func (q sealingQuerier) StageChange(id int32, partner models.Partner) error {
+	sealed, err := q.sealPartners([]models.Partner{partner})
+	if err != nil {
+		return errors.Wrap(err, "error staging change in StageChange")
+	}
+	return q.PartnerServiceQuerier.StageChange(id, sealed[0])
+}
+
+func (q sealingQuerier) ClaimIdempotencyKey(key, method, requestHash string, since time.Time) (models.IdempotentResponse, bool, error) {
+	stored, claimed, err := q.PartnerServiceQuerier.ClaimIdempotencyKey(key, method, requestHash, since)
+	if err != nil || !stored.Response.Valid {
+		return stored, claimed, err
+	}
+	if stored.Response.String, err = q.keyring.Open(method, stored.Response.String); err != nil {
+		return models.IdempotentResponse{}, false, errors.Wrap(err, "error opening response in ClaimIdempotencyKey")
+	}
+	return stored, claimed, nil
+}
+
+func (q sealingQuerier) SaveIdempotentResponse(key, method, response string) error {
+	if q.keyring != nil {
+		var err error
+		if response, err = q.keyring.Seal(method, response); err != nil {
+			return errors.Wrap(err, "error sealing response in SaveIdempotentResponse")
+		}
+	}
+	return q.PartnerServiceQuerier.SaveIdempotentResponse(key, method, response)
+}
@@ -0,0 +1,273 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/tracing"
+)
+
+var tracer = tracing.Tracer("db")
+
+//NewTracingQuerier returns a querier that records a span for each query, as part of the call in ctx.
+func NewTracingQuerier(ctx context.Context, next PartnerServiceQuerier) PartnerServiceQuerier {
+	return tracingQuerier{
+		ctx:  ctx,
+		next: next,
+	}
+}
+
+//tracingQuerier does not embed the querier it wraps, so that every query is traced.
+type tracingQuerier struct {
+	ctx  context.Context
+	next PartnerServiceQuerier
+}
+
+func (q tracingQuerier) start(query string) trace.Span {
+	_, span := tracer.Start(q.ctx, "db."+query, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system", "postgresql"), attribute.String("db.operation", query)))
+	return span
+}
+
+//end is deferred with the error the query returns, which is set by the time it runs.
+func (q tracingQuerier) end(span trace.Span, err *error) {
+	tracing.End(span, *err)
+}
+
+func (q tracingQuerier) FindPartnerDataFromKeyValue(key, value string) (partnerId int32, code string, err error) {
+	defer q.end(q.start("FindPartnerDataFromKeyValue"), &err)
+	return q.next.FindPartnerDataFromKeyValue(key, value)
+}
+
+func (q tracingQuerier) FindAllAttributesForPartner(id int32) (attributes map[string]string, err error) {
+	defer q.end(q.start("FindAllAttributesForPartner"), &err)
+	return q.next.FindAllAttributesForPartner(id)
+}
+
+func (q tracingQuerier) FindPartnerAttribute(id int32, group string) (attributes map[string]string, err error) {
+	defer q.end(q.start("FindPartnerAttribute"), &err)
+	return q.next.FindPartnerAttribute(id, group)
+}
+
+func (q tracingQuerier) FindPartnerDataByID(partnerId int32, code string) (id int32, partnerCode string, err error) {
+	defer q.end(q.start("FindPartnerDataByID"), &err)
+	return q.next.FindPartnerDataByID(partnerId, code)
+}
+
+func (q tracingQuerier) CheckPartnerIDEqualsPartnerCode(partnerId int32, code string) (equal bool, err error) {
+	defer q.end(q.start("CheckPartnerIDEqualsPartnerCode"), &err)
+	return q.next.CheckPartnerIDEqualsPartnerCode(partnerId, code)
+}
+
+func (q tracingQuerier) FindPartners(codes []string, group string) (partners []models.Partner, err error) {
+	defer q.end(q.start("FindPartners"), &err)
+	return q.next.FindPartners(codes, group)
+}
+
+func (q tracingQuerier) SavePartners(partners []models.Partner) (err error) {
+	defer q.end(q.start("SavePartners"), &err)
+	return q.next.SavePartners(partners)
+}
+
+func (q tracingQuerier) ApplyPartners(partners, deletes []models.Partner) (err error) {
+	defer q.end(q.start("ApplyPartners"), &err)
+	return q.next.ApplyPartners(partners, deletes)
+}
+
+func (q tracingQuerier) FindIdentifierKeys() (keys []string, err error) {
+	defer q.end(q.start("FindIdentifierKeys"), &err)
+	return q.next.FindIdentifierKeys()
+}
+
+func (q tracingQuerier) FindKeyGroups(keys []string) (groups map[string][]string, err error) {
+	defer q.end(q.start("FindKeyGroups"), &err)
+	return q.next.FindKeyGroups(keys)
+}
+
+func (q tracingQuerier) FindSensitiveKeys() (keys []string, err error) {
+	defer q.end(q.start("FindSensitiveKeys"), &err)
+	return q.next.FindSensitiveKeys()
+}
+
+func (q tracingQuerier) FindTemplateAttributes(name, group string) (attributes map[string]string, err error) {
+	defer q.end(q.start("FindTemplateAttributes"), &err)
+	return q.next.FindTemplateAttributes(name, group)
+}
+
+func (q tracingQuerier) CreatePartner(p models.Partner) (partnerId int32, err error) {
+	defer q.end(q.start("CreatePartner"), &err)
+	return q.next.CreatePartner(p)
+}
+
+func (q tracingQuerier) FindPartnerStatus(id int32) (status string, err error) {
+	defer q.end(q.start("FindPartnerStatus"), &err)
+	return q.next.FindPartnerStatus(id)
+}
+
+func (q tracingQuerier) FindPartnerRevision(id int32) (revision int32, err error) {
+	defer q.end(q.start("FindPartnerRevision"), &err)
+	return q.next.FindPartnerRevision(id)
+}
+
+func (q tracingQuerier) UpdatePartnerStatus(id int32, from, to string, expectedRevision int32) (changedAt time.Time, err error) {
+	defer q.end(q.start("UpdatePartnerStatus"), &err)
+	return q.next.UpdatePartnerStatus(id, from, to, expectedRevision)
+}
+
+func (q tracingQuerier) RestorePartner(code string) (n int64, err error) {
+	defer q.end(q.start("RestorePartner"), &err)
+	return q.next.RestorePartner(code)
+}
+
+func (q tracingQuerier) RestoreKey(name string) (n int64, err error) {
+	defer q.end(q.start("RestoreKey"), &err)
+	return q.next.RestoreKey(name)
+}
+
+func (q tracingQuerier) RestoreGroup(name string) (n int64, err error) {
+	defer q.end(q.start("RestoreGroup"), &err)
+	return q.next.RestoreGroup(name)
+}
+
+func (q tracingQuerier) RestorePartnerAttribute(code, key string, expectedRevision int32) (n int64, err error) {
+	defer q.end(q.start("RestorePartnerAttribute"), &err)
+	return q.next.RestorePartnerAttribute(code, key, expectedRevision)
+}
+
+func (q tracingQuerier) PurgeDeleted(before time.Time) (n int64, err error) {
+	defer q.end(q.start("PurgeDeleted"), &err)
+	return q.next.PurgeDeleted(before)
+}
+
+func (q tracingQuerier) CreateChangeSet(name string) (changeSetId int32, err error) {
+	defer q.end(q.start("CreateChangeSet"), &err)
+	return q.next.CreateChangeSet(name)
+}
+
+func (q tracingQuerier) FindChangeSet(id int32) (changeSet models.ChangeSet, err error) {
+	defer q.end(q.start("FindChangeSet"), &err)
+	return q.next.FindChangeSet(id)
+}
+
+func (q tracingQuerier) StageChange(id int32, partner models.Partner) (err error) {
+	defer q.end(q.start("StageChange"), &err)
+	return q.next.StageChange(id, partner)
+}
+
+func (q tracingQuerier) PublishChangeSet(id int32) (err error) {
+	defer q.end(q.start("PublishChangeSet"), &err)
+	return q.next.PublishChangeSet(id)
+}
+
+func (q tracingQuerier) DiscardChangeSet(id int32) (err error) {
+	defer q.end(q.start("DiscardChangeSet"), &err)
+	return q.next.DiscardChangeSet(id)
+}
+
+func (q tracingQuerier) FindApprovalPolicies(keys []string) (approvers map[string][]string, err error) {
+	defer q.end(q.start("FindApprovalPolicies"), &err)
+	return q.next.FindApprovalPolicies(keys)
+}
+
+func (q tracingQuerier) FindRestoreApprovalPolicies(table, name string) (approvers map[string][]string, err error) {
+	defer q.end(q.start("FindRestoreApprovalPolicies"), &err)
+	return q.next.FindRestoreApprovalPolicies(table, name)
+}
+
+func (q tracingQuerier) RequestApproval(changeSetId int32, requestedBy string, groups []string) (requestId int32, err error) {
+	defer q.end(q.start("RequestApproval"), &err)
+	return q.next.RequestApproval(changeSetId, requestedBy, groups)
+}
+
+func (q tracingQuerier) FindApprovalRequests(status string) (requests []models.ApprovalRequest, err error) {
+	defer q.end(q.start("FindApprovalRequests"), &err)
+	return q.next.FindApprovalRequests(status)
+}
+
+func (q tracingQuerier) FindApprovalRequest(id int32) (request models.ApprovalRequest, err error) {
+	defer q.end(q.start("FindApprovalRequest"), &err)
+	return q.next.FindApprovalRequest(id)
+}
+
+func (q tracingQuerier) ApproveRequest(id int32, approver string) (err error) {
+	defer q.end(q.start("ApproveRequest"), &err)
+	return q.next.ApproveRequest(id, approver)
+}
+
+func (q tracingQuerier) RejectRequest(id int32, approver, reason string) (err error) {
+	defer q.end(q.start("RejectRequest"), &err)
+	return q.next.RejectRequest(id, approver, reason)
+}
+
+func (q tracingQuerier) ClaimIdempotencyKey(key, method, requestHash string, since time.Time) (response models.IdempotentResponse, claimed bool, err error) {
+	defer q.end(q.start("ClaimIdempotencyKey"), &err)
+	return q.next.ClaimIdempotencyKey(key, method, requestHash, since)
+}
+
+func (q tracingQuerier) SaveIdempotentResponse(key, method, response string) (err error) {
+	defer q.end(q.start("SaveIdempotentResponse"), &err)
+	return q.next.SaveIdempotentResponse(key, method, response)
+}
+
+func (q tracingQuerier) ReleaseIdempotencyKey(key, method string) (err error) {
+	defer q.end(q.start("ReleaseIdempotencyKey"), &err)
+	return q.next.ReleaseIdempotencyKey(key, method)
+}
+
+func (q tracingQuerier) PurgeIdempotencyKeys(before time.Time) (n int64, err error) {
+	defer q.end(q.start("PurgeIdempotencyKeys"), &err)
+	return q.next.PurgeIdempotencyKeys(before)
+}
+
+func (q tracingQuerier) RecordAccess(records []models.AccessRecord) (err error) {
+	defer q.end(q.start("RecordAccess"), &err)
+	return q.next.RecordAccess(records)
+}
+
+func (q tracingQuerier) FindAccessRecords(partnerCode string, from, to time.Time) (records []models.AccessRecord, err error) {
+	defer q.end(q.start("FindAccessRecords"), &err)
+	return q.next.FindAccessRecords(partnerCode, from, to)
+}
+
+func (q tracingQuerier) CreateAPIKey(key models.APIKey, hash string) (created models.APIKey, err error) {
+	defer q.end(q.start("CreateAPIKey"), &err)
+	return q.next.CreateAPIKey(key, hash)
+}
+
+func (q tracingQuerier) FindAPIKeys(owner string) (apiKeys []models.APIKey, err error) {
+	defer q.end(q.start("FindAPIKeys"), &err)
+	return q.next.FindAPIKeys(owner)
+}
+
+func (q tracingQuerier) FindAPIKeyByHash(hash string) (apiKey models.APIKey, err error) {
+	defer q.end(q.start("FindAPIKeyByHash"), &err)
+	return q.next.FindAPIKeyByHash(hash)
+}
+
+func (q tracingQuerier) RotateAPIKey(id int32, prefix, hash string) (apiKey models.APIKey, err error) {
+	defer q.end(q.start("RotateAPIKey"), &err)
+	return q.next.RotateAPIKey(id, prefix, hash)
+}
+
+func (q tracingQuerier) RevokeAPIKey(id int32) (apiKey models.APIKey, err error) {
+	defer q.end(q.start("RevokeAPIKey"), &err)
+	return q.next.RevokeAPIKey(id)
+}
+
+func (q tracingQuerier) TouchAPIKey(id int32, usedAt time.Time) (err error) {
+	defer q.end(q.start("TouchAPIKey"), &err)
+	return q.next.TouchAPIKey(id, usedAt)
+}
+
+func (q tracingQuerier) Ping() (err error) {
+	defer q.end(q.start("Ping"), &err)
+	return q.next.Ping()
+}
+
+func (q tracingQuerier) FindSchemaVersion() (version int32, err error) {
+	defer q.end(q.start("FindSchemaVersion"), &err)
+	return q.next.FindSchemaVersion()
+}
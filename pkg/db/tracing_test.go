@@ -0,0 +1,31 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracingQuerier(t *testing.T) {
+	a := assert.New(t)
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "service.GetDataById")
+	next := &statusQuerier{err: errors.New("no rows returned from id: 1")}
+
+	_, err := NewTracingQuerier(ctx, next).FindPartnerStatus(1)
+	parent.End()
+
+	a.NotNil(err)
+	spans := recorder.Ended()
+	a.Len(spans, 2)
+	a.Equal("db.FindPartnerStatus", spans[0].Name())
+	a.Equal(parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	a.Equal(codes.Error, spans[0].Status().Code)
+}
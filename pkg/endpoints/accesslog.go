@@ -0,0 +1,83 @@
+package endpoints
+
+import (
+	"context"
+	"sort"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/jackc/pgx"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/identity"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/secrets"
+)
+
+// AccessRecorder keeps a record of each read of sensitive values. The accesslog recorder is one.
+type AccessRecorder interface {
+	Record(models.AccessRecord)
+}
+
+// AccessLogMiddleware returns an endpoint middleware that records, for each partner in a reply, which sensitive values
+// the caller was given, along with the caller and the request ID. Redacted values are not recorded. It must run
+// outside AuthorizationMiddleware, which redacts, and inside AuthMiddleware, which names the caller.
+func AccessLogMiddleware(recorder AccessRecorder, sensitive *secrets.SensitiveKeys) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			response, err := next(ctx, request)
+			if err != nil {
+				return response, err
+			}
+
+			caller, _ := identity.FromContext(ctx)
+			requestID := identity.RequestIDFromContext(ctx)
+			read := sensitiveReads(response, sensitive)
+			codes := make([]string, 0, len(read))
+			for code := range read {
+				codes = append(codes, code)
+			}
+			sort.Strings(codes)
+			for _, code := range codes {
+				keys := read[code]
+				sort.Strings(keys)
+				recorder.Record(models.AccessRecord{
+					Caller:      pgx.NullString{String: caller, Valid: true},
+					PartnerCode: pgx.NullString{String: code, Valid: true},
+					Keys:        keys,
+					RequestId:   pgx.NullString{String: requestID, Valid: requestID != ""},
+				})
+			}
+			return response, nil
+		}
+	}
+}
+
+// sensitiveReads returns the sensitive keys whose values are in a reply, by partner code.
+func sensitiveReads(response interface{}, sensitive *secrets.SensitiveKeys) map[string][]string {
+	read := make(map[string][]string)
+	add := func(code string, attributes map[string]string) {
+		for key, value := range attributes {
+			if value != secrets.Redacted && sensitive.IsSensitive(key) {
+				read[code] = append(read[code], key)
+			}
+		}
+	}
+
+	switch rep := response.(type) {
+	case PartnerDataReply:
+		add(rep.PartnerCode, rep.Attributes)
+	case ExportReply:
+		for _, p := range rep.Partners {
+			add(p.Code.String, p.Attributes)
+		}
+	case CompareReply:
+		for _, k := range rep.Keys {
+			for code, value := range k.Values {
+				add(code, map[string]string{k.Key: value})
+			}
+		}
+	case ChangeSetReply:
+		for _, p := range rep.ChangeSet.Partners {
+			add(p.Code.String, p.Attributes)
+		}
+	}
+	return read
+}
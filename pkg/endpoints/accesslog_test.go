@@ -0,0 +1,71 @@
+package endpoints
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx"
+	"github.com/stretchr/testify/assert"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/identity"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/secrets"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/service"
+)
+
+//accessRecords keeps the records it is given.
+type accessRecords []models.AccessRecord
+
+func (r *accessRecords) Record(record models.AccessRecord) {
+	*r = append(*r, record)
+}
+
+var passwordSensitive = secrets.NewSensitiveKeys(sensitiveKeys{"AS2 Password", "Bank Account"}, time.Minute)
+
+func accessContext() context.Context {
+	return identity.NewRequestIDContext(identity.NewContext(context.Background(), "jdoe"), "abc123")
+}
+
+func TestAccessLogMiddlewareRecordsSensitiveReads(t *testing.T) {
+	a := assert.New(t)
+	var records accessRecords
+	reply := PartnerDataReply{PartnerId: 1, PartnerCode: "KOH", Attributes: map[string]string{"Currency": "USD", "Bank Account": "1234", "AS2 Password": "hunter2"}}
+
+	res, err := AccessLogMiddleware(&records, passwordSensitive)(replyEndpoint(reply))(accessContext(), IdRequest{PartnerId: 1})
+
+	a.Nil(err)
+	a.Equal(reply, res)
+	a.Equal(accessRecords{{
+		Caller:      pgx.NullString{String: "jdoe", Valid: true},
+		PartnerCode: pgx.NullString{String: "KOH", Valid: true},
+		Keys:        []string{"AS2 Password", "Bank Account"},
+		RequestId:   pgx.NullString{String: "abc123", Valid: true},
+	}}, records)
+}
+
+func TestAccessLogMiddlewareSkipsRedacted(t *testing.T) {
+	a := assert.New(t)
+	var records accessRecords
+	reply := PartnerDataReply{PartnerId: 1, PartnerCode: "KOH", Attributes: map[string]string{"Currency": "USD", "AS2 Password": secrets.Redacted}}
+
+	_, err := AccessLogMiddleware(&records, passwordSensitive)(replyEndpoint(reply))(accessContext(), IdRequest{PartnerId: 1})
+
+	a.Nil(err)
+	a.Empty(records)
+}
+
+func TestAccessLogMiddlewareCompare(t *testing.T) {
+	a := assert.New(t)
+	var records accessRecords
+	reply := CompareReply{PartnerCodes: []string{"KOH", "JCP"}, Keys: []service.KeyComparison{
+		{Key: "AS2 Password", Status: "DIFFERENT", Values: map[string]string{"KOH": "hunter2", "JCP": secrets.Redacted}},
+		{Key: "Currency", Status: "EQUAL", Values: map[string]string{"KOH": "USD", "JCP": "USD"}},
+	}}
+
+	_, err := AccessLogMiddleware(&records, passwordSensitive)(replyEndpoint(reply))(accessContext(), CompareRequest{PartnerCodes: []string{"KOH", "JCP"}})
+
+	a.Nil(err)
+	a.Equal(1, len(records))
+	a.Equal("KOH", records[0].PartnerCode.String)
+	a.Equal([]string{"AS2 Password"}, records[0].Keys)
+}
@@ -0,0 +1,62 @@
+package endpoints
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/pkg/errors"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/apikeys"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/identity"
+)
+
+// apiKeyTouchInterval is how stale the last-used time of a key can get, so a busy key is not written on every call.
+const apiKeyTouchInterval = time.Minute
+
+// APIKeyStore finds API keys by their hash. The db querier is one.
+type APIKeyStore interface {
+	FindAPIKeyByHash(string) (models.APIKey, error)
+	TouchAPIKey(int32, time.Time) error
+}
+
+type apiKeyKey struct{}
+
+// NewAPIKeyContext returns a copy of ctx that carries the API key sent with a request.
+func NewAPIKeyContext(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, apiKeyKey{}, key)
+}
+
+// APIKeyMiddleware returns an endpoint middleware that authenticates callers who send an API key, as the key with the
+// roles in its scope. They are named for the key rather than its owner, so they can never pass for a person, such as
+// an approver. Keys that are unknown, expired or revoked are refused. Callers who send no key are passed on, so it
+// goes in front of AuthMiddleware, which then lets the ones it named through.
+func APIKeyMiddleware(store APIKeyStore) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			key, _ := ctx.Value(apiKeyKey{}).(string)
+			if key == "" {
+				return next(ctx, request)
+			}
+			if !apikeys.LooksLikeKey(key) {
+				return nil, errors.Wrap(ErrUnauthenticated, "invalid api key")
+			}
+
+			apiKey, err := store.FindAPIKeyByHash(apikeys.Hash(key))
+			if err != nil {
+				return nil, err
+			}
+			now := time.Now()
+			if !apiKey.Usable(now) {
+				return nil, errors.Wrap(ErrUnauthenticated, "invalid api key")
+			}
+			if !apiKey.LastUsedAt.Valid || now.Sub(apiKey.LastUsedAt.Time) > apiKeyTouchInterval {
+				//the last-used time is only a guide, so failing to keep it does not fail the call
+				store.TouchAPIKey(apiKey.Id.Int32, now)
+			}
+
+			ctx = identity.NewRolesContext(ctx, apiKey.Scope)
+			return next(identity.NewContext(ctx, identity.APIKeyCaller(apiKey.Id.Int32)), request)
+		}
+	}
+}
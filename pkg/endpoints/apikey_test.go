@@ -0,0 +1,126 @@
+package endpoints
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/apikeys"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/identity"
+)
+
+const testAPIKey = "psk_0a1b2c3d_c2VjcmV0IHNlY3JldCBzZWNyZXQgc2VjcmV0IHNlY3JldA"
+
+func issuedKey() models.APIKey {
+	return models.APIKey{
+		Id:     pgx.NullInt32{Int32: 7, Valid: true},
+		Prefix: pgx.NullString{String: "psk_0a1b2c3d", Valid: true},
+		Owner:  pgx.NullString{String: "onboarding-pipeline", Valid: true},
+		Scope:  []string{"edi"},
+	}
+}
+
+//rolesEndpoint replies with the roles it was served for.
+func rolesEndpoint(ctx context.Context, request interface{}) (interface{}, error) {
+	return identity.RolesFromContext(ctx), nil
+}
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	a := assert.New(t)
+	mockQ := new(mockQuerier)
+	mockQ.On("FindAPIKeyByHash", apikeys.Hash(testAPIKey)).Return(issuedKey(), nil)
+	mockQ.On("TouchAPIKey", int32(7), mock.AnythingOfType("time.Time")).Return(nil)
+
+	res, err := APIKeyMiddleware(mockQ)(callerEndpoint)(NewAPIKeyContext(context.Background(), testAPIKey), nil)
+
+	a.Nil(err)
+	a.Equal("apikey:7", res)
+	mockQ.AssertExpectations(t)
+}
+
+func TestAPIKeyMiddlewareScope(t *testing.T) {
+	a := assert.New(t)
+	key := issuedKey()
+	key.LastUsedAt = pgx.NullTime{Time: time.Now(), Valid: true}
+	mockQ := new(mockQuerier)
+	mockQ.On("FindAPIKeyByHash", apikeys.Hash(testAPIKey)).Return(key, nil)
+
+	res, err := APIKeyMiddleware(mockQ)(rolesEndpoint)(NewAPIKeyContext(context.Background(), testAPIKey), nil)
+
+	a.Nil(err)
+	a.Equal([]string{"edi"}, res)
+	mockQ.AssertNotCalled(t, "TouchAPIKey", mock.Anything, mock.Anything)
+}
+
+func TestAPIKeyMiddlewareNoKey(t *testing.T) {
+	a := assert.New(t)
+	mockQ := new(mockQuerier)
+
+	res, err := APIKeyMiddleware(mockQ)(callerEndpoint)(identity.NewContext(context.Background(), "jdoe"), nil)
+
+	a.Nil(err)
+	a.Equal("jdoe", res)
+	mockQ.AssertExpectations(t)
+}
+
+func TestAPIKeyMiddlewareUnknown(t *testing.T) {
+	a := assert.New(t)
+	mockQ := new(mockQuerier)
+	mockQ.On("FindAPIKeyByHash", apikeys.Hash(testAPIKey)).Return(models.APIKey{}, nil)
+
+	res, err := APIKeyMiddleware(mockQ)(callerEndpoint)(NewAPIKeyContext(context.Background(), testAPIKey), nil)
+
+	a.Nil(res)
+	a.True(IsUnauthenticated(err))
+}
+
+func TestAPIKeyMiddlewareMalformed(t *testing.T) {
+	a := assert.New(t)
+	mockQ := new(mockQuerier)
+
+	_, err := APIKeyMiddleware(mockQ)(callerEndpoint)(NewAPIKeyContext(context.Background(), "hunter2"), nil)
+
+	a.True(IsUnauthenticated(err))
+	mockQ.AssertNotCalled(t, "FindAPIKeyByHash", mock.Anything)
+}
+
+func TestAPIKeyMiddlewareRevoked(t *testing.T) {
+	a := assert.New(t)
+	key := issuedKey()
+	key.RevokedAt = pgx.NullTime{Time: time.Now().Add(-time.Hour), Valid: true}
+	mockQ := new(mockQuerier)
+	mockQ.On("FindAPIKeyByHash", apikeys.Hash(testAPIKey)).Return(key, nil)
+
+	_, err := APIKeyMiddleware(mockQ)(callerEndpoint)(NewAPIKeyContext(context.Background(), testAPIKey), nil)
+
+	a.True(IsUnauthenticated(err))
+}
+
+func TestAPIKeyMiddlewareExpired(t *testing.T) {
+	a := assert.New(t)
+	key := issuedKey()
+	key.ExpiresAt = pgx.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}
+	mockQ := new(mockQuerier)
+	mockQ.On("FindAPIKeyByHash", apikeys.Hash(testAPIKey)).Return(key, nil)
+
+	_, err := APIKeyMiddleware(mockQ)(callerEndpoint)(NewAPIKeyContext(context.Background(), testAPIKey), nil)
+
+	a.True(IsUnauthenticated(err))
+}
+
+func TestAPIKeyMiddlewareBeforeAuth(t *testing.T) {
+	a := assert.New(t)
+	key := issuedKey()
+	key.LastUsedAt = pgx.NullTime{Time: time.Now(), Valid: true}
+	mockQ := new(mockQuerier)
+	mockQ.On("FindAPIKeyByHash", apikeys.Hash(testAPIKey)).Return(key, nil)
+
+	res, err := APIKeyMiddleware(mockQ)(AuthMiddleware(nil)(callerEndpoint))(NewAPIKeyContext(context.Background(), testAPIKey), nil)
+
+	a.Nil(err)
+	a.Equal("apikey:7", res)
+}
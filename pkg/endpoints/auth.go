@@ -0,0 +1,136 @@
+package endpoints
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+
+	stdjwt "github.com/dgrijalva/jwt-go"
+	kitjwt "github.com/go-kit/kit/auth/jwt"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/pkg/errors"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/identity"
+)
+
+// ErrUnauthenticated is the cause of the error returned to callers without a client certificate or a valid token.
+var ErrUnauthenticated = errors.New("caller is not authenticated")
+
+// IsUnauthenticated reports whether err was returned by AuthMiddleware.
+func IsUnauthenticated(err error) bool {
+	return errors.Cause(err) == ErrUnauthenticated
+}
+
+// Claims are the claims read from a bearer token. Roles are checked against the authorization policy.
+type Claims struct {
+	stdjwt.StandardClaims
+	Roles []string `json:"roles,omitempty"`
+}
+
+// AuthMiddleware returns an endpoint middleware that only serves callers with an identity. A caller whose client
+// certificate was verified already has one. Otherwise the bearer token sent with the request must be a JWT signed
+// with one of the keys, and its subject becomes the caller and its roles claim their roles. With no keys only client
+// certificates are accepted.
+func AuthMiddleware(keys stdjwt.Keyfunc) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			if _, ok := identity.FromContext(ctx); ok {
+				return next(ctx, request)
+			}
+			if keys == nil {
+				return nil, errors.Wrap(ErrUnauthenticated, "a client certificate is required")
+			}
+			tokenString, ok := ctx.Value(kitjwt.JWTTokenContextKey).(string)
+			if !ok || tokenString == "" {
+				return nil, errors.Wrap(ErrUnauthenticated, "a bearer token is required")
+			}
+
+			claims := &Claims{}
+			token, err := stdjwt.ParseWithClaims(tokenString, claims, keys)
+			if err != nil || !token.Valid {
+				return nil, errors.Wrap(ErrUnauthenticated, fmt.Sprintf("invalid token: %v", err))
+			}
+			if claims.Subject == "" {
+				return nil, errors.Wrap(ErrUnauthenticated, "token has no subject")
+			}
+			if identity.IsAPIKeyCaller(claims.Subject) {
+				return nil, errors.Wrap(ErrUnauthenticated, "token subject names an api key")
+			}
+
+			ctx = context.WithValue(ctx, kitjwt.JWTClaimsContextKey, claims)
+			ctx = identity.NewRolesContext(ctx, claims.Roles)
+			return next(identity.NewContext(ctx, claims.Subject), request)
+		}
+	}
+}
+
+// HMACKeys returns the keys for tokens signed with an HMAC secret.
+func HMACKeys(secret []byte) stdjwt.Keyfunc {
+	return func(token *stdjwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*stdjwt.SigningMethodHMAC); !ok {
+			return nil, errors.New(fmt.Sprintf("unexpected signing method: %v", token.Header["alg"]))
+		}
+		return secret, nil
+	}
+}
+
+// jwk is an RSA key in a JSON Web Key Set.
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// LoadJWKS returns the keys for tokens signed with one of the RSA keys in a JSON Web Key Set file. A token picks its
+// key with the kid header, which it can leave out when the set has only one key.
+func LoadJWKS(path string) (stdjwt.Keyfunc, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("failed to read JWKS file %s", path))
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err = json.Unmarshal(data, &set); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("failed to parse JWKS file %s", path))
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("bad modulus for key %s", k.Kid))
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("bad exponent for key %s", k.Kid))
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New(fmt.Sprintf("JWKS file %s has no RSA keys", path))
+	}
+
+	return func(token *stdjwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*stdjwt.SigningMethodRSA); !ok {
+			return nil, errors.New(fmt.Sprintf("unexpected signing method: %v", token.Header["alg"]))
+		}
+		kid, _ := token.Header["kid"].(string)
+		if key, ok := keys[kid]; ok {
+			return key, nil
+		}
+		if kid == "" && len(keys) == 1 {
+			for _, key := range keys {
+				return key, nil
+			}
+		}
+		return nil, errors.New(fmt.Sprintf("unknown key id: %s", kid))
+	}, nil
+}
@@ -0,0 +1,144 @@
+package endpoints
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	stdjwt "github.com/dgrijalva/jwt-go"
+	kitjwt "github.com/go-kit/kit/auth/jwt"
+	"github.com/stretchr/testify/assert"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/identity"
+)
+
+var secret = []byte("test secret")
+
+//callerEndpoint replies with the caller it was served for.
+func callerEndpoint(ctx context.Context, request interface{}) (interface{}, error) {
+	caller, _ := identity.FromContext(ctx)
+	return caller, nil
+}
+
+func tokenContext(token string) context.Context {
+	return context.WithValue(context.Background(), kitjwt.JWTTokenContextKey, token)
+}
+
+func signHMAC(claims stdjwt.StandardClaims) string {
+	token, _ := stdjwt.NewWithClaims(stdjwt.SigningMethodHS256, claims).SignedString(secret)
+	return token
+}
+
+func TestAuthMiddlewareHMAC(t *testing.T) {
+	a := assert.New(t)
+	token := signHMAC(stdjwt.StandardClaims{Subject: "jdoe", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+
+	res, err := AuthMiddleware(HMACKeys(secret))(callerEndpoint)(tokenContext(token), nil)
+
+	a.Nil(err)
+	a.Equal("jdoe", res)
+}
+
+func TestAuthMiddlewareNoToken(t *testing.T) {
+	a := assert.New(t)
+
+	res, err := AuthMiddleware(HMACKeys(secret))(callerEndpoint)(context.Background(), nil)
+
+	a.Nil(res)
+	a.True(IsUnauthenticated(err))
+}
+
+func TestAuthMiddlewareWrongSecret(t *testing.T) {
+	a := assert.New(t)
+	token := signHMAC(stdjwt.StandardClaims{Subject: "jdoe"})
+
+	_, err := AuthMiddleware(HMACKeys([]byte("other secret")))(callerEndpoint)(tokenContext(token), nil)
+
+	a.True(IsUnauthenticated(err))
+}
+
+func TestAuthMiddlewareExpired(t *testing.T) {
+	a := assert.New(t)
+	token := signHMAC(stdjwt.StandardClaims{Subject: "jdoe", ExpiresAt: time.Now().Add(-time.Hour).Unix()})
+
+	_, err := AuthMiddleware(HMACKeys(secret))(callerEndpoint)(tokenContext(token), nil)
+
+	a.True(IsUnauthenticated(err))
+}
+
+func TestAuthMiddlewareNoSubject(t *testing.T) {
+	a := assert.New(t)
+	token := signHMAC(stdjwt.StandardClaims{ExpiresAt: time.Now().Add(time.Hour).Unix()})
+
+	_, err := AuthMiddleware(HMACKeys(secret))(callerEndpoint)(tokenContext(token), nil)
+
+	a.True(IsUnauthenticated(err))
+}
+
+func TestAuthMiddlewareAPIKeySubject(t *testing.T) {
+	a := assert.New(t)
+	token := signHMAC(stdjwt.StandardClaims{Subject: "apikey:7", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+
+	_, err := AuthMiddleware(HMACKeys(secret))(callerEndpoint)(tokenContext(token), nil)
+
+	a.True(IsUnauthenticated(err))
+}
+
+func TestAuthMiddlewareClientCert(t *testing.T) {
+	a := assert.New(t)
+	ctx := identity.NewContext(context.Background(), "onboarding-pipeline")
+
+	res, err := AuthMiddleware(nil)(callerEndpoint)(ctx, nil)
+
+	a.Nil(err)
+	a.Equal("onboarding-pipeline", res)
+}
+
+func TestAuthMiddlewareNoKeys(t *testing.T) {
+	a := assert.New(t)
+	token := signHMAC(stdjwt.StandardClaims{Subject: "jdoe"})
+
+	_, err := AuthMiddleware(nil)(callerEndpoint)(tokenContext(token), nil)
+
+	a.True(IsUnauthenticated(err))
+}
+
+func TestLoadJWKS(t *testing.T) {
+	a := assert.New(t)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	a.Nil(err)
+	file, err := ioutil.TempFile("", "jwks")
+	a.Nil(err)
+	defer os.Remove(file.Name())
+	fmt.Fprintf(file, `{"keys": [{"kid": "test", "kty": "RSA", "alg": "RS256", "n": "%s", "e": "%s"}]}`,
+		base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()))
+	file.Close()
+
+	keys, err := LoadJWKS(file.Name())
+	a.Nil(err)
+
+	token := stdjwt.NewWithClaims(stdjwt.SigningMethodRS256, stdjwt.StandardClaims{Subject: "jdoe"})
+	token.Header["kid"] = "test"
+	signed, err := token.SignedString(key)
+	a.Nil(err)
+	res, err := AuthMiddleware(keys)(callerEndpoint)(tokenContext(signed), nil)
+	a.Nil(err)
+	a.Equal("jdoe", res)
+
+	//a token signed with the secret of an HMAC key is not accepted by RSA keys
+	_, err = AuthMiddleware(keys)(callerEndpoint)(tokenContext(signHMAC(stdjwt.StandardClaims{Subject: "jdoe"})), nil)
+	a.True(IsUnauthenticated(err))
+}
+
+func TestLoadJWKSMissing(t *testing.T) {
+	_, err := LoadJWKS("./does-not-exist.json")
+
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,269 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/pkg/errors"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/authz"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/identity"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/secrets"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/service"
+)
+
+// ErrPermissionDenied is the cause of the error returned to callers whose roles do not allow the request.
+var ErrPermissionDenied = errors.New("permission denied")
+
+// IsPermissionDenied reports whether err was returned by AuthorizationMiddleware.
+func IsPermissionDenied(err error) bool {
+	return errors.Cause(err) == ErrPermissionDenied
+}
+
+// KeyGroupFinder finds the groups keys are in. The db querier is one.
+type KeyGroupFinder interface {
+	FindKeyGroups([]string) (map[string][]string, error)
+}
+
+// AuthorizationMiddleware returns an endpoint middleware that serves a request only if the caller's roles allow it,
+// drops the keys the caller cannot read from the reply and redacts the sensitive values they cannot see. A write to a
+// key needs every group the key is in to be writable, and a write to a whole partner, such as a status change, needs
+// every group to be. The caller's roles, from the policy when their token gave them none, are put in the context for
+// the service. It must run inside AuthMiddleware, which names the caller.
+func AuthorizationMiddleware(policy authz.Policy, keyGroups KeyGroupFinder, sensitive *secrets.SensitiveKeys) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			caller, _ := identity.FromContext(ctx)
+			roles := policy.CallerRoles(caller, identity.RolesFromContext(ctx))
+			ctx = identity.NewRolesContext(ctx, roles)
+			access := policy.Access(caller, roles)
+			if err := authorizeRequest(access, keyGroups, request); err != nil {
+				return nil, err
+			}
+
+			response, err := next(ctx, request)
+			if err != nil {
+				return response, err
+			}
+			return filterReply(access, keyGroups, sensitive, response)
+		}
+	}
+}
+
+func denied(format string, args ...interface{}) error {
+	return errors.Wrap(ErrPermissionDenied, fmt.Sprintf(format, args...))
+}
+
+// authorizeRequest returns an error if the access does not allow the request. Requests it does not know are denied.
+func authorizeRequest(access authz.Access, keyGroups KeyGroupFinder, request interface{}) error {
+	switch req := request.(type) {
+	case KeyValueRequest:
+		if err := authorizeRead(access, req.Group); err != nil {
+			return err
+		}
+		return authorizeKeys(access, keyGroups, []string{req.Key}, false)
+	case IdRequest:
+		return authorizeRead(access, req.Group)
+	case ExportRequest:
+		return authorizeRead(access, req.Group)
+	case CompareRequest:
+		return authorizeRead(access, req.Group)
+	case PreviewRequest, ListApprovalRequestsRequest:
+		return authorizeRead(access, "")
+	case CloneRequest:
+		if len(req.Groups) == 0 && !access.CanWriteAll() {
+			return denied("cannot clone every group")
+		}
+		for _, group := range req.Groups {
+			if !access.CanWrite(group) {
+				return denied("cannot write group %s", group)
+			}
+		}
+		return authorizeKeys(access, keyGroups, mapKeys(req.Overrides), true)
+	case StatusRequest:
+		if !access.CanWriteAll() {
+			return denied("cannot change the status of partner %s", req.PartnerCode)
+		}
+		return nil
+	case RestoreRequest:
+		switch {
+		case req.Key != "":
+			return authorizeKeys(access, keyGroups, []string{req.Key}, true)
+		case req.Group != "":
+			if !access.CanWrite(req.Group) {
+				return denied("cannot write group %s", req.Group)
+			}
+			return nil
+		case !access.CanWriteAll():
+			return denied("cannot restore partner %s", req.PartnerCode)
+		}
+		return nil
+	case StageChangeRequest:
+		if !access.CanWriteAny() {
+			return denied("cannot stage changes")
+		}
+		return authorizeKeys(access, keyGroups, mapKeys(req.Attributes), true)
+	case OpenChangeSetRequest, ChangeSetRequest, ApprovalDecisionRequest:
+		if !access.CanWriteAny() {
+			return denied("cannot change partners")
+		}
+		return nil
+	case AccessLogRequest:
+		if !access.CanAudit() {
+			return denied("cannot read the access log")
+		}
+		return nil
+	case IssueAPIKeyRequest, ListAPIKeysRequest, APIKeyRequest:
+		if !access.CanWriteAll() {
+			return denied("cannot manage api keys")
+		}
+		return nil
+	}
+	return denied("unknown request %T", request)
+}
+
+// authorizeRead allows reading the group, or reading at all when no group is given.
+func authorizeRead(access authz.Access, group string) error {
+	if group == "" && !access.CanReadAny() {
+		return denied("cannot read any group")
+	}
+	if group != "" && !access.CanRead(group) {
+		return denied("cannot read group %s", group)
+	}
+	return nil
+}
+
+func authorizeKeys(access authz.Access, keyGroups KeyGroupFinder, keys []string, write bool) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	groups, err := keyGroups.FindKeyGroups(keys)
+	if err != nil {
+		return errors.Wrap(err, "could not authorize keys")
+	}
+	for _, key := range keys {
+		if write && !access.CanWriteKey(groups[key]) {
+			return denied("cannot write key %s", key)
+		}
+		if !write && !access.CanReadKey(groups[key]) {
+			return denied("cannot read key %s", key)
+		}
+	}
+	return nil
+}
+
+// filterReply drops the keys the access cannot read from the attributes in a reply, and redacts the values of
+// sensitive keys the access cannot see. The reply's maps are copied rather than changed.
+func filterReply(access authz.Access, keyGroups KeyGroupFinder, sensitive *secrets.SensitiveKeys, response interface{}) (interface{}, error) {
+	var keys []string
+	switch rep := response.(type) {
+	case PartnerDataReply:
+		keys = mapKeys(rep.Attributes)
+	case ExportReply:
+		keys = partnerKeys(rep.Partners)
+	case CompareReply:
+		for _, k := range rep.Keys {
+			keys = append(keys, k.Key)
+		}
+	case ChangeSetReply:
+		keys = partnerKeys(rep.ChangeSet.Partners)
+	default:
+		return response, nil
+	}
+	if len(keys) == 0 {
+		return response, nil
+	}
+
+	groups, err := keyGroups.FindKeyGroups(keys)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not filter reply")
+	}
+	show := func(key, value string) (string, bool) {
+		if !access.CanReadKey(groups[key]) {
+			return "", false
+		}
+		if sensitive.IsSensitive(key) && !access.CanSeeSensitive(groups[key]) {
+			return secrets.Redacted, true
+		}
+		return value, true
+	}
+
+	switch rep := response.(type) {
+	case PartnerDataReply:
+		rep.Attributes = filterAttributes(rep.Attributes, show)
+		return rep, nil
+	case ExportReply:
+		rep.Partners = filterPartners(rep.Partners, show)
+		return rep, nil
+	case CompareReply:
+		var comparisons []service.KeyComparison
+		for _, k := range rep.Keys {
+			if _, ok := show(k.Key, ""); !ok {
+				continue
+			}
+			//the values are by partner code, all of the one key
+			k.Values = filterAttributes(k.Values, func(code, value string) (string, bool) {
+				return show(k.Key, value)
+			})
+			comparisons = append(comparisons, k)
+		}
+		rep.Keys = comparisons
+		return rep, nil
+	case ChangeSetReply:
+		rep.ChangeSet.Partners = filterPartners(rep.ChangeSet.Partners, show)
+		return rep, nil
+	}
+	return response, nil
+}
+
+//filterAttributes copies the attributes that show lets through, with the values it gives.
+func filterAttributes(attributes map[string]string, show func(string, string) (string, bool)) map[string]string {
+	if attributes == nil {
+		return nil
+	}
+	filtered := make(map[string]string)
+	for key, value := range attributes {
+		if value, ok := show(key, value); ok {
+			filtered[key] = value
+		}
+	}
+	return filtered
+}
+
+func filterPartners(partners []models.Partner, show func(string, string) (string, bool)) []models.Partner {
+	if partners == nil {
+		return nil
+	}
+	filtered := make([]models.Partner, len(partners))
+	for i, p := range partners {
+		p.Attributes = filterAttributes(p.Attributes, show)
+		filtered[i] = p
+	}
+	return filtered
+}
+
+func partnerKeys(partners []models.Partner) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, p := range partners {
+		for key := range p.Attributes {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func mapKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,193 @@
+package endpoints
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/authz"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/identity"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/secrets"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/service"
+)
+
+var testPolicy = authz.Policy{
+	Roles: map[string]authz.Grant{
+		"admin":   {Write: []string{authz.AllGroups}},
+		"edi":     {Write: []string{"EDI"}},
+		"finance": {Write: []string{"Money"}, Sensitive: []string{"Money"}},
+		"reader":  {Read: []string{authz.AllGroups}},
+	},
+	DefaultRoles: []string{"reader"},
+}
+
+//sensitiveKeys finds the keys in it as the sensitive ones.
+type sensitiveKeys []string
+
+func (k sensitiveKeys) FindSensitiveKeys() ([]string, error) {
+	return k, nil
+}
+
+var noSensitiveKeys = secrets.NewSensitiveKeys(sensitiveKeys(nil), time.Minute)
+
+func rolesContext(roles ...string) context.Context {
+	return identity.NewRolesContext(identity.NewContext(context.Background(), "jdoe"), roles)
+}
+
+//replyEndpoint replies with the reply it was made with.
+func replyEndpoint(reply interface{}) func(context.Context, interface{}) (interface{}, error) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		return reply, nil
+	}
+}
+
+func TestAuthorizationMiddlewareStatusDenied(t *testing.T) {
+	a := assert.New(t)
+	mockQ := new(mockQuerier)
+
+	res, err := AuthorizationMiddleware(testPolicy, mockQ, noSensitiveKeys)(callerEndpoint)(rolesContext("reader"), StatusRequest{PartnerCode: "KOH", Status: "inactive"})
+
+	a.Nil(res)
+	a.True(IsPermissionDenied(err))
+	mockQ.AssertExpectations(t)
+}
+
+func TestAuthorizationMiddlewareStatusAdmin(t *testing.T) {
+	a := assert.New(t)
+	mockQ := new(mockQuerier)
+
+	res, err := AuthorizationMiddleware(testPolicy, mockQ, noSensitiveKeys)(callerEndpoint)(rolesContext("admin"), StatusRequest{PartnerCode: "KOH", Status: "inactive"})
+
+	a.Nil(err)
+	a.Equal("jdoe", res)
+}
+
+func TestAuthorizationMiddlewareKeyValueUnreadable(t *testing.T) {
+	a := assert.New(t)
+	mockQ := new(mockQuerier)
+	mockQ.On("FindKeyGroups", []string{"Currency"}).Return(map[string][]string{"Currency": {"Money"}}, nil)
+
+	_, err := AuthorizationMiddleware(testPolicy, mockQ, noSensitiveKeys)(callerEndpoint)(rolesContext("edi"), KeyValueRequest{Key: "Currency", Value: "USD"})
+
+	a.True(IsPermissionDenied(err))
+	mockQ.AssertExpectations(t)
+}
+
+func TestAuthorizationMiddlewareFiltersReply(t *testing.T) {
+	a := assert.New(t)
+	mockQ := new(mockQuerier)
+	mockQ.On("FindKeyGroups", []string{"Currency", "ISAID"}).Return(map[string][]string{"Currency": {"Money"}, "ISAID": {"EDI"}}, nil)
+	reply := PartnerDataReply{PartnerId: 1, Attributes: map[string]string{"Currency": "USD", "ISAID": "ZZ"}}
+
+	res, err := AuthorizationMiddleware(testPolicy, mockQ, noSensitiveKeys)(replyEndpoint(reply))(rolesContext("edi"), IdRequest{PartnerId: 1})
+
+	a.Nil(err)
+	a.Equal(map[string]string{"ISAID": "ZZ"}, res.(PartnerDataReply).Attributes)
+	//the endpoint's reply is not changed
+	a.Equal("USD", reply.Attributes["Currency"])
+	mockQ.AssertExpectations(t)
+}
+
+func TestAuthorizationMiddlewareStageChangeDenied(t *testing.T) {
+	a := assert.New(t)
+	mockQ := new(mockQuerier)
+	mockQ.On("FindKeyGroups", []string{"Currency", "ISAID"}).Return(map[string][]string{"Currency": {"EDI", "Money"}, "ISAID": {"EDI"}}, nil)
+	request := StageChangeRequest{ChangeSetId: 1, PartnerCode: "KOH", Attributes: map[string]string{"Currency": "USD", "ISAID": "ZZ"}}
+
+	_, err := AuthorizationMiddleware(testPolicy, mockQ, noSensitiveKeys)(callerEndpoint)(rolesContext("edi"), request)
+
+	a.True(IsPermissionDenied(err))
+	mockQ.AssertExpectations(t)
+}
+
+func TestAuthorizationMiddlewareDefaultRoles(t *testing.T) {
+	a := assert.New(t)
+	mockQ := new(mockQuerier)
+	ctx := identity.NewContext(context.Background(), "jdoe")
+
+	_, err := AuthorizationMiddleware(testPolicy, mockQ, noSensitiveKeys)(callerEndpoint)(ctx, ExportRequest{Group: "Money"})
+	a.Nil(err)
+
+	_, err = AuthorizationMiddleware(testPolicy, mockQ, noSensitiveKeys)(callerEndpoint)(ctx, OpenChangeSetRequest{Name: "q3"})
+	a.True(IsPermissionDenied(err))
+}
+
+func TestAuthorizationMiddlewareUnknownRequest(t *testing.T) {
+	_, err := AuthorizationMiddleware(testPolicy, new(mockQuerier), noSensitiveKeys)(callerEndpoint)(rolesContext("admin"), "request")
+
+	assert.True(t, IsPermissionDenied(err))
+}
+
+func TestAuthorizationMiddlewareRedactsSensitive(t *testing.T) {
+	a := assert.New(t)
+	mockQ := new(mockQuerier)
+	mockQ.On("FindKeyGroups", []string{"AS2 Password", "Currency"}).Return(map[string][]string{"AS2 Password": {"Money"}, "Currency": {"Money"}}, nil)
+	sensitive := secrets.NewSensitiveKeys(sensitiveKeys{"AS2 Password"}, time.Minute)
+	reply := PartnerDataReply{PartnerId: 1, Attributes: map[string]string{"AS2 Password": "hunter2", "Currency": "USD"}}
+
+	res, err := AuthorizationMiddleware(testPolicy, mockQ, sensitive)(replyEndpoint(reply))(rolesContext("reader"), IdRequest{PartnerId: 1})
+	a.Nil(err)
+	a.Equal(map[string]string{"AS2 Password": secrets.Redacted, "Currency": "USD"}, res.(PartnerDataReply).Attributes)
+
+	res, err = AuthorizationMiddleware(testPolicy, mockQ, sensitive)(replyEndpoint(reply))(rolesContext("finance"), IdRequest{PartnerId: 1})
+	a.Nil(err)
+	a.Equal(map[string]string{"AS2 Password": "hunter2", "Currency": "USD"}, res.(PartnerDataReply).Attributes)
+	mockQ.AssertExpectations(t)
+}
+
+func TestAuthorizationMiddlewareRedactsSensitiveComparison(t *testing.T) {
+	a := assert.New(t)
+	mockQ := new(mockQuerier)
+	mockQ.On("FindKeyGroups", []string{"AS2 Password"}).Return(map[string][]string{"AS2 Password": {"Money"}}, nil)
+	sensitive := secrets.NewSensitiveKeys(sensitiveKeys{"AS2 Password"}, time.Minute)
+	reply := CompareReply{PartnerCodes: []string{"KOH", "MAC"}, Keys: []service.KeyComparison{{Key: "AS2 Password", Status: "different", Values: map[string]string{"KOH": "hunter2", "MAC": "hunter3"}}}}
+
+	res, err := AuthorizationMiddleware(testPolicy, mockQ, sensitive)(replyEndpoint(reply))(rolesContext("reader"), CompareRequest{PartnerCodes: []string{"KOH", "MAC"}})
+
+	a.Nil(err)
+	a.Equal(map[string]string{"KOH": secrets.Redacted, "MAC": secrets.Redacted}, res.(CompareReply).Keys[0].Values)
+	a.Equal("hunter2", reply.Keys[0].Values["KOH"])
+}
+
+func TestAuthorizationMiddlewareAccessLogDenied(t *testing.T) {
+	a := assert.New(t)
+	mockQ := new(mockQuerier)
+
+	_, err := AuthorizationMiddleware(testPolicy, mockQ, noSensitiveKeys)(callerEndpoint)(rolesContext("admin"), AccessLogRequest{PartnerCode: "KOH"})
+
+	a.True(IsPermissionDenied(err))
+}
+
+func TestAuthorizationMiddlewareAccessLogAuditor(t *testing.T) {
+	a := assert.New(t)
+	mockQ := new(mockQuerier)
+	policy := authz.Policy{Roles: map[string]authz.Grant{"auditor": {Read: []string{authz.AllGroups}, Audit: true}}}
+
+	res, err := AuthorizationMiddleware(policy, mockQ, noSensitiveKeys)(callerEndpoint)(rolesContext("auditor"), AccessLogRequest{PartnerCode: "KOH"})
+
+	a.Nil(err)
+	a.Equal("jdoe", res)
+}
+
+func TestAuthorizationMiddlewarePolicyRoles(t *testing.T) {
+	a := assert.New(t)
+	mockQ := new(mockQuerier)
+
+	//a caller whose token gave them no roles is served with the default ones
+	res, err := AuthorizationMiddleware(testPolicy, mockQ, noSensitiveKeys)(rolesEndpoint)(rolesContext(), PreviewRequest{})
+	a.Nil(err)
+	a.Equal([]string{"reader"}, res)
+}
+
+func TestAuthorizationMiddlewareAPIKeysAdminOnly(t *testing.T) {
+	a := assert.New(t)
+	mockQ := new(mockQuerier)
+
+	_, err := AuthorizationMiddleware(testPolicy, mockQ, noSensitiveKeys)(callerEndpoint)(rolesContext("edi"), IssueAPIKeyRequest{Owner: "onboarding-pipeline", Scope: []string{"edi"}})
+	a.True(IsPermissionDenied(err))
+
+	res, err := AuthorizationMiddleware(testPolicy, mockQ, noSensitiveKeys)(callerEndpoint)(rolesContext("admin"), APIKeyRequest{Id: 7})
+	a.Nil(err)
+	a.Equal("jdoe", res)
+}
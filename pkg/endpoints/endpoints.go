@@ -2,34 +2,375 @@ package endpoints
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-kit/kit/endpoint"
 	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/secrets"
 	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/service"
+	"google.golang.org/grpc/codes"
 )
 
-func New(svc service.PartnerService, logger log.Logger) Endpoints {
+//New makes the endpoints for the service. Every endpoint is behind the auth middleware, which names the caller, the
+//limiter, which holds each caller to a rate for each method, and then the authorize middleware, which checks what they
+//may do. The access log middleware records the sensitive values
+//returned by the endpoints whose replies hold attributes. Writes sent with an idempotency key are served once
+//within the window, with their replies kept in the idempotency store. Sensitive values are redacted from the logs.
+//Every call is counted in requests, timed in duration and traced.
+func New(svc service.PartnerService, logger log.Logger, sensitive *secrets.SensitiveKeys, auth, authorize, accessLog endpoint.Middleware, limiter RateLimiter, idempotency IdempotencyStore, idempotencyWindow time.Duration, requests metrics.Counter, duration metrics.Histogram) Endpoints {
 	var keyValueEndpoint endpoint.Endpoint
 	{
 		keyValueEndpoint = MakeKeyValueEndpoint(svc)
-		keyValueEndpoint = LoggingMiddleware(log.With(logger, "method", "Get data by Key/Value"))(keyValueEndpoint)
+		keyValueEndpoint = authorize(keyValueEndpoint)
+		keyValueEndpoint = accessLog(keyValueEndpoint)
+		keyValueEndpoint = RateLimitMiddleware(limiter, "GetPartnerDataByKeyValue")(keyValueEndpoint)
+		keyValueEndpoint = auth(keyValueEndpoint)
+		keyValueEndpoint = AddrRateLimitMiddleware(limiter)(keyValueEndpoint)
+		keyValueEndpoint = LoggingMiddleware(log.With(logger, "method", "Get data by Key/Value"), sensitive)(keyValueEndpoint)
+		keyValueEndpoint = TracingMiddleware(sensitive, "GetPartnerDataByKeyValue")(keyValueEndpoint)
+		keyValueEndpoint = InstrumentingMiddleware(requests, duration, "GetPartnerDataByKeyValue")(keyValueEndpoint)
 	}
 
 	var getDataByIdEndpoint endpoint.Endpoint
 	{
 		getDataByIdEndpoint = MakeGetDataByIdEndpoint(svc)
-		getDataByIdEndpoint = LoggingMiddleware(log.With(logger, "method", "Get Data By Id"))(getDataByIdEndpoint)
+		getDataByIdEndpoint = authorize(getDataByIdEndpoint)
+		getDataByIdEndpoint = accessLog(getDataByIdEndpoint)
+		getDataByIdEndpoint = RateLimitMiddleware(limiter, "GetDataById")(getDataByIdEndpoint)
+		getDataByIdEndpoint = auth(getDataByIdEndpoint)
+		getDataByIdEndpoint = AddrRateLimitMiddleware(limiter)(getDataByIdEndpoint)
+		getDataByIdEndpoint = LoggingMiddleware(log.With(logger, "method", "Get Data By Id"), sensitive)(getDataByIdEndpoint)
+		getDataByIdEndpoint = TracingMiddleware(sensitive, "GetDataById")(getDataByIdEndpoint)
+		getDataByIdEndpoint = InstrumentingMiddleware(requests, duration, "GetDataById")(getDataByIdEndpoint)
+	}
+
+	var exportPartnersEndpoint endpoint.Endpoint
+	{
+		exportPartnersEndpoint = MakeExportPartnersEndpoint(svc)
+		exportPartnersEndpoint = authorize(exportPartnersEndpoint)
+		exportPartnersEndpoint = accessLog(exportPartnersEndpoint)
+		exportPartnersEndpoint = RateLimitMiddleware(limiter, "ExportPartners")(exportPartnersEndpoint)
+		exportPartnersEndpoint = auth(exportPartnersEndpoint)
+		exportPartnersEndpoint = AddrRateLimitMiddleware(limiter)(exportPartnersEndpoint)
+		exportPartnersEndpoint = LoggingMiddleware(log.With(logger, "method", "Export Partners"), sensitive)(exportPartnersEndpoint)
+		exportPartnersEndpoint = TracingMiddleware(sensitive, "ExportPartners")(exportPartnersEndpoint)
+		exportPartnersEndpoint = InstrumentingMiddleware(requests, duration, "ExportPartners")(exportPartnersEndpoint)
+	}
+
+	var comparePartnersEndpoint endpoint.Endpoint
+	{
+		comparePartnersEndpoint = MakeComparePartnersEndpoint(svc)
+		comparePartnersEndpoint = authorize(comparePartnersEndpoint)
+		comparePartnersEndpoint = accessLog(comparePartnersEndpoint)
+		comparePartnersEndpoint = RateLimitMiddleware(limiter, "ComparePartners")(comparePartnersEndpoint)
+		comparePartnersEndpoint = auth(comparePartnersEndpoint)
+		comparePartnersEndpoint = AddrRateLimitMiddleware(limiter)(comparePartnersEndpoint)
+		comparePartnersEndpoint = LoggingMiddleware(log.With(logger, "method", "Compare Partners"), sensitive)(comparePartnersEndpoint)
+		comparePartnersEndpoint = TracingMiddleware(sensitive, "ComparePartners")(comparePartnersEndpoint)
+		comparePartnersEndpoint = InstrumentingMiddleware(requests, duration, "ComparePartners")(comparePartnersEndpoint)
+	}
+
+	var clonePartnerEndpoint endpoint.Endpoint
+	{
+		clonePartnerEndpoint = MakeClonePartnerEndpoint(svc)
+		clonePartnerEndpoint = IdempotencyMiddleware(idempotency, idempotencyWindow, "ClonePartner", PartnerDataReply{})(clonePartnerEndpoint)
+		clonePartnerEndpoint = authorize(clonePartnerEndpoint)
+		clonePartnerEndpoint = accessLog(clonePartnerEndpoint)
+		clonePartnerEndpoint = RateLimitMiddleware(limiter, "ClonePartner")(clonePartnerEndpoint)
+		clonePartnerEndpoint = auth(clonePartnerEndpoint)
+		clonePartnerEndpoint = AddrRateLimitMiddleware(limiter)(clonePartnerEndpoint)
+		clonePartnerEndpoint = LoggingMiddleware(log.With(logger, "method", "Clone Partner"), sensitive)(clonePartnerEndpoint)
+		clonePartnerEndpoint = TracingMiddleware(sensitive, "ClonePartner")(clonePartnerEndpoint)
+		clonePartnerEndpoint = InstrumentingMiddleware(requests, duration, "ClonePartner")(clonePartnerEndpoint)
+	}
+
+	var setPartnerStatusEndpoint endpoint.Endpoint
+	{
+		setPartnerStatusEndpoint = MakeSetPartnerStatusEndpoint(svc)
+		setPartnerStatusEndpoint = IdempotencyMiddleware(idempotency, idempotencyWindow, "SetPartnerStatus", StatusReply{})(setPartnerStatusEndpoint)
+		setPartnerStatusEndpoint = authorize(setPartnerStatusEndpoint)
+		setPartnerStatusEndpoint = RateLimitMiddleware(limiter, "SetPartnerStatus")(setPartnerStatusEndpoint)
+		setPartnerStatusEndpoint = auth(setPartnerStatusEndpoint)
+		setPartnerStatusEndpoint = AddrRateLimitMiddleware(limiter)(setPartnerStatusEndpoint)
+		setPartnerStatusEndpoint = LoggingMiddleware(log.With(logger, "method", "Set Partner Status"), sensitive)(setPartnerStatusEndpoint)
+		setPartnerStatusEndpoint = TracingMiddleware(sensitive, "SetPartnerStatus")(setPartnerStatusEndpoint)
+		setPartnerStatusEndpoint = InstrumentingMiddleware(requests, duration, "SetPartnerStatus")(setPartnerStatusEndpoint)
+	}
+
+	var restorePartnerEndpoint endpoint.Endpoint
+	{
+		restorePartnerEndpoint = MakeRestorePartnerEndpoint(svc)
+		restorePartnerEndpoint = IdempotencyMiddleware(idempotency, idempotencyWindow, "RestorePartner", RestoreReply{})(restorePartnerEndpoint)
+		restorePartnerEndpoint = authorize(restorePartnerEndpoint)
+		restorePartnerEndpoint = RateLimitMiddleware(limiter, "RestorePartner")(restorePartnerEndpoint)
+		restorePartnerEndpoint = auth(restorePartnerEndpoint)
+		restorePartnerEndpoint = AddrRateLimitMiddleware(limiter)(restorePartnerEndpoint)
+		restorePartnerEndpoint = LoggingMiddleware(log.With(logger, "method", "Restore Partner"), sensitive)(restorePartnerEndpoint)
+		restorePartnerEndpoint = TracingMiddleware(sensitive, "RestorePartner")(restorePartnerEndpoint)
+		restorePartnerEndpoint = InstrumentingMiddleware(requests, duration, "RestorePartner")(restorePartnerEndpoint)
+	}
+
+	var restoreKeyEndpoint endpoint.Endpoint
+	{
+		restoreKeyEndpoint = MakeRestoreKeyEndpoint(svc)
+		restoreKeyEndpoint = IdempotencyMiddleware(idempotency, idempotencyWindow, "RestoreKey", RestoreReply{})(restoreKeyEndpoint)
+		restoreKeyEndpoint = authorize(restoreKeyEndpoint)
+		restoreKeyEndpoint = RateLimitMiddleware(limiter, "RestoreKey")(restoreKeyEndpoint)
+		restoreKeyEndpoint = auth(restoreKeyEndpoint)
+		restoreKeyEndpoint = AddrRateLimitMiddleware(limiter)(restoreKeyEndpoint)
+		restoreKeyEndpoint = LoggingMiddleware(log.With(logger, "method", "Restore Key"), sensitive)(restoreKeyEndpoint)
+		restoreKeyEndpoint = TracingMiddleware(sensitive, "RestoreKey")(restoreKeyEndpoint)
+		restoreKeyEndpoint = InstrumentingMiddleware(requests, duration, "RestoreKey")(restoreKeyEndpoint)
+	}
+
+	var restoreGroupEndpoint endpoint.Endpoint
+	{
+		restoreGroupEndpoint = MakeRestoreGroupEndpoint(svc)
+		restoreGroupEndpoint = IdempotencyMiddleware(idempotency, idempotencyWindow, "RestoreGroup", RestoreReply{})(restoreGroupEndpoint)
+		restoreGroupEndpoint = authorize(restoreGroupEndpoint)
+		restoreGroupEndpoint = RateLimitMiddleware(limiter, "RestoreGroup")(restoreGroupEndpoint)
+		restoreGroupEndpoint = auth(restoreGroupEndpoint)
+		restoreGroupEndpoint = AddrRateLimitMiddleware(limiter)(restoreGroupEndpoint)
+		restoreGroupEndpoint = LoggingMiddleware(log.With(logger, "method", "Restore Group"), sensitive)(restoreGroupEndpoint)
+		restoreGroupEndpoint = TracingMiddleware(sensitive, "RestoreGroup")(restoreGroupEndpoint)
+		restoreGroupEndpoint = InstrumentingMiddleware(requests, duration, "RestoreGroup")(restoreGroupEndpoint)
+	}
+
+	var restorePartnerAttributeEndpoint endpoint.Endpoint
+	{
+		restorePartnerAttributeEndpoint = MakeRestorePartnerAttributeEndpoint(svc)
+		restorePartnerAttributeEndpoint = IdempotencyMiddleware(idempotency, idempotencyWindow, "RestorePartnerAttribute", RestoreReply{})(restorePartnerAttributeEndpoint)
+		restorePartnerAttributeEndpoint = authorize(restorePartnerAttributeEndpoint)
+		restorePartnerAttributeEndpoint = RateLimitMiddleware(limiter, "RestorePartnerAttribute")(restorePartnerAttributeEndpoint)
+		restorePartnerAttributeEndpoint = auth(restorePartnerAttributeEndpoint)
+		restorePartnerAttributeEndpoint = AddrRateLimitMiddleware(limiter)(restorePartnerAttributeEndpoint)
+		restorePartnerAttributeEndpoint = LoggingMiddleware(log.With(logger, "method", "Restore Partner Attribute"), sensitive)(restorePartnerAttributeEndpoint)
+		restorePartnerAttributeEndpoint = TracingMiddleware(sensitive, "RestorePartnerAttribute")(restorePartnerAttributeEndpoint)
+		restorePartnerAttributeEndpoint = InstrumentingMiddleware(requests, duration, "RestorePartnerAttribute")(restorePartnerAttributeEndpoint)
+	}
+
+	var openChangeSetEndpoint endpoint.Endpoint
+	{
+		openChangeSetEndpoint = MakeOpenChangeSetEndpoint(svc)
+		openChangeSetEndpoint = IdempotencyMiddleware(idempotency, idempotencyWindow, "OpenChangeSet", ChangeSetReply{})(openChangeSetEndpoint)
+		openChangeSetEndpoint = authorize(openChangeSetEndpoint)
+		openChangeSetEndpoint = RateLimitMiddleware(limiter, "OpenChangeSet")(openChangeSetEndpoint)
+		openChangeSetEndpoint = auth(openChangeSetEndpoint)
+		openChangeSetEndpoint = AddrRateLimitMiddleware(limiter)(openChangeSetEndpoint)
+		openChangeSetEndpoint = LoggingMiddleware(log.With(logger, "method", "Open Change Set"), sensitive)(openChangeSetEndpoint)
+		openChangeSetEndpoint = TracingMiddleware(sensitive, "OpenChangeSet")(openChangeSetEndpoint)
+		openChangeSetEndpoint = InstrumentingMiddleware(requests, duration, "OpenChangeSet")(openChangeSetEndpoint)
+	}
+
+	var stageChangeEndpoint endpoint.Endpoint
+	{
+		stageChangeEndpoint = MakeStageChangeEndpoint(svc)
+		stageChangeEndpoint = IdempotencyMiddleware(idempotency, idempotencyWindow, "StageChange", ChangeSetReply{})(stageChangeEndpoint)
+		stageChangeEndpoint = authorize(stageChangeEndpoint)
+		stageChangeEndpoint = accessLog(stageChangeEndpoint)
+		stageChangeEndpoint = RateLimitMiddleware(limiter, "StageChange")(stageChangeEndpoint)
+		stageChangeEndpoint = auth(stageChangeEndpoint)
+		stageChangeEndpoint = AddrRateLimitMiddleware(limiter)(stageChangeEndpoint)
+		stageChangeEndpoint = LoggingMiddleware(log.With(logger, "method", "Stage Change"), sensitive)(stageChangeEndpoint)
+		stageChangeEndpoint = TracingMiddleware(sensitive, "StageChange")(stageChangeEndpoint)
+		stageChangeEndpoint = InstrumentingMiddleware(requests, duration, "StageChange")(stageChangeEndpoint)
+	}
+
+	var previewChangeSetEndpoint endpoint.Endpoint
+	{
+		previewChangeSetEndpoint = MakePreviewChangeSetEndpoint(svc)
+		previewChangeSetEndpoint = authorize(previewChangeSetEndpoint)
+		previewChangeSetEndpoint = accessLog(previewChangeSetEndpoint)
+		previewChangeSetEndpoint = RateLimitMiddleware(limiter, "PreviewChangeSet")(previewChangeSetEndpoint)
+		previewChangeSetEndpoint = auth(previewChangeSetEndpoint)
+		previewChangeSetEndpoint = AddrRateLimitMiddleware(limiter)(previewChangeSetEndpoint)
+		previewChangeSetEndpoint = LoggingMiddleware(log.With(logger, "method", "Preview Change Set"), sensitive)(previewChangeSetEndpoint)
+		previewChangeSetEndpoint = TracingMiddleware(sensitive, "PreviewChangeSet")(previewChangeSetEndpoint)
+		previewChangeSetEndpoint = InstrumentingMiddleware(requests, duration, "PreviewChangeSet")(previewChangeSetEndpoint)
+	}
+
+	var publishChangeSetEndpoint endpoint.Endpoint
+	{
+		publishChangeSetEndpoint = MakePublishChangeSetEndpoint(svc)
+		publishChangeSetEndpoint = IdempotencyMiddleware(idempotency, idempotencyWindow, "PublishChangeSet", ChangeSetReply{})(publishChangeSetEndpoint)
+		publishChangeSetEndpoint = authorize(publishChangeSetEndpoint)
+		publishChangeSetEndpoint = accessLog(publishChangeSetEndpoint)
+		publishChangeSetEndpoint = RateLimitMiddleware(limiter, "PublishChangeSet")(publishChangeSetEndpoint)
+		publishChangeSetEndpoint = auth(publishChangeSetEndpoint)
+		publishChangeSetEndpoint = AddrRateLimitMiddleware(limiter)(publishChangeSetEndpoint)
+		publishChangeSetEndpoint = LoggingMiddleware(log.With(logger, "method", "Publish Change Set"), sensitive)(publishChangeSetEndpoint)
+		publishChangeSetEndpoint = TracingMiddleware(sensitive, "PublishChangeSet")(publishChangeSetEndpoint)
+		publishChangeSetEndpoint = InstrumentingMiddleware(requests, duration, "PublishChangeSet")(publishChangeSetEndpoint)
+	}
+
+	var discardChangeSetEndpoint endpoint.Endpoint
+	{
+		discardChangeSetEndpoint = MakeDiscardChangeSetEndpoint(svc)
+		discardChangeSetEndpoint = IdempotencyMiddleware(idempotency, idempotencyWindow, "DiscardChangeSet", ChangeSetReply{})(discardChangeSetEndpoint)
+		discardChangeSetEndpoint = authorize(discardChangeSetEndpoint)
+		discardChangeSetEndpoint = accessLog(discardChangeSetEndpoint)
+		discardChangeSetEndpoint = RateLimitMiddleware(limiter, "DiscardChangeSet")(discardChangeSetEndpoint)
+		discardChangeSetEndpoint = auth(discardChangeSetEndpoint)
+		discardChangeSetEndpoint = AddrRateLimitMiddleware(limiter)(discardChangeSetEndpoint)
+		discardChangeSetEndpoint = LoggingMiddleware(log.With(logger, "method", "Discard Change Set"), sensitive)(discardChangeSetEndpoint)
+		discardChangeSetEndpoint = TracingMiddleware(sensitive, "DiscardChangeSet")(discardChangeSetEndpoint)
+		discardChangeSetEndpoint = InstrumentingMiddleware(requests, duration, "DiscardChangeSet")(discardChangeSetEndpoint)
+	}
+
+	var listApprovalRequestsEndpoint endpoint.Endpoint
+	{
+		listApprovalRequestsEndpoint = MakeListApprovalRequestsEndpoint(svc)
+		listApprovalRequestsEndpoint = authorize(listApprovalRequestsEndpoint)
+		listApprovalRequestsEndpoint = RateLimitMiddleware(limiter, "ListApprovalRequests")(listApprovalRequestsEndpoint)
+		listApprovalRequestsEndpoint = auth(listApprovalRequestsEndpoint)
+		listApprovalRequestsEndpoint = AddrRateLimitMiddleware(limiter)(listApprovalRequestsEndpoint)
+		listApprovalRequestsEndpoint = LoggingMiddleware(log.With(logger, "method", "List Approval Requests"), sensitive)(listApprovalRequestsEndpoint)
+		listApprovalRequestsEndpoint = TracingMiddleware(sensitive, "ListApprovalRequests")(listApprovalRequestsEndpoint)
+		listApprovalRequestsEndpoint = InstrumentingMiddleware(requests, duration, "ListApprovalRequests")(listApprovalRequestsEndpoint)
+	}
+
+	var approveRequestEndpoint endpoint.Endpoint
+	{
+		approveRequestEndpoint = MakeApproveRequestEndpoint(svc)
+		approveRequestEndpoint = IdempotencyMiddleware(idempotency, idempotencyWindow, "ApproveRequest", ApprovalReply{})(approveRequestEndpoint)
+		approveRequestEndpoint = authorize(approveRequestEndpoint)
+		approveRequestEndpoint = RateLimitMiddleware(limiter, "ApproveRequest")(approveRequestEndpoint)
+		approveRequestEndpoint = auth(approveRequestEndpoint)
+		approveRequestEndpoint = AddrRateLimitMiddleware(limiter)(approveRequestEndpoint)
+		approveRequestEndpoint = LoggingMiddleware(log.With(logger, "method", "Approve Request"), sensitive)(approveRequestEndpoint)
+		approveRequestEndpoint = TracingMiddleware(sensitive, "ApproveRequest")(approveRequestEndpoint)
+		approveRequestEndpoint = InstrumentingMiddleware(requests, duration, "ApproveRequest")(approveRequestEndpoint)
+	}
+
+	var rejectRequestEndpoint endpoint.Endpoint
+	{
+		rejectRequestEndpoint = MakeRejectRequestEndpoint(svc)
+		rejectRequestEndpoint = IdempotencyMiddleware(idempotency, idempotencyWindow, "RejectRequest", ApprovalReply{})(rejectRequestEndpoint)
+		rejectRequestEndpoint = authorize(rejectRequestEndpoint)
+		rejectRequestEndpoint = RateLimitMiddleware(limiter, "RejectRequest")(rejectRequestEndpoint)
+		rejectRequestEndpoint = auth(rejectRequestEndpoint)
+		rejectRequestEndpoint = AddrRateLimitMiddleware(limiter)(rejectRequestEndpoint)
+		rejectRequestEndpoint = LoggingMiddleware(log.With(logger, "method", "Reject Request"), sensitive)(rejectRequestEndpoint)
+		rejectRequestEndpoint = TracingMiddleware(sensitive, "RejectRequest")(rejectRequestEndpoint)
+		rejectRequestEndpoint = InstrumentingMiddleware(requests, duration, "RejectRequest")(rejectRequestEndpoint)
+	}
+
+	var listAccessRecordsEndpoint endpoint.Endpoint
+	{
+		listAccessRecordsEndpoint = MakeListAccessRecordsEndpoint(svc)
+		listAccessRecordsEndpoint = authorize(listAccessRecordsEndpoint)
+		listAccessRecordsEndpoint = RateLimitMiddleware(limiter, "ListAccessRecords")(listAccessRecordsEndpoint)
+		listAccessRecordsEndpoint = auth(listAccessRecordsEndpoint)
+		listAccessRecordsEndpoint = AddrRateLimitMiddleware(limiter)(listAccessRecordsEndpoint)
+		listAccessRecordsEndpoint = LoggingMiddleware(log.With(logger, "method", "List Access Records"), sensitive)(listAccessRecordsEndpoint)
+		listAccessRecordsEndpoint = TracingMiddleware(sensitive, "ListAccessRecords")(listAccessRecordsEndpoint)
+		listAccessRecordsEndpoint = InstrumentingMiddleware(requests, duration, "ListAccessRecords")(listAccessRecordsEndpoint)
+	}
+
+	var issueAPIKeyEndpoint endpoint.Endpoint
+	{
+		issueAPIKeyEndpoint = MakeIssueAPIKeyEndpoint(svc)
+		issueAPIKeyEndpoint = IdempotencyMiddleware(idempotency, idempotencyWindow, "IssueApiKey", APIKeyReply{})(issueAPIKeyEndpoint)
+		issueAPIKeyEndpoint = authorize(issueAPIKeyEndpoint)
+		issueAPIKeyEndpoint = RateLimitMiddleware(limiter, "IssueApiKey")(issueAPIKeyEndpoint)
+		issueAPIKeyEndpoint = auth(issueAPIKeyEndpoint)
+		issueAPIKeyEndpoint = AddrRateLimitMiddleware(limiter)(issueAPIKeyEndpoint)
+		issueAPIKeyEndpoint = LoggingMiddleware(log.With(logger, "method", "Issue API Key"), sensitive)(issueAPIKeyEndpoint)
+		issueAPIKeyEndpoint = TracingMiddleware(sensitive, "IssueApiKey")(issueAPIKeyEndpoint)
+		issueAPIKeyEndpoint = InstrumentingMiddleware(requests, duration, "IssueApiKey")(issueAPIKeyEndpoint)
+	}
+
+	var listAPIKeysEndpoint endpoint.Endpoint
+	{
+		listAPIKeysEndpoint = MakeListAPIKeysEndpoint(svc)
+		listAPIKeysEndpoint = authorize(listAPIKeysEndpoint)
+		listAPIKeysEndpoint = RateLimitMiddleware(limiter, "ListApiKeys")(listAPIKeysEndpoint)
+		listAPIKeysEndpoint = auth(listAPIKeysEndpoint)
+		listAPIKeysEndpoint = AddrRateLimitMiddleware(limiter)(listAPIKeysEndpoint)
+		listAPIKeysEndpoint = LoggingMiddleware(log.With(logger, "method", "List API Keys"), sensitive)(listAPIKeysEndpoint)
+		listAPIKeysEndpoint = TracingMiddleware(sensitive, "ListApiKeys")(listAPIKeysEndpoint)
+		listAPIKeysEndpoint = InstrumentingMiddleware(requests, duration, "ListApiKeys")(listAPIKeysEndpoint)
+	}
+
+	var rotateAPIKeyEndpoint endpoint.Endpoint
+	{
+		rotateAPIKeyEndpoint = MakeRotateAPIKeyEndpoint(svc)
+		rotateAPIKeyEndpoint = IdempotencyMiddleware(idempotency, idempotencyWindow, "RotateApiKey", APIKeyReply{})(rotateAPIKeyEndpoint)
+		rotateAPIKeyEndpoint = authorize(rotateAPIKeyEndpoint)
+		rotateAPIKeyEndpoint = RateLimitMiddleware(limiter, "RotateApiKey")(rotateAPIKeyEndpoint)
+		rotateAPIKeyEndpoint = auth(rotateAPIKeyEndpoint)
+		rotateAPIKeyEndpoint = AddrRateLimitMiddleware(limiter)(rotateAPIKeyEndpoint)
+		rotateAPIKeyEndpoint = LoggingMiddleware(log.With(logger, "method", "Rotate API Key"), sensitive)(rotateAPIKeyEndpoint)
+		rotateAPIKeyEndpoint = TracingMiddleware(sensitive, "RotateApiKey")(rotateAPIKeyEndpoint)
+		rotateAPIKeyEndpoint = InstrumentingMiddleware(requests, duration, "RotateApiKey")(rotateAPIKeyEndpoint)
+	}
+
+	var revokeAPIKeyEndpoint endpoint.Endpoint
+	{
+		revokeAPIKeyEndpoint = MakeRevokeAPIKeyEndpoint(svc)
+		revokeAPIKeyEndpoint = IdempotencyMiddleware(idempotency, idempotencyWindow, "RevokeApiKey", APIKeyReply{})(revokeAPIKeyEndpoint)
+		revokeAPIKeyEndpoint = authorize(revokeAPIKeyEndpoint)
+		revokeAPIKeyEndpoint = RateLimitMiddleware(limiter, "RevokeApiKey")(revokeAPIKeyEndpoint)
+		revokeAPIKeyEndpoint = auth(revokeAPIKeyEndpoint)
+		revokeAPIKeyEndpoint = AddrRateLimitMiddleware(limiter)(revokeAPIKeyEndpoint)
+		revokeAPIKeyEndpoint = LoggingMiddleware(log.With(logger, "method", "Revoke API Key"), sensitive)(revokeAPIKeyEndpoint)
+		revokeAPIKeyEndpoint = TracingMiddleware(sensitive, "RevokeApiKey")(revokeAPIKeyEndpoint)
+		revokeAPIKeyEndpoint = InstrumentingMiddleware(requests, duration, "RevokeApiKey")(revokeAPIKeyEndpoint)
 	}
 
 	return Endpoints{
-		KeyValueEndpoint:    keyValueEndpoint,
-		GetDataByIdEndpoint: getDataByIdEndpoint,
+		KeyValueEndpoint:                keyValueEndpoint,
+		GetDataByIdEndpoint:             getDataByIdEndpoint,
+		ExportPartnersEndpoint:          exportPartnersEndpoint,
+		ComparePartnersEndpoint:         comparePartnersEndpoint,
+		ClonePartnerEndpoint:            clonePartnerEndpoint,
+		SetPartnerStatusEndpoint:        setPartnerStatusEndpoint,
+		RestorePartnerEndpoint:          restorePartnerEndpoint,
+		RestoreKeyEndpoint:              restoreKeyEndpoint,
+		RestoreGroupEndpoint:            restoreGroupEndpoint,
+		RestorePartnerAttributeEndpoint: restorePartnerAttributeEndpoint,
+		OpenChangeSetEndpoint:           openChangeSetEndpoint,
+		StageChangeEndpoint:             stageChangeEndpoint,
+		PreviewChangeSetEndpoint:        previewChangeSetEndpoint,
+		PublishChangeSetEndpoint:        publishChangeSetEndpoint,
+		DiscardChangeSetEndpoint:        discardChangeSetEndpoint,
+		ListApprovalRequestsEndpoint:    listApprovalRequestsEndpoint,
+		ApproveRequestEndpoint:          approveRequestEndpoint,
+		RejectRequestEndpoint:           rejectRequestEndpoint,
+		ListAccessRecordsEndpoint:       listAccessRecordsEndpoint,
+		IssueAPIKeyEndpoint:             issueAPIKeyEndpoint,
+		ListAPIKeysEndpoint:             listAPIKeysEndpoint,
+		RotateAPIKeyEndpoint:            rotateAPIKeyEndpoint,
+		RevokeAPIKeyEndpoint:            revokeAPIKeyEndpoint,
 	}
 }
 
 type Endpoints struct {
-	KeyValueEndpoint    endpoint.Endpoint
-	GetDataByIdEndpoint endpoint.Endpoint
+	KeyValueEndpoint                endpoint.Endpoint
+	GetDataByIdEndpoint             endpoint.Endpoint
+	ExportPartnersEndpoint          endpoint.Endpoint
+	ComparePartnersEndpoint         endpoint.Endpoint
+	ClonePartnerEndpoint            endpoint.Endpoint
+	SetPartnerStatusEndpoint        endpoint.Endpoint
+	RestorePartnerEndpoint          endpoint.Endpoint
+	RestoreKeyEndpoint              endpoint.Endpoint
+	RestoreGroupEndpoint            endpoint.Endpoint
+	RestorePartnerAttributeEndpoint endpoint.Endpoint
+	OpenChangeSetEndpoint           endpoint.Endpoint
+	StageChangeEndpoint             endpoint.Endpoint
+	PreviewChangeSetEndpoint        endpoint.Endpoint
+	PublishChangeSetEndpoint        endpoint.Endpoint
+	DiscardChangeSetEndpoint        endpoint.Endpoint
+	ListApprovalRequestsEndpoint    endpoint.Endpoint
+	ApproveRequestEndpoint          endpoint.Endpoint
+	RejectRequestEndpoint           endpoint.Endpoint
+	ListAccessRecordsEndpoint       endpoint.Endpoint
+	IssueAPIKeyEndpoint             endpoint.Endpoint
+	ListAPIKeysEndpoint             endpoint.Endpoint
+	RotateAPIKeyEndpoint            endpoint.Endpoint
+	RevokeAPIKeyEndpoint            endpoint.Endpoint
 }
 
 //MakeKeyValueEndpoint returns an endpoint that invokes GetPartnerDataByKeyValue on the service.
@@ -38,12 +379,16 @@ type Endpoints struct {
 func MakeKeyValueEndpoint(service service.PartnerService) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
 		keyValueReq := request.(KeyValueRequest)
-		partnerIdReply, partnerCodeReply, attributes, err := service.GetPartnerDataByKeyValue(ctx, keyValueReq.Key, keyValueReq.Value, keyValueReq.Group)
+		partnerIdReply, partnerCodeReply, attributes, revision, err := service.GetPartnerDataByKeyValue(ctx, keyValueReq.Key, keyValueReq.Value, keyValueReq.Group, keyValueReq.IncludeInactive)
+		if isUnavailable(err) {
+			return nil, err
+		}
 
 		return PartnerDataReply{
 			PartnerId:   partnerIdReply,
 			PartnerCode: partnerCodeReply,
 			Attributes:  attributes,
+			Revision:    revision,
 			Error:       err2str(err),
 		}, nil
 	}
@@ -53,17 +398,344 @@ func MakeKeyValueEndpoint(service service.PartnerService) endpoint.Endpoint {
 func MakeGetDataByIdEndpoint(service service.PartnerService) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
 		getDataByIdReq := request.(IdRequest)
-		partnerIdReply, partnerCodeReply, attributes, err := service.GetDataById(ctx, getDataByIdReq.PartnerId, getDataByIdReq.PartnerCode, getDataByIdReq.Group)
+		partnerIdReply, partnerCodeReply, attributes, revision, err := service.GetDataById(ctx, getDataByIdReq.PartnerId, getDataByIdReq.PartnerCode, getDataByIdReq.Group, getDataByIdReq.IncludeInactive)
+		if isUnavailable(err) {
+			return nil, err
+		}
 
 		return PartnerDataReply{
 			PartnerId:   partnerIdReply,
 			PartnerCode: partnerCodeReply,
 			Attributes:  attributes,
+			Revision:    revision,
+			Error:       err2str(err),
+		}, nil
+	}
+}
+
+//MakeExportPartnersEndpoint returns an endpoint that invokes ExportPartners on the service.
+func MakeExportPartnersEndpoint(service service.PartnerService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		exportReq := request.(ExportRequest)
+		partners, err := service.ExportPartners(ctx, exportReq.Group, exportReq.PartnerCodes)
+		if isUnavailable(err) {
+			return nil, err
+		}
+
+		return ExportReply{
+			Partners: partners,
+			Error:    err2str(err),
+		}, nil
+	}
+}
+
+//MakeComparePartnersEndpoint returns an endpoint that invokes ComparePartners on the service.
+func MakeComparePartnersEndpoint(service service.PartnerService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		compareReq := request.(CompareRequest)
+		keys, err := service.ComparePartners(ctx, compareReq.PartnerCodes, compareReq.Group)
+		if isUnavailable(err) {
+			return nil, err
+		}
+
+		return CompareReply{
+			PartnerCodes: compareReq.PartnerCodes,
+			Keys:         keys,
+			Error:        err2str(err),
+		}, nil
+	}
+}
+
+//MakeClonePartnerEndpoint returns an endpoint that invokes ClonePartner on the service.
+func MakeClonePartnerEndpoint(service service.PartnerService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		cloneReq := request.(CloneRequest)
+		partnerIdReply, partnerCodeReply, attributes, err := service.ClonePartner(ctx, cloneReq.Name, cloneReq.Code, cloneReq.SourceCode, cloneReq.Template, cloneReq.Groups, cloneReq.Overrides)
+		if isUnavailable(err) {
+			return nil, err
+		}
+
+		return PartnerDataReply{
+			PartnerId:   partnerIdReply,
+			PartnerCode: partnerCodeReply,
+			Attributes:  attributes,
+			Error:       err2str(err),
+		}, nil
+	}
+}
+
+//MakeSetPartnerStatusEndpoint returns an endpoint that invokes SetPartnerStatus on the service.
+// A revision conflict is returned as the endpoint's error rather than in the reply, so the transport can answer it
+// with a conflict status. The other endpoints that write to a partner do the same.
+func MakeSetPartnerStatusEndpoint(service service.PartnerService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		statusReq := request.(StatusRequest)
+		partnerIdReply, changedAt, err := service.SetPartnerStatus(ctx, statusReq.PartnerCode, statusReq.Status, statusReq.ExpectedRevision)
+		if isConflict(err) || isUnavailable(err) {
+			return nil, err
+		}
+
+		return StatusReply{
+			PartnerId:       partnerIdReply,
+			PartnerCode:     statusReq.PartnerCode,
+			Status:          statusReq.Status,
+			StatusChangedAt: changedAt,
+			Error:           err2str(err),
+		}, nil
+	}
+}
+
+//MakeRestorePartnerEndpoint returns an endpoint that invokes RestorePartner on the service.
+func MakeRestorePartnerEndpoint(service service.PartnerService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		restoreReq := request.(RestoreRequest)
+		restored, err := service.RestorePartner(ctx, restoreReq.PartnerCode)
+		if isUnavailable(err) {
+			return nil, err
+		}
+		return RestoreReply{Restored: restored, Error: err2str(err)}, nil
+	}
+}
+
+//MakeRestoreKeyEndpoint returns an endpoint that invokes RestoreKey on the service.
+func MakeRestoreKeyEndpoint(service service.PartnerService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		restoreReq := request.(RestoreRequest)
+		restored, err := service.RestoreKey(ctx, restoreReq.Key)
+		if isUnavailable(err) {
+			return nil, err
+		}
+		return RestoreReply{Restored: restored, Error: err2str(err)}, nil
+	}
+}
+
+//MakeRestoreGroupEndpoint returns an endpoint that invokes RestoreGroup on the service.
+func MakeRestoreGroupEndpoint(service service.PartnerService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		restoreReq := request.(RestoreRequest)
+		restored, err := service.RestoreGroup(ctx, restoreReq.Group)
+		if isUnavailable(err) {
+			return nil, err
+		}
+		return RestoreReply{Restored: restored, Error: err2str(err)}, nil
+	}
+}
+
+//MakeRestorePartnerAttributeEndpoint returns an endpoint that invokes RestorePartnerAttribute on the service.
+func MakeRestorePartnerAttributeEndpoint(service service.PartnerService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		restoreReq := request.(RestoreRequest)
+		restored, err := service.RestorePartnerAttribute(ctx, restoreReq.PartnerCode, restoreReq.Key, restoreReq.ExpectedRevision)
+		if isConflict(err) || isUnavailable(err) {
+			return nil, err
+		}
+		return RestoreReply{Restored: restored, Error: err2str(err)}, nil
+	}
+}
+
+//MakeOpenChangeSetEndpoint returns an endpoint that invokes OpenChangeSet on the service.
+func MakeOpenChangeSetEndpoint(service service.PartnerService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		openReq := request.(OpenChangeSetRequest)
+		changeSet, err := service.OpenChangeSet(ctx, openReq.Name)
+		if isUnavailable(err) {
+			return nil, err
+		}
+		return ChangeSetReply{ChangeSet: changeSet, Error: err2str(err)}, nil
+	}
+}
+
+//MakeStageChangeEndpoint returns an endpoint that invokes StageChange on the service.
+func MakeStageChangeEndpoint(service service.PartnerService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		stageReq := request.(StageChangeRequest)
+		changeSet, err := service.StageChange(ctx, stageReq.ChangeSetId, stageReq.PartnerCode, stageReq.PartnerName, stageReq.Attributes, stageReq.ExpectedRevision)
+		if isUnavailable(err) {
+			return nil, err
+		}
+		return ChangeSetReply{ChangeSet: changeSet, Error: err2str(err)}, nil
+	}
+}
+
+//MakePreviewChangeSetEndpoint returns an endpoint that invokes PreviewChangeSet on the service.
+func MakePreviewChangeSetEndpoint(service service.PartnerService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		previewReq := request.(PreviewRequest)
+		partnerIdReply, partnerCodeReply, attributesReply, err := service.PreviewChangeSet(ctx, previewReq.ChangeSetId, previewReq.PartnerCode)
+		if isUnavailable(err) {
+			return nil, err
+		}
+
+		return PartnerDataReply{
+			PartnerId:   partnerIdReply,
+			PartnerCode: partnerCodeReply,
+			Attributes:  attributesReply,
 			Error:       err2str(err),
 		}, nil
 	}
 }
 
+//MakePublishChangeSetEndpoint returns an endpoint that invokes PublishChangeSet on the service.
+func MakePublishChangeSetEndpoint(service service.PartnerService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		changeSetReq := request.(ChangeSetRequest)
+		changeSet, err := service.PublishChangeSet(ctx, changeSetReq.ChangeSetId)
+		if isConflict(err) || isUnavailable(err) {
+			return nil, err
+		}
+		return ChangeSetReply{ChangeSet: changeSet, Error: err2str(err)}, nil
+	}
+}
+
+//MakeDiscardChangeSetEndpoint returns an endpoint that invokes DiscardChangeSet on the service.
+func MakeDiscardChangeSetEndpoint(service service.PartnerService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		changeSetReq := request.(ChangeSetRequest)
+		changeSet, err := service.DiscardChangeSet(ctx, changeSetReq.ChangeSetId)
+		if isUnavailable(err) {
+			return nil, err
+		}
+		return ChangeSetReply{ChangeSet: changeSet, Error: err2str(err)}, nil
+	}
+}
+
+//MakeListApprovalRequestsEndpoint returns an endpoint that invokes ListApprovalRequests on the service.
+func MakeListApprovalRequestsEndpoint(service service.PartnerService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		listReq := request.(ListApprovalRequestsRequest)
+		requests, err := service.ListApprovalRequests(ctx, listReq.Status)
+		if isUnavailable(err) {
+			return nil, err
+		}
+		return ApprovalRequestsReply{Requests: requests, Error: err2str(err)}, nil
+	}
+}
+
+//MakeApproveRequestEndpoint returns an endpoint that invokes ApproveRequest on the service.
+func MakeApproveRequestEndpoint(service service.PartnerService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		decisionReq := request.(ApprovalDecisionRequest)
+		approvalRequest, err := service.ApproveRequest(ctx, decisionReq.RequestId)
+		if isConflict(err) || isUnavailable(err) {
+			return nil, err
+		}
+		return ApprovalReply{Request: approvalRequest, Error: err2str(err)}, nil
+	}
+}
+
+//MakeRejectRequestEndpoint returns an endpoint that invokes RejectRequest on the service.
+func MakeRejectRequestEndpoint(service service.PartnerService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		decisionReq := request.(ApprovalDecisionRequest)
+		approvalRequest, err := service.RejectRequest(ctx, decisionReq.RequestId, decisionReq.Reason)
+		if isUnavailable(err) {
+			return nil, err
+		}
+		return ApprovalReply{Request: approvalRequest, Error: err2str(err)}, nil
+	}
+}
+
+//MakeListAccessRecordsEndpoint returns an endpoint that invokes ListAccessRecords on the service.
+func MakeListAccessRecordsEndpoint(service service.PartnerService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		accessReq := request.(AccessLogRequest)
+		records, err := service.ListAccessRecords(ctx, accessReq.PartnerCode, accessReq.From, accessReq.To)
+		if isUnavailable(err) {
+			return nil, err
+		}
+		return AccessLogReply{Records: records, Error: err2str(err)}, nil
+	}
+}
+
+//MakeIssueAPIKeyEndpoint returns an endpoint that invokes IssueAPIKey on the service.
+func MakeIssueAPIKeyEndpoint(service service.PartnerService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		issueReq := request.(IssueAPIKeyRequest)
+		apiKey, key, err := service.IssueAPIKey(ctx, issueReq.Owner, issueReq.Scope, issueReq.ExpiresAt)
+		if isUnavailable(err) {
+			return nil, err
+		}
+		return APIKeyReply{APIKey: apiKey, Key: key, Error: err2str(err)}, nil
+	}
+}
+
+//MakeListAPIKeysEndpoint returns an endpoint that invokes ListAPIKeys on the service.
+func MakeListAPIKeysEndpoint(service service.PartnerService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		listReq := request.(ListAPIKeysRequest)
+		apiKeys, err := service.ListAPIKeys(ctx, listReq.Owner)
+		if isUnavailable(err) {
+			return nil, err
+		}
+		return APIKeysReply{APIKeys: apiKeys, Error: err2str(err)}, nil
+	}
+}
+
+//MakeRotateAPIKeyEndpoint returns an endpoint that invokes RotateAPIKey on the service.
+func MakeRotateAPIKeyEndpoint(service service.PartnerService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		keyReq := request.(APIKeyRequest)
+		apiKey, key, err := service.RotateAPIKey(ctx, keyReq.Id)
+		if isUnavailable(err) {
+			return nil, err
+		}
+		return APIKeyReply{APIKey: apiKey, Key: key, Error: err2str(err)}, nil
+	}
+}
+
+//MakeRevokeAPIKeyEndpoint returns an endpoint that invokes RevokeAPIKey on the service.
+func MakeRevokeAPIKeyEndpoint(service service.PartnerService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		keyReq := request.(APIKeyRequest)
+		apiKey, err := service.RevokeAPIKey(ctx, keyReq.Id)
+		if isUnavailable(err) {
+			return nil, err
+		}
+		return APIKeyReply{APIKey: apiKey, Error: err2str(err)}, nil
+	}
+}
+
+// isConflict is service.IsConflict, which the endpoint makers cannot reach past their service parameter.
+func isConflict(err error) bool {
+	return service.IsConflict(err)
+}
+
+// isUnavailable is service.IsUnavailable, for the same reason. Calls that could not reach the database fail instead of
+// replying with the error, so that callers see they can be retried.
+func isUnavailable(err error) bool {
+	return service.IsUnavailable(err)
+}
+
+// StatusCode returns the status code for an error from an endpoint, for the errors the caller can act on. A revision
+// conflict or an idempotency key still in use is Aborted, an idempotency key sent with another request is
+// InvalidArgument, a caller over its rate limit is ResourceExhausted, a caller without credentials is Unauthenticated,
+// a caller whose roles do not allow the call is PermissionDenied and a call that could not reach the database is
+// Unavailable. Any other error is Unknown, and no error is OK.
+func StatusCode(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	if _, ok := RetryAfter(err); ok {
+		return codes.ResourceExhausted
+	}
+	if IsUnauthenticated(err) {
+		return codes.Unauthenticated
+	}
+	if IsPermissionDenied(err) {
+		return codes.PermissionDenied
+	}
+	if isConflict(err) || err == ErrIdempotencyKeyInProgress {
+		return codes.Aborted
+	}
+	if err == ErrIdempotencyKeyReused {
+		return codes.InvalidArgument
+	}
+	if isUnavailable(err) {
+		return codes.Unavailable
+	}
+	return codes.Unknown
+}
+
 func err2str(err error) string {
 	if err == nil {
 		return ""
@@ -72,20 +744,193 @@ func err2str(err error) string {
 }
 
 type KeyValueRequest struct {
-	Key   string
-	Value string
-	Group string
+	Key             string
+	Value           string
+	Group           string
+	IncludeInactive bool
 }
 
 type IdRequest struct {
-	PartnerId   int32
-	PartnerCode string
-	Group       string
+	PartnerId       int32
+	PartnerCode     string
+	Group           string
+	IncludeInactive bool
 }
 
 type PartnerDataReply struct {
 	PartnerId   int32
 	PartnerCode string
 	Attributes  map[string]string
+	Revision    int32
 	Error       string
 }
+
+//failed reports whether the reply carries an error, so that it is not kept for an idempotency key.
+func (r PartnerDataReply) failed() bool {
+	return r.Error != ""
+}
+
+type ExportRequest struct {
+	Group        string
+	PartnerCodes []string
+}
+
+type ExportReply struct {
+	Partners []models.Partner
+	Error    string
+}
+
+type CompareRequest struct {
+	PartnerCodes []string
+	Group        string
+}
+
+type CompareReply struct {
+	PartnerCodes []string
+	Keys         []service.KeyComparison
+	Error        string
+}
+
+type CloneRequest struct {
+	Name       string
+	Code       string
+	SourceCode string
+	Template   string
+	Groups     []string
+	Overrides  map[string]string
+}
+
+type StatusRequest struct {
+	PartnerCode      string
+	Status           string
+	ExpectedRevision int32
+}
+
+type StatusReply struct {
+	PartnerId       int32
+	PartnerCode     string
+	Status          string
+	StatusChangedAt time.Time
+	Error           string
+}
+
+func (r StatusReply) failed() bool {
+	return r.Error != ""
+}
+
+type RestoreRequest struct {
+	PartnerCode      string
+	Key              string
+	Group            string
+	ExpectedRevision int32
+}
+
+type RestoreReply struct {
+	Restored int32
+	Error    string
+}
+
+func (r RestoreReply) failed() bool {
+	return r.Error != ""
+}
+
+type OpenChangeSetRequest struct {
+	Name string
+}
+
+type StageChangeRequest struct {
+	ChangeSetId      int32
+	PartnerCode      string
+	PartnerName      string
+	Attributes       map[string]string
+	ExpectedRevision int32
+}
+
+type PreviewRequest struct {
+	ChangeSetId int32
+	PartnerCode string
+}
+
+type ChangeSetRequest struct {
+	ChangeSetId int32
+}
+
+type ChangeSetReply struct {
+	ChangeSet models.ChangeSet
+	Error     string
+}
+
+func (r ChangeSetReply) failed() bool {
+	return r.Error != ""
+}
+
+type ListApprovalRequestsRequest struct {
+	Status string
+}
+
+type ApprovalRequestsReply struct {
+	Requests []models.ApprovalRequest
+	Error    string
+}
+
+type ApprovalDecisionRequest struct {
+	RequestId int32
+	Reason    string
+}
+
+type ApprovalReply struct {
+	Request models.ApprovalRequest
+	Error   string
+}
+
+func (r ApprovalReply) failed() bool {
+	return r.Error != ""
+}
+
+type AccessLogRequest struct {
+	PartnerCode string
+	From        string
+	To          string
+}
+
+type AccessLogReply struct {
+	Records []models.AccessRecord
+	Error   string
+}
+
+type IssueAPIKeyRequest struct {
+	Owner     string
+	Scope     []string
+	ExpiresAt string
+}
+
+type ListAPIKeysRequest struct {
+	Owner string
+}
+
+type APIKeyRequest struct {
+	Id int32
+}
+
+//APIKeyReply has the key itself only when it was issued or rotated.
+type APIKeyReply struct {
+	APIKey models.APIKey
+	Key    string
+	Error  string
+}
+
+func (r APIKeyReply) failed() bool {
+	return r.Error != ""
+}
+
+//withoutSecrets leaves the key itself out of the reply kept for an idempotency key, so that it is only ever shown
+//once. A replay of IssueApiKey or RotateApiKey gets the key's details with an empty Key; rotate it to get a new one.
+func (r APIKeyReply) withoutSecrets() interface{} {
+	r.Key = ""
+	return r
+}
+
+type APIKeysReply struct {
+	APIKeys []models.APIKey
+	Error   string
+}
@@ -1,12 +1,21 @@
 package endpoints
 
 import (
+	"bytes"
 	"context"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-kit/kit/log"
+	"github.com/jackc/pgx"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/identity"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/secrets"
 	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/service"
 )
 
@@ -41,9 +50,233 @@ func (m *mockQuerier) CheckPartnerIDEqualsPartnerCode(partnerId int32, partnerCo
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *mockQuerier) FindPartners(partnerCodes []string, group string) ([]models.Partner, error) {
+	args := m.Called(partnerCodes, group)
+	typePartners, _ := args.Get(0).([]models.Partner)
+	return typePartners, args.Error(1)
+}
+
+func (m *mockQuerier) SavePartners(partners []models.Partner) error {
+	args := m.Called(partners)
+	return args.Error(0)
+}
+
+func (m *mockQuerier) ApplyPartners(partners, deletes []models.Partner) error {
+	args := m.Called(partners, deletes)
+	return args.Error(0)
+}
+
+func (m *mockQuerier) FindIdentifierKeys() ([]string, error) {
+	args := m.Called()
+	typeKeys, _ := args.Get(0).([]string)
+	return typeKeys, args.Error(1)
+}
+
+func (m *mockQuerier) FindTemplateAttributes(name string, group string) (map[string]string, error) {
+	args := m.Called(name, group)
+	typeMapStringString, _ := args.Get(0).(map[string]string)
+	return typeMapStringString, args.Error(1)
+}
+
+func (m *mockQuerier) CreatePartner(partner models.Partner) (int32, error) {
+	args := m.Called(partner)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *mockQuerier) FindPartnerStatus(partnerId int32) (string, error) {
+	args := m.Called(partnerId)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockQuerier) FindPartnerRevision(partnerId int32) (int32, error) {
+	args := m.Called(partnerId)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *mockQuerier) UpdatePartnerStatus(partnerId int32, from string, to string, expectedRevision int32) (time.Time, error) {
+	args := m.Called(partnerId, from, to, expectedRevision)
+	typeTime, _ := args.Get(0).(time.Time)
+	return typeTime, args.Error(1)
+}
+
+func (m *mockQuerier) RestorePartner(partnerCode string) (int64, error) {
+	args := m.Called(partnerCode)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockQuerier) RestoreKey(key string) (int64, error) {
+	args := m.Called(key)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockQuerier) RestoreGroup(group string) (int64, error) {
+	args := m.Called(group)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockQuerier) RestorePartnerAttribute(partnerCode string, key string, expectedRevision int32) (int64, error) {
+	args := m.Called(partnerCode, key, expectedRevision)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockQuerier) PurgeDeleted(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockQuerier) CreateChangeSet(name string) (int32, error) {
+	args := m.Called(name)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *mockQuerier) FindChangeSet(changeSetId int32) (models.ChangeSet, error) {
+	args := m.Called(changeSetId)
+	typeChangeSet, _ := args.Get(0).(models.ChangeSet)
+	return typeChangeSet, args.Error(1)
+}
+
+func (m *mockQuerier) StageChange(changeSetId int32, partner models.Partner) error {
+	args := m.Called(changeSetId, partner)
+	return args.Error(0)
+}
+
+func (m *mockQuerier) PublishChangeSet(changeSetId int32) error {
+	args := m.Called(changeSetId)
+	return args.Error(0)
+}
+
+func (m *mockQuerier) DiscardChangeSet(changeSetId int32) error {
+	args := m.Called(changeSetId)
+	return args.Error(0)
+}
+
+func (m *mockQuerier) FindApprovalPolicies(keys []string) (map[string][]string, error) {
+	args := m.Called(keys)
+	typePolicies, _ := args.Get(0).(map[string][]string)
+	return typePolicies, args.Error(1)
+}
+
+func (m *mockQuerier) FindRestoreApprovalPolicies(table, name string) (map[string][]string, error) {
+	args := m.Called(table, name)
+	typePolicies, _ := args.Get(0).(map[string][]string)
+	return typePolicies, args.Error(1)
+}
+
+func (m *mockQuerier) RequestApproval(changeSetId int32, requestedBy string, groups []string) (int32, error) {
+	args := m.Called(changeSetId, requestedBy, groups)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *mockQuerier) FindApprovalRequests(status string) ([]models.ApprovalRequest, error) {
+	args := m.Called(status)
+	typeRequests, _ := args.Get(0).([]models.ApprovalRequest)
+	return typeRequests, args.Error(1)
+}
+
+func (m *mockQuerier) FindApprovalRequest(requestId int32) (models.ApprovalRequest, error) {
+	args := m.Called(requestId)
+	typeRequest, _ := args.Get(0).(models.ApprovalRequest)
+	return typeRequest, args.Error(1)
+}
+
+func (m *mockQuerier) ApproveRequest(requestId int32, approver string) error {
+	args := m.Called(requestId, approver)
+	return args.Error(0)
+}
+
+func (m *mockQuerier) RejectRequest(requestId int32, approver string, reason string) error {
+	args := m.Called(requestId, approver, reason)
+	return args.Error(0)
+}
+
+func (m *mockQuerier) FindKeyGroups(keys []string) (map[string][]string, error) {
+	args := m.Called(keys)
+	typeMap, _ := args.Get(0).(map[string][]string)
+	return typeMap, args.Error(1)
+}
+
+func (m *mockQuerier) FindSensitiveKeys() ([]string, error) {
+	args := m.Called()
+	typeKeys, _ := args.Get(0).([]string)
+	return typeKeys, args.Error(1)
+}
+
+func (m *mockQuerier) ClaimIdempotencyKey(key string, method string, requestHash string, since time.Time) (models.IdempotentResponse, bool, error) {
+	args := m.Called(key, method, requestHash, since)
+	typeResponse, _ := args.Get(0).(models.IdempotentResponse)
+	return typeResponse, args.Bool(1), args.Error(2)
+}
+
+func (m *mockQuerier) SaveIdempotentResponse(key string, method string, response string) error {
+	args := m.Called(key, method, response)
+	return args.Error(0)
+}
+
+func (m *mockQuerier) ReleaseIdempotencyKey(key string, method string) error {
+	args := m.Called(key, method)
+	return args.Error(0)
+}
+
+func (m *mockQuerier) PurgeIdempotencyKeys(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockQuerier) RecordAccess(records []models.AccessRecord) error {
+	args := m.Called(records)
+	return args.Error(0)
+}
+
+func (m *mockQuerier) FindAccessRecords(partnerCode string, from time.Time, to time.Time) ([]models.AccessRecord, error) {
+	args := m.Called(partnerCode, from, to)
+	typeRecords, _ := args.Get(0).([]models.AccessRecord)
+	return typeRecords, args.Error(1)
+}
+
+func (m *mockQuerier) CreateAPIKey(key models.APIKey, hash string) (models.APIKey, error) {
+	args := m.Called(key, hash)
+	return args.Get(0).(models.APIKey), args.Error(1)
+}
+
+func (m *mockQuerier) FindAPIKeys(owner string) ([]models.APIKey, error) {
+	args := m.Called(owner)
+	typeKeys, _ := args.Get(0).([]models.APIKey)
+	return typeKeys, args.Error(1)
+}
+
+func (m *mockQuerier) FindAPIKeyByHash(hash string) (models.APIKey, error) {
+	args := m.Called(hash)
+	return args.Get(0).(models.APIKey), args.Error(1)
+}
+
+func (m *mockQuerier) RotateAPIKey(id int32, prefix, hash string) (models.APIKey, error) {
+	args := m.Called(id, prefix, hash)
+	return args.Get(0).(models.APIKey), args.Error(1)
+}
+
+func (m *mockQuerier) RevokeAPIKey(id int32) (models.APIKey, error) {
+	args := m.Called(id)
+	return args.Get(0).(models.APIKey), args.Error(1)
+}
+
+func (m *mockQuerier) TouchAPIKey(id int32, usedAt time.Time) error {
+	args := m.Called(id, usedAt)
+	return args.Error(0)
+}
+func (m *mockQuerier) Ping() error {
+	args := m.Called()
+	return args.Error(0)
+}
+func (m *mockQuerier) FindSchemaVersion() (int32, error) {
+	args := m.Called()
+	return args.Get(0).(int32), args.Error(1)
+}
+
 func TestMakeKeyValueEndpointHappy(t *testing.T) {
 	a := assert.New(t)
 	mq := new(mockQuerier)
+	mq.On("FindPartnerStatus", int32(1)).Return("active", nil)
+	mq.On("FindPartnerRevision", int32(1)).Return(int32(3), nil)
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
@@ -66,12 +299,15 @@ func TestMakeKeyValueEndpointHappy(t *testing.T) {
 	a.Equal(int32(1), int32(res.(PartnerDataReply).PartnerId))
 	a.Equal("KOH", res.(PartnerDataReply).PartnerCode)
 	a.Equal(wantedMap, res.(PartnerDataReply).Attributes)
+	a.Equal(int32(3), res.(PartnerDataReply).Revision)
 	a.Nil(err)
 }
 
 func TestMakeKeyValueEndpointBadKey(t *testing.T) {
 	a := assert.New(t)
 	mq := new(mockQuerier)
+	mq.On("FindPartnerStatus", int32(1)).Return("active", nil)
+	mq.On("FindPartnerRevision", int32(1)).Return(int32(3), nil)
 	mq.On("FindPartnerDataFromKeyValue", "lkshdglk", "USD").Return(int32(0), "", errors.New("error finding partner data from key value"))
 	mq.On("FindAllAttributesForPartner", int32(0)).Return((make(map[string]string)), errors.New("error finding all attributes for Partner"))
 	mq.On("FindPartnerAttribute", int32(0), "Money").Return((make(map[string]string)), errors.New("error finding attributes for Partner & Group"))
@@ -92,9 +328,32 @@ func TestMakeKeyValueEndpointBadKey(t *testing.T) {
 	a.Equal("", res.(PartnerDataReply).PartnerCode)
 	a.Equal((make(map[string]string)), res.(PartnerDataReply).Attributes)
 }
+
+// Test that a call that could not reach the database fails instead of replying with the error
+func TestMakeKeyValueEndpointUnavailable(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	mq.On("FindPartnerDataFromKeyValue", "Currency", "USD").Return(int32(0), "", errors.Wrap(db.ErrUnavailable, "circuit breaker is open"))
+
+	s := service.NewPartnerService(mq)
+
+	req := &KeyValueRequest{
+		Key:   "Currency",
+		Value: "USD",
+		Group: "Money",
+	}
+
+	res, err := MakeKeyValueEndpoint(s)(context.Background(), *req)
+
+	a.Nil(res)
+	a.True(service.IsUnavailable(err))
+}
+
 func TestMakeKeyValueEndpointBadValue(t *testing.T) {
 	a := assert.New(t)
 	mq := new(mockQuerier)
+	mq.On("FindPartnerStatus", int32(1)).Return("active", nil)
+	mq.On("FindPartnerRevision", int32(1)).Return(int32(3), nil)
 	mq.On("FindPartnerDataFromKeyValue", "Currency", "sgdsd").Return(int32(0), "", errors.New("error finding partner data from key value"))
 	mq.On("FindAllAttributesForPartner", int32(0)).Return((make(map[string]string)), errors.New("error finding all attributes for Partner"))
 	mq.On("FindPartnerAttribute", int32(0), "Money").Return((make(map[string]string)), errors.New("error finding attributes for Partner & Group"))
@@ -122,6 +381,8 @@ func TestMakeKeyValueEndpointBadGroup(t *testing.T) {
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
 	mq := new(mockQuerier)
+	mq.On("FindPartnerStatus", int32(1)).Return("active", nil)
+	mq.On("FindPartnerRevision", int32(1)).Return(int32(3), nil)
 	mq.On("FindPartnerDataFromKeyValue", "Currency", "USD").Return(int32(1), "KOH", nil)
 	mq.On("FindAllAttributesForPartner", int32(1)).Return(wantedMap, nil)
 	mq.On("FindPartnerAttribute", int32(1), "lksdhf").Return((make(map[string]string)), errors.New("error finding attributes for Partner & Group because bad group"))
@@ -145,6 +406,8 @@ func TestMakeKeyValueEndpointBadGroup(t *testing.T) {
 func TestMakeKeyValueEndpointNilKey(t *testing.T) {
 	a := assert.New(t)
 	mq := new(mockQuerier)
+	mq.On("FindPartnerStatus", int32(1)).Return("active", nil)
+	mq.On("FindPartnerRevision", int32(1)).Return(int32(3), nil)
 	mq.On("FindPartnerDataFromKeyValue", "", "USD").Return(int32(0), "", errors.New("error finding partner data from nil key value"))
 	mq.On("FindAllAttributesForPartner", int32(0)).Return((make(map[string]string)), errors.New("error finding all attributes for Partner because nil key"))
 	mq.On("FindPartnerAttribute", int32(0), "Money").Return((make(map[string]string)), errors.New("error finding attributes for Partner & Group because nil key"))
@@ -169,6 +432,8 @@ func TestMakeKeyValueEndpointNilKey(t *testing.T) {
 func TestMakeKeyValueEndpointNilValue(t *testing.T) {
 	a := assert.New(t)
 	mq := new(mockQuerier)
+	mq.On("FindPartnerStatus", int32(1)).Return("active", nil)
+	mq.On("FindPartnerRevision", int32(1)).Return(int32(3), nil)
 	mq.On("FindPartnerDataFromKeyValue", "Currency", "").Return(int32(0), "", errors.New("error finding partner data from key value because nil value"))
 	mq.On("FindAllAttributesForPartner", int32(0)).Return((make(map[string]string)), errors.New("error finding all attributes for Partner because nil value"))
 	mq.On("FindPartnerAttribute", int32(0), "Money").Return((make(map[string]string)), errors.New("error finding attributes for Partner & Group because nil value"))
@@ -196,6 +461,8 @@ func TestMakeKeyValueEndpointNilGroup(t *testing.T) {
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
 	mq := new(mockQuerier)
+	mq.On("FindPartnerStatus", int32(1)).Return("active", nil)
+	mq.On("FindPartnerRevision", int32(1)).Return(int32(3), nil)
 	mq.On("FindPartnerDataFromKeyValue", "Currency", "USD").Return(int32(1), "KOH", nil)
 	mq.On("FindAllAttributesForPartner", int32(1)).Return(wantedMap, nil)
 	mq.On("FindPartnerAttribute", int32(1), "").Return(wantedMap, nil)
@@ -220,6 +487,8 @@ func TestMakeKeyValueEndpointNilGroup(t *testing.T) {
 func TestMakeDataByIdEndpointHappy(t *testing.T) {
 	a := assert.New(t)
 	mq := new(mockQuerier)
+	mq.On("FindPartnerStatus", int32(1)).Return("active", nil)
+	mq.On("FindPartnerRevision", int32(1)).Return(int32(3), nil)
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
@@ -249,6 +518,8 @@ func TestMakeDataByIdEndpointHappy(t *testing.T) {
 func TestMakeGetDataByIdEndpointBadId(t *testing.T) {
 	a := assert.New(t)
 	mq := new(mockQuerier)
+	mq.On("FindPartnerStatus", int32(1)).Return("active", nil)
+	mq.On("FindPartnerRevision", int32(1)).Return(int32(3), nil)
 	mq.On("FindPartnerDataByID", int32(-1), "KOH").Return(int32(0), "", errors.New("error finding partner data from id or Code because bad id"))
 	mq.On("FindAllAttributesForPartner", int32(0)).Return((make(map[string]string)), errors.New("error finding all attributes for Partner because bad id"))
 	mq.On("FindPartnerAttribute", int32(0), "Money").Return((make(map[string]string)), errors.New("error finding attributes for Partner & Group because bad id"))
@@ -274,6 +545,8 @@ func TestMakeGetDataByIdEndpointBadId(t *testing.T) {
 func TestMakeGetDataByIdEndpointBadCode(t *testing.T) {
 	a := assert.New(t)
 	mq := new(mockQuerier)
+	mq.On("FindPartnerStatus", int32(1)).Return("active", nil)
+	mq.On("FindPartnerRevision", int32(1)).Return(int32(3), nil)
 	mq.On("FindPartnerDataByID", int32(1), "lhdfhg").Return(int32(0), "", errors.New("error finding partner data from id or Code because bad code"))
 	mq.On("FindAllAttributesForPartner", int32(0)).Return((make(map[string]string)), errors.New("error finding all attributes for Partner because bad code"))
 	mq.On("FindPartnerAttribute", int32(0), "Money").Return((make(map[string]string)), errors.New("error finding attributes for Partner & Group because bad code"))
@@ -299,6 +572,8 @@ func TestMakeGetDataByIdEndpointBadCode(t *testing.T) {
 func TestMakeGetDataByIdEndpointBadGroup(t *testing.T) {
 	a := assert.New(t)
 	mq := new(mockQuerier)
+	mq.On("FindPartnerStatus", int32(1)).Return("active", nil)
+	mq.On("FindPartnerRevision", int32(1)).Return(int32(3), nil)
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
@@ -327,6 +602,8 @@ func TestMakeGetDataByIdEndpointBadGroup(t *testing.T) {
 func TestMakeGetDataByIdEndpointNilId(t *testing.T) {
 	a := assert.New(t)
 	mq := new(mockQuerier)
+	mq.On("FindPartnerStatus", int32(1)).Return("active", nil)
+	mq.On("FindPartnerRevision", int32(1)).Return(int32(3), nil)
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
@@ -354,6 +631,8 @@ func TestMakeGetDataByIdEndpointNilId(t *testing.T) {
 func TestMakeGetDataByIdEndpointNilCode(t *testing.T) {
 	a := assert.New(t)
 	mq := new(mockQuerier)
+	mq.On("FindPartnerStatus", int32(1)).Return("active", nil)
+	mq.On("FindPartnerRevision", int32(1)).Return(int32(3), nil)
 
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
@@ -383,6 +662,8 @@ func TestMakeGetDataByIdEndpointNilCode(t *testing.T) {
 func TestMakeGetDataByIdEndpointNilGroup(t *testing.T) {
 	a := assert.New(t)
 	mq := new(mockQuerier)
+	mq.On("FindPartnerStatus", int32(1)).Return("active", nil)
+	mq.On("FindPartnerRevision", int32(1)).Return(int32(3), nil)
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
@@ -411,6 +692,8 @@ func TestMakeGetDataByIdEndpointNilGroup(t *testing.T) {
 func TestMakeGetDataByIdEndpointNegativeId(t *testing.T) {
 	a := assert.New(t)
 	mq := new(mockQuerier)
+	mq.On("FindPartnerStatus", int32(1)).Return("active", nil)
+	mq.On("FindPartnerRevision", int32(1)).Return(int32(3), nil)
 	mq.On("FindPartnerDataByID", int32(-1), "KOH").Return(int32(0), "", errors.New("error finding partner data from id or Code because negative id"))
 	mq.On("FindAllAttributesForPartner", int32(-1)).Return((make(map[string]string)), errors.New("error finding all attributes for Partner because negative id"))
 	mq.On("FindPartnerAttribute", int32(-1), "Money").Return((make(map[string]string)), errors.New("error finding attributes for Partner & Group because negative id"))
@@ -432,3 +715,648 @@ func TestMakeGetDataByIdEndpointNegativeId(t *testing.T) {
 	a.Equal("", res.(PartnerDataReply).PartnerCode)
 	a.Equal((make(map[string]string)), res.(PartnerDataReply).Attributes)
 }
+
+func TestMakeExportPartnersEndpointHappy(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	wantedMap := make(map[string]string)
+	wantedMap["Currency"] = "USD"
+	kohls := models.Partner{
+		Id:         pgx.NullInt32{Int32: 1, Valid: true},
+		Name:       pgx.NullString{String: "Kohls", Valid: true},
+		Code:       pgx.NullString{String: "KOH", Valid: true},
+		Attributes: wantedMap,
+	}
+	mq.On("FindPartners", []string{"KOH"}, "Money").Return([]models.Partner{kohls}, nil)
+
+	s := service.NewPartnerService(mq)
+
+	req := &ExportRequest{
+		Group:        "Money",
+		PartnerCodes: []string{"KOH"},
+	}
+
+	ctx := context.Background()
+
+	res, err := MakeExportPartnersEndpoint(s)(ctx, *req)
+
+	a.Nil(err)
+	a.Equal([]models.Partner{kohls}, res.(ExportReply).Partners)
+	a.Equal("", res.(ExportReply).Error)
+}
+
+func TestMakeExportPartnersEndpointBadGroup(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	mq.On("FindPartners", []string(nil), "asdfjkl").Return(nil, errors.New("error finding partners because bad group"))
+
+	s := service.NewPartnerService(mq)
+
+	req := &ExportRequest{
+		Group: "asdfjkl", //bad group
+	}
+
+	ctx := context.Background()
+
+	res, _ := MakeExportPartnersEndpoint(s)(ctx, *req)
+
+	a.Nil(res.(ExportReply).Partners)
+	a.NotEqual("", res.(ExportReply).Error)
+}
+
+func TestMakeComparePartnersEndpointHappy(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	kohls := models.Partner{
+		Code:       pgx.NullString{String: "KOH", Valid: true},
+		Attributes: map[string]string{"Currency": "USD"},
+	}
+	jcPenny := models.Partner{
+		Code:       pgx.NullString{String: "JCP", Valid: true},
+		Attributes: map[string]string{"Currency": "CAD"},
+	}
+	mq.On("FindPartners", []string{"KOH", "JCP"}, "Money").Return([]models.Partner{kohls, jcPenny}, nil)
+
+	s := service.NewPartnerService(mq)
+
+	req := &CompareRequest{
+		PartnerCodes: []string{"KOH", "JCP"},
+		Group:        "Money",
+	}
+
+	ctx := context.Background()
+
+	res, err := MakeComparePartnersEndpoint(s)(ctx, *req)
+
+	a.Nil(err)
+	a.Equal([]string{"KOH", "JCP"}, res.(CompareReply).PartnerCodes)
+	a.Equal([]service.KeyComparison{{Key: "Currency", Status: service.KeyDifferent, Values: map[string]string{"KOH": "USD", "JCP": "CAD"}}}, res.(CompareReply).Keys)
+	a.Equal("", res.(CompareReply).Error)
+}
+
+func TestMakeComparePartnersEndpointOneCode(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+
+	s := service.NewPartnerService(mq)
+
+	req := &CompareRequest{
+		PartnerCodes: []string{"KOH"}, //nothing to compare against
+	}
+
+	ctx := context.Background()
+
+	res, _ := MakeComparePartnersEndpoint(s)(ctx, *req)
+
+	a.Nil(res.(CompareReply).Keys)
+	a.NotEqual("", res.(CompareReply).Error)
+}
+
+func TestMakeClonePartnerEndpointHappy(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	mq.On("FindTemplateAttributes", "Standard US EDI retailer", "EDI").Return(map[string]string{"850": "Received"}, nil)
+	mq.On("FindIdentifierKeys").Return([]string{"ISAID"}, nil)
+	mq.On("FindApprovalPolicies", []string{"850", "ISAID"}).Return(map[string][]string{}, nil)
+	mq.On("CreatePartner", models.Partner{
+		Name:       pgx.NullString{String: "Target", Valid: true},
+		Code:       pgx.NullString{String: "TAR", Valid: true},
+		Attributes: map[string]string{"850": "Received", "ISAID": "TARGET1"},
+	}).Return(int32(9), nil)
+
+	s := service.NewPartnerService(mq)
+
+	req := &CloneRequest{
+		Name:      "Target",
+		Code:      "TAR",
+		Template:  "Standard US EDI retailer",
+		Groups:    []string{"EDI"},
+		Overrides: map[string]string{"ISAID": "TARGET1"},
+	}
+
+	ctx := context.Background()
+
+	res, err := MakeClonePartnerEndpoint(s)(ctx, *req)
+
+	a.Nil(err)
+	a.Equal(int32(9), res.(PartnerDataReply).PartnerId)
+	a.Equal("TAR", res.(PartnerDataReply).PartnerCode)
+	a.Equal(map[string]string{"850": "Received", "ISAID": "TARGET1"}, res.(PartnerDataReply).Attributes)
+	a.Equal("", res.(PartnerDataReply).Error)
+}
+
+func TestMakeClonePartnerEndpointNoSource(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+
+	s := service.NewPartnerService(mq)
+
+	req := &CloneRequest{
+		Name: "Target",
+		Code: "TAR", //neither a source partner nor a template
+	}
+
+	ctx := context.Background()
+
+	res, _ := MakeClonePartnerEndpoint(s)(ctx, *req)
+
+	a.Equal(int32(0), res.(PartnerDataReply).PartnerId)
+	a.NotEqual("", res.(PartnerDataReply).Error)
+}
+
+func TestMakeKeyValueEndpointInactive(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	mq.On("FindPartnerDataFromKeyValue", "Currency", "USD").Return(int32(1), "KOH", nil)
+	mq.On("FindPartnerStatus", int32(1)).Return("onboarding", nil)
+	mq.On("FindPartnerRevision", int32(1)).Return(int32(3), nil)
+	mq.On("FindAllAttributesForPartner", int32(1)).Return(map[string]string{"Currency": "USD"}, nil)
+
+	s := service.NewPartnerService(mq)
+
+	req := &KeyValueRequest{
+		Key:   "Currency",
+		Value: "USD",
+	}
+
+	ctx := context.Background()
+
+	res, _ := MakeKeyValueEndpoint(s)(ctx, *req)
+	a.Equal(int32(0), res.(PartnerDataReply).PartnerId)
+	a.NotEqual("", res.(PartnerDataReply).Error)
+
+	req.IncludeInactive = true
+	res, _ = MakeKeyValueEndpoint(s)(ctx, *req)
+	a.Equal(int32(1), res.(PartnerDataReply).PartnerId)
+	a.Equal(map[string]string{"Currency": "USD"}, res.(PartnerDataReply).Attributes)
+	a.Equal("", res.(PartnerDataReply).Error)
+}
+
+func TestMakeSetPartnerStatusEndpointHappy(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	changedAt := time.Date(2017, 8, 1, 12, 0, 0, 0, time.UTC)
+	mq.On("FindPartnerDataByID", int32(0), "KOH").Return(int32(1), "KOH", nil)
+	mq.On("FindPartnerStatus", int32(1)).Return("onboarding", nil)
+	mq.On("FindPartnerRevision", int32(1)).Return(int32(3), nil)
+	mq.On("UpdatePartnerStatus", int32(1), "onboarding", "active", int32(0)).Return(changedAt, nil)
+
+	s := service.NewPartnerService(mq)
+
+	req := &StatusRequest{
+		PartnerCode: "KOH",
+		Status:      "active",
+	}
+
+	ctx := context.Background()
+
+	res, err := MakeSetPartnerStatusEndpoint(s)(ctx, *req)
+
+	a.Nil(err)
+	a.Equal(StatusReply{PartnerId: 1, PartnerCode: "KOH", Status: "active", StatusChangedAt: changedAt}, res.(StatusReply))
+}
+
+func TestMakeSetPartnerStatusEndpointConflict(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	mq.On("FindPartnerDataByID", int32(0), "KOH").Return(int32(1), "KOH", nil)
+	mq.On("FindPartnerStatus", int32(1)).Return("onboarding", nil)
+	mq.On("UpdatePartnerStatus", int32(1), "onboarding", "active", int32(2)).Return(time.Time{}, errors.Wrap(db.ErrRevisionConflict, "partnerId: 1 is not at revision 2"))
+
+	s := service.NewPartnerService(mq)
+
+	req := &StatusRequest{
+		PartnerCode:      "KOH",
+		Status:           "active",
+		ExpectedRevision: 2,
+	}
+
+	ctx := context.Background()
+
+	res, err := MakeSetPartnerStatusEndpoint(s)(ctx, *req)
+
+	a.Nil(res)
+	a.True(service.IsConflict(err))
+}
+
+func TestMakeSetPartnerStatusEndpointRetired(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	mq.On("FindPartnerDataByID", int32(0), "KOH").Return(int32(1), "KOH", nil)
+	mq.On("FindPartnerStatus", int32(1)).Return("retired", nil)
+
+	s := service.NewPartnerService(mq)
+
+	req := &StatusRequest{
+		PartnerCode: "KOH",
+		Status:      "active", //retired partners cannot come back
+	}
+
+	ctx := context.Background()
+
+	res, _ := MakeSetPartnerStatusEndpoint(s)(ctx, *req)
+
+	a.Equal(int32(0), res.(StatusReply).PartnerId)
+	a.True(res.(StatusReply).StatusChangedAt.IsZero())
+	a.NotEqual("", res.(StatusReply).Error)
+}
+
+func TestMakeRestorePartnerEndpointHappy(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	mq.On("RestorePartner", "MUS").Return(int64(3), nil)
+	mq.On("FindRestoreApprovalPolicies", "partners", "MUS").Return(map[string][]string{}, nil)
+
+	s := service.NewPartnerService(mq)
+
+	req := &RestoreRequest{
+		PartnerCode: "MUS",
+	}
+
+	ctx := context.Background()
+
+	res, err := MakeRestorePartnerEndpoint(s)(ctx, *req)
+
+	a.Nil(err)
+	a.Equal(RestoreReply{Restored: 3}, res.(RestoreReply))
+}
+
+func TestMakeRestoreKeyEndpointError(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	mq.On("RestoreKey", "Currency").Return(int64(0), errors.New("error restoring key because name taken"))
+	mq.On("FindRestoreApprovalPolicies", "keys", "Currency").Return(map[string][]string{}, nil)
+
+	s := service.NewPartnerService(mq)
+
+	req := &RestoreRequest{
+		Key: "Currency",
+	}
+
+	ctx := context.Background()
+
+	res, _ := MakeRestoreKeyEndpoint(s)(ctx, *req)
+
+	a.Equal(int32(0), res.(RestoreReply).Restored)
+	a.NotEqual("", res.(RestoreReply).Error)
+}
+
+func TestMakeRestorePartnerAttributeEndpointHappy(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	mq.On("RestorePartnerAttribute", "KOH", "Qualifier", int32(0)).Return(int64(1), nil)
+	mq.On("FindApprovalPolicies", []string{"Qualifier"}).Return(map[string][]string{}, nil)
+
+	s := service.NewPartnerService(mq)
+
+	req := &RestoreRequest{
+		PartnerCode: "KOH",
+		Key:         "Qualifier",
+	}
+
+	ctx := context.Background()
+
+	res, err := MakeRestorePartnerAttributeEndpoint(s)(ctx, *req)
+
+	a.Nil(err)
+	a.Equal(RestoreReply{Restored: 1}, res.(RestoreReply))
+}
+
+func TestMakeStageChangeEndpointHappy(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	changeSet := models.ChangeSet{
+		Id:       pgx.NullInt32{Int32: 3, Valid: true},
+		Status:   pgx.NullString{String: models.ChangeSetDraft, Valid: true},
+		Partners: []models.Partner{{Code: pgx.NullString{String: "KOH", Valid: true}, Attributes: map[string]string{"Currency": "CAD"}}},
+	}
+	mq.On("FindChangeSet", int32(3)).Return(changeSet, nil)
+	mq.On("FindPartnerDataByID", int32(0), "KOH").Return(int32(1), "KOH", nil)
+	mq.On("StageChange", int32(3), models.Partner{Code: pgx.NullString{String: "KOH", Valid: true}, Attributes: map[string]string{"Currency": "CAD"}}).Return(nil)
+
+	s := service.NewPartnerService(mq)
+
+	req := &StageChangeRequest{
+		ChangeSetId: 3,
+		PartnerCode: "KOH",
+		Attributes:  map[string]string{"Currency": "CAD"},
+	}
+
+	ctx := context.Background()
+
+	res, err := MakeStageChangeEndpoint(s)(ctx, *req)
+
+	a.Nil(err)
+	a.Equal(ChangeSetReply{ChangeSet: changeSet}, res.(ChangeSetReply))
+}
+
+func TestMakePreviewChangeSetEndpointHappy(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	mq.On("FindChangeSet", int32(3)).Return(models.ChangeSet{
+		Status:   pgx.NullString{String: models.ChangeSetDraft, Valid: true},
+		Partners: []models.Partner{{Code: pgx.NullString{String: "KOH", Valid: true}, Attributes: map[string]string{"Currency": "CAD"}}},
+	}, nil)
+	mq.On("FindPartnerDataByID", int32(0), "KOH").Return(int32(1), "KOH", nil)
+	mq.On("FindAllAttributesForPartner", int32(1)).Return(map[string]string{"Currency": "USD", "Type of Payment": "Credit"}, nil)
+
+	s := service.NewPartnerService(mq)
+
+	req := &PreviewRequest{
+		ChangeSetId: 3,
+		PartnerCode: "KOH",
+	}
+
+	ctx := context.Background()
+
+	res, err := MakePreviewChangeSetEndpoint(s)(ctx, *req)
+
+	a.Nil(err)
+	a.Equal(PartnerDataReply{PartnerId: 1, PartnerCode: "KOH", Attributes: map[string]string{"Currency": "CAD", "Type of Payment": "Credit"}}, res.(PartnerDataReply))
+}
+
+func TestMakePublishChangeSetEndpointDiscarded(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	mq.On("FindChangeSet", int32(3)).Return(models.ChangeSet{Status: pgx.NullString{String: models.ChangeSetDiscarded, Valid: true}}, nil)
+
+	s := service.NewPartnerService(mq)
+
+	req := &ChangeSetRequest{
+		ChangeSetId: 3,
+	}
+
+	ctx := context.Background()
+
+	res, _ := MakePublishChangeSetEndpoint(s)(ctx, *req)
+
+	a.NotEqual("", res.(ChangeSetReply).Error)
+	mq.AssertNotCalled(t, "PublishChangeSet", int32(3))
+}
+
+func TestMakeApproveRequestEndpointHappy(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	pending := models.ApprovalRequest{
+		Id:          pgx.NullInt32{Int32: 10, Valid: true},
+		ChangeSetId: pgx.NullInt32{Int32: 3, Valid: true},
+		Status:      pgx.NullString{String: models.ApprovalPending, Valid: true},
+		RequestedBy: pgx.NullString{String: "jdoe", Valid: true},
+	}
+	mq.On("FindApprovalRequest", int32(10)).Return(pending, nil).Once()
+	mq.On("FindChangeSet", int32(3)).Return(models.ChangeSet{
+		Partners: []models.Partner{{Code: pgx.NullString{String: "KOH", Valid: true}, Attributes: map[string]string{"Currency": "CAD"}}},
+	}, nil)
+	mq.On("FindApprovalPolicies", []string{"Currency"}).Return(map[string][]string{"Money": {"controller"}}, nil)
+	mq.On("ApproveRequest", int32(10), "controller").Return(nil)
+	approved := pending
+	approved.Status = pgx.NullString{String: models.ApprovalApproved, Valid: true}
+	approved.DecidedBy = pgx.NullString{String: "controller", Valid: true}
+	mq.On("FindApprovalRequest", int32(10)).Return(approved, nil)
+
+	s := service.NewPartnerService(mq)
+
+	req := &ApprovalDecisionRequest{
+		RequestId: 10,
+	}
+
+	ctx := identity.NewContext(context.Background(), "controller")
+
+	res, err := MakeApproveRequestEndpoint(s)(ctx, *req)
+
+	a.Nil(err)
+	a.Equal(ApprovalReply{Request: approved}, res.(ApprovalReply))
+}
+
+func TestMakeApproveRequestEndpointSameUser(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	mq.On("FindApprovalRequest", int32(10)).Return(models.ApprovalRequest{
+		Id:          pgx.NullInt32{Int32: 10, Valid: true},
+		Status:      pgx.NullString{String: models.ApprovalPending, Valid: true},
+		RequestedBy: pgx.NullString{String: "jdoe", Valid: true},
+	}, nil)
+
+	s := service.NewPartnerService(mq)
+
+	req := &ApprovalDecisionRequest{
+		RequestId: 10,
+	}
+
+	ctx := identity.NewContext(context.Background(), "jdoe")
+
+	res, _ := MakeApproveRequestEndpoint(s)(ctx, *req)
+
+	a.NotEqual("", res.(ApprovalReply).Error)
+	mq.AssertNotCalled(t, "ApproveRequest", int32(10), "jdoe")
+}
+
+func TestMakeListApprovalRequestsEndpointHappy(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	requests := []models.ApprovalRequest{{Id: pgx.NullInt32{Int32: 10, Valid: true}}}
+	mq.On("FindApprovalRequests", "pending").Return(requests, nil)
+
+	s := service.NewPartnerService(mq)
+
+	req := &ListApprovalRequestsRequest{
+		Status: "pending",
+	}
+
+	ctx := context.Background()
+
+	res, err := MakeListApprovalRequestsEndpoint(s)(ctx, *req)
+
+	a.Nil(err)
+	a.Equal(ApprovalRequestsReply{Requests: requests}, res.(ApprovalRequestsReply))
+}
+
+func TestIdempotencyMiddlewareNoKey(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	mq.On("RestorePartner", "MUS").Return(int64(3), nil)
+	mq.On("FindRestoreApprovalPolicies", "partners", "MUS").Return(map[string][]string{}, nil)
+
+	s := service.NewPartnerService(mq)
+	e := IdempotencyMiddleware(mq, time.Hour, "RestorePartner", RestoreReply{})(MakeRestorePartnerEndpoint(s))
+
+	res, err := e(context.Background(), RestoreRequest{PartnerCode: "MUS"})
+
+	a.Nil(err)
+	a.Equal(RestoreReply{Restored: 3}, res)
+	mq.AssertNotCalled(t, "ClaimIdempotencyKey", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestIdempotencyMiddlewareFirstWrite(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	ctx := NewIdempotencyContext(context.Background(), "abc")
+	req := RestoreRequest{PartnerCode: "MUS"}
+	hash, _ := requestHash(ctx, req)
+	mq.On("ClaimIdempotencyKey", "abc", "RestorePartner", hash, mock.AnythingOfType("time.Time")).Return(models.IdempotentResponse{}, true, nil)
+	mq.On("RestorePartner", "MUS").Return(int64(3), nil)
+	mq.On("FindRestoreApprovalPolicies", "partners", "MUS").Return(map[string][]string{}, nil)
+	mq.On("SaveIdempotentResponse", "abc", "RestorePartner", `{"Restored":3,"Error":""}`).Return(nil)
+
+	s := service.NewPartnerService(mq)
+	e := IdempotencyMiddleware(mq, time.Hour, "RestorePartner", RestoreReply{})(MakeRestorePartnerEndpoint(s))
+
+	res, err := e(ctx, req)
+
+	a.Nil(err)
+	a.Equal(RestoreReply{Restored: 3}, res)
+	mq.AssertExpectations(t)
+}
+
+func TestIdempotencyMiddlewareReplay(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	ctx := NewIdempotencyContext(context.Background(), "abc")
+	req := RestoreRequest{PartnerCode: "MUS"}
+	hash, _ := requestHash(ctx, req)
+	stored := models.IdempotentResponse{
+		RequestHash: pgx.NullString{String: hash, Valid: true},
+		Response:    pgx.NullString{String: `{"Restored":3,"Error":""}`, Valid: true},
+	}
+	mq.On("ClaimIdempotencyKey", "abc", "RestorePartner", hash, mock.AnythingOfType("time.Time")).Return(stored, false, nil)
+
+	s := service.NewPartnerService(mq)
+	e := IdempotencyMiddleware(mq, time.Hour, "RestorePartner", RestoreReply{})(MakeRestorePartnerEndpoint(s))
+
+	res, err := e(ctx, req)
+
+	a.Nil(err)
+	a.Equal(RestoreReply{Restored: 3}, res)
+	mq.AssertNotCalled(t, "RestorePartner", "MUS")
+}
+
+func TestIdempotencyMiddlewareReused(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	ctx := NewIdempotencyContext(context.Background(), "abc")
+	stored := models.IdempotentResponse{
+		RequestHash: pgx.NullString{String: "other", Valid: true},
+		Response:    pgx.NullString{String: `{"Restored":3,"Error":""}`, Valid: true},
+	}
+	mq.On("ClaimIdempotencyKey", "abc", "RestorePartner", mock.Anything, mock.AnythingOfType("time.Time")).Return(stored, false, nil)
+
+	s := service.NewPartnerService(mq)
+	e := IdempotencyMiddleware(mq, time.Hour, "RestorePartner", RestoreReply{})(MakeRestorePartnerEndpoint(s))
+
+	res, err := e(ctx, RestoreRequest{PartnerCode: "MUS"})
+
+	a.Nil(res)
+	a.Equal(ErrIdempotencyKeyReused, err)
+}
+
+func TestIdempotencyMiddlewareInProgress(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	ctx := NewIdempotencyContext(context.Background(), "abc")
+	req := RestoreRequest{PartnerCode: "MUS"}
+	hash, _ := requestHash(ctx, req)
+	stored := models.IdempotentResponse{RequestHash: pgx.NullString{String: hash, Valid: true}}
+	mq.On("ClaimIdempotencyKey", "abc", "RestorePartner", hash, mock.AnythingOfType("time.Time")).Return(stored, false, nil)
+
+	s := service.NewPartnerService(mq)
+	e := IdempotencyMiddleware(mq, time.Hour, "RestorePartner", RestoreReply{})(MakeRestorePartnerEndpoint(s))
+
+	res, err := e(ctx, req)
+
+	a.Nil(res)
+	a.Equal(ErrIdempotencyKeyInProgress, err)
+}
+
+func TestIdempotencyMiddlewareKeepsNoAPIKey(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	ctx := NewIdempotencyContext(context.Background(), "abc")
+	req := IssueAPIKeyRequest{Owner: "onboarding-pipeline", Scope: []string{"edi"}}
+	hash, _ := requestHash(ctx, req)
+	issued := APIKeyReply{APIKey: models.APIKey{Id: pgx.NullInt32{Int32: 7, Valid: true}}, Key: "psk_0a1b2c3d_secret"}
+	mq.On("ClaimIdempotencyKey", "abc", "IssueApiKey", hash, mock.AnythingOfType("time.Time")).Return(models.IdempotentResponse{}, true, nil)
+	mq.On("SaveIdempotentResponse", "abc", "IssueApiKey", mock.MatchedBy(func(response string) bool {
+		return !strings.Contains(response, "secret") && strings.Contains(response, `"Key":""`)
+	})).Return(nil)
+
+	e := IdempotencyMiddleware(mq, time.Hour, "IssueApiKey", APIKeyReply{})(func(ctx context.Context, request interface{}) (interface{}, error) {
+		return issued, nil
+	})
+
+	res, err := e(ctx, req)
+
+	a.Nil(err)
+	a.Equal(issued, res)
+	mq.AssertExpectations(t)
+}
+
+func TestIdempotencyMiddlewareReleasesOnError(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	ctx := NewIdempotencyContext(context.Background(), "abc")
+	req := StatusRequest{PartnerCode: "KOH", Status: "active", ExpectedRevision: 2}
+	hash, _ := requestHash(ctx, req)
+	mq.On("ClaimIdempotencyKey", "abc", "SetPartnerStatus", hash, mock.AnythingOfType("time.Time")).Return(models.IdempotentResponse{}, true, nil)
+	mq.On("FindPartnerDataByID", int32(0), "KOH").Return(int32(1), "KOH", nil)
+	mq.On("FindPartnerStatus", int32(1)).Return("onboarding", nil)
+	mq.On("UpdatePartnerStatus", int32(1), "onboarding", "active", int32(2)).Return(time.Time{}, errors.Wrap(db.ErrRevisionConflict, "partnerId: 1 is not at revision 2"))
+	mq.On("ReleaseIdempotencyKey", "abc", "SetPartnerStatus").Return(nil)
+
+	s := service.NewPartnerService(mq)
+	e := IdempotencyMiddleware(mq, time.Hour, "SetPartnerStatus", StatusReply{})(MakeSetPartnerStatusEndpoint(s))
+
+	_, err := e(ctx, req)
+
+	a.True(service.IsConflict(err))
+	mq.AssertExpectations(t)
+}
+
+func TestIdempotencyMiddlewareReleasesOnErrorReply(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	ctx := NewIdempotencyContext(context.Background(), "abc")
+	req := RestoreRequest{PartnerCode: "MUS"}
+	hash, _ := requestHash(ctx, req)
+	mq.On("ClaimIdempotencyKey", "abc", "RestorePartner", hash, mock.AnythingOfType("time.Time")).Return(models.IdempotentResponse{}, true, nil)
+	mq.On("FindRestoreApprovalPolicies", "partners", "MUS").Return(map[string][]string{}, nil)
+	mq.On("RestorePartner", "MUS").Return(int64(0), errors.New("ERROR: deadlock detected (SQLSTATE 40P01)"))
+	mq.On("ReleaseIdempotencyKey", "abc", "RestorePartner").Return(nil)
+
+	s := service.NewPartnerService(mq)
+	e := IdempotencyMiddleware(mq, time.Hour, "RestorePartner", RestoreReply{})(MakeRestorePartnerEndpoint(s))
+
+	res, err := e(ctx, req)
+
+	a.Nil(err)
+	a.NotEqual("", res.(RestoreReply).Error)
+	mq.AssertExpectations(t)
+	mq.AssertNotCalled(t, "SaveIdempotentResponse", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRequestHashDependsOnCaller(t *testing.T) {
+	a := assert.New(t)
+	req := RestoreRequest{PartnerCode: "MUS"}
+
+	first, err := requestHash(identity.NewContext(context.Background(), "jdoe"), req)
+	a.Nil(err)
+	second, err := requestHash(identity.NewContext(context.Background(), "asmith"), req)
+	a.Nil(err)
+
+	a.NotEqual(first, second)
+}
+
+//test the logging middleware keeps sensitive values sent with a request out of the logged error
+func TestLoggingMiddlewareRedactsSensitive(t *testing.T) {
+	a := assert.New(t)
+	var buf bytes.Buffer
+	sensitive := secrets.NewSensitiveKeys(sensitiveKeys{"AS2 Password"}, time.Minute)
+	failing := func(ctx context.Context, request interface{}) (interface{}, error) {
+		return nil, errors.New("could not find Id or Code from key: AS2 Password and value: hunter2")
+	}
+
+	_, err := LoggingMiddleware(log.NewLogfmtLogger(&buf), sensitive)(failing)(context.Background(), KeyValueRequest{Key: "AS2 Password", Value: "hunter2"})
+
+	a.NotNil(err)
+	a.Contains(buf.String(), secrets.Redacted)
+	a.NotContains(buf.String(), "hunter2")
+}
@@ -0,0 +1,49 @@
+package endpoints
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/metrics"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/identity"
+)
+
+// InstrumentingMiddleware returns an endpoint middleware that counts the calls to a method and observes how many
+// seconds they took, by method, transport, status code and whether the reply has an error. Replies with an error are
+// still OK as far as the status code goes. It should run outside every other middleware, so that calls they turn away
+// are counted too.
+func InstrumentingMiddleware(requests metrics.Counter, duration metrics.Histogram, method string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			defer func(begin time.Time) {
+				labels := []string{
+					"method", method,
+					"transport", identity.TransportFromContext(ctx),
+					"code", StatusCode(err).String(),
+					"error", strconv.FormatBool(err != nil || replyError(response) != ""),
+				}
+				requests.With(labels...).Add(1)
+				duration.With(labels...).Observe(time.Since(begin).Seconds())
+			}(time.Now())
+			return next(ctx, request)
+		}
+	}
+}
+
+// replyError returns the Error of a reply, which every reply has.
+func replyError(response interface{}) string {
+	v := reflect.ValueOf(response)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	if field := v.FieldByName("Error"); field.Kind() == reflect.String {
+		return field.String()
+	}
+	return ""
+}
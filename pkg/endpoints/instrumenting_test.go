@@ -0,0 +1,87 @@
+package endpoints
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/identity"
+	"google.golang.org/grpc/codes"
+)
+
+//labelCounter adds up what it is given by its label values, joined with spaces.
+type labelCounter struct {
+	counts map[string]float64
+	labels string
+}
+
+func (c labelCounter) With(labelValues ...string) metrics.Counter {
+	return labelCounter{counts: c.counts, labels: strings.Join(labelValues, " ")}
+}
+
+func (c labelCounter) Add(delta float64) {
+	c.counts[c.labels] += delta
+}
+
+//labelHistogram keeps what it observes by its label values, joined with spaces.
+type labelHistogram struct {
+	observed map[string][]float64
+	labels   string
+}
+
+func (h labelHistogram) With(labelValues ...string) metrics.Histogram {
+	return labelHistogram{observed: h.observed, labels: strings.Join(labelValues, " ")}
+}
+
+func (h labelHistogram) Observe(value float64) {
+	h.observed[h.labels] = append(h.observed[h.labels], value)
+}
+
+func TestInstrumentingMiddleware(t *testing.T) {
+	a := assert.New(t)
+	requests := labelCounter{counts: make(map[string]float64)}
+	duration := labelHistogram{observed: make(map[string][]float64)}
+	ep := InstrumentingMiddleware(requests, duration, "GetDataById")(func(ctx context.Context, request interface{}) (interface{}, error) {
+		return PartnerDataReply{Error: "could not find partner"}, nil
+	})
+
+	ep(identity.NewTransportContext(context.Background(), "http"), IdRequest{PartnerId: 1})
+	ep(context.Background(), IdRequest{PartnerId: 1})
+
+	a.Equal(map[string]float64{
+		"method GetDataById transport http code OK error true": 1,
+		"method GetDataById transport grpc code OK error true": 1,
+	}, requests.counts)
+	a.Len(duration.observed["method GetDataById transport http code OK error true"], 1)
+}
+
+func TestInstrumentingMiddlewareStatusCode(t *testing.T) {
+	a := assert.New(t)
+	requests := labelCounter{counts: make(map[string]float64)}
+	duration := labelHistogram{observed: make(map[string][]float64)}
+	ep := InstrumentingMiddleware(requests, duration, "GetDataById")(func(ctx context.Context, request interface{}) (interface{}, error) {
+		return nil, errors.Wrap(ErrPermissionDenied, "cannot read group Money")
+	})
+
+	ep(context.Background(), IdRequest{PartnerId: 1})
+
+	a.Equal(map[string]float64{"method GetDataById transport grpc code PermissionDenied error true": 1}, requests.counts)
+}
+
+func TestStatusCode(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(codes.OK, StatusCode(nil))
+	a.Equal(codes.ResourceExhausted, StatusCode(RateLimitError{Method: "GetDataById", Client: "jdoe", RetryAfter: time.Second}))
+	a.Equal(codes.Unauthenticated, StatusCode(errors.Wrap(ErrUnauthenticated, "a bearer token is required")))
+	a.Equal(codes.Aborted, StatusCode(errors.Wrap(db.ErrRevisionConflict, "partnerId: 1 is not at revision 2")))
+	a.Equal(codes.Aborted, StatusCode(ErrIdempotencyKeyInProgress))
+	a.Equal(codes.InvalidArgument, StatusCode(ErrIdempotencyKeyReused))
+	a.Equal(codes.Unavailable, StatusCode(errors.Wrap(db.ErrUnavailable, "circuit breaker is open")))
+	a.Equal(codes.Unknown, StatusCode(errors.New("test error")))
+}
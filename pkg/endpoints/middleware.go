@@ -2,21 +2,149 @@ package endpoints
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/go-kit/kit/endpoint"
 	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/identity"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/secrets"
 )
 
 // EndpointLoggingMiddleware returns an endpoint middleware that logs the
-// duration of each invocation, and the resulting error, if any.
-func LoggingMiddleware(logger log.Logger) endpoint.Middleware {
+// duration of each invocation, and the resulting error, if any. Sensitive
+// values sent with the request are redacted from the error.
+func LoggingMiddleware(logger log.Logger, sensitive *secrets.SensitiveKeys) endpoint.Middleware {
 	return func(next endpoint.Endpoint) endpoint.Endpoint {
 		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
 			defer func(begin time.Time) {
-				logger.Log("error", err, "took", time.Since(begin))
+				logger.Log("error", sensitive.RedactError(err, requestAttributes(request)), "took", time.Since(begin))
 			}(time.Now())
 			return next(ctx, request)
 		}
 	}
 }
+
+//requestAttributes returns the values of keys sent with a request.
+func requestAttributes(request interface{}) map[string]string {
+	switch req := request.(type) {
+	case KeyValueRequest:
+		return map[string]string{req.Key: req.Value}
+	case CloneRequest:
+		return req.Overrides
+	case StageChangeRequest:
+		return req.Attributes
+	}
+	return nil
+}
+
+// ErrIdempotencyKeyReused is returned when an idempotency key is sent again with a different request.
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used with a different request")
+
+// ErrIdempotencyKeyInProgress is returned when an idempotency key is sent again before the first write with it is done.
+var ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still in progress")
+
+// IdempotencyStore keeps the replies to writes sent with an idempotency key. The db querier is one.
+type IdempotencyStore interface {
+	ClaimIdempotencyKey(string, string, string, time.Time) (models.IdempotentResponse, bool, error)
+	SaveIdempotentResponse(string, string, string) error
+	ReleaseIdempotencyKey(string, string) error
+}
+
+// secretReply is a reply with secrets that are left out of the copy kept for replays.
+type secretReply interface {
+	withoutSecrets() interface{}
+}
+
+// failedReply is a reply that can carry an error in place of failing.
+type failedReply interface {
+	failed() bool
+}
+
+type idempotencyKey struct{}
+
+// NewIdempotencyContext returns a copy of ctx that carries the idempotency key sent with a request.
+func NewIdempotencyContext(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// IdempotencyMiddleware returns an endpoint middleware that serves a write once for each idempotency key sent with
+// it. A repeat within the window gets the reply to the first write, decoded into the type of reply, without the
+// write being served again. Writes sent without a key are always served. Replies with secrets are kept without them,
+// so a repeat gets the rest of the reply only. Only writes that succeeded are kept; a write that failed, even with an
+// error in its reply, gives up the key.
+func IdempotencyMiddleware(store IdempotencyStore, window time.Duration, method string, reply interface{}) endpoint.Middleware {
+	replyType := reflect.TypeOf(reply)
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			key, _ := ctx.Value(idempotencyKey{}).(string)
+			if key == "" {
+				return next(ctx, request)
+			}
+			hash, err := requestHash(ctx, request)
+			if err != nil {
+				return nil, err
+			}
+
+			stored, claimed, err := store.ClaimIdempotencyKey(key, method, hash, time.Now().Add(-window))
+			if err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("could not claim idempotency key %s", key))
+			}
+			if !claimed {
+				if stored.RequestHash.String != hash {
+					return nil, ErrIdempotencyKeyReused
+				}
+				if !stored.Response.Valid {
+					return nil, ErrIdempotencyKeyInProgress
+				}
+				replayed := reflect.New(replyType)
+				if err = json.Unmarshal([]byte(stored.Response.String), replayed.Interface()); err != nil {
+					return nil, errors.Wrap(err, fmt.Sprintf("could not read reply stored for idempotency key %s", key))
+				}
+				return replayed.Elem().Interface(), nil
+			}
+
+			//A write that fails gives up the key so it can be sent again, whether it fails outright or with an error in
+			//its reply. The error may not happen again, such as a deadlock, and a write that failed changed nothing.
+			response, err := next(ctx, request)
+			if err != nil {
+				store.ReleaseIdempotencyKey(key, method)
+				return response, err
+			}
+			if failed, ok := response.(failedReply); ok && failed.failed() {
+				store.ReleaseIdempotencyKey(key, method)
+				return response, nil
+			}
+			kept := response
+			if secret, ok := response.(secretReply); ok {
+				kept = secret.withoutSecrets()
+			}
+			encoded, err := json.Marshal(kept)
+			if err == nil {
+				err = store.SaveIdempotentResponse(key, method, string(encoded))
+			}
+			if err != nil {
+				store.ReleaseIdempotencyKey(key, method)
+			}
+			return response, nil
+		}
+	}
+}
+
+// requestHash identifies a request by its caller and fields, so a key sent again by someone else or with other
+// fields is not answered with the first reply.
+func requestHash(ctx context.Context, request interface{}) (string, error) {
+	encoded, err := json.Marshal(request)
+	if err != nil {
+		return "", errors.Wrap(err, "could not hash request")
+	}
+	caller, _ := identity.FromContext(ctx)
+	sum := sha256.Sum256(append([]byte(caller+"\n"), encoded...))
+	return hex.EncodeToString(sum[:]), nil
+}
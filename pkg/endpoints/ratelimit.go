@@ -0,0 +1,69 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/pkg/errors"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/identity"
+)
+
+// AuthenticateMethod is the method the calls from each IP address are limited under before they are authenticated, so
+// that calls with bad credentials are limited too.
+const AuthenticateMethod = "Authenticate"
+
+// RateLimiter decides whether a client can call a method now. The ratelimit limiter is one.
+type RateLimiter interface {
+	Allow(method, client string) (bool, time.Duration)
+}
+
+// RateLimitError is returned to a client that called a method more often than its limit allows.
+type RateLimitError struct {
+	Method     string
+	Client     string
+	RetryAfter time.Duration
+}
+
+func (e RateLimitError) Error() string {
+	return fmt.Sprintf("%s is over the rate limit for %s, retry after %s", e.Client, e.Method, e.RetryAfter)
+}
+
+// RetryAfter returns how long a client has to wait before calling again, if err was returned by RateLimitMiddleware.
+func RetryAfter(err error) (time.Duration, bool) {
+	limited, ok := errors.Cause(err).(RateLimitError)
+	return limited.RetryAfter, ok
+}
+
+// RateLimitMiddleware returns an endpoint middleware that limits how often each client can call the method. Clients
+// are told apart by their identity, so it goes inside AuthMiddleware, or else by their IP address.
+func RateLimitMiddleware(limiter RateLimiter, method string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			client, ok := identity.FromContext(ctx)
+			if !ok {
+				client = identity.AddrFromContext(ctx)
+			}
+			if allowed, retryAfter := limiter.Allow(method, client); !allowed {
+				return nil, RateLimitError{Method: method, Client: client, RetryAfter: retryAfter}
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// AddrRateLimitMiddleware returns an endpoint middleware that limits how often each IP address can make calls, under
+// AuthenticateMethod whatever the method. It goes outside AuthMiddleware, so a caller is limited before its credentials
+// are looked up.
+func AddrRateLimitMiddleware(limiter RateLimiter) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			client := identity.AddrFromContext(ctx)
+			if allowed, retryAfter := limiter.Allow(AuthenticateMethod, client); !allowed {
+				return nil, RateLimitError{Method: AuthenticateMethod, Client: client, RetryAfter: retryAfter}
+			}
+			return next(ctx, request)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package endpoints
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/identity"
+)
+
+//clientLimiter allows the clients in it and makes the rest wait a second, remembering who it was asked about.
+type clientLimiter struct {
+	allowed map[string]bool
+	asked   []string
+}
+
+func (l *clientLimiter) Allow(method, client string) (bool, time.Duration) {
+	l.asked = append(l.asked, method+" "+client)
+	if l.allowed[client] {
+		return true, 0
+	}
+	return false, time.Second
+}
+
+func TestRateLimitMiddlewareAllowed(t *testing.T) {
+	a := assert.New(t)
+	limiter := &clientLimiter{allowed: map[string]bool{"jdoe": true}}
+
+	res, err := RateLimitMiddleware(limiter, "GetDataById")(callerEndpoint)(identity.NewContext(context.Background(), "jdoe"), nil)
+
+	a.Nil(err)
+	a.Equal("jdoe", res)
+	a.Equal([]string{"GetDataById jdoe"}, limiter.asked)
+}
+
+func TestRateLimitMiddlewareLimited(t *testing.T) {
+	a := assert.New(t)
+	limiter := &clientLimiter{}
+
+	res, err := RateLimitMiddleware(limiter, "GetDataById")(callerEndpoint)(identity.NewContext(context.Background(), "jdoe"), nil)
+
+	a.Nil(res)
+	retryAfter, ok := RetryAfter(err)
+	a.True(ok)
+	a.Equal(time.Second, retryAfter)
+}
+
+func TestRateLimitMiddlewareByAddr(t *testing.T) {
+	a := assert.New(t)
+	limiter := &clientLimiter{allowed: map[string]bool{"10.0.0.7": true}}
+
+	_, err := RateLimitMiddleware(limiter, "GetDataById")(callerEndpoint)(identity.NewAddrContext(context.Background(), "10.0.0.7"), nil)
+
+	a.Nil(err)
+	a.Equal([]string{"GetDataById 10.0.0.7"}, limiter.asked)
+}
+
+func TestAddrRateLimitMiddlewareBeforeAuth(t *testing.T) {
+	a := assert.New(t)
+	limiter := &clientLimiter{}
+	authenticated := false
+	auth := func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			authenticated = true
+			return next(ctx, request)
+		}
+	}
+
+	_, err := AddrRateLimitMiddleware(limiter)(auth(callerEndpoint))(identity.NewAddrContext(context.Background(), "10.0.0.7"), nil)
+
+	_, ok := RetryAfter(err)
+	a.True(ok)
+	a.False(authenticated)
+	a.Equal([]string{"Authenticate 10.0.0.7"}, limiter.asked)
+}
+
+func TestRetryAfterOtherError(t *testing.T) {
+	_, ok := RetryAfter(errors.New("test error"))
+
+	assert.False(t, ok)
+}
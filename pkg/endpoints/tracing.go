@@ -0,0 +1,30 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"go.opentelemetry.io/otel/attribute"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/secrets"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/tracing"
+)
+
+var tracer = tracing.Tracer("endpoints")
+
+// TracingMiddleware returns an endpoint middleware that records a span for each call to a method, with its status
+// code. Values of sensitive keys are redacted from the error of a failed call.
+func TracingMiddleware(sensitive *secrets.SensitiveKeys, method string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			ctx, span := tracer.Start(ctx, "endpoint."+method)
+			defer func() {
+				span.SetAttributes(attribute.String("rpc.grpc.status_code", StatusCode(err).String()))
+				if err != nil {
+					tracing.Fail(span, sensitive.RedactError(err, requestAttributes(request)).Error())
+				}
+				span.End()
+			}()
+			return next(ctx, request)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package endpoints
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracingMiddlewareRedactsErrors(t *testing.T) {
+	a := assert.New(t)
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	ep := TracingMiddleware(passwordSensitive, "GetPartnerDataByKeyValue")(func(ctx context.Context, request interface{}) (interface{}, error) {
+		return nil, errors.Wrap(ErrPermissionDenied, "cannot read AS2 Password: hunter2")
+	})
+
+	ep(context.Background(), KeyValueRequest{Key: "AS2 Password", Value: "hunter2"})
+
+	spans := recorder.Ended()
+	a.Len(spans, 1)
+	a.Equal("endpoint.GetPartnerDataByKeyValue", spans[0].Name())
+	a.Equal(codes.Error, spans[0].Status().Code)
+	a.Equal("cannot read AS2 Password: [REDACTED]: permission denied", spans[0].Status().Description)
+	a.Contains(spans[0].Attributes(), attribute.String("rpc.grpc.status_code", "PermissionDenied"))
+}
@@ -0,0 +1,199 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/pb"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// csvHeader holds the fixed leading columns of a CSV export. Every column after them is a key. Exports from before
+// partners had a status have no status column, and are read with the partners' status left empty.
+var csvHeader = []string{"id", "name", "code", "status"}
+
+// Partner is how a partner is written in JSON and YAML exports.
+type Partner struct {
+	Id         int32             `json:"id,omitempty" yaml:"id,omitempty"`
+	Name       string            `json:"name" yaml:"name"`
+	Code       string            `json:"code" yaml:"code"`
+	Status     string            `json:"status,omitempty" yaml:"status,omitempty"`
+	Attributes map[string]string `json:"attributes" yaml:"attributes"`
+}
+
+// Write writes the partners to w in the given format. CSV has one row per partner and one column per key,
+// with an empty cell where a partner has no value for that key. An empty value could not be told apart from no value,
+// so CSV cannot hold partners with empty values; they have to be exported as JSON or YAML.
+func Write(w io.Writer, format string, partners []*pb.Partner) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, partners)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return errors.Wrap(enc.Encode(toDocs(partners)), "failed to write json export")
+	case FormatYAML:
+		b, err := yaml.Marshal(toDocs(partners))
+		if err != nil {
+			return errors.Wrap(err, "failed to write yaml export")
+		}
+		_, err = w.Write(b)
+		return err
+	}
+	return errors.New(fmt.Sprintf("unknown export format: %s", format))
+}
+
+// Read parses an export written by Write. Ids are read back but the import matches partners on code.
+func Read(r io.Reader, format string) ([]*pb.Partner, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r)
+	case FormatJSON:
+		var docs []Partner
+		if err := json.NewDecoder(r).Decode(&docs); err != nil {
+			return nil, errors.Wrap(err, "failed to read json export")
+		}
+		return fromDocs(docs)
+	case FormatYAML:
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read yaml export")
+		}
+		var docs []Partner
+		if err = yaml.Unmarshal(b, &docs); err != nil {
+			return nil, errors.Wrap(err, "failed to read yaml export")
+		}
+		return fromDocs(docs)
+	}
+	return nil, errors.New(fmt.Sprintf("unknown export format: %s", format))
+}
+
+// Keys returns every key used by the partners, sorted.
+func Keys(partners []*pb.Partner) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, p := range partners {
+		for key := range p.Attributes {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeCSV(w io.Writer, partners []*pb.Partner) error {
+	keys := Keys(partners)
+	for _, p := range partners {
+		for _, key := range keys {
+			if value, ok := p.Attributes[key]; ok && value == "" {
+				return errors.New(fmt.Sprintf("partner %s has an empty %s, which csv cannot tell from no value; export as json or yaml", p.Code, key))
+			}
+		}
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append(append([]string{}, csvHeader...), keys...)); err != nil {
+		return errors.Wrap(err, "failed to write csv header")
+	}
+	for _, p := range partners {
+		row := []string{strconv.Itoa(int(p.Id)), p.Name, p.Code, p.Status}
+		for _, key := range keys {
+			row = append(row, p.Attributes[key])
+		}
+		if err := cw.Write(row); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to write csv row for partner: %s", p.Code))
+		}
+	}
+	cw.Flush()
+	return errors.Wrap(cw.Error(), "failed to write csv export")
+}
+
+func readCSV(r io.Reader) ([]*pb.Partner, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read csv export")
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("csv export is empty")
+	}
+	header := rows[0]
+	fixed := csvHeader
+	if len(header) < len(fixed) || header[len(fixed)-1] != "status" {
+		fixed = csvHeader[:len(csvHeader)-1]
+	}
+	if len(header) < len(fixed) {
+		return nil, errors.New(fmt.Sprintf("csv header must start with %v", csvHeader))
+	}
+	for i, col := range fixed {
+		if header[i] != col {
+			return nil, errors.New(fmt.Sprintf("csv header must start with %v", csvHeader))
+		}
+	}
+
+	var partners []*pb.Partner
+	for line, row := range rows[1:] {
+		id := 0
+		if row[0] != "" {
+			id, err = strconv.Atoi(row[0])
+			if err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("bad id on csv line %d", line+2))
+			}
+		}
+		p := &pb.Partner{Id: int32(id), Name: row[1], Code: row[2], Attributes: make(map[string]string)}
+		if len(fixed) == len(csvHeader) {
+			p.Status = row[3]
+		}
+		if p.Code == "" {
+			return nil, errors.New(fmt.Sprintf("missing code on csv line %d", line+2))
+		}
+		for i, key := range header[len(fixed):] {
+			//An empty cell means the partner has no value for the key.
+			if value := row[len(fixed)+i]; value != "" {
+				p.Attributes[key] = value
+			}
+		}
+		partners = append(partners, p)
+	}
+	return partners, nil
+}
+
+func toDocs(partners []*pb.Partner) []Partner {
+	docs := make([]Partner, 0, len(partners))
+	for _, p := range partners {
+		attrs := p.Attributes
+		if attrs == nil {
+			attrs = make(map[string]string)
+		}
+		docs = append(docs, Partner{Id: p.Id, Name: p.Name, Code: p.Code, Status: p.Status, Attributes: attrs})
+	}
+	return docs
+}
+
+func fromDocs(docs []Partner) ([]*pb.Partner, error) {
+	partners := make([]*pb.Partner, 0, len(docs))
+	for i, d := range docs {
+		if d.Code == "" {
+			return nil, errors.New(fmt.Sprintf("missing code for partner %d", i+1))
+		}
+		attrs := d.Attributes
+		if attrs == nil {
+			attrs = make(map[string]string)
+		}
+		partners = append(partners, &pb.Partner{Id: d.Id, Name: d.Name, Code: d.Code, Status: d.Status, Attributes: attrs})
+	}
+	return partners, nil
+}
@@ -0,0 +1,104 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/pb"
+)
+
+func testPartners() []*pb.Partner {
+	return []*pb.Partner{
+		{Id: 1, Name: "Kohls", Code: "KOH", Status: "active", Attributes: map[string]string{"Currency": "USD", "ISAID": "KOHLS, INC"}},
+		{Id: 2, Name: "JC Penny", Code: "JCP", Status: "suspended", Attributes: map[string]string{"Currency": "CAD", "Qualifier": "ZZ"}},
+		{Id: 3, Name: "Mustang", Code: "MUS", Status: "onboarding", Attributes: map[string]string{}},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range []string{FormatCSV, FormatJSON, FormatYAML} {
+		var buf bytes.Buffer
+
+		err := Write(&buf, format, testPartners())
+		assert.Nil(t, err, format)
+
+		partners, err := Read(&buf, format)
+		assert.Nil(t, err, format)
+		assert.Equal(t, testPartners(), partners, format)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := Write(&buf, FormatCSV, testPartners())
+
+	assert.Nil(t, err)
+	assert.Equal(t, "id,name,code,status,Currency,ISAID,Qualifier\n1,Kohls,KOH,active,USD,\"KOHLS, INC\",\n2,JC Penny,JCP,suspended,CAD,,ZZ\n3,Mustang,MUS,onboarding,,,\n", buf.String())
+}
+
+func TestReadCSVWithoutStatus(t *testing.T) {
+	partners, err := Read(strings.NewReader("id,name,code,Currency\n1,Kohls,KOH,USD\n"), FormatCSV)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*pb.Partner{{Id: 1, Name: "Kohls", Code: "KOH", Attributes: map[string]string{"Currency": "USD"}}}, partners)
+}
+
+func TestWriteCSVEmptyValue(t *testing.T) {
+	var buf bytes.Buffer
+	partners := []*pb.Partner{{Name: "Kohls", Code: "KOH", Attributes: map[string]string{"Currency": ""}}}
+
+	err := Write(&buf, FormatCSV, partners)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "", buf.String())
+}
+
+func TestRoundTripEmptyValue(t *testing.T) {
+	partners := []*pb.Partner{{Name: "Kohls", Code: "KOH", Attributes: map[string]string{"Currency": ""}}}
+	for _, format := range []string{FormatJSON, FormatYAML} {
+		var buf bytes.Buffer
+
+		err := Write(&buf, format, partners)
+		assert.Nil(t, err, format)
+
+		read, err := Read(&buf, format)
+		assert.Nil(t, err, format)
+		assert.Equal(t, partners, read, format)
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := Write(&buf, "xml", testPartners())
+
+	assert.NotNil(t, err)
+}
+
+func TestReadCSVBadHeader(t *testing.T) {
+	partners, err := Read(strings.NewReader("code,name\nKOH,Kohls\n"), FormatCSV)
+
+	assert.Nil(t, partners)
+	assert.NotNil(t, err)
+}
+
+func TestReadCSVMissingCode(t *testing.T) {
+	partners, err := Read(strings.NewReader("id,name,code,Currency\n1,Kohls,,USD\n"), FormatCSV)
+
+	assert.Nil(t, partners)
+	assert.NotNil(t, err)
+}
+
+func TestReadJSONMissingCode(t *testing.T) {
+	partners, err := Read(strings.NewReader(`[{"name": "Kohls"}]`), FormatJSON)
+
+	assert.Nil(t, partners)
+	assert.NotNil(t, err)
+}
+
+func TestKeys(t *testing.T) {
+	assert.Equal(t, []string{"Currency", "ISAID", "Qualifier"}, Keys(testPartners()))
+}
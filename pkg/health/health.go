@@ -0,0 +1,121 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Services are the names the grpc health service reports on; "" is the server as a whole.
+var Services = []string{"", "pb.PartnerService"}
+
+// ErrShuttingDown is why a Checker is not ready once Shutdown has been called.
+var ErrShuttingDown = errors.New("shutting down")
+
+// Querier is the part of the database querier the readiness check uses.
+type Querier interface {
+	Ping() error
+	FindSchemaVersion() (int32, error)
+}
+
+// Checker says whether the service is ready for calls: the database answers and its schema is the version the code
+// works with.
+type Checker struct {
+	querier Querier
+	version int32
+
+	mtx          sync.Mutex
+	shuttingDown bool
+}
+
+// NewChecker returns a Checker that expects the schema to be at version.
+func NewChecker(querier Querier, version int32) *Checker {
+	return &Checker{querier: querier, version: version}
+}
+
+// Ready returns why the service is not ready, or nil if it is.
+func (c *Checker) Ready() error {
+	if c.isShuttingDown() {
+		return ErrShuttingDown
+	}
+	if err := c.querier.Ping(); err != nil {
+		return errors.Wrap(err, "database is not answering")
+	}
+	version, err := c.querier.FindSchemaVersion()
+	if err != nil {
+		return errors.Wrap(err, "failed to find the schema version")
+	}
+	if version != c.version {
+		return fmt.Errorf("schema is at version %d, expected %d", version, c.version)
+	}
+	return nil
+}
+
+// Watch sets the status of Services on the grpc health server from Ready every interval, until ctx is done.
+func (c *Checker) Watch(ctx context.Context, server *health.Server, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		status := healthpb.HealthCheckResponse_SERVING
+		if c.Ready() != nil {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		c.mtx.Lock()
+		if c.shuttingDown {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		for _, service := range Services {
+			server.SetServingStatus(service, status)
+		}
+		c.mtx.Unlock()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown marks the service as not ready for good, on the grpc health server and at the readiness handler, so
+// that load balancers stop sending calls while the ones in flight finish.
+func (c *Checker) Shutdown(server *health.Server) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.shuttingDown = true
+	for _, service := range Services {
+		server.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+}
+
+func (c *Checker) isShuttingDown() bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.shuttingDown
+}
+
+// LivenessHandler answers 200 as long as the process is serving http, whatever the state of the database.
+func LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprintln(w, "ok")
+	})
+}
+
+// ReadinessHandler answers 200 if the Checker is ready, or 503 with the reason it is not.
+func ReadinessHandler(c *Checker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		if err := c.Ready(); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintln(w, err)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+}
@@ -0,0 +1,106 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+//fakeQuerier answers Ping with pingErr and FindSchemaVersion with version
+type fakeQuerier struct {
+	pingErr error
+	version int32
+}
+
+func (q *fakeQuerier) Ping() error {
+	return q.pingErr
+}
+
+func (q *fakeQuerier) FindSchemaVersion() (int32, error) {
+	return q.version, nil
+}
+
+func TestReady(t *testing.T) {
+	a := assert.New(t)
+	q := &fakeQuerier{version: 2}
+	c := NewChecker(q, 2)
+
+	a.Nil(c.Ready())
+
+	q.version = 1
+	a.EqualError(c.Ready(), "schema is at version 1, expected 2")
+
+	q.pingErr = errors.New("connection refused")
+	a.EqualError(c.Ready(), "database is not answering: connection refused")
+}
+
+func TestReadinessHandler(t *testing.T) {
+	a := assert.New(t)
+	q := &fakeQuerier{version: 1}
+	h := ReadinessHandler(NewChecker(q, 1))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	a.Equal(http.StatusOK, w.Code)
+
+	q.pingErr = errors.New("connection refused")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	a.Equal(http.StatusServiceUnavailable, w.Code)
+	a.Contains(w.Body.String(), "connection refused")
+}
+
+func TestLivenessHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	LivenessHandler().ServeHTTP(w, httptest.NewRequest("GET", "/healthz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestWatch(t *testing.T) {
+	a := assert.New(t)
+	server := health.NewServer()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		NewChecker(&fakeQuerier{version: 0}, 1).Watch(ctx, server, time.Hour)
+		close(done)
+	}()
+	//wait for the first check to be recorded
+	var resp *healthpb.HealthCheckResponse
+	for resp == nil {
+		resp, _ = server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "pb.PartnerService"})
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	a.Equal(healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+}
+
+func TestShutdown(t *testing.T) {
+	a := assert.New(t)
+	server := health.NewServer()
+	c := NewChecker(&fakeQuerier{version: 1}, 1)
+	a.Nil(c.Ready())
+
+	c.Shutdown(server)
+
+	a.Equal(ErrShuttingDown, c.Ready())
+	resp, err := server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: ""})
+	a.Nil(err)
+	a.Equal(healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+
+	//a check that was already running cannot mark it serving again
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c.Watch(ctx, server, time.Hour)
+	resp, _ = server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "pb.PartnerService"})
+	a.Equal(healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+}
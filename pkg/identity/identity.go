@@ -0,0 +1,108 @@
+package identity
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+type contextKey int
+
+const (
+	callerKey contextKey = iota
+	rolesKey
+	requestIDKey
+	addrKey
+	transportKey
+)
+
+// NewContext returns a copy of ctx that carries the name of the caller.
+func NewContext(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey, caller)
+}
+
+// FromContext returns the name of the caller, and false if ctx does not carry one.
+func FromContext(ctx context.Context) (string, bool) {
+	caller, ok := ctx.Value(callerKey).(string)
+	return caller, ok && caller != ""
+}
+
+// apiKeyPrefix starts the names of callers who authenticate with an API key.
+const apiKeyPrefix = "apikey:"
+
+// APIKeyCaller returns the name callers who authenticate with the API key get, which keeps them apart from the people
+// and services the key is issued to.
+func APIKeyCaller(id int32) string {
+	return fmt.Sprintf("%s%d", apiKeyPrefix, id)
+}
+
+// IsAPIKeyCaller reports whether the caller authenticated with an API key. Tokens and certificates cannot name such a
+// caller.
+func IsAPIKeyCaller(caller string) bool {
+	return strings.HasPrefix(caller, apiKeyPrefix)
+}
+
+// NewRolesContext returns a copy of ctx that carries the roles of the caller.
+func NewRolesContext(ctx context.Context, roles []string) context.Context {
+	return context.WithValue(ctx, rolesKey, roles)
+}
+
+// RolesFromContext returns the roles of the caller, if any.
+func RolesFromContext(ctx context.Context) []string {
+	roles, _ := ctx.Value(rolesKey).([]string)
+	return roles
+}
+
+// NewRequestIDContext returns a copy of ctx that carries the ID of the request being served for the caller.
+func NewRequestIDContext(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// NewRequestID returns a random ID for a request that was not sent with one.
+func NewRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RequestIDFromContext returns the ID of the request being served, if any.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// NewAddrContext returns a copy of ctx that carries the IP address the request came from.
+func NewAddrContext(ctx context.Context, addr string) context.Context {
+	return context.WithValue(ctx, addrKey, addr)
+}
+
+// AddrFromContext returns the IP address the request came from, if it is known.
+func AddrFromContext(ctx context.Context) string {
+	addr, _ := ctx.Value(addrKey).(string)
+	return addr
+}
+
+// NewTransportContext returns a copy of ctx that carries the transport the request came in on, such as grpc or http.
+func NewTransportContext(ctx context.Context, transport string) context.Context {
+	return context.WithValue(ctx, transportKey, transport)
+}
+
+// TransportFromContext returns the transport the request came in on, or grpc if it is not known.
+func TransportFromContext(ctx context.Context) string {
+	if transport, _ := ctx.Value(transportKey).(string); transport != "" {
+		return transport
+	}
+	return "grpc"
+}
+
+// CertificateSubject returns the caller named by a client certificate, which is the common name of its subject, or
+// the whole subject if it has no common name.
+func CertificateSubject(cert *x509.Certificate) string {
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+	return cert.Subject.String()
+}
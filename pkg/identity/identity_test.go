@@ -0,0 +1,64 @@
+package identity
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromContext(t *testing.T) {
+	ctx := NewContext(context.Background(), "jdoe")
+
+	caller, ok := FromContext(ctx)
+
+	assert.True(t, ok)
+	assert.Equal(t, "jdoe", caller)
+}
+
+func TestAPIKeyCaller(t *testing.T) {
+	assert.Equal(t, "apikey:7", APIKeyCaller(7))
+	assert.True(t, IsAPIKeyCaller(APIKeyCaller(7)))
+	assert.False(t, IsAPIKeyCaller("jdoe"))
+}
+
+func TestFromContextMissing(t *testing.T) {
+	caller, ok := FromContext(context.Background())
+
+	assert.False(t, ok)
+	assert.Equal(t, "", caller)
+}
+
+func TestFromContextEmpty(t *testing.T) {
+	_, ok := FromContext(NewContext(context.Background(), ""))
+
+	assert.False(t, ok)
+}
+
+func TestRolesFromContext(t *testing.T) {
+	ctx := NewRolesContext(context.Background(), []string{"edi"})
+
+	assert.Equal(t, []string{"edi"}, RolesFromContext(ctx))
+	assert.Nil(t, RolesFromContext(context.Background()))
+}
+
+func TestRequestIDFromContext(t *testing.T) {
+	ctx := NewRequestIDContext(context.Background(), "4bf92f35")
+
+	assert.Equal(t, "4bf92f35", RequestIDFromContext(ctx))
+	assert.Equal(t, "", RequestIDFromContext(context.Background()))
+}
+
+func TestCertificateSubject(t *testing.T) {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "onboarding-pipeline", Organization: []string{"Fanatics"}}}
+
+	assert.Equal(t, "onboarding-pipeline", CertificateSubject(cert))
+}
+
+func TestCertificateSubjectNoCommonName(t *testing.T) {
+	cert := &x509.Certificate{Subject: pkix.Name{Organization: []string{"Fanatics"}}}
+
+	assert.Equal(t, "O=Fanatics", CertificateSubject(cert))
+}
@@ -0,0 +1,41 @@
+package openapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+)
+
+//go:embed explorer.html
+var explorerPage string
+
+var explorer = template.Must(template.New("explorer").Parse(explorerPage))
+
+// SpecHandler serves spec as JSON. It is tagged with a hash of its contents, so that browsers can keep it until the
+// binary changes.
+func SpecHandler(spec []byte) http.Handler {
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256(spec))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", etag)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(spec))
+	})
+}
+
+// ExplorerHandler serves a page for reading the spec at specURL and trying its calls from a browser. The page is
+// self-contained, so that it works without reaching anything but this service.
+func ExplorerHandler(specURL string) http.Handler {
+	var page bytes.Buffer
+	if err := explorer.Execute(&page, struct{ SpecURL string }{specURL}); err != nil {
+		panic(err)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'unsafe-inline'; style-src 'unsafe-inline'")
+		w.Write(page.Bytes())
+	})
+}
@@ -0,0 +1,38 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpecHandler(t *testing.T) {
+	a := assert.New(t)
+	h := SpecHandler([]byte(`{"openapi": "3.0.3"}`))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/openapi.json", nil))
+	a.Equal(http.StatusOK, w.Code)
+	a.Equal("application/json", w.Header().Get("Content-Type"))
+	a.Equal(`{"openapi": "3.0.3"}`, w.Body.String())
+
+	r := httptest.NewRequest("GET", "/api/v1/openapi.json", nil)
+	r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	a.Equal(http.StatusNotModified, w.Code)
+}
+
+func TestExplorerHandler(t *testing.T) {
+	a := assert.New(t)
+	h := ExplorerHandler("/api/v1/openapi.json")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/docs", nil))
+	a.Equal(http.StatusOK, w.Code)
+	a.Equal("text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	a.Contains(w.Body.String(), `var specURL = "/api/v1/openapi.json";`)
+	a.NotContains(w.Body.String(), "<script src=")
+}
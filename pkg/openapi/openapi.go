@@ -0,0 +1,361 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Version is the OpenAPI version of the documents Convert makes.
+const Version = "3.0.3"
+
+// Info describes the API, in place of the info protoc-gen-swagger makes up from the proto file.
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// streamingDescription is how protoc-gen-swagger describes the reply of a call that streams.
+const streamingDescription = "(streaming responses)"
+
+// swagger is the part of a Swagger 2.0 document from protoc-gen-swagger that Convert reads.
+type swagger struct {
+	Swagger     string                                 `json:"swagger"`
+	Consumes    []string                               `json:"consumes"`
+	Produces    []string                               `json:"produces"`
+	Paths       map[string]map[string]swaggerOperation `json:"paths"`
+	Definitions map[string]json.RawMessage             `json:"definitions"`
+}
+
+type swaggerOperation struct {
+	OperationID string                     `json:"operationId"`
+	Summary     string                     `json:"summary"`
+	Description string                     `json:"description"`
+	Parameters  []swaggerParameter         `json:"parameters"`
+	Responses   map[string]swaggerResponse `json:"responses"`
+	Tags        []string                   `json:"tags"`
+	Consumes    []string                   `json:"consumes"`
+	Produces    []string                   `json:"produces"`
+}
+
+type swaggerParameter struct {
+	Name        string          `json:"name"`
+	In          string          `json:"in"`
+	Description string          `json:"description"`
+	Required    bool            `json:"required"`
+	Schema      json.RawMessage `json:"schema"`
+
+	//the schema of parameters not in the body
+	Type    string            `json:"type"`
+	Format  string            `json:"format"`
+	Items   json.RawMessage   `json:"items"`
+	Enum    []json.RawMessage `json:"enum"`
+	Default json.RawMessage   `json:"default"`
+}
+
+type swaggerResponse struct {
+	Description string          `json:"description"`
+	Schema      json.RawMessage `json:"schema"`
+}
+
+// document is an OpenAPI 3 document.
+type document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Security   []map[string][]string            `json:"security"`
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components components                       `json:"components"`
+}
+
+type operation struct {
+	Tags        []string             `json:"tags,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	OperationID string               `json:"operationId"`
+	Parameters  []parameter          `json:"parameters,omitempty"`
+	RequestBody *requestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*response `json:"responses"`
+}
+
+type parameter struct {
+	Name        string          `json:"name"`
+	In          string          `json:"in"`
+	Description string          `json:"description,omitempty"`
+	Required    bool            `json:"required,omitempty"`
+	Schema      json.RawMessage `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]mediaType `json:"content"`
+}
+
+type response struct {
+	Ref         string               `json:"$ref,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Headers     map[string]header    `json:"headers,omitempty"`
+	Content     map[string]mediaType `json:"content,omitempty"`
+}
+
+type header struct {
+	Description string          `json:"description"`
+	Schema      json.RawMessage `json:"schema"`
+}
+
+type mediaType struct {
+	Schema json.RawMessage `json:"schema"`
+}
+
+type components struct {
+	Schemas         map[string]json.RawMessage `json:"schemas"`
+	Responses       map[string]*response       `json:"responses"`
+	SecuritySchemes map[string]securityScheme  `json:"securitySchemes"`
+}
+
+type securityScheme struct {
+	Type         string `json:"type"`
+	Description  string `json:"description"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+}
+
+// errorResponse is an error the gateway answers every call with, by status code.
+type errorResponse struct {
+	status      string
+	name        string
+	description string
+}
+
+// errorResponses are the errors from endpoints.StatusCode, as the gateway answers them. Any other error is the
+// default response.
+var errorResponses = []errorResponse{
+	{"400", "BadRequest", "The request is not valid, or its Idempotency-Key was sent before with another request."},
+	{"401", "Unauthorized", "The call has no credentials, or they are not valid."},
+	{"403", "Forbidden", "The caller's roles do not allow the call."},
+	{"409", "Conflict", "The partner changed since the revision the call expected, or a call with the same Idempotency-Key is still in progress."},
+	{"429", "TooManyRequests", "The caller is over its rate limit. Retry-After says how many seconds to wait."},
+	{"503", "ServiceUnavailable", "The database could not be reached. The call can be retried."},
+	{"default", "Error", "Any other error."},
+}
+
+// preconditionFailed is the answer to a write whose If-Match is not an ETag from this service.
+const preconditionFailed = "PreconditionFailed"
+
+// Convert makes an OpenAPI 3 document from the Swagger 2.0 document protoc-gen-swagger generates for the gateway,
+// with info in place of the info it made up. The errors the gateway answers with are added to every operation, with
+// their schemas, along with the ways callers authenticate.
+func Convert(in []byte, info Info) ([]byte, error) {
+	var s swagger
+	if err := json.Unmarshal(in, &s); err != nil {
+		return nil, errors.Wrap(err, "failed to read swagger document")
+	}
+	if s.Swagger != "2.0" {
+		return nil, errors.Errorf("swagger version %q is not 2.0", s.Swagger)
+	}
+
+	doc := document{
+		OpenAPI:  Version,
+		Info:     info,
+		Security: []map[string][]string{{"bearer": {}}, {"apiKey": {}}},
+		Paths:    map[string]map[string]*operation{},
+		Components: components{
+			Schemas:   map[string]json.RawMessage{},
+			Responses: map[string]*response{},
+			SecuritySchemes: map[string]securityScheme{
+				"bearer": {
+					Type:         "http",
+					Description:  "A JWT signed with the service's HMAC secret or a key in its JWKS, in the Authorization header.",
+					Scheme:       "bearer",
+					BearerFormat: "JWT",
+				},
+				"apiKey": {
+					Type:        "apiKey",
+					Description: "A key issued by IssueApiKey, in the X-Api-Key header.",
+					In:          "header",
+					Name:        "X-Api-Key",
+				},
+			},
+		},
+	}
+
+	for name, schema := range s.Definitions {
+		doc.Components.Schemas[name] = ref(schema)
+	}
+	addErrors(&doc.Components)
+
+	for path, operations := range s.Paths {
+		doc.Paths[path] = map[string]*operation{}
+		for method, op := range operations {
+			converted, err := convertOperation(s, method, op)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to convert %s %s", strings.ToUpper(method), path)
+			}
+			doc.Paths[path][method] = converted
+		}
+	}
+
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to write openapi document")
+	}
+	return append(out, '\n'), nil
+}
+
+func convertOperation(s swagger, method string, op swaggerOperation) (*operation, error) {
+	converted := &operation{
+		Tags:        op.Tags,
+		Summary:     op.Summary,
+		Description: op.Description,
+		OperationID: op.OperationID,
+		Responses:   map[string]*response{},
+	}
+
+	consumes := op.Consumes
+	if len(consumes) == 0 {
+		consumes = s.Consumes
+	}
+	for _, p := range op.Parameters {
+		if p.In == "body" {
+			body := &requestBody{Required: p.Required, Content: map[string]mediaType{}}
+			for _, contentType := range consumes {
+				body.Content[contentType] = mediaType{Schema: ref(p.Schema)}
+			}
+			converted.RequestBody = body
+			continue
+		}
+		schema, err := parameterSchema(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to convert parameter %s", p.Name)
+		}
+		converted.Parameters = append(converted.Parameters, parameter{
+			Name:        p.Name,
+			In:          p.In,
+			Description: p.Description,
+			Required:    p.Required || p.In == "path",
+			Schema:      schema,
+		})
+	}
+
+	produces := op.Produces
+	if len(produces) == 0 {
+		produces = s.Produces
+	}
+	for status, r := range op.Responses {
+		converted.Responses[status] = convertResponse(r, produces)
+	}
+
+	for _, e := range errorResponses {
+		converted.Responses[e.status] = &response{Ref: "#/components/responses/" + e.name}
+	}
+	if method == "post" || method == "put" || method == "patch" || method == "delete" {
+		converted.Responses["412"] = &response{Ref: "#/components/responses/" + preconditionFailed}
+	}
+	return converted, nil
+}
+
+// convertResponse converts a reply. A streamed reply is a JSON object per line, each with either a result or the
+// error that ended the stream.
+func convertResponse(r swaggerResponse, produces []string) *response {
+	converted := &response{Description: r.Description}
+	if r.Schema == nil {
+		if converted.Description == "" {
+			converted.Description = "OK"
+		}
+		return converted
+	}
+	schema := ref(r.Schema)
+	switch {
+	case r.Description == streamingDescription:
+		converted.Description = "A stream of JSON objects, one per line, each with a result or the error that ended the stream."
+		schema = mustMarshal(map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"result": json.RawMessage(schema),
+				"error":  map[string]string{"$ref": "#/components/schemas/StreamError"},
+			},
+		})
+	case converted.Description == "":
+		converted.Description = "OK"
+	}
+	converted.Content = map[string]mediaType{}
+	for _, contentType := range produces {
+		converted.Content[contentType] = mediaType{Schema: schema}
+	}
+	return converted
+}
+
+// parameterSchema moves the schema of a query or path parameter, which Swagger 2.0 keeps on the parameter itself,
+// into a schema of its own.
+func parameterSchema(p swaggerParameter) (json.RawMessage, error) {
+	if p.Type == "" {
+		return nil, errors.New("parameter has no type")
+	}
+	schema := map[string]interface{}{"type": p.Type}
+	if p.Format != "" {
+		schema["format"] = p.Format
+	}
+	if p.Items != nil {
+		schema["items"] = json.RawMessage(ref(p.Items))
+	}
+	if p.Enum != nil {
+		schema["enum"] = p.Enum
+	}
+	if p.Default != nil {
+		schema["default"] = p.Default
+	}
+	return mustMarshal(schema), nil
+}
+
+// addErrors adds the schemas of the gateway's error replies and the responses that use them.
+func addErrors(c *components) {
+	c.Schemas["Error"] = mustMarshal(map[string]interface{}{
+		"type":     "object",
+		"required": []string{"error", "code"},
+		"properties": map[string]interface{}{
+			"error": map[string]string{"type": "string", "description": "What went wrong."},
+			"code":  map[string]string{"type": "integer", "format": "int32", "description": "The gRPC status code of the error."},
+		},
+	})
+	c.Schemas["StreamError"] = mustMarshal(map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"grpc_code":   map[string]string{"type": "integer", "format": "int32", "description": "The gRPC status code of the error."},
+			"http_code":   map[string]string{"type": "integer", "format": "int32", "description": "The http status code the error would have had."},
+			"message":     map[string]string{"type": "string", "description": "What went wrong."},
+			"http_status": map[string]string{"type": "string", "description": "The text of the http status."},
+		},
+	})
+
+	errorContent := map[string]mediaType{"application/json": {Schema: mustMarshal(map[string]string{"$ref": "#/components/schemas/Error"})}}
+	for _, e := range errorResponses {
+		c.Responses[e.name] = &response{Description: e.description, Content: errorContent}
+	}
+	c.Responses["TooManyRequests"].Headers = map[string]header{
+		"Retry-After": {
+			Description: "The seconds to wait before calling again.",
+			Schema:      mustMarshal(map[string]string{"type": "integer"}),
+		},
+	}
+	c.Responses[preconditionFailed] = &response{
+		Description: "The If-Match header is not a single ETag from this service.",
+		Content:     map[string]mediaType{"text/plain": {Schema: mustMarshal(map[string]string{"type": "string"})}},
+	}
+}
+
+// ref points the references in a Swagger 2.0 schema at the components of an OpenAPI 3 document.
+func ref(schema json.RawMessage) json.RawMessage {
+	return bytes.Replace(schema, []byte(`"#/definitions/`), []byte(`"#/components/schemas/`), -1)
+}
+
+func mustMarshal(v interface{}) json.RawMessage {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -0,0 +1,132 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const swaggerDoc = `{
+  "swagger": "2.0",
+  "info": {"title": "pkg/pb/partner_service.proto", "version": "version not set"},
+  "consumes": ["application/json"],
+  "produces": ["application/json"],
+  "paths": {
+    "/ws/v1/partners/clone": {
+      "post": {
+        "operationId": "ClonePartner",
+        "responses": {"200": {"description": "", "schema": {"$ref": "#/definitions/pbPartnerDataReply"}}},
+        "parameters": [{"name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/pbCloneRequest"}}],
+        "tags": ["PartnerService"]
+      }
+    },
+    "/ws/v1/partners/export": {
+      "get": {
+        "operationId": "ExportPartners",
+        "responses": {"200": {"description": "(streaming responses)", "schema": {"$ref": "#/definitions/pbPartner"}}},
+        "parameters": [
+          {"name": "group", "in": "query", "required": false, "type": "string"},
+          {"name": "partnerCodes", "in": "query", "required": false, "type": "array", "items": {"type": "string"}}
+        ],
+        "tags": ["PartnerService"]
+      }
+    }
+  },
+  "definitions": {
+    "pbCloneRequest": {"type": "object", "properties": {"partner": {"$ref": "#/definitions/pbPartner"}}},
+    "pbPartner": {"type": "object", "properties": {"code": {"type": "string"}}},
+    "pbPartnerDataReply": {"type": "object", "properties": {"partners": {"type": "array", "items": {"$ref": "#/definitions/pbPartner"}}}}
+  }
+}`
+
+var info = Info{Title: "Partner Service", Version: "v1"}
+
+// convert converts swaggerDoc and reads it back generically, for the tests to look into.
+func convert(t *testing.T) map[string]interface{} {
+	out, err := Convert([]byte(swaggerDoc), info)
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	var doc map[string]interface{}
+	if !assert.Nil(t, json.Unmarshal(out, &doc)) {
+		t.FailNow()
+	}
+	return doc
+}
+
+// lookup follows keys into a document, returning nil if one is missing.
+func lookup(node interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		node = m[key]
+	}
+	return node
+}
+
+func TestConvert(t *testing.T) {
+	a := assert.New(t)
+	doc := convert(t)
+
+	a.Equal(Version, doc["openapi"])
+	a.Equal("Partner Service", lookup(doc, "info", "title"))
+	a.Nil(doc["definitions"])
+	a.Equal("#/components/schemas/pbPartner", lookup(doc, "components", "schemas", "pbCloneRequest", "properties", "partner", "$ref"))
+
+	clone := lookup(doc, "paths", "/ws/v1/partners/clone", "post")
+	a.Nil(lookup(clone, "parameters"))
+	a.Equal(true, lookup(clone, "requestBody", "required"))
+	a.Equal("#/components/schemas/pbCloneRequest", lookup(clone, "requestBody", "content", "application/json", "schema", "$ref"))
+	a.Equal("OK", lookup(clone, "responses", "200", "description"))
+	a.Equal("#/components/schemas/pbPartnerDataReply", lookup(clone, "responses", "200", "content", "application/json", "schema", "$ref"))
+	a.Equal("#/components/responses/PreconditionFailed", lookup(clone, "responses", "412", "$ref"))
+}
+
+func TestConvertQueryParameters(t *testing.T) {
+	a := assert.New(t)
+	doc := convert(t)
+
+	parameters, _ := lookup(doc, "paths", "/ws/v1/partners/export", "get", "parameters").([]interface{})
+	if !a.Len(parameters, 2) {
+		return
+	}
+	a.Equal("query", lookup(parameters[1], "in"))
+	a.Equal("array", lookup(parameters[1], "schema", "type"))
+	a.Equal("string", lookup(parameters[1], "schema", "items", "type"))
+	a.Nil(lookup(parameters[1], "type"))
+}
+
+func TestConvertStream(t *testing.T) {
+	a := assert.New(t)
+	doc := convert(t)
+
+	schema := lookup(doc, "paths", "/ws/v1/partners/export", "get", "responses", "200", "content", "application/json", "schema")
+	a.Equal("#/components/schemas/pbPartner", lookup(schema, "properties", "result", "$ref"))
+	a.Equal("#/components/schemas/StreamError", lookup(schema, "properties", "error", "$ref"))
+	a.Nil(lookup(doc, "paths", "/ws/v1/partners/export", "get", "responses", "412"))
+}
+
+func TestConvertErrors(t *testing.T) {
+	a := assert.New(t)
+	doc := convert(t)
+
+	responses := lookup(doc, "paths", "/ws/v1/partners/export", "get", "responses")
+	for status, name := range map[string]string{"400": "BadRequest", "401": "Unauthorized", "403": "Forbidden", "409": "Conflict", "429": "TooManyRequests", "503": "ServiceUnavailable", "default": "Error"} {
+		a.Equal("#/components/responses/"+name, lookup(responses, status, "$ref"), status)
+		a.Equal("#/components/schemas/Error", lookup(doc, "components", "responses", name, "content", "application/json", "schema", "$ref"), name)
+	}
+	a.NotNil(lookup(doc, "components", "responses", "TooManyRequests", "headers", "Retry-After"))
+	a.Equal([]interface{}{"error", "code"}, lookup(doc, "components", "schemas", "Error", "required"))
+	a.NotNil(lookup(doc, "components", "schemas", "StreamError", "properties", "grpc_code"))
+}
+
+func TestConvertRejectsOtherVersions(t *testing.T) {
+	_, err := Convert([]byte(`{"openapi": "3.0.0"}`), info)
+	assert.EqualError(t, err, `swagger version "" is not 2.0`)
+
+	_, err = Convert([]byte(`{`), info)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,8 @@
+package pb
+
+import _ "embed"
+
+// OpenAPI is the OpenAPI 3 spec of the http gateway, which compile.sh makes from partner_service.swagger.json.
+//
+//go:embed partner_service.openapi.json
+var OpenAPI []byte
@@ -13,6 +13,34 @@ It has these top-level messages:
 	KeyValueRequest
 	IdRequest
 	PartnerDataReply
+	ExportRequest
+	CompareRequest
+	CloneRequest
+	StatusRequest
+	StatusReply
+	RestoreRequest
+	RestoreReply
+	OpenChangeSetRequest
+	StageChangeRequest
+	PreviewRequest
+	ChangeSetRequest
+	ChangeSetReply
+	ListApprovalRequestsRequest
+	ApprovalDecisionRequest
+	ApprovalRequest
+	ApprovalRequestsReply
+	ApprovalReply
+	AccessLogRequest
+	AccessRecord
+	AccessLogReply
+	IssueApiKeyRequest
+	ListApiKeysRequest
+	ApiKeyRequest
+	ApiKey
+	ApiKeyReply
+	ApiKeysReply
+	KeyComparison
+	CompareReply
 	Partner
 */
 package pb
@@ -38,11 +66,36 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+type KeyStatus int32
+
+const (
+	KeyStatus_EQUAL     KeyStatus = 0
+	KeyStatus_DIFFERENT KeyStatus = 1
+	KeyStatus_MISSING   KeyStatus = 2
+)
+
+var KeyStatus_name = map[int32]string{
+	0: "EQUAL",
+	1: "DIFFERENT",
+	2: "MISSING",
+}
+var KeyStatus_value = map[string]int32{
+	"EQUAL":     0,
+	"DIFFERENT": 1,
+	"MISSING":   2,
+}
+
+func (x KeyStatus) String() string {
+	return proto.EnumName(KeyStatus_name, int32(x))
+}
+func (KeyStatus) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
 // Message definitions.
 type KeyValueRequest struct {
-	Key   string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
-	Value string `protobuf:"bytes,2,opt,name=value" json:"value,omitempty"`
-	Group string `protobuf:"bytes,3,opt,name=group" json:"group,omitempty"`
+	Key             string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	Value           string `protobuf:"bytes,2,opt,name=value" json:"value,omitempty"`
+	Group           string `protobuf:"bytes,3,opt,name=group" json:"group,omitempty"`
+	IncludeInactive bool   `protobuf:"varint,4,opt,name=includeInactive" json:"includeInactive,omitempty"`
 }
 
 func (m *KeyValueRequest) Reset()                    { *m = KeyValueRequest{} }
@@ -71,10 +124,18 @@ func (m *KeyValueRequest) GetGroup() string {
 	return ""
 }
 
+func (m *KeyValueRequest) GetIncludeInactive() bool {
+	if m != nil {
+		return m.IncludeInactive
+	}
+	return false
+}
+
 type IdRequest struct {
-	PartnerId   int32  `protobuf:"varint,1,opt,name=partnerId" json:"partnerId,omitempty"`
-	PartnerCode string `protobuf:"bytes,2,opt,name=partnerCode" json:"partnerCode,omitempty"`
-	Group       string `protobuf:"bytes,3,opt,name=group" json:"group,omitempty"`
+	PartnerId       int32  `protobuf:"varint,1,opt,name=partnerId" json:"partnerId,omitempty"`
+	PartnerCode     string `protobuf:"bytes,2,opt,name=partnerCode" json:"partnerCode,omitempty"`
+	Group           string `protobuf:"bytes,3,opt,name=group" json:"group,omitempty"`
+	IncludeInactive bool   `protobuf:"varint,4,opt,name=includeInactive" json:"includeInactive,omitempty"`
 }
 
 func (m *IdRequest) Reset()                    { *m = IdRequest{} }
@@ -103,11 +164,19 @@ func (m *IdRequest) GetGroup() string {
 	return ""
 }
 
+func (m *IdRequest) GetIncludeInactive() bool {
+	if m != nil {
+		return m.IncludeInactive
+	}
+	return false
+}
+
 type PartnerDataReply struct {
 	PartnerId   int32             `protobuf:"varint,1,opt,name=PartnerId" json:"PartnerId,omitempty"`
 	PartnerCode string            `protobuf:"bytes,2,opt,name=PartnerCode" json:"PartnerCode,omitempty"`
 	Attributes  map[string]string `protobuf:"bytes,3,rep,name=Attributes" json:"Attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	Error       string            `protobuf:"bytes,4,opt,name=Error" json:"Error,omitempty"`
+	Revision    int32             `protobuf:"varint,5,opt,name=Revision" json:"Revision,omitempty"`
 }
 
 func (m *PartnerDataReply) Reset()                    { *m = PartnerDataReply{} }
@@ -143,187 +212,1980 @@ func (m *PartnerDataReply) GetError() string {
 	return ""
 }
 
-type Partner struct {
+func (m *PartnerDataReply) GetRevision() int32 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+type ExportRequest struct {
+	Group        string   `protobuf:"bytes,1,opt,name=group" json:"group,omitempty"`
+	PartnerCodes []string `protobuf:"bytes,2,rep,name=partnerCodes" json:"partnerCodes,omitempty"`
+}
+
+func (m *ExportRequest) Reset()                    { *m = ExportRequest{} }
+func (m *ExportRequest) String() string            { return proto.CompactTextString(m) }
+func (*ExportRequest) ProtoMessage()               {}
+func (*ExportRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *ExportRequest) GetGroup() string {
+	if m != nil {
+		return m.Group
+	}
+	return ""
+}
+
+func (m *ExportRequest) GetPartnerCodes() []string {
+	if m != nil {
+		return m.PartnerCodes
+	}
+	return nil
+}
+
+type CompareRequest struct {
+	PartnerCodes []string `protobuf:"bytes,1,rep,name=partnerCodes" json:"partnerCodes,omitempty"`
+	Group        string   `protobuf:"bytes,2,opt,name=group" json:"group,omitempty"`
+}
+
+func (m *CompareRequest) Reset()                    { *m = CompareRequest{} }
+func (m *CompareRequest) String() string            { return proto.CompactTextString(m) }
+func (*CompareRequest) ProtoMessage()               {}
+func (*CompareRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *CompareRequest) GetPartnerCodes() []string {
+	if m != nil {
+		return m.PartnerCodes
+	}
+	return nil
+}
+
+func (m *CompareRequest) GetGroup() string {
+	if m != nil {
+		return m.Group
+	}
+	return ""
+}
+
+type CloneRequest struct {
 	Name       string            `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
 	Code       string            `protobuf:"bytes,2,opt,name=code" json:"code,omitempty"`
-	Id         int32             `protobuf:"varint,3,opt,name=id" json:"id,omitempty"`
-	Attributes map[string]string `protobuf:"bytes,4,rep,name=attributes" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	SourceCode string            `protobuf:"bytes,3,opt,name=sourceCode" json:"sourceCode,omitempty"`
+	Template   string            `protobuf:"bytes,4,opt,name=template" json:"template,omitempty"`
+	Groups     []string          `protobuf:"bytes,5,rep,name=groups" json:"groups,omitempty"`
+	Overrides  map[string]string `protobuf:"bytes,6,rep,name=overrides" json:"overrides,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
 
-func (m *Partner) Reset()                    { *m = Partner{} }
-func (m *Partner) String() string            { return proto.CompactTextString(m) }
-func (*Partner) ProtoMessage()               {}
-func (*Partner) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+func (m *CloneRequest) Reset()                    { *m = CloneRequest{} }
+func (m *CloneRequest) String() string            { return proto.CompactTextString(m) }
+func (*CloneRequest) ProtoMessage()               {}
+func (*CloneRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
 
-func (m *Partner) GetName() string {
+func (m *CloneRequest) GetName() string {
 	if m != nil {
 		return m.Name
 	}
 	return ""
 }
 
-func (m *Partner) GetCode() string {
+func (m *CloneRequest) GetCode() string {
 	if m != nil {
 		return m.Code
 	}
 	return ""
 }
 
-func (m *Partner) GetId() int32 {
+func (m *CloneRequest) GetSourceCode() string {
 	if m != nil {
-		return m.Id
+		return m.SourceCode
 	}
-	return 0
+	return ""
 }
 
-func (m *Partner) GetAttributes() map[string]string {
+func (m *CloneRequest) GetTemplate() string {
 	if m != nil {
-		return m.Attributes
+		return m.Template
+	}
+	return ""
+}
+
+func (m *CloneRequest) GetGroups() []string {
+	if m != nil {
+		return m.Groups
 	}
 	return nil
 }
 
-func init() {
-	proto.RegisterType((*KeyValueRequest)(nil), "pb.KeyValueRequest")
-	proto.RegisterType((*IdRequest)(nil), "pb.IdRequest")
-	proto.RegisterType((*PartnerDataReply)(nil), "pb.PartnerDataReply")
-	proto.RegisterType((*Partner)(nil), "pb.Partner")
+func (m *CloneRequest) GetOverrides() map[string]string {
+	if m != nil {
+		return m.Overrides
+	}
+	return nil
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+type StatusRequest struct {
+	PartnerCode      string `protobuf:"bytes,1,opt,name=partnerCode" json:"partnerCode,omitempty"`
+	Status           string `protobuf:"bytes,2,opt,name=status" json:"status,omitempty"`
+	ExpectedRevision int32  `protobuf:"varint,3,opt,name=expectedRevision" json:"expectedRevision,omitempty"`
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+func (m *StatusRequest) Reset()                    { *m = StatusRequest{} }
+func (m *StatusRequest) String() string            { return proto.CompactTextString(m) }
+func (*StatusRequest) ProtoMessage()               {}
+func (*StatusRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
 
-// Client API for PartnerService service
+func (m *StatusRequest) GetPartnerCode() string {
+	if m != nil {
+		return m.PartnerCode
+	}
+	return ""
+}
 
-type PartnerServiceClient interface {
-	GetPartnerDataByKeyValue(ctx context.Context, in *KeyValueRequest, opts ...grpc.CallOption) (*PartnerDataReply, error)
-	GetDataById(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*PartnerDataReply, error)
+func (m *StatusRequest) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
 }
 
-type partnerServiceClient struct {
-	cc *grpc.ClientConn
+func (m *StatusRequest) GetExpectedRevision() int32 {
+	if m != nil {
+		return m.ExpectedRevision
+	}
+	return 0
 }
 
-func NewPartnerServiceClient(cc *grpc.ClientConn) PartnerServiceClient {
-	return &partnerServiceClient{cc}
+type StatusReply struct {
+	PartnerId       int32  `protobuf:"varint,1,opt,name=partnerId" json:"partnerId,omitempty"`
+	PartnerCode     string `protobuf:"bytes,2,opt,name=partnerCode" json:"partnerCode,omitempty"`
+	Status          string `protobuf:"bytes,3,opt,name=status" json:"status,omitempty"`
+	StatusChangedAt string `protobuf:"bytes,4,opt,name=statusChangedAt" json:"statusChangedAt,omitempty"`
+	Error           string `protobuf:"bytes,5,opt,name=Error" json:"Error,omitempty"`
 }
 
-func (c *partnerServiceClient) GetPartnerDataByKeyValue(ctx context.Context, in *KeyValueRequest, opts ...grpc.CallOption) (*PartnerDataReply, error) {
-	out := new(PartnerDataReply)
-	err := grpc.Invoke(ctx, "/pb.PartnerService/GetPartnerDataByKeyValue", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *StatusReply) Reset()                    { *m = StatusReply{} }
+func (m *StatusReply) String() string            { return proto.CompactTextString(m) }
+func (*StatusReply) ProtoMessage()               {}
+func (*StatusReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *StatusReply) GetPartnerId() int32 {
+	if m != nil {
+		return m.PartnerId
 	}
-	return out, nil
+	return 0
 }
 
-func (c *partnerServiceClient) GetDataById(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*PartnerDataReply, error) {
-	out := new(PartnerDataReply)
-	err := grpc.Invoke(ctx, "/pb.PartnerService/GetDataById", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *StatusReply) GetPartnerCode() string {
+	if m != nil {
+		return m.PartnerCode
 	}
-	return out, nil
+	return ""
 }
 
-// Server API for PartnerService service
+func (m *StatusReply) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
 
-type PartnerServiceServer interface {
-	GetPartnerDataByKeyValue(context.Context, *KeyValueRequest) (*PartnerDataReply, error)
-	GetDataById(context.Context, *IdRequest) (*PartnerDataReply, error)
+func (m *StatusReply) GetStatusChangedAt() string {
+	if m != nil {
+		return m.StatusChangedAt
+	}
+	return ""
 }
 
-func RegisterPartnerServiceServer(s *grpc.Server, srv PartnerServiceServer) {
-	s.RegisterService(&_PartnerService_serviceDesc, srv)
+func (m *StatusReply) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
 }
 
-func _PartnerService_GetPartnerDataByKeyValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(KeyValueRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+type RestoreRequest struct {
+	PartnerCode      string `protobuf:"bytes,1,opt,name=partnerCode" json:"partnerCode,omitempty"`
+	Key              string `protobuf:"bytes,2,opt,name=key" json:"key,omitempty"`
+	Group            string `protobuf:"bytes,3,opt,name=group" json:"group,omitempty"`
+	ExpectedRevision int32  `protobuf:"varint,4,opt,name=expectedRevision" json:"expectedRevision,omitempty"`
+}
+
+func (m *RestoreRequest) Reset()                    { *m = RestoreRequest{} }
+func (m *RestoreRequest) String() string            { return proto.CompactTextString(m) }
+func (*RestoreRequest) ProtoMessage()               {}
+func (*RestoreRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *RestoreRequest) GetPartnerCode() string {
+	if m != nil {
+		return m.PartnerCode
 	}
-	if interceptor == nil {
-		return srv.(PartnerServiceServer).GetPartnerDataByKeyValue(ctx, in)
+	return ""
+}
+
+func (m *RestoreRequest) GetKey() string {
+	if m != nil {
+		return m.Key
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/pb.PartnerService/GetPartnerDataByKeyValue",
+	return ""
+}
+
+func (m *RestoreRequest) GetGroup() string {
+	if m != nil {
+		return m.Group
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(PartnerServiceServer).GetPartnerDataByKeyValue(ctx, req.(*KeyValueRequest))
+	return ""
+}
+
+func (m *RestoreRequest) GetExpectedRevision() int32 {
+	if m != nil {
+		return m.ExpectedRevision
 	}
-	return interceptor(ctx, in, info, handler)
+	return 0
 }
 
-func _PartnerService_GetDataById_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(IdRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+type RestoreReply struct {
+	Restored int32  `protobuf:"varint,1,opt,name=restored" json:"restored,omitempty"`
+	Error    string `protobuf:"bytes,2,opt,name=Error" json:"Error,omitempty"`
+}
+
+func (m *RestoreReply) Reset()                    { *m = RestoreReply{} }
+func (m *RestoreReply) String() string            { return proto.CompactTextString(m) }
+func (*RestoreReply) ProtoMessage()               {}
+func (*RestoreReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *RestoreReply) GetRestored() int32 {
+	if m != nil {
+		return m.Restored
 	}
-	if interceptor == nil {
-		return srv.(PartnerServiceServer).GetDataById(ctx, in)
+	return 0
+}
+
+func (m *RestoreReply) GetError() string {
+	if m != nil {
+		return m.Error
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/pb.PartnerService/GetDataById",
+	return ""
+}
+
+type OpenChangeSetRequest struct {
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+}
+
+func (m *OpenChangeSetRequest) Reset()                    { *m = OpenChangeSetRequest{} }
+func (m *OpenChangeSetRequest) String() string            { return proto.CompactTextString(m) }
+func (*OpenChangeSetRequest) ProtoMessage()               {}
+func (*OpenChangeSetRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *OpenChangeSetRequest) GetName() string {
+	if m != nil {
+		return m.Name
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(PartnerServiceServer).GetDataById(ctx, req.(*IdRequest))
+	return ""
+}
+
+type StageChangeRequest struct {
+	ChangeSetId      int32             `protobuf:"varint,1,opt,name=changeSetId" json:"changeSetId,omitempty"`
+	PartnerCode      string            `protobuf:"bytes,2,opt,name=partnerCode" json:"partnerCode,omitempty"`
+	PartnerName      string            `protobuf:"bytes,3,opt,name=partnerName" json:"partnerName,omitempty"`
+	Attributes       map[string]string `protobuf:"bytes,4,rep,name=attributes" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ExpectedRevision int32             `protobuf:"varint,5,opt,name=expectedRevision" json:"expectedRevision,omitempty"`
+}
+
+func (m *StageChangeRequest) Reset()                    { *m = StageChangeRequest{} }
+func (m *StageChangeRequest) String() string            { return proto.CompactTextString(m) }
+func (*StageChangeRequest) ProtoMessage()               {}
+func (*StageChangeRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *StageChangeRequest) GetChangeSetId() int32 {
+	if m != nil {
+		return m.ChangeSetId
 	}
-	return interceptor(ctx, in, info, handler)
+	return 0
 }
 
-var _PartnerService_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "pb.PartnerService",
-	HandlerType: (*PartnerServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "GetPartnerDataByKeyValue",
-			Handler:    _PartnerService_GetPartnerDataByKeyValue_Handler,
-		},
-		{
-			MethodName: "GetDataById",
-			Handler:    _PartnerService_GetDataById_Handler,
+func (m *StageChangeRequest) GetPartnerCode() string {
+	if m != nil {
+		return m.PartnerCode
+	}
+	return ""
+}
+
+func (m *StageChangeRequest) GetPartnerName() string {
+	if m != nil {
+		return m.PartnerName
+	}
+	return ""
+}
+
+func (m *StageChangeRequest) GetAttributes() map[string]string {
+	if m != nil {
+		return m.Attributes
+	}
+	return nil
+}
+
+func (m *StageChangeRequest) GetExpectedRevision() int32 {
+	if m != nil {
+		return m.ExpectedRevision
+	}
+	return 0
+}
+
+type PreviewRequest struct {
+	ChangeSetId int32  `protobuf:"varint,1,opt,name=changeSetId" json:"changeSetId,omitempty"`
+	PartnerCode string `protobuf:"bytes,2,opt,name=partnerCode" json:"partnerCode,omitempty"`
+}
+
+func (m *PreviewRequest) Reset()                    { *m = PreviewRequest{} }
+func (m *PreviewRequest) String() string            { return proto.CompactTextString(m) }
+func (*PreviewRequest) ProtoMessage()               {}
+func (*PreviewRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *PreviewRequest) GetChangeSetId() int32 {
+	if m != nil {
+		return m.ChangeSetId
+	}
+	return 0
+}
+
+func (m *PreviewRequest) GetPartnerCode() string {
+	if m != nil {
+		return m.PartnerCode
+	}
+	return ""
+}
+
+type ChangeSetRequest struct {
+	ChangeSetId int32 `protobuf:"varint,1,opt,name=changeSetId" json:"changeSetId,omitempty"`
+}
+
+func (m *ChangeSetRequest) Reset()                    { *m = ChangeSetRequest{} }
+func (m *ChangeSetRequest) String() string            { return proto.CompactTextString(m) }
+func (*ChangeSetRequest) ProtoMessage()               {}
+func (*ChangeSetRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *ChangeSetRequest) GetChangeSetId() int32 {
+	if m != nil {
+		return m.ChangeSetId
+	}
+	return 0
+}
+
+type ChangeSetReply struct {
+	ChangeSetId int32      `protobuf:"varint,1,opt,name=changeSetId" json:"changeSetId,omitempty"`
+	Name        string     `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Status      string     `protobuf:"bytes,3,opt,name=status" json:"status,omitempty"`
+	Partners    []*Partner `protobuf:"bytes,4,rep,name=partners" json:"partners,omitempty"`
+	Error       string     `protobuf:"bytes,5,opt,name=Error" json:"Error,omitempty"`
+}
+
+func (m *ChangeSetReply) Reset()                    { *m = ChangeSetReply{} }
+func (m *ChangeSetReply) String() string            { return proto.CompactTextString(m) }
+func (*ChangeSetReply) ProtoMessage()               {}
+func (*ChangeSetReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *ChangeSetReply) GetChangeSetId() int32 {
+	if m != nil {
+		return m.ChangeSetId
+	}
+	return 0
+}
+
+func (m *ChangeSetReply) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ChangeSetReply) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *ChangeSetReply) GetPartners() []*Partner {
+	if m != nil {
+		return m.Partners
+	}
+	return nil
+}
+
+func (m *ChangeSetReply) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type ListApprovalRequestsRequest struct {
+	Status string `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+}
+
+func (m *ListApprovalRequestsRequest) Reset()                    { *m = ListApprovalRequestsRequest{} }
+func (m *ListApprovalRequestsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListApprovalRequestsRequest) ProtoMessage()               {}
+func (*ListApprovalRequestsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *ListApprovalRequestsRequest) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+type ApprovalDecisionRequest struct {
+	RequestId int32  `protobuf:"varint,1,opt,name=requestId" json:"requestId,omitempty"`
+	Reason    string `protobuf:"bytes,2,opt,name=reason" json:"reason,omitempty"`
+}
+
+func (m *ApprovalDecisionRequest) Reset()                    { *m = ApprovalDecisionRequest{} }
+func (m *ApprovalDecisionRequest) String() string            { return proto.CompactTextString(m) }
+func (*ApprovalDecisionRequest) ProtoMessage()               {}
+func (*ApprovalDecisionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *ApprovalDecisionRequest) GetRequestId() int32 {
+	if m != nil {
+		return m.RequestId
+	}
+	return 0
+}
+
+func (m *ApprovalDecisionRequest) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+type ApprovalRequest struct {
+	Id          int32    `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+	ChangeSetId int32    `protobuf:"varint,2,opt,name=changeSetId" json:"changeSetId,omitempty"`
+	Groups      []string `protobuf:"bytes,3,rep,name=groups" json:"groups,omitempty"`
+	Status      string   `protobuf:"bytes,4,opt,name=status" json:"status,omitempty"`
+	RequestedBy string   `protobuf:"bytes,5,opt,name=requestedBy" json:"requestedBy,omitempty"`
+	RequestedAt string   `protobuf:"bytes,6,opt,name=requestedAt" json:"requestedAt,omitempty"`
+	DecidedBy   string   `protobuf:"bytes,7,opt,name=decidedBy" json:"decidedBy,omitempty"`
+	DecidedAt   string   `protobuf:"bytes,8,opt,name=decidedAt" json:"decidedAt,omitempty"`
+	Reason      string   `protobuf:"bytes,9,opt,name=reason" json:"reason,omitempty"`
+}
+
+func (m *ApprovalRequest) Reset()                    { *m = ApprovalRequest{} }
+func (m *ApprovalRequest) String() string            { return proto.CompactTextString(m) }
+func (*ApprovalRequest) ProtoMessage()               {}
+func (*ApprovalRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *ApprovalRequest) GetId() int32 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *ApprovalRequest) GetChangeSetId() int32 {
+	if m != nil {
+		return m.ChangeSetId
+	}
+	return 0
+}
+
+func (m *ApprovalRequest) GetGroups() []string {
+	if m != nil {
+		return m.Groups
+	}
+	return nil
+}
+
+func (m *ApprovalRequest) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *ApprovalRequest) GetRequestedBy() string {
+	if m != nil {
+		return m.RequestedBy
+	}
+	return ""
+}
+
+func (m *ApprovalRequest) GetRequestedAt() string {
+	if m != nil {
+		return m.RequestedAt
+	}
+	return ""
+}
+
+func (m *ApprovalRequest) GetDecidedBy() string {
+	if m != nil {
+		return m.DecidedBy
+	}
+	return ""
+}
+
+func (m *ApprovalRequest) GetDecidedAt() string {
+	if m != nil {
+		return m.DecidedAt
+	}
+	return ""
+}
+
+func (m *ApprovalRequest) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+type ApprovalRequestsReply struct {
+	Requests []*ApprovalRequest `protobuf:"bytes,1,rep,name=requests" json:"requests,omitempty"`
+	Error    string             `protobuf:"bytes,2,opt,name=Error" json:"Error,omitempty"`
+}
+
+func (m *ApprovalRequestsReply) Reset()                    { *m = ApprovalRequestsReply{} }
+func (m *ApprovalRequestsReply) String() string            { return proto.CompactTextString(m) }
+func (*ApprovalRequestsReply) ProtoMessage()               {}
+func (*ApprovalRequestsReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+func (m *ApprovalRequestsReply) GetRequests() []*ApprovalRequest {
+	if m != nil {
+		return m.Requests
+	}
+	return nil
+}
+
+func (m *ApprovalRequestsReply) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type ApprovalReply struct {
+	Request *ApprovalRequest `protobuf:"bytes,1,opt,name=request" json:"request,omitempty"`
+	Error   string           `protobuf:"bytes,2,opt,name=Error" json:"Error,omitempty"`
+}
+
+func (m *ApprovalReply) Reset()                    { *m = ApprovalReply{} }
+func (m *ApprovalReply) String() string            { return proto.CompactTextString(m) }
+func (*ApprovalReply) ProtoMessage()               {}
+func (*ApprovalReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+func (m *ApprovalReply) GetRequest() *ApprovalRequest {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+func (m *ApprovalReply) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type AccessLogRequest struct {
+	PartnerCode string `protobuf:"bytes,1,opt,name=partnerCode" json:"partnerCode,omitempty"`
+	From        string `protobuf:"bytes,2,opt,name=from" json:"from,omitempty"`
+	To          string `protobuf:"bytes,3,opt,name=to" json:"to,omitempty"`
+}
+
+func (m *AccessLogRequest) Reset()                    { *m = AccessLogRequest{} }
+func (m *AccessLogRequest) String() string            { return proto.CompactTextString(m) }
+func (*AccessLogRequest) ProtoMessage()               {}
+func (*AccessLogRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *AccessLogRequest) GetPartnerCode() string {
+	if m != nil {
+		return m.PartnerCode
+	}
+	return ""
+}
+
+func (m *AccessLogRequest) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+func (m *AccessLogRequest) GetTo() string {
+	if m != nil {
+		return m.To
+	}
+	return ""
+}
+
+type AccessRecord struct {
+	Id          int32    `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+	Caller      string   `protobuf:"bytes,2,opt,name=caller" json:"caller,omitempty"`
+	PartnerCode string   `protobuf:"bytes,3,opt,name=partnerCode" json:"partnerCode,omitempty"`
+	Keys        []string `protobuf:"bytes,4,rep,name=keys" json:"keys,omitempty"`
+	RequestId   string   `protobuf:"bytes,5,opt,name=requestId" json:"requestId,omitempty"`
+	ReadAt      string   `protobuf:"bytes,6,opt,name=readAt" json:"readAt,omitempty"`
+}
+
+func (m *AccessRecord) Reset()                    { *m = AccessRecord{} }
+func (m *AccessRecord) String() string            { return proto.CompactTextString(m) }
+func (*AccessRecord) ProtoMessage()               {}
+func (*AccessRecord) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+
+func (m *AccessRecord) GetId() int32 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *AccessRecord) GetCaller() string {
+	if m != nil {
+		return m.Caller
+	}
+	return ""
+}
+
+func (m *AccessRecord) GetPartnerCode() string {
+	if m != nil {
+		return m.PartnerCode
+	}
+	return ""
+}
+
+func (m *AccessRecord) GetKeys() []string {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+func (m *AccessRecord) GetRequestId() string {
+	if m != nil {
+		return m.RequestId
+	}
+	return ""
+}
+
+func (m *AccessRecord) GetReadAt() string {
+	if m != nil {
+		return m.ReadAt
+	}
+	return ""
+}
+
+type AccessLogReply struct {
+	Records []*AccessRecord `protobuf:"bytes,1,rep,name=records" json:"records,omitempty"`
+	Error   string          `protobuf:"bytes,2,opt,name=Error" json:"Error,omitempty"`
+}
+
+func (m *AccessLogReply) Reset()                    { *m = AccessLogReply{} }
+func (m *AccessLogReply) String() string            { return proto.CompactTextString(m) }
+func (*AccessLogReply) ProtoMessage()               {}
+func (*AccessLogReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+
+func (m *AccessLogReply) GetRecords() []*AccessRecord {
+	if m != nil {
+		return m.Records
+	}
+	return nil
+}
+
+func (m *AccessLogReply) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type IssueApiKeyRequest struct {
+	Owner     string   `protobuf:"bytes,1,opt,name=owner" json:"owner,omitempty"`
+	Scope     []string `protobuf:"bytes,2,rep,name=scope" json:"scope,omitempty"`
+	ExpiresAt string   `protobuf:"bytes,3,opt,name=expiresAt" json:"expiresAt,omitempty"`
+}
+
+func (m *IssueApiKeyRequest) Reset()                    { *m = IssueApiKeyRequest{} }
+func (m *IssueApiKeyRequest) String() string            { return proto.CompactTextString(m) }
+func (*IssueApiKeyRequest) ProtoMessage()               {}
+func (*IssueApiKeyRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+
+func (m *IssueApiKeyRequest) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *IssueApiKeyRequest) GetScope() []string {
+	if m != nil {
+		return m.Scope
+	}
+	return nil
+}
+
+func (m *IssueApiKeyRequest) GetExpiresAt() string {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return ""
+}
+
+type ListApiKeysRequest struct {
+	Owner string `protobuf:"bytes,1,opt,name=owner" json:"owner,omitempty"`
+}
+
+func (m *ListApiKeysRequest) Reset()                    { *m = ListApiKeysRequest{} }
+func (m *ListApiKeysRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListApiKeysRequest) ProtoMessage()               {}
+func (*ListApiKeysRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+
+func (m *ListApiKeysRequest) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+type ApiKeyRequest struct {
+	Id int32 `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+}
+
+func (m *ApiKeyRequest) Reset()                    { *m = ApiKeyRequest{} }
+func (m *ApiKeyRequest) String() string            { return proto.CompactTextString(m) }
+func (*ApiKeyRequest) ProtoMessage()               {}
+func (*ApiKeyRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+
+func (m *ApiKeyRequest) GetId() int32 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+type ApiKey struct {
+	Id         int32    `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+	Prefix     string   `protobuf:"bytes,2,opt,name=prefix" json:"prefix,omitempty"`
+	Owner      string   `protobuf:"bytes,3,opt,name=owner" json:"owner,omitempty"`
+	Scope      []string `protobuf:"bytes,4,rep,name=scope" json:"scope,omitempty"`
+	ExpiresAt  string   `protobuf:"bytes,5,opt,name=expiresAt" json:"expiresAt,omitempty"`
+	LastUsedAt string   `protobuf:"bytes,6,opt,name=lastUsedAt" json:"lastUsedAt,omitempty"`
+	CreatedAt  string   `protobuf:"bytes,7,opt,name=createdAt" json:"createdAt,omitempty"`
+	RevokedAt  string   `protobuf:"bytes,8,opt,name=revokedAt" json:"revokedAt,omitempty"`
+}
+
+func (m *ApiKey) Reset()                    { *m = ApiKey{} }
+func (m *ApiKey) String() string            { return proto.CompactTextString(m) }
+func (*ApiKey) ProtoMessage()               {}
+func (*ApiKey) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+
+func (m *ApiKey) GetId() int32 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *ApiKey) GetPrefix() string {
+	if m != nil {
+		return m.Prefix
+	}
+	return ""
+}
+
+func (m *ApiKey) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *ApiKey) GetScope() []string {
+	if m != nil {
+		return m.Scope
+	}
+	return nil
+}
+
+func (m *ApiKey) GetExpiresAt() string {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return ""
+}
+
+func (m *ApiKey) GetLastUsedAt() string {
+	if m != nil {
+		return m.LastUsedAt
+	}
+	return ""
+}
+
+func (m *ApiKey) GetCreatedAt() string {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return ""
+}
+
+func (m *ApiKey) GetRevokedAt() string {
+	if m != nil {
+		return m.RevokedAt
+	}
+	return ""
+}
+
+type ApiKeyReply struct {
+	ApiKey *ApiKey `protobuf:"bytes,1,opt,name=apiKey" json:"apiKey,omitempty"`
+	Key    string  `protobuf:"bytes,2,opt,name=key" json:"key,omitempty"`
+	Error  string  `protobuf:"bytes,3,opt,name=Error" json:"Error,omitempty"`
+}
+
+func (m *ApiKeyReply) Reset()                    { *m = ApiKeyReply{} }
+func (m *ApiKeyReply) String() string            { return proto.CompactTextString(m) }
+func (*ApiKeyReply) ProtoMessage()               {}
+func (*ApiKeyReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
+
+func (m *ApiKeyReply) GetApiKey() *ApiKey {
+	if m != nil {
+		return m.ApiKey
+	}
+	return nil
+}
+
+func (m *ApiKeyReply) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *ApiKeyReply) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type ApiKeysReply struct {
+	ApiKeys []*ApiKey `protobuf:"bytes,1,rep,name=apiKeys" json:"apiKeys,omitempty"`
+	Error   string    `protobuf:"bytes,2,opt,name=Error" json:"Error,omitempty"`
+}
+
+func (m *ApiKeysReply) Reset()                    { *m = ApiKeysReply{} }
+func (m *ApiKeysReply) String() string            { return proto.CompactTextString(m) }
+func (*ApiKeysReply) ProtoMessage()               {}
+func (*ApiKeysReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
+
+func (m *ApiKeysReply) GetApiKeys() []*ApiKey {
+	if m != nil {
+		return m.ApiKeys
+	}
+	return nil
+}
+
+func (m *ApiKeysReply) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type KeyComparison struct {
+	Key    string            `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	Status KeyStatus         `protobuf:"varint,2,opt,name=status,enum=pb.KeyStatus" json:"status,omitempty"`
+	Values map[string]string `protobuf:"bytes,3,rep,name=values" json:"values,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (m *KeyComparison) Reset()                    { *m = KeyComparison{} }
+func (m *KeyComparison) String() string            { return proto.CompactTextString(m) }
+func (*KeyComparison) ProtoMessage()               {}
+func (*KeyComparison) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
+
+func (m *KeyComparison) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *KeyComparison) GetStatus() KeyStatus {
+	if m != nil {
+		return m.Status
+	}
+	return KeyStatus_EQUAL
+}
+
+func (m *KeyComparison) GetValues() map[string]string {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type CompareReply struct {
+	PartnerCodes []string         `protobuf:"bytes,1,rep,name=partnerCodes" json:"partnerCodes,omitempty"`
+	Keys         []*KeyComparison `protobuf:"bytes,2,rep,name=keys" json:"keys,omitempty"`
+	Error        string           `protobuf:"bytes,3,opt,name=Error" json:"Error,omitempty"`
+}
+
+func (m *CompareReply) Reset()                    { *m = CompareReply{} }
+func (m *CompareReply) String() string            { return proto.CompactTextString(m) }
+func (*CompareReply) ProtoMessage()               {}
+func (*CompareReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
+
+func (m *CompareReply) GetPartnerCodes() []string {
+	if m != nil {
+		return m.PartnerCodes
+	}
+	return nil
+}
+
+func (m *CompareReply) GetKeys() []*KeyComparison {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+func (m *CompareReply) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type Partner struct {
+	Name       string            `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Code       string            `protobuf:"bytes,2,opt,name=code" json:"code,omitempty"`
+	Id         int32             `protobuf:"varint,3,opt,name=id" json:"id,omitempty"`
+	Attributes map[string]string `protobuf:"bytes,4,rep,name=attributes" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Status     string            `protobuf:"bytes,5,opt,name=status" json:"status,omitempty"`
+}
+
+func (m *Partner) Reset()                    { *m = Partner{} }
+func (m *Partner) String() string            { return proto.CompactTextString(m) }
+func (*Partner) ProtoMessage()               {}
+func (*Partner) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
+
+func (m *Partner) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Partner) GetCode() string {
+	if m != nil {
+		return m.Code
+	}
+	return ""
+}
+
+func (m *Partner) GetId() int32 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *Partner) GetAttributes() map[string]string {
+	if m != nil {
+		return m.Attributes
+	}
+	return nil
+}
+
+func (m *Partner) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*KeyValueRequest)(nil), "pb.KeyValueRequest")
+	proto.RegisterType((*IdRequest)(nil), "pb.IdRequest")
+	proto.RegisterType((*PartnerDataReply)(nil), "pb.PartnerDataReply")
+	proto.RegisterType((*ExportRequest)(nil), "pb.ExportRequest")
+	proto.RegisterType((*CompareRequest)(nil), "pb.CompareRequest")
+	proto.RegisterType((*CloneRequest)(nil), "pb.CloneRequest")
+	proto.RegisterType((*StatusRequest)(nil), "pb.StatusRequest")
+	proto.RegisterType((*StatusReply)(nil), "pb.StatusReply")
+	proto.RegisterType((*RestoreRequest)(nil), "pb.RestoreRequest")
+	proto.RegisterType((*RestoreReply)(nil), "pb.RestoreReply")
+	proto.RegisterType((*OpenChangeSetRequest)(nil), "pb.OpenChangeSetRequest")
+	proto.RegisterType((*StageChangeRequest)(nil), "pb.StageChangeRequest")
+	proto.RegisterType((*PreviewRequest)(nil), "pb.PreviewRequest")
+	proto.RegisterType((*ChangeSetRequest)(nil), "pb.ChangeSetRequest")
+	proto.RegisterType((*ChangeSetReply)(nil), "pb.ChangeSetReply")
+	proto.RegisterType((*ListApprovalRequestsRequest)(nil), "pb.ListApprovalRequestsRequest")
+	proto.RegisterType((*ApprovalDecisionRequest)(nil), "pb.ApprovalDecisionRequest")
+	proto.RegisterType((*ApprovalRequest)(nil), "pb.ApprovalRequest")
+	proto.RegisterType((*ApprovalRequestsReply)(nil), "pb.ApprovalRequestsReply")
+	proto.RegisterType((*ApprovalReply)(nil), "pb.ApprovalReply")
+	proto.RegisterType((*AccessLogRequest)(nil), "pb.AccessLogRequest")
+	proto.RegisterType((*AccessRecord)(nil), "pb.AccessRecord")
+	proto.RegisterType((*AccessLogReply)(nil), "pb.AccessLogReply")
+	proto.RegisterType((*IssueApiKeyRequest)(nil), "pb.IssueApiKeyRequest")
+	proto.RegisterType((*ListApiKeysRequest)(nil), "pb.ListApiKeysRequest")
+	proto.RegisterType((*ApiKeyRequest)(nil), "pb.ApiKeyRequest")
+	proto.RegisterType((*ApiKey)(nil), "pb.ApiKey")
+	proto.RegisterType((*ApiKeyReply)(nil), "pb.ApiKeyReply")
+	proto.RegisterType((*ApiKeysReply)(nil), "pb.ApiKeysReply")
+	proto.RegisterType((*KeyComparison)(nil), "pb.KeyComparison")
+	proto.RegisterType((*CompareReply)(nil), "pb.CompareReply")
+	proto.RegisterType((*Partner)(nil), "pb.Partner")
+	proto.RegisterEnum("pb.KeyStatus", KeyStatus_name, KeyStatus_value)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for PartnerService service
+
+type PartnerServiceClient interface {
+	GetPartnerDataByKeyValue(ctx context.Context, in *KeyValueRequest, opts ...grpc.CallOption) (*PartnerDataReply, error)
+	GetDataById(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*PartnerDataReply, error)
+	ExportPartners(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (PartnerService_ExportPartnersClient, error)
+	ComparePartners(ctx context.Context, in *CompareRequest, opts ...grpc.CallOption) (*CompareReply, error)
+	ClonePartner(ctx context.Context, in *CloneRequest, opts ...grpc.CallOption) (*PartnerDataReply, error)
+	SetPartnerStatus(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusReply, error)
+	RestorePartner(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreReply, error)
+	RestoreKey(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreReply, error)
+	RestoreGroup(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreReply, error)
+	RestorePartnerAttribute(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreReply, error)
+	OpenChangeSet(ctx context.Context, in *OpenChangeSetRequest, opts ...grpc.CallOption) (*ChangeSetReply, error)
+	StageChange(ctx context.Context, in *StageChangeRequest, opts ...grpc.CallOption) (*ChangeSetReply, error)
+	PreviewChangeSet(ctx context.Context, in *PreviewRequest, opts ...grpc.CallOption) (*PartnerDataReply, error)
+	PublishChangeSet(ctx context.Context, in *ChangeSetRequest, opts ...grpc.CallOption) (*ChangeSetReply, error)
+	DiscardChangeSet(ctx context.Context, in *ChangeSetRequest, opts ...grpc.CallOption) (*ChangeSetReply, error)
+	ListApprovalRequests(ctx context.Context, in *ListApprovalRequestsRequest, opts ...grpc.CallOption) (*ApprovalRequestsReply, error)
+	ApproveRequest(ctx context.Context, in *ApprovalDecisionRequest, opts ...grpc.CallOption) (*ApprovalReply, error)
+	RejectRequest(ctx context.Context, in *ApprovalDecisionRequest, opts ...grpc.CallOption) (*ApprovalReply, error)
+	ListAccessRecords(ctx context.Context, in *AccessLogRequest, opts ...grpc.CallOption) (*AccessLogReply, error)
+	IssueApiKey(ctx context.Context, in *IssueApiKeyRequest, opts ...grpc.CallOption) (*ApiKeyReply, error)
+	ListApiKeys(ctx context.Context, in *ListApiKeysRequest, opts ...grpc.CallOption) (*ApiKeysReply, error)
+	RotateApiKey(ctx context.Context, in *ApiKeyRequest, opts ...grpc.CallOption) (*ApiKeyReply, error)
+	RevokeApiKey(ctx context.Context, in *ApiKeyRequest, opts ...grpc.CallOption) (*ApiKeyReply, error)
+}
+
+type partnerServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewPartnerServiceClient(cc *grpc.ClientConn) PartnerServiceClient {
+	return &partnerServiceClient{cc}
+}
+
+func (c *partnerServiceClient) GetPartnerDataByKeyValue(ctx context.Context, in *KeyValueRequest, opts ...grpc.CallOption) (*PartnerDataReply, error) {
+	out := new(PartnerDataReply)
+	err := grpc.Invoke(ctx, "/pb.PartnerService/GetPartnerDataByKeyValue", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *partnerServiceClient) GetDataById(ctx context.Context, in *IdRequest, opts ...grpc.CallOption) (*PartnerDataReply, error) {
+	out := new(PartnerDataReply)
+	err := grpc.Invoke(ctx, "/pb.PartnerService/GetDataById", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *partnerServiceClient) ExportPartners(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (PartnerService_ExportPartnersClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_PartnerService_serviceDesc.Streams[0], c.cc, "/pb.PartnerService/ExportPartners", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &partnerServiceExportPartnersClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type PartnerService_ExportPartnersClient interface {
+	Recv() (*Partner, error)
+	grpc.ClientStream
+}
+
+type partnerServiceExportPartnersClient struct {
+	grpc.ClientStream
+}
+
+func (x *partnerServiceExportPartnersClient) Recv() (*Partner, error) {
+	m := new(Partner)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *partnerServiceClient) ComparePartners(ctx context.Context, in *CompareRequest, opts ...grpc.CallOption) (*CompareReply, error) {
+	out := new(CompareReply)
+	err := grpc.Invoke(ctx, "/pb.PartnerService/ComparePartners", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *partnerServiceClient) ClonePartner(ctx context.Context, in *CloneRequest, opts ...grpc.CallOption) (*PartnerDataReply, error) {
+	out := new(PartnerDataReply)
+	err := grpc.Invoke(ctx, "/pb.PartnerService/ClonePartner", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *partnerServiceClient) SetPartnerStatus(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusReply, error) {
+	out := new(StatusReply)
+	err := grpc.Invoke(ctx, "/pb.PartnerService/SetPartnerStatus", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *partnerServiceClient) RestorePartner(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreReply, error) {
+	out := new(RestoreReply)
+	err := grpc.Invoke(ctx, "/pb.PartnerService/RestorePartner", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *partnerServiceClient) RestoreKey(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreReply, error) {
+	out := new(RestoreReply)
+	err := grpc.Invoke(ctx, "/pb.PartnerService/RestoreKey", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *partnerServiceClient) RestoreGroup(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreReply, error) {
+	out := new(RestoreReply)
+	err := grpc.Invoke(ctx, "/pb.PartnerService/RestoreGroup", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *partnerServiceClient) RestorePartnerAttribute(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreReply, error) {
+	out := new(RestoreReply)
+	err := grpc.Invoke(ctx, "/pb.PartnerService/RestorePartnerAttribute", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *partnerServiceClient) OpenChangeSet(ctx context.Context, in *OpenChangeSetRequest, opts ...grpc.CallOption) (*ChangeSetReply, error) {
+	out := new(ChangeSetReply)
+	err := grpc.Invoke(ctx, "/pb.PartnerService/OpenChangeSet", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *partnerServiceClient) StageChange(ctx context.Context, in *StageChangeRequest, opts ...grpc.CallOption) (*ChangeSetReply, error) {
+	out := new(ChangeSetReply)
+	err := grpc.Invoke(ctx, "/pb.PartnerService/StageChange", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *partnerServiceClient) PreviewChangeSet(ctx context.Context, in *PreviewRequest, opts ...grpc.CallOption) (*PartnerDataReply, error) {
+	out := new(PartnerDataReply)
+	err := grpc.Invoke(ctx, "/pb.PartnerService/PreviewChangeSet", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *partnerServiceClient) PublishChangeSet(ctx context.Context, in *ChangeSetRequest, opts ...grpc.CallOption) (*ChangeSetReply, error) {
+	out := new(ChangeSetReply)
+	err := grpc.Invoke(ctx, "/pb.PartnerService/PublishChangeSet", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *partnerServiceClient) DiscardChangeSet(ctx context.Context, in *ChangeSetRequest, opts ...grpc.CallOption) (*ChangeSetReply, error) {
+	out := new(ChangeSetReply)
+	err := grpc.Invoke(ctx, "/pb.PartnerService/DiscardChangeSet", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *partnerServiceClient) ListApprovalRequests(ctx context.Context, in *ListApprovalRequestsRequest, opts ...grpc.CallOption) (*ApprovalRequestsReply, error) {
+	out := new(ApprovalRequestsReply)
+	err := grpc.Invoke(ctx, "/pb.PartnerService/ListApprovalRequests", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *partnerServiceClient) ApproveRequest(ctx context.Context, in *ApprovalDecisionRequest, opts ...grpc.CallOption) (*ApprovalReply, error) {
+	out := new(ApprovalReply)
+	err := grpc.Invoke(ctx, "/pb.PartnerService/ApproveRequest", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *partnerServiceClient) RejectRequest(ctx context.Context, in *ApprovalDecisionRequest, opts ...grpc.CallOption) (*ApprovalReply, error) {
+	out := new(ApprovalReply)
+	err := grpc.Invoke(ctx, "/pb.PartnerService/RejectRequest", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *partnerServiceClient) ListAccessRecords(ctx context.Context, in *AccessLogRequest, opts ...grpc.CallOption) (*AccessLogReply, error) {
+	out := new(AccessLogReply)
+	err := grpc.Invoke(ctx, "/pb.PartnerService/ListAccessRecords", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *partnerServiceClient) IssueApiKey(ctx context.Context, in *IssueApiKeyRequest, opts ...grpc.CallOption) (*ApiKeyReply, error) {
+	out := new(ApiKeyReply)
+	err := grpc.Invoke(ctx, "/pb.PartnerService/IssueApiKey", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *partnerServiceClient) ListApiKeys(ctx context.Context, in *ListApiKeysRequest, opts ...grpc.CallOption) (*ApiKeysReply, error) {
+	out := new(ApiKeysReply)
+	err := grpc.Invoke(ctx, "/pb.PartnerService/ListApiKeys", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *partnerServiceClient) RotateApiKey(ctx context.Context, in *ApiKeyRequest, opts ...grpc.CallOption) (*ApiKeyReply, error) {
+	out := new(ApiKeyReply)
+	err := grpc.Invoke(ctx, "/pb.PartnerService/RotateApiKey", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *partnerServiceClient) RevokeApiKey(ctx context.Context, in *ApiKeyRequest, opts ...grpc.CallOption) (*ApiKeyReply, error) {
+	out := new(ApiKeyReply)
+	err := grpc.Invoke(ctx, "/pb.PartnerService/RevokeApiKey", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for PartnerService service
+
+type PartnerServiceServer interface {
+	GetPartnerDataByKeyValue(context.Context, *KeyValueRequest) (*PartnerDataReply, error)
+	GetDataById(context.Context, *IdRequest) (*PartnerDataReply, error)
+	ExportPartners(*ExportRequest, PartnerService_ExportPartnersServer) error
+	ComparePartners(context.Context, *CompareRequest) (*CompareReply, error)
+	ClonePartner(context.Context, *CloneRequest) (*PartnerDataReply, error)
+	SetPartnerStatus(context.Context, *StatusRequest) (*StatusReply, error)
+	RestorePartner(context.Context, *RestoreRequest) (*RestoreReply, error)
+	RestoreKey(context.Context, *RestoreRequest) (*RestoreReply, error)
+	RestoreGroup(context.Context, *RestoreRequest) (*RestoreReply, error)
+	RestorePartnerAttribute(context.Context, *RestoreRequest) (*RestoreReply, error)
+	OpenChangeSet(context.Context, *OpenChangeSetRequest) (*ChangeSetReply, error)
+	StageChange(context.Context, *StageChangeRequest) (*ChangeSetReply, error)
+	PreviewChangeSet(context.Context, *PreviewRequest) (*PartnerDataReply, error)
+	PublishChangeSet(context.Context, *ChangeSetRequest) (*ChangeSetReply, error)
+	DiscardChangeSet(context.Context, *ChangeSetRequest) (*ChangeSetReply, error)
+	ListApprovalRequests(context.Context, *ListApprovalRequestsRequest) (*ApprovalRequestsReply, error)
+	ApproveRequest(context.Context, *ApprovalDecisionRequest) (*ApprovalReply, error)
+	RejectRequest(context.Context, *ApprovalDecisionRequest) (*ApprovalReply, error)
+	ListAccessRecords(context.Context, *AccessLogRequest) (*AccessLogReply, error)
+	IssueApiKey(context.Context, *IssueApiKeyRequest) (*ApiKeyReply, error)
+	ListApiKeys(context.Context, *ListApiKeysRequest) (*ApiKeysReply, error)
+	RotateApiKey(context.Context, *ApiKeyRequest) (*ApiKeyReply, error)
+	RevokeApiKey(context.Context, *ApiKeyRequest) (*ApiKeyReply, error)
+}
+
+func RegisterPartnerServiceServer(s *grpc.Server, srv PartnerServiceServer) {
+	s.RegisterService(&_PartnerService_serviceDesc, srv)
+}
+
+func _PartnerService_GetPartnerDataByKeyValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyValueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PartnerServiceServer).GetPartnerDataByKeyValue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.PartnerService/GetPartnerDataByKeyValue",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PartnerServiceServer).GetPartnerDataByKeyValue(ctx, req.(*KeyValueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PartnerService_GetDataById_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PartnerServiceServer).GetDataById(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.PartnerService/GetDataById",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PartnerServiceServer).GetDataById(ctx, req.(*IdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PartnerService_ExportPartners_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PartnerServiceServer).ExportPartners(m, &partnerServiceExportPartnersServer{stream})
+}
+
+type PartnerService_ExportPartnersServer interface {
+	Send(*Partner) error
+	grpc.ServerStream
+}
+
+type partnerServiceExportPartnersServer struct {
+	grpc.ServerStream
+}
+
+func (x *partnerServiceExportPartnersServer) Send(m *Partner) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _PartnerService_ComparePartners_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompareRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PartnerServiceServer).ComparePartners(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.PartnerService/ComparePartners",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PartnerServiceServer).ComparePartners(ctx, req.(*CompareRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PartnerService_ClonePartner_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CloneRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PartnerServiceServer).ClonePartner(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.PartnerService/ClonePartner",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PartnerServiceServer).ClonePartner(ctx, req.(*CloneRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PartnerService_SetPartnerStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PartnerServiceServer).SetPartnerStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.PartnerService/SetPartnerStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PartnerServiceServer).SetPartnerStatus(ctx, req.(*StatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PartnerService_RestorePartner_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PartnerServiceServer).RestorePartner(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.PartnerService/RestorePartner",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PartnerServiceServer).RestorePartner(ctx, req.(*RestoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PartnerService_RestoreKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PartnerServiceServer).RestoreKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.PartnerService/RestoreKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PartnerServiceServer).RestoreKey(ctx, req.(*RestoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PartnerService_RestoreGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PartnerServiceServer).RestoreGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.PartnerService/RestoreGroup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PartnerServiceServer).RestoreGroup(ctx, req.(*RestoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PartnerService_RestorePartnerAttribute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PartnerServiceServer).RestorePartnerAttribute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.PartnerService/RestorePartnerAttribute",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PartnerServiceServer).RestorePartnerAttribute(ctx, req.(*RestoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PartnerService_OpenChangeSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OpenChangeSetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PartnerServiceServer).OpenChangeSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.PartnerService/OpenChangeSet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PartnerServiceServer).OpenChangeSet(ctx, req.(*OpenChangeSetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PartnerService_StageChange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StageChangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PartnerServiceServer).StageChange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.PartnerService/StageChange",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PartnerServiceServer).StageChange(ctx, req.(*StageChangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PartnerService_PreviewChangeSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PreviewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PartnerServiceServer).PreviewChangeSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.PartnerService/PreviewChangeSet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PartnerServiceServer).PreviewChangeSet(ctx, req.(*PreviewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PartnerService_PublishChangeSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangeSetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PartnerServiceServer).PublishChangeSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.PartnerService/PublishChangeSet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PartnerServiceServer).PublishChangeSet(ctx, req.(*ChangeSetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PartnerService_DiscardChangeSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangeSetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PartnerServiceServer).DiscardChangeSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.PartnerService/DiscardChangeSet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PartnerServiceServer).DiscardChangeSet(ctx, req.(*ChangeSetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PartnerService_ListApprovalRequests_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListApprovalRequestsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PartnerServiceServer).ListApprovalRequests(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.PartnerService/ListApprovalRequests",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PartnerServiceServer).ListApprovalRequests(ctx, req.(*ListApprovalRequestsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PartnerService_ApproveRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApprovalDecisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PartnerServiceServer).ApproveRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.PartnerService/ApproveRequest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PartnerServiceServer).ApproveRequest(ctx, req.(*ApprovalDecisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PartnerService_RejectRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApprovalDecisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PartnerServiceServer).RejectRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.PartnerService/RejectRequest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PartnerServiceServer).RejectRequest(ctx, req.(*ApprovalDecisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PartnerService_ListAccessRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccessLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PartnerServiceServer).ListAccessRecords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.PartnerService/ListAccessRecords",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PartnerServiceServer).ListAccessRecords(ctx, req.(*AccessLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PartnerService_IssueApiKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IssueApiKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PartnerServiceServer).IssueApiKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.PartnerService/IssueApiKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PartnerServiceServer).IssueApiKey(ctx, req.(*IssueApiKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PartnerService_ListApiKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListApiKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PartnerServiceServer).ListApiKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.PartnerService/ListApiKeys",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PartnerServiceServer).ListApiKeys(ctx, req.(*ListApiKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PartnerService_RotateApiKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApiKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PartnerServiceServer).RotateApiKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.PartnerService/RotateApiKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PartnerServiceServer).RotateApiKey(ctx, req.(*ApiKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PartnerService_RevokeApiKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApiKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PartnerServiceServer).RevokeApiKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.PartnerService/RevokeApiKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PartnerServiceServer).RevokeApiKey(ctx, req.(*ApiKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _PartnerService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.PartnerService",
+	HandlerType: (*PartnerServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetPartnerDataByKeyValue",
+			Handler:    _PartnerService_GetPartnerDataByKeyValue_Handler,
+		},
+		{
+			MethodName: "GetDataById",
+			Handler:    _PartnerService_GetDataById_Handler,
+		},
+		{
+			MethodName: "ComparePartners",
+			Handler:    _PartnerService_ComparePartners_Handler,
+		},
+		{
+			MethodName: "ClonePartner",
+			Handler:    _PartnerService_ClonePartner_Handler,
+		},
+		{
+			MethodName: "SetPartnerStatus",
+			Handler:    _PartnerService_SetPartnerStatus_Handler,
+		},
+		{
+			MethodName: "RestorePartner",
+			Handler:    _PartnerService_RestorePartner_Handler,
+		},
+		{
+			MethodName: "RestoreKey",
+			Handler:    _PartnerService_RestoreKey_Handler,
+		},
+		{
+			MethodName: "RestoreGroup",
+			Handler:    _PartnerService_RestoreGroup_Handler,
+		},
+		{
+			MethodName: "RestorePartnerAttribute",
+			Handler:    _PartnerService_RestorePartnerAttribute_Handler,
+		},
+		{
+			MethodName: "OpenChangeSet",
+			Handler:    _PartnerService_OpenChangeSet_Handler,
+		},
+		{
+			MethodName: "StageChange",
+			Handler:    _PartnerService_StageChange_Handler,
+		},
+		{
+			MethodName: "PreviewChangeSet",
+			Handler:    _PartnerService_PreviewChangeSet_Handler,
+		},
+		{
+			MethodName: "PublishChangeSet",
+			Handler:    _PartnerService_PublishChangeSet_Handler,
+		},
+		{
+			MethodName: "DiscardChangeSet",
+			Handler:    _PartnerService_DiscardChangeSet_Handler,
+		},
+		{
+			MethodName: "ListApprovalRequests",
+			Handler:    _PartnerService_ListApprovalRequests_Handler,
+		},
+		{
+			MethodName: "ApproveRequest",
+			Handler:    _PartnerService_ApproveRequest_Handler,
+		},
+		{
+			MethodName: "RejectRequest",
+			Handler:    _PartnerService_RejectRequest_Handler,
+		},
+		{
+			MethodName: "ListAccessRecords",
+			Handler:    _PartnerService_ListAccessRecords_Handler,
+		},
+		{
+			MethodName: "IssueApiKey",
+			Handler:    _PartnerService_IssueApiKey_Handler,
+		},
+		{
+			MethodName: "ListApiKeys",
+			Handler:    _PartnerService_ListApiKeys_Handler,
+		},
+		{
+			MethodName: "RotateApiKey",
+			Handler:    _PartnerService_RotateApiKey_Handler,
+		},
+		{
+			MethodName: "RevokeApiKey",
+			Handler:    _PartnerService_RevokeApiKey_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportPartners",
+			Handler:       _PartnerService_ExportPartners_Handler,
+			ServerStreams: true,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "pkg/pb/partner_service.proto",
 }
 
 func init() { proto.RegisterFile("pkg/pb/partner_service.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 423 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x53, 0xc1, 0xca, 0xd3, 0x40,
-	0x10, 0x26, 0x49, 0xab, 0x74, 0x8a, 0x6d, 0x59, 0x8b, 0x84, 0x36, 0x42, 0x89, 0x1e, 0x7a, 0x69,
-	0x82, 0xf5, 0x22, 0x8a, 0x07, 0xb5, 0xa5, 0x14, 0x0f, 0x86, 0x08, 0x5e, 0x65, 0xd3, 0x2c, 0x21,
-	0xb6, 0x66, 0xd7, 0xcd, 0xa6, 0x92, 0xab, 0xaf, 0xe0, 0xf3, 0xf8, 0x0a, 0x5e, 0x7c, 0x05, 0xaf,
-	0xbe, 0x83, 0xec, 0x6e, 0x62, 0x42, 0x6a, 0xe1, 0x87, 0xff, 0x36, 0xf3, 0x31, 0xf3, 0x7d, 0xdf,
-	0xcc, 0xce, 0x82, 0xc3, 0x8e, 0x89, 0xcf, 0x22, 0x9f, 0x61, 0x2e, 0x32, 0xc2, 0x3f, 0xe6, 0x84,
-	0x9f, 0xd3, 0x03, 0xf1, 0x18, 0xa7, 0x82, 0x22, 0x93, 0x45, 0x33, 0x27, 0xa1, 0x34, 0x39, 0x11,
-	0x1f, 0xb3, 0xd4, 0xc7, 0x59, 0x46, 0x05, 0x16, 0x29, 0xcd, 0x72, 0x5d, 0xe1, 0xbe, 0x83, 0xf1,
-	0x5b, 0x52, 0x7e, 0xc0, 0xa7, 0x82, 0x84, 0xe4, 0x4b, 0x41, 0x72, 0x81, 0x26, 0x60, 0x1d, 0x49,
-	0x69, 0x1b, 0x0b, 0x63, 0x39, 0x08, 0x65, 0x88, 0xa6, 0xd0, 0x3f, 0xcb, 0x0a, 0xdb, 0x54, 0x98,
-	0x4e, 0x24, 0x9a, 0x70, 0x5a, 0x30, 0xdb, 0xd2, 0xa8, 0x4a, 0x5c, 0x0c, 0x83, 0x7d, 0x5c, 0x53,
-	0x39, 0x30, 0xa8, 0x8c, 0xed, 0x63, 0x45, 0xd8, 0x0f, 0x1b, 0x00, 0x2d, 0x60, 0x58, 0x25, 0x6f,
-	0x68, 0x5c, 0x93, 0xb7, 0xa1, 0x2b, 0x12, 0x7f, 0x0c, 0x98, 0x04, 0xba, 0x6a, 0x83, 0x05, 0x0e,
-	0x09, 0x3b, 0x95, 0x52, 0x2a, 0xe8, 0x4a, 0x05, 0x6d, 0xa9, 0xe0, 0x52, 0xaa, 0x05, 0xa1, 0x0d,
-	0xc0, 0x2b, 0x21, 0x78, 0x1a, 0x15, 0x82, 0xe4, 0xb6, 0xb5, 0xb0, 0x96, 0xc3, 0xf5, 0x63, 0x8f,
-	0x45, 0x5e, 0x57, 0xc9, 0x6b, 0xca, 0xb6, 0x99, 0xe0, 0x65, 0xd8, 0xea, 0x93, 0x86, 0xb7, 0x9c,
-	0x53, 0x6e, 0xf7, 0xb4, 0x61, 0x95, 0xcc, 0x5e, 0xc2, 0xb8, 0xd3, 0x74, 0xd3, 0x25, 0x3f, 0x37,
-	0x9f, 0x19, 0xee, 0x0f, 0x03, 0xee, 0x56, 0x2e, 0x10, 0x82, 0x5e, 0x86, 0x3f, 0x93, 0xaa, 0x51,
-	0xc5, 0x12, 0x3b, 0x34, 0x53, 0xa9, 0x18, 0x8d, 0xc0, 0x4c, 0x63, 0xb5, 0xb6, 0x7e, 0x68, 0xa6,
-	0x31, 0x7a, 0x01, 0x80, 0x9b, 0xf1, 0x7a, 0x6a, 0xbc, 0x79, 0x6b, 0xbc, 0xcb, 0xa9, 0x9a, 0xf2,
-	0x5b, 0xfa, 0x5f, 0xff, 0x34, 0x60, 0x54, 0xc9, 0xbc, 0xd7, 0xe7, 0x89, 0x3e, 0x81, 0xbd, 0x23,
-	0xa2, 0xb5, 0xda, 0xd7, 0x65, 0x7d, 0x86, 0xe8, 0xbe, 0xb4, 0xd5, 0x39, 0xca, 0xd9, 0xf4, 0x7f,
-	0x4f, 0xe1, 0x3e, 0xfa, 0xf6, 0xeb, 0xf7, 0x77, 0xf3, 0x21, 0x9a, 0xfb, 0x5f, 0x73, 0xff, 0xfc,
-	0xa4, 0xfe, 0x05, 0xab, 0xa8, 0x5c, 0x1d, 0x49, 0xb9, 0xd2, 0x77, 0x1a, 0xc0, 0x70, 0x47, 0x84,
-	0x16, 0xd9, 0xc7, 0xe8, 0x9e, 0x64, 0xfa, 0x77, 0xa2, 0x57, 0x88, 0x1d, 0x45, 0xfc, 0x00, 0x4d,
-	0x2f, 0x89, 0xd3, 0x38, 0xba, 0xa3, 0xfe, 0xce, 0xd3, 0xbf, 0x01, 0x00, 0x00, 0xff, 0xff, 0x9d,
-	0x70, 0x12, 0x2e, 0x7d, 0x03, 0x00, 0x00,
+	// 1903 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x59, 0xdd, 0x72, 0xdb, 0x5a,
+	0x15, 0x46, 0x72, 0xec, 0xc4, 0xcb, 0xb1, 0xad, 0xec, 0xe6, 0x24, 0xae, 0x92, 0xd3, 0x06, 0xd1,
+	0xc3, 0xc9, 0x64, 0x26, 0x31, 0x04, 0xce, 0x0c, 0x1c, 0xe8, 0x0c, 0x6e, 0x92, 0x66, 0xdc, 0x94,
+	0xd6, 0x28, 0x6d, 0xa7, 0x85, 0xa1, 0x1d, 0x59, 0xda, 0x75, 0xd5, 0x38, 0x92, 0x90, 0xb6, 0xdd,
+	0xf8, 0x96, 0x0b, 0x18, 0xa6, 0x97, 0x5c, 0x73, 0xc3, 0x0c, 0x97, 0xbc, 0x00, 0xaf, 0xc1, 0xc0,
+	0x13, 0xf0, 0x1e, 0x30, 0xfb, 0x47, 0xd2, 0xd6, 0x8f, 0x93, 0x94, 0xf4, 0x4e, 0x7b, 0x69, 0xed,
+	0x6f, 0xfd, 0x2f, 0xad, 0x65, 0xc3, 0x66, 0x70, 0x36, 0xea, 0x06, 0xc3, 0x6e, 0x60, 0x85, 0xc4,
+	0xc3, 0xe1, 0x9b, 0x08, 0x87, 0x53, 0xd7, 0xc6, 0x7b, 0x41, 0xe8, 0x13, 0x1f, 0xa9, 0xc1, 0x50,
+	0xdf, 0x1c, 0xf9, 0xfe, 0x68, 0x8c, 0xbb, 0x56, 0xe0, 0x76, 0x2d, 0xcf, 0xf3, 0x89, 0x45, 0x5c,
+	0xdf, 0x8b, 0x38, 0x87, 0x31, 0x83, 0xf6, 0x09, 0x9e, 0xbd, 0xb0, 0xc6, 0x13, 0x6c, 0xe2, 0xdf,
+	0x4d, 0x70, 0x44, 0x90, 0x06, 0x95, 0x33, 0x3c, 0xeb, 0x28, 0x5b, 0xca, 0x76, 0xdd, 0xa4, 0x8f,
+	0x68, 0x15, 0xaa, 0x53, 0xca, 0xd1, 0x51, 0x19, 0x8d, 0x1f, 0x28, 0x75, 0x14, 0xfa, 0x93, 0xa0,
+	0x53, 0xe1, 0x54, 0x76, 0x40, 0xdb, 0xd0, 0x76, 0x3d, 0x7b, 0x3c, 0x71, 0x70, 0xdf, 0xb3, 0x6c,
+	0xe2, 0x4e, 0x71, 0x67, 0x61, 0x4b, 0xd9, 0x5e, 0x32, 0xf3, 0x64, 0xe3, 0xa3, 0x02, 0xf5, 0xbe,
+	0x13, 0x4b, 0xdd, 0x84, 0xba, 0xb0, 0xa1, 0xef, 0x30, 0xd9, 0x55, 0x33, 0x25, 0xa0, 0x2d, 0x68,
+	0x88, 0xc3, 0x81, 0xef, 0xc4, 0x7a, 0xc8, 0xa4, 0x1b, 0x6b, 0xf3, 0x27, 0x15, 0xb4, 0x01, 0xc7,
+	0x3b, 0xb4, 0x88, 0x65, 0xe2, 0x60, 0x3c, 0xa3, 0x4a, 0x0d, 0xf2, 0x4a, 0x0d, 0x64, 0xa5, 0x06,
+	0x45, 0xa5, 0x24, 0x12, 0x3a, 0x04, 0xe8, 0x11, 0x12, 0xba, 0xc3, 0x09, 0xc1, 0x51, 0xa7, 0xb2,
+	0x55, 0xd9, 0x6e, 0xec, 0xdf, 0xdb, 0x0b, 0x86, 0x7b, 0x79, 0x49, 0x7b, 0x29, 0xdb, 0x91, 0x47,
+	0xc2, 0x99, 0x29, 0xdd, 0xa3, 0xa6, 0x1d, 0x85, 0xa1, 0x1f, 0x32, 0xd5, 0xeb, 0x26, 0x3f, 0x20,
+	0x1d, 0x96, 0x4c, 0x3c, 0x75, 0x23, 0xd7, 0xf7, 0x3a, 0x55, 0xa6, 0x5a, 0x72, 0xd6, 0xef, 0x43,
+	0x3b, 0x07, 0x78, 0xdd, 0xa8, 0x7e, 0xab, 0xfe, 0x44, 0x31, 0xfa, 0xd0, 0x3c, 0xba, 0x08, 0xfc,
+	0x90, 0xc4, 0xc1, 0x49, 0x9c, 0xab, 0xc8, 0xce, 0x35, 0x60, 0x59, 0x8a, 0x40, 0xd4, 0x51, 0xb7,
+	0x2a, 0xdb, 0x75, 0x33, 0x43, 0x33, 0x1e, 0x41, 0xeb, 0xc0, 0x3f, 0x0f, 0xac, 0x30, 0x49, 0xaf,
+	0xfc, 0x2d, 0xa5, 0x78, 0x2b, 0x95, 0xa7, 0x4a, 0xf2, 0x8c, 0x3f, 0xaa, 0xb0, 0x7c, 0x30, 0xf6,
+	0xbd, 0x04, 0x0a, 0xc1, 0x82, 0x67, 0x9d, 0x63, 0xa1, 0x15, 0x7b, 0xa6, 0x34, 0x3b, 0x8d, 0x06,
+	0x7b, 0x46, 0x77, 0x00, 0x22, 0x7f, 0x12, 0xda, 0x98, 0xc5, 0x89, 0x27, 0x88, 0x44, 0xa1, 0xae,
+	0x24, 0xf8, 0x3c, 0x18, 0x5b, 0x04, 0x0b, 0x1f, 0x27, 0x67, 0xb4, 0x06, 0x35, 0x26, 0x3d, 0xea,
+	0x54, 0x99, 0xa2, 0xe2, 0x84, 0xee, 0x43, 0xdd, 0x9f, 0xe2, 0x30, 0x74, 0xa9, 0x0d, 0x35, 0x16,
+	0xd9, 0xbb, 0x34, 0xb2, 0xb2, 0x82, 0x7b, 0x4f, 0x63, 0x0e, 0x1e, 0xd4, 0xf4, 0x86, 0xfe, 0x73,
+	0x68, 0x65, 0x5f, 0x7e, 0x52, 0x80, 0x26, 0xd0, 0x3c, 0x25, 0x16, 0x99, 0x44, 0xb1, 0x27, 0x72,
+	0xf5, 0xa1, 0x14, 0xeb, 0x63, 0x0d, 0x6a, 0x11, 0xbb, 0x22, 0xd0, 0xc4, 0x09, 0xed, 0x80, 0x86,
+	0x2f, 0x02, 0x6c, 0x13, 0xec, 0x24, 0xe9, 0x54, 0x61, 0xe9, 0x54, 0xa0, 0x1b, 0x7f, 0x53, 0xa0,
+	0x11, 0xcb, 0x15, 0xe5, 0x71, 0xa3, 0x9a, 0x4d, 0x75, 0xaa, 0x64, 0x74, 0xda, 0x86, 0x36, 0x7f,
+	0x3a, 0x78, 0x67, 0x79, 0x23, 0xec, 0xf4, 0x88, 0x08, 0x4b, 0x9e, 0x9c, 0x96, 0x46, 0x55, 0x2a,
+	0x0d, 0xe3, 0x0f, 0x0a, 0xb4, 0x4c, 0x1c, 0x11, 0x3f, 0xcd, 0xba, 0xab, 0x1d, 0x24, 0xfc, 0xaf,
+	0x66, 0xfc, 0x5f, 0xd2, 0x52, 0xca, 0x1c, 0xb6, 0x30, 0xc7, 0x61, 0xbf, 0x80, 0xe5, 0x44, 0x0f,
+	0xea, 0x30, 0x1d, 0x96, 0x42, 0x7e, 0x8e, 0xfd, 0x95, 0x9c, 0x53, 0x53, 0x54, 0xd9, 0x94, 0x1d,
+	0x58, 0x7d, 0x1a, 0x60, 0x8f, 0x5b, 0x7c, 0x8a, 0xc9, 0x25, 0xa9, 0x6f, 0xfc, 0x5d, 0x05, 0x74,
+	0x4a, 0xac, 0x11, 0xe6, 0xdc, 0x92, 0xe9, 0x76, 0x7c, 0x3d, 0x89, 0x93, 0x4c, 0xba, 0x46, 0xa4,
+	0x52, 0x8e, 0x27, 0x54, 0x6a, 0x25, 0xc3, 0x41, 0x49, 0xe8, 0x21, 0x80, 0x95, 0xb6, 0xba, 0x05,
+	0x56, 0x10, 0xdf, 0xa7, 0x05, 0x51, 0xd4, 0xa8, 0xd8, 0xec, 0xd2, 0x9b, 0xa5, 0xee, 0xad, 0x96,
+	0xbb, 0xf7, 0xa6, 0x6d, 0xee, 0x19, 0xb4, 0x06, 0x21, 0x9e, 0xba, 0xf8, 0xc3, 0x67, 0x74, 0x95,
+	0xf1, 0x63, 0xd0, 0x0a, 0xd1, 0xba, 0x12, 0xd7, 0xf8, 0x8b, 0x02, 0x2d, 0xe9, 0x1a, 0x4d, 0x96,
+	0xab, 0x95, 0x89, 0x93, 0x40, 0x95, 0xfa, 0xdf, 0xbc, 0x9a, 0xfa, 0x1a, 0x96, 0x84, 0x96, 0x71,
+	0x74, 0x1a, 0xd2, 0x87, 0xc8, 0x4c, 0x5e, 0xce, 0x29, 0xa9, 0x6f, 0x60, 0xe3, 0xb1, 0x1b, 0x91,
+	0x5e, 0x10, 0x84, 0xfe, 0xd4, 0x1a, 0x0b, 0xc3, 0x92, 0xfe, 0x93, 0x4a, 0x55, 0x64, 0xa9, 0xc6,
+	0x53, 0x58, 0x8f, 0xaf, 0x1c, 0x62, 0x9b, 0x45, 0x4d, 0xfa, 0xe0, 0x87, 0xfc, 0x31, 0x6d, 0x1e,
+	0x09, 0x81, 0x02, 0x86, 0xd8, 0x8a, 0x7c, 0x2f, 0x6e, 0x57, 0xfc, 0x64, 0x7c, 0x54, 0xa1, 0x9d,
+	0x53, 0x02, 0xb5, 0x40, 0x75, 0x63, 0x08, 0xd5, 0x75, 0xf2, 0x8e, 0x53, 0x8b, 0x8e, 0x4b, 0x9b,
+	0x7a, 0x25, 0xd3, 0xd4, 0x53, 0x33, 0x16, 0x32, 0xce, 0xdb, 0x82, 0x86, 0x50, 0x0d, 0x3b, 0x0f,
+	0x66, 0xc2, 0x33, 0x32, 0x29, 0xc3, 0xd1, 0x23, 0x9d, 0x5a, 0x8e, 0xa3, 0xc7, 0xec, 0x75, 0xb0,
+	0xed, 0x3a, 0x0c, 0x61, 0x91, 0xbd, 0x4f, 0x09, 0xd2, 0xdb, 0x1e, 0xe9, 0x2c, 0x65, 0xde, 0xf6,
+	0x88, 0xe4, 0x8d, 0x7a, 0xc6, 0x1b, 0xaf, 0xe1, 0x8b, 0x62, 0x44, 0x68, 0xee, 0x74, 0x69, 0xa3,
+	0xe1, 0x04, 0xf6, 0x81, 0x6d, 0xec, 0xdf, 0xa2, 0xd1, 0xce, 0x31, 0x9b, 0x09, 0xd3, 0x9c, 0xee,
+	0xf3, 0x0c, 0x9a, 0xe9, 0x15, 0x8a, 0xbb, 0x0b, 0x8b, 0xe2, 0x0a, 0xf3, 0xf7, 0x1c, 0xd8, 0x98,
+	0x67, 0x0e, 0xea, 0x4b, 0xd0, 0x7a, 0xb6, 0x8d, 0xa3, 0xe8, 0xb1, 0x3f, 0xba, 0x7e, 0x7f, 0x46,
+	0xb0, 0xf0, 0x36, 0xf4, 0xcf, 0xe3, 0x64, 0xa7, 0xcf, 0x34, 0xf2, 0xc4, 0x17, 0x89, 0xae, 0x12,
+	0xdf, 0xf8, 0xab, 0x02, 0xcb, 0x1c, 0xda, 0xc4, 0xb6, 0x1f, 0x3a, 0x85, 0xd4, 0x58, 0x83, 0x9a,
+	0x6d, 0x8d, 0xc7, 0x38, 0xd6, 0x48, 0x9c, 0xf2, 0xe2, 0x2b, 0xa5, 0xe2, 0xcf, 0xf0, 0x8c, 0xd7,
+	0x4e, 0xdd, 0x64, 0xcf, 0xd9, 0x14, 0xe6, 0x49, 0x51, 0x48, 0xe1, 0x34, 0x1b, 0xc4, 0xc9, 0x30,
+	0xa1, 0x25, 0x99, 0x4f, 0xbd, 0xba, 0x43, 0xbd, 0x4a, 0xf5, 0x8d, 0x83, 0xa5, 0x31, 0xaf, 0x4a,
+	0x86, 0x98, 0x31, 0xc3, 0x1c, 0x97, 0xbe, 0x06, 0xd4, 0x8f, 0xa2, 0x09, 0xee, 0x05, 0xee, 0x09,
+	0x9e, 0x49, 0x63, 0x9b, 0xff, 0xc1, 0xc3, 0x61, 0x3c, 0xb6, 0xb1, 0x03, 0xa5, 0x46, 0xb6, 0x1f,
+	0x60, 0x31, 0xaf, 0xf1, 0x03, 0xb5, 0x05, 0x5f, 0x04, 0x6e, 0x88, 0xa3, 0x1e, 0x11, 0xf6, 0xa7,
+	0x04, 0x63, 0x07, 0x10, 0x2f, 0x7f, 0x0a, 0x1f, 0x5d, 0x8a, 0x6f, 0xdc, 0x85, 0x26, 0xe7, 0x9b,
+	0x53, 0x9f, 0xc6, 0xbf, 0x15, 0xa8, 0x71, 0x8e, 0xb2, 0xf8, 0x04, 0x21, 0x7e, 0xeb, 0x5e, 0xc4,
+	0xf1, 0xe1, 0xa7, 0x54, 0x52, 0xa5, 0xd4, 0x92, 0x85, 0xb9, 0x96, 0x54, 0x73, 0x96, 0xd0, 0x59,
+	0x70, 0x6c, 0x45, 0xe4, 0x79, 0x24, 0xd5, 0xa9, 0x44, 0xa1, 0xb7, 0xed, 0x10, 0x5b, 0xbc, 0x8c,
+	0x45, 0x99, 0x26, 0x04, 0x1e, 0xf1, 0xa9, 0x7f, 0x26, 0x97, 0x69, 0x42, 0x30, 0x5e, 0x41, 0x23,
+	0xb6, 0x9c, 0x86, 0xd5, 0x80, 0x9a, 0xc5, 0x8e, 0xa2, 0x56, 0x80, 0xd7, 0x0a, 0x63, 0x10, 0x6f,
+	0xca, 0xa7, 0x0e, 0x1e, 0xe0, 0x8a, 0x1c, 0xe0, 0x47, 0xb0, 0x9c, 0x38, 0x9f, 0x62, 0xdf, 0x83,
+	0x45, 0x8e, 0x10, 0xa7, 0x8c, 0x0c, 0x1e, 0xbf, 0x9a, 0x93, 0x2c, 0xff, 0x50, 0xa0, 0x79, 0x82,
+	0x67, 0x7c, 0x2e, 0x77, 0x23, 0xdf, 0x2b, 0xf9, 0x6a, 0x7e, 0x95, 0x19, 0x17, 0x5b, 0xfb, 0x4d,
+	0x0a, 0x7f, 0x82, 0x67, 0x62, 0xfc, 0x13, 0x2f, 0xd1, 0x37, 0x50, 0x63, 0xdf, 0xd3, 0x78, 0xb9,
+	0xf9, 0x52, 0xb0, 0xa5, 0xd8, 0x7b, 0x6c, 0xb7, 0x14, 0x1f, 0x7a, 0xc1, 0xac, 0xff, 0x14, 0x1a,
+	0x12, 0xf9, 0x93, 0x3e, 0xda, 0x3e, 0x2c, 0x27, 0x0b, 0x05, 0x77, 0xf2, 0xd5, 0xeb, 0xc4, 0x57,
+	0xa2, 0x76, 0x55, 0xa6, 0xe3, 0x4a, 0x41, 0x47, 0x51, 0xce, 0xe5, 0x9e, 0xff, 0x97, 0x02, 0x8b,
+	0xe2, 0x2b, 0x79, 0xed, 0x85, 0x83, 0xa7, 0x75, 0x25, 0x49, 0xeb, 0x9f, 0x95, 0x0c, 0x47, 0x1b,
+	0xd2, 0xe7, 0xf7, 0xd2, 0x89, 0x28, 0xfd, 0x28, 0x55, 0xe5, 0x8f, 0xd2, 0x0d, 0xa7, 0x9f, 0x9d,
+	0x7d, 0xa8, 0x27, 0xf1, 0x44, 0x75, 0xa8, 0x1e, 0xfd, 0xea, 0x79, 0xef, 0xb1, 0xf6, 0x1d, 0xd4,
+	0x84, 0xfa, 0x61, 0xff, 0xe1, 0xc3, 0x23, 0xf3, 0xe8, 0xc9, 0x33, 0x4d, 0x41, 0x0d, 0x58, 0xfc,
+	0x65, 0xff, 0xf4, 0xb4, 0xff, 0xe4, 0x58, 0x53, 0xf7, 0xff, 0xab, 0x41, 0x4b, 0xa8, 0x7c, 0xca,
+	0x7f, 0x68, 0x40, 0xef, 0xa1, 0x73, 0x8c, 0x89, 0xb4, 0xcf, 0x3e, 0x98, 0xc5, 0x3f, 0x28, 0xa0,
+	0x5b, 0xc2, 0xd3, 0xf2, 0xcf, 0x0b, 0xfa, 0x6a, 0xd9, 0xfe, 0x6b, 0x7c, 0xef, 0xf7, 0xff, 0xfc,
+	0xcf, 0x9f, 0xd5, 0x2f, 0xd1, 0x46, 0xf7, 0x43, 0xd4, 0x9d, 0xfe, 0x30, 0xfe, 0x3d, 0x63, 0x77,
+	0x38, 0xdb, 0x3d, 0xc3, 0xb3, 0x5d, 0xa6, 0x36, 0x1a, 0x40, 0xe3, 0x18, 0x13, 0x2e, 0xa4, 0xef,
+	0x20, 0x96, 0x93, 0x7d, 0xe7, 0x72, 0xe0, 0x4d, 0x06, 0xbc, 0x86, 0x56, 0x8b, 0xc0, 0xae, 0x83,
+	0x4c, 0x68, 0xf1, 0x4d, 0x77, 0x10, 0x8f, 0x3f, 0x2c, 0x3b, 0x32, 0xdb, 0xaf, 0x2e, 0x0f, 0x4a,
+	0xc6, 0x1d, 0x86, 0xd7, 0x41, 0x6b, 0x59, 0xbc, 0xa8, 0x8b, 0xd9, 0x9d, 0x1f, 0x28, 0xe8, 0x25,
+	0xb4, 0x45, 0x86, 0x26, 0xa0, 0x88, 0x6d, 0x86, 0x99, 0x3d, 0x58, 0xd7, 0x32, 0x34, 0xaa, 0xea,
+	0x5d, 0x06, 0x7d, 0x1b, 0xad, 0xe7, 0xa1, 0x6d, 0xce, 0x85, 0x5e, 0x8a, 0xfd, 0x37, 0x4e, 0x47,
+	0x2d, 0xbf, 0x70, 0xce, 0xf1, 0xc1, 0x16, 0x03, 0xd6, 0xbf, 0x55, 0x76, 0x8c, 0x2f, 0x0a, 0xd8,
+	0xf4, 0x3a, 0x7a, 0x05, 0xda, 0x69, 0x12, 0x45, 0x91, 0x13, 0x2b, 0x62, 0x7a, 0x4f, 0xd7, 0x4c,
+	0xbd, 0x2d, 0x93, 0x28, 0xf2, 0x77, 0x19, 0xf2, 0x06, 0x45, 0x2e, 0x38, 0x44, 0xb4, 0x88, 0x5f,
+	0x27, 0xbb, 0x58, 0x52, 0x45, 0x14, 0x25, 0xbb, 0x9f, 0xe9, 0x5a, 0x86, 0x46, 0xa1, 0x0d, 0x06,
+	0xbd, 0x49, 0xa1, 0x0b, 0x0e, 0x11, 0x4b, 0x13, 0x32, 0x01, 0xc4, 0x1d, 0xda, 0x4b, 0xaf, 0x87,
+	0x2b, 0x02, 0x48, 0x71, 0x6f, 0x09, 0x5c, 0x5a, 0xfd, 0x09, 0xe6, 0x8b, 0x64, 0x67, 0x3b, 0x66,
+	0xfb, 0xde, 0xf5, 0x50, 0x4b, 0x5c, 0xcc, 0x67, 0xca, 0x04, 0xd7, 0x83, 0xf5, 0xac, 0x1f, 0x92,
+	0xe2, 0xbd, 0xa6, 0x88, 0x5d, 0x26, 0xe2, 0x6b, 0x2a, 0xc2, 0xc8, 0x3b, 0x24, 0x6d, 0x17, 0x89,
+	0xbc, 0xdf, 0x40, 0x33, 0xb3, 0x39, 0xa2, 0x0e, 0x45, 0x2c, 0x5b, 0x26, 0x75, 0x9e, 0x9e, 0x99,
+	0xed, 0x23, 0xae, 0x1b, 0x2a, 0x6d, 0x45, 0x48, 0xe3, 0x13, 0x74, 0x84, 0x49, 0x84, 0x7e, 0x0b,
+	0x0d, 0x69, 0xaf, 0x43, 0x6b, 0xe5, 0x8b, 0x5e, 0x29, 0x70, 0x49, 0x5c, 0x53, 0x60, 0x9a, 0x34,
+	0x23, 0x8c, 0xde, 0x80, 0x26, 0x36, 0xb3, 0x54, 0x7d, 0x86, 0x95, 0xdd, 0xd7, 0xe6, 0xa4, 0xbb,
+	0x48, 0x4a, 0x74, 0xbb, 0x08, 0x1f, 0xf0, 0xfb, 0x68, 0x08, 0xda, 0x60, 0x32, 0x1c, 0xbb, 0xd1,
+	0xbb, 0x54, 0xc0, 0x6a, 0x4e, 0xd9, 0xf9, 0x26, 0xdc, 0x63, 0x02, 0xee, 0x50, 0x13, 0xca, 0x64,
+	0x70, 0x60, 0x2a, 0xe3, 0xd0, 0x8d, 0x6c, 0x2b, 0x74, 0x3e, 0xbf, 0x0c, 0x87, 0x03, 0xa3, 0x31,
+	0xac, 0x96, 0xad, 0x65, 0x88, 0xfd, 0x14, 0x75, 0xc9, 0xc2, 0xa6, 0xdf, 0x2e, 0x99, 0xdb, 0x45,
+	0x4d, 0x77, 0x98, 0x64, 0x84, 0x34, 0x21, 0xd6, 0x12, 0x5c, 0x11, 0xc2, 0xd0, 0xe2, 0x57, 0x92,
+	0xdf, 0x16, 0x36, 0x64, 0x98, 0xdc, 0x86, 0xa7, 0xaf, 0x64, 0x65, 0x48, 0x6d, 0x9e, 0x5a, 0xd5,
+	0xc9, 0xc3, 0x8b, 0x27, 0x8c, 0x6c, 0x68, 0x9a, 0xf8, 0x3d, 0xb6, 0xc9, 0xff, 0x2b, 0xa5, 0x24,
+	0xc5, 0x52, 0x29, 0x21, 0x83, 0x46, 0x16, 0xac, 0x30, 0xff, 0x48, 0x43, 0x76, 0xc4, 0xc3, 0x93,
+	0xdf, 0x4d, 0x74, 0x94, 0xa3, 0x4a, 0x22, 0x90, 0x5e, 0xa8, 0x44, 0xc6, 0xb7, 0x3b, 0xf6, 0x47,
+	0xc8, 0x84, 0x86, 0x34, 0x94, 0xf3, 0x22, 0x29, 0x4e, 0xe9, 0xbc, 0xa9, 0x4a, 0x73, 0xa3, 0xa1,
+	0x33, 0xec, 0x55, 0xaa, 0x7e, 0x3b, 0x51, 0xdf, 0xdd, 0x65, 0x33, 0xca, 0x00, 0x1a, 0xd2, 0x20,
+	0xce, 0x31, 0x8b, 0x93, 0x39, 0xef, 0x1e, 0xf2, 0xc0, 0x68, 0xac, 0x33, 0xd0, 0x15, 0x54, 0x40,
+	0x7c, 0x0e, 0xcb, 0xa6, 0x4f, 0x2c, 0x12, 0xab, 0xb9, 0x22, 0xab, 0x33, 0x47, 0xc3, 0x92, 0xb6,
+	0x1f, 0xe3, 0x75, 0x43, 0x06, 0xc6, 0x60, 0xd9, 0x60, 0xfc, 0xb9, 0x60, 0x19, 0xd8, 0xb0, 0xc6,
+	0xfe, 0xb6, 0xf8, 0xd1, 0xff, 0x06, 0x00, 0xe4, 0xb1, 0x8b, 0x18, 0xf8, 0x18, 0x00, 0x00,
 }
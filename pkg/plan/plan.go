@@ -0,0 +1,256 @@
+package plan
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/export"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/pb"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/secrets"
+)
+
+const (
+	Create = "create"
+	Update = "update"
+	Delete = "delete"
+)
+
+// Change is one partner that applying the plan will create, update or delete.
+// Before is nil for a create and After is nil for a delete.
+type Change struct {
+	Action string
+	Code   string
+	Before *pb.Partner
+	After  *pb.Partner
+}
+
+// Plan is the set of changes that brings the live partners in line with the partner files, ordered by code.
+type Plan struct {
+	Changes []Change
+}
+
+// LoadDir reads every .yaml and .yml file in dir. Each file holds one partner in the same shape as a YAML export entry.
+func LoadDir(dir string) ([]*pb.Partner, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("failed to read partner directory: %s", dir))
+	}
+
+	var partners []*pb.Partner
+	seen := make(map[string]string)
+	for _, f := range files {
+		ext := filepath.Ext(f.Name())
+		if f.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		path := filepath.Join(dir, f.Name())
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to read partner file: %s", path))
+		}
+		var doc export.Partner
+		if err = yaml.Unmarshal(b, &doc); err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to parse partner file: %s", path))
+		}
+		if doc.Code == "" {
+			return nil, errors.New(fmt.Sprintf("missing code in partner file: %s", path))
+		}
+		if other, ok := seen[doc.Code]; ok {
+			return nil, errors.New(fmt.Sprintf("partner code %s is in both %s and %s", doc.Code, other, path))
+		}
+		seen[doc.Code] = path
+
+		attrs := doc.Attributes
+		if attrs == nil {
+			attrs = make(map[string]string)
+		}
+		partners = append(partners, &pb.Partner{Name: doc.Name, Code: doc.Code, Status: doc.Status, Attributes: attrs})
+	}
+	return partners, nil
+}
+
+// Diff compares the desired partners against the live ones. Partners are matched on code and ids are ignored.
+// Live partners without a desired counterpart are only deleted when prune is set. Redacted values keep the live
+// values they stand in for, see KeepRedacted.
+func Diff(desired, live []*pb.Partner, prune bool) (Plan, error) {
+	desired, err := KeepRedacted(desired, live)
+	if err != nil {
+		return Plan{}, err
+	}
+	liveByCode := make(map[string]*pb.Partner)
+	for _, p := range live {
+		liveByCode[p.Code] = p
+	}
+	desiredCodes := make(map[string]bool)
+
+	var changes []Change
+	for _, want := range desired {
+		desiredCodes[want.Code] = true
+		have, ok := liveByCode[want.Code]
+		if !ok {
+			changes = append(changes, Change{Action: Create, Code: want.Code, After: want})
+			continue
+		}
+		if have.Name != want.Name || !equalAttributes(have.Attributes, want.Attributes) {
+			changes = append(changes, Change{Action: Update, Code: want.Code, Before: have, After: want})
+		}
+	}
+	if prune {
+		for _, have := range live {
+			if !desiredCodes[have.Code] {
+				changes = append(changes, Change{Action: Delete, Code: have.Code, Before: have})
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Code < changes[j].Code })
+	return Plan{Changes: changes}, nil
+}
+
+// KeepRedacted returns copies of the desired partners with each secrets.Redacted value replaced by the live value it
+// stands in for, so that files exported by a caller who could not see sensitive values leave them as they are.
+// It fails if a redacted value has no live value to keep, rather than saving the placeholder.
+func KeepRedacted(desired, live []*pb.Partner) ([]*pb.Partner, error) {
+	liveByCode := make(map[string]*pb.Partner)
+	for _, p := range live {
+		liveByCode[p.Code] = p
+	}
+
+	kept := make([]*pb.Partner, 0, len(desired))
+	for _, want := range desired {
+		attrs := make(map[string]string, len(want.Attributes))
+		for _, key := range sortedKeys(want.Attributes) {
+			value := want.Attributes[key]
+			if value == secrets.Redacted {
+				have, ok := liveByCode[want.Code]
+				if !ok {
+					return nil, errors.New(fmt.Sprintf("partner %s has a redacted %s but does not exist yet", want.Code, key))
+				}
+				if value, ok = have.Attributes[key]; !ok {
+					return nil, errors.New(fmt.Sprintf("partner %s has a redacted %s but no value to keep", want.Code, key))
+				}
+			}
+			attrs[key] = value
+		}
+		partner := *want
+		partner.Attributes = attrs
+		kept = append(kept, &partner)
+	}
+	return kept, nil
+}
+
+// Empty reports whether applying the plan would change nothing.
+func (p Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// Saves returns the partners to create or update, with the full set of attributes each should end up with.
+func (p Plan) Saves() []*pb.Partner {
+	var partners []*pb.Partner
+	for _, c := range p.Changes {
+		if c.Action != Delete {
+			partners = append(partners, c.After)
+		}
+	}
+	return partners
+}
+
+// Deletes returns the codes of the partners to delete.
+func (p Plan) Deletes() []string {
+	var codes []string
+	for _, c := range p.Changes {
+		if c.Action == Delete {
+			codes = append(codes, c.Code)
+		}
+	}
+	return codes
+}
+
+// Write prints the plan in the style of terraform plan: + for creates, ~ for updates and - for deletes,
+// with the changed keys of each partner indented below it and a summary line at the end.
+func (p Plan) Write(w io.Writer) error {
+	if p.Empty() {
+		_, err := fmt.Fprintln(w, "No changes. Partners are up to date.")
+		return err
+	}
+
+	var lines []string
+	counts := make(map[string]int)
+	for _, c := range p.Changes {
+		counts[c.Action]++
+		switch c.Action {
+		case Create:
+			lines = append(lines, fmt.Sprintf("+ partner %q (%s)", c.Code, c.After.Name))
+			for _, key := range sortedKeys(c.After.Attributes) {
+				lines = append(lines, fmt.Sprintf("    + %s = %q", key, c.After.Attributes[key]))
+			}
+		case Update:
+			lines = append(lines, fmt.Sprintf("~ partner %q (%s)", c.Code, c.After.Name))
+			if c.Before.Name != c.After.Name {
+				lines = append(lines, fmt.Sprintf("    ~ name: %q -> %q", c.Before.Name, c.After.Name))
+			}
+			lines = append(lines, attributeChanges(c.Before.Attributes, c.After.Attributes)...)
+		case Delete:
+			lines = append(lines, fmt.Sprintf("- partner %q (%s)", c.Code, c.Before.Name))
+			for _, key := range sortedKeys(c.Before.Attributes) {
+				lines = append(lines, fmt.Sprintf("    - %s = %q", key, c.Before.Attributes[key]))
+			}
+		}
+	}
+	lines = append(lines, "", fmt.Sprintf("Plan: %d to create, %d to update, %d to delete.", counts[Create], counts[Update], counts[Delete]))
+
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+func attributeChanges(before, after map[string]string) []string {
+	all := make(map[string]string)
+	for key, value := range before {
+		all[key] = value
+	}
+	for key, value := range after {
+		all[key] = value
+	}
+
+	var lines []string
+	for _, key := range sortedKeys(all) {
+		old, hadOld := before[key]
+		value, hasNew := after[key]
+		switch {
+		case !hadOld:
+			lines = append(lines, fmt.Sprintf("    + %s = %q", key, value))
+		case !hasNew:
+			lines = append(lines, fmt.Sprintf("    - %s = %q", key, old))
+		case old != value:
+			lines = append(lines, fmt.Sprintf("    ~ %s: %q -> %q", key, old, value))
+		}
+	}
+	return lines
+}
+
+func equalAttributes(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if other, ok := b[key]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
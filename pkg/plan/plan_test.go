@@ -0,0 +1,189 @@
+package plan
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/export"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/pb"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/secrets"
+)
+
+func livePartners() []*pb.Partner {
+	return []*pb.Partner{
+		{Id: 1, Name: "Kohls", Code: "KOH", Attributes: map[string]string{"Currency": "USD", "Qualifier": "ZZ"}},
+		{Id: 2, Name: "JC Penny", Code: "JCP", Attributes: map[string]string{"Currency": "CAD"}},
+		{Id: 3, Name: "Mustang", Code: "MUS", Attributes: map[string]string{}},
+	}
+}
+
+func desiredPartners() []*pb.Partner {
+	return []*pb.Partner{
+		{Name: "Kohls", Code: "KOH", Attributes: map[string]string{"Currency": "CAD", "ISAID": "KOHLS"}},
+		{Name: "JC Penny", Code: "JCP", Attributes: map[string]string{"Currency": "CAD"}},
+		{Name: "Dicks", Code: "DIC", Attributes: map[string]string{"Currency": "USD"}},
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := assert.New(t)
+
+	p, err := Diff(desiredPartners(), livePartners(), false)
+
+	a.Nil(err)
+	a.Equal(2, len(p.Changes))
+	a.Equal(Change{Action: Create, Code: "DIC", After: desiredPartners()[2]}, p.Changes[0])
+	a.Equal(Change{Action: Update, Code: "KOH", Before: livePartners()[0], After: desiredPartners()[0]}, p.Changes[1])
+	a.Equal([]*pb.Partner{desiredPartners()[2], desiredPartners()[0]}, p.Saves())
+	a.Nil(p.Deletes())
+}
+
+func TestDiffPrune(t *testing.T) {
+	a := assert.New(t)
+
+	p, err := Diff(desiredPartners(), livePartners(), true)
+
+	a.Nil(err)
+	a.Equal(3, len(p.Changes))
+	a.Equal(Change{Action: Delete, Code: "MUS", Before: livePartners()[2]}, p.Changes[2])
+	a.Equal([]string{"MUS"}, p.Deletes())
+}
+
+func TestDiffNoChanges(t *testing.T) {
+	a := assert.New(t)
+
+	p, err := Diff(livePartners(), livePartners(), true)
+
+	a.Nil(err)
+	a.True(p.Empty())
+}
+
+func TestDiffRedactedRoundTrip(t *testing.T) {
+	a := assert.New(t)
+	live := livePartners()
+	live[0].Attributes["Password"] = "hunter2"
+
+	//export as a caller who cannot see the password, then apply the export as it was read back
+	exported := livePartners()
+	exported[0].Attributes["Password"] = secrets.Redacted
+	var buf bytes.Buffer
+	a.Nil(export.Write(&buf, export.FormatYAML, exported))
+	read, err := export.Read(&buf, export.FormatYAML)
+	a.Nil(err)
+
+	p, err := Diff(read, live, true)
+	a.Nil(err)
+	a.True(p.Empty())
+
+	read[0].Attributes["Currency"] = "CAD"
+	p, err = Diff(read, live, true)
+	a.Nil(err)
+	if a.Len(p.Saves(), 1) {
+		a.Equal("hunter2", p.Saves()[0].Attributes["Password"])
+	}
+	a.Equal(secrets.Redacted, read[0].Attributes["Password"])
+}
+
+func TestDiffRedactedWithoutLiveValue(t *testing.T) {
+	desired := desiredPartners()
+	desired[0].Attributes["Password"] = secrets.Redacted
+	_, err := Diff(desired, livePartners(), false)
+	assert.EqualError(t, err, "partner KOH has a redacted Password but no value to keep")
+
+	desired = desiredPartners()
+	desired[2].Attributes["Password"] = secrets.Redacted
+	_, err = Diff(desired, livePartners(), false)
+	assert.EqualError(t, err, "partner DIC has a redacted Password but does not exist yet")
+}
+
+func TestWrite(t *testing.T) {
+	a := assert.New(t)
+	var buf bytes.Buffer
+
+	live := livePartners()
+	live[1].Name = "JCPenney"
+	p, err := Diff(desiredPartners(), live, true)
+	a.Nil(err)
+	err = p.Write(&buf)
+
+	a.Nil(err)
+	a.Equal(`+ partner "DIC" (Dicks)
+    + Currency = "USD"
+~ partner "JCP" (JC Penny)
+    ~ name: "JCPenney" -> "JC Penny"
+~ partner "KOH" (Kohls)
+    ~ Currency: "USD" -> "CAD"
+    + ISAID = "KOHLS"
+    - Qualifier = "ZZ"
+- partner "MUS" (Mustang)
+
+Plan: 1 to create, 2 to update, 1 to delete.
+`, buf.String())
+}
+
+func TestWriteNoChanges(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := Plan{}.Write(&buf)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "No changes. Partners are up to date.\n", buf.String())
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "partners")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadDir(t *testing.T) {
+	a := assert.New(t)
+	dir := writeFiles(t, map[string]string{
+		"kohls.yaml": "name: Kohls\ncode: KOH\nattributes:\n  Currency: USD\n",
+		"mus.yml":    "name: Mustang\ncode: MUS\n",
+		"README.md":  "not a partner",
+	})
+	defer os.RemoveAll(dir)
+
+	partners, err := LoadDir(dir)
+
+	a.Nil(err)
+	a.Equal([]*pb.Partner{
+		{Name: "Kohls", Code: "KOH", Attributes: map[string]string{"Currency": "USD"}},
+		{Name: "Mustang", Code: "MUS", Attributes: map[string]string{}},
+	}, partners)
+}
+
+func TestLoadDirDuplicateCode(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.yaml": "name: Kohls\ncode: KOH\n",
+		"b.yaml": "name: Kohls Again\ncode: KOH\n",
+	})
+	defer os.RemoveAll(dir)
+
+	partners, err := LoadDir(dir)
+
+	assert.Nil(t, partners)
+	assert.NotNil(t, err)
+}
+
+func TestLoadDirMissingCode(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"a.yaml": "name: Kohls\n"})
+	defer os.RemoveAll(dir)
+
+	partners, err := LoadDir(dir)
+
+	assert.Nil(t, partners)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,172 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// sweepInterval is how often buckets that have filled back up are dropped, since a full bucket is the same as none.
+const sweepInterval = time.Minute
+
+// Limit is a token bucket that lets a client make Rate calls a second, and up to Burst at once. A Rate of 0 is no limit.
+type Limit struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+// Config is the limit on each client for each method, and the default limit for methods it does not list.
+type Config struct {
+	Default Limit            `yaml:"default"`
+	Methods map[string]Limit `yaml:"methods"`
+}
+
+// LoadConfig reads a config from a YAML file. Every limit with a rate must allow a burst of at least one call.
+func LoadConfig(path string) (Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Config{}, errors.Wrap(err, fmt.Sprintf("failed to read rate limit file: %s", path))
+	}
+	var config Config
+	if err = yaml.Unmarshal(b, &config); err != nil {
+		return Config{}, errors.Wrap(err, fmt.Sprintf("failed to parse rate limit file: %s", path))
+	}
+	if err = config.Default.validate("default"); err != nil {
+		return Config{}, errors.Wrap(err, fmt.Sprintf("bad rate limit file: %s", path))
+	}
+	for method, limit := range config.Methods {
+		if err = limit.validate(method); err != nil {
+			return Config{}, errors.Wrap(err, fmt.Sprintf("bad rate limit file: %s", path))
+		}
+	}
+	return config, nil
+}
+
+func (l Limit) validate(name string) error {
+	if l.Rate < 0 {
+		return errors.New(fmt.Sprintf("rate of %s is negative", name))
+	}
+	if l.Rate > 0 && l.Burst < 1 {
+		return errors.New(fmt.Sprintf("burst of %s must be at least 1", name))
+	}
+	return nil
+}
+
+func (c Config) limit(method string) Limit {
+	if limit, ok := c.Methods[method]; ok {
+		return limit
+	}
+	return c.Default
+}
+
+// Counts are the calls a client made that were allowed and that were over the limit.
+type Counts struct {
+	Allowed int64 `json:"allowed"`
+	Limited int64 `json:"limited"`
+}
+
+type bucketKey struct {
+	method string
+	client string
+}
+
+type bucket struct {
+	tokens float64
+	at     time.Time
+}
+
+// Limiter keeps a token bucket for each client of each method.
+type Limiter struct {
+	config Config
+	now    func() time.Time
+
+	mu      sync.Mutex
+	buckets map[bucketKey]*bucket
+	counts  map[string]*Counts
+	swept   time.Time
+}
+
+func NewLimiter(config Config) *Limiter {
+	return &Limiter{
+		config:  config,
+		now:     time.Now,
+		buckets: make(map[bucketKey]*bucket),
+		counts:  make(map[string]*Counts),
+	}
+}
+
+// Allow takes a token from the client's bucket for the method. When the bucket is empty the call is not allowed, and
+// the time until there is a token again is returned.
+func (l *Limiter) Allow(method, client string) (bool, time.Duration) {
+	limit := l.config.limit(method)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	counts, ok := l.counts[client]
+	if !ok {
+		counts = &Counts{}
+		l.counts[client] = counts
+	}
+	if limit.Rate == 0 {
+		counts.Allowed++
+		return true, 0
+	}
+
+	now := l.now()
+	l.sweep(now)
+	key := bucketKey{method: method, client: client}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), at: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.at).Seconds()*limit.Rate)
+	b.at = now
+	if b.tokens < 1 {
+		counts.Limited++
+		return false, time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+	}
+	b.tokens--
+	counts.Allowed++
+	return true, 0
+}
+
+//sweep drops the buckets that have filled back up since they were last used.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < sweepInterval {
+		return
+	}
+	l.swept = now
+	for key, b := range l.buckets {
+		limit := l.config.limit(key.method)
+		if b.tokens+now.Sub(b.at).Seconds()*limit.Rate >= float64(limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Counts returns the counts of every client that has made a call.
+func (l *Limiter) Counts() map[string]Counts {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	counts := make(map[string]Counts)
+	for client, c := range l.counts {
+		counts[client] = *c
+	}
+	return counts
+}
+
+// CountsHandler serves the counts of every client as JSON.
+func CountsHandler(l *Limiter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(l.Counts())
+	})
+}
@@ -0,0 +1,150 @@
+package ratelimit
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testConfig = Config{
+	Default: Limit{Rate: 1, Burst: 2},
+	Methods: map[string]Limit{
+		"ExportPartners": {Rate: 0.5, Burst: 1},
+		"GetDataById":    {Rate: 0},
+	},
+}
+
+//testLimiter returns a limiter whose clock only moves when the returned func is called.
+func testLimiter() (*Limiter, func(time.Duration)) {
+	now := time.Date(2017, 8, 1, 12, 0, 0, 0, time.UTC)
+	l := NewLimiter(testConfig)
+	l.now = func() time.Time { return now }
+	return l, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestAllowBurst(t *testing.T) {
+	a := assert.New(t)
+	l, _ := testLimiter()
+
+	ok, _ := l.Allow("ComparePartners", "jdoe")
+	a.True(ok)
+	ok, _ = l.Allow("ComparePartners", "jdoe")
+	a.True(ok)
+	ok, retryAfter := l.Allow("ComparePartners", "jdoe")
+	a.False(ok)
+	a.Equal(time.Second, retryAfter)
+}
+
+func TestAllowRefills(t *testing.T) {
+	a := assert.New(t)
+	l, advance := testLimiter()
+
+	l.Allow("ExportPartners", "jdoe")
+	ok, retryAfter := l.Allow("ExportPartners", "jdoe")
+	a.False(ok)
+	a.Equal(2*time.Second, retryAfter)
+
+	advance(time.Second)
+	ok, retryAfter = l.Allow("ExportPartners", "jdoe")
+	a.False(ok)
+	a.Equal(time.Second, retryAfter)
+
+	advance(time.Second)
+	ok, _ = l.Allow("ExportPartners", "jdoe")
+	a.True(ok)
+}
+
+func TestAllowPerClientAndMethod(t *testing.T) {
+	a := assert.New(t)
+	l, _ := testLimiter()
+
+	l.Allow("ExportPartners", "jdoe")
+	ok, _ := l.Allow("ExportPartners", "onboarding-pipeline")
+	a.True(ok)
+	ok, _ = l.Allow("ComparePartners", "jdoe")
+	a.True(ok)
+}
+
+func TestAllowUnlimited(t *testing.T) {
+	l, _ := testLimiter()
+
+	for i := 0; i < 100; i++ {
+		ok, _ := l.Allow("GetDataById", "jdoe")
+		assert.True(t, ok)
+	}
+}
+
+func TestCounts(t *testing.T) {
+	a := assert.New(t)
+	l, _ := testLimiter()
+
+	l.Allow("ExportPartners", "jdoe")
+	l.Allow("ExportPartners", "jdoe")
+	l.Allow("GetDataById", "onboarding-pipeline")
+
+	a.Equal(map[string]Counts{"jdoe": {Allowed: 1, Limited: 1}, "onboarding-pipeline": {Allowed: 1}}, l.Counts())
+}
+
+func TestSweepDropsFullBuckets(t *testing.T) {
+	a := assert.New(t)
+	l, advance := testLimiter()
+
+	l.Allow("ComparePartners", "jdoe")
+	advance(2 * sweepInterval)
+	l.Allow("ExportPartners", "onboarding-pipeline")
+
+	a.Equal(1, len(l.buckets))
+}
+
+func TestCountsHandler(t *testing.T) {
+	l, _ := testLimiter()
+	l.Allow("ComparePartners", "jdoe")
+	w := httptest.NewRecorder()
+
+	CountsHandler(l).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/ratelimit", nil))
+
+	assert.JSONEq(t, `{"jdoe": {"allowed": 1, "limited": 0}}`, w.Body.String())
+}
+
+func writeConfig(t *testing.T, config string) string {
+	file, err := ioutil.TempFile("", "ratelimits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(config)
+	file.Close()
+	return file.Name()
+}
+
+func TestLoadConfig(t *testing.T) {
+	a := assert.New(t)
+	path := writeConfig(t, "default: {rate: 20, burst: 40}\nmethods:\n  ExportPartners: {rate: 1, burst: 5}\n")
+	defer os.Remove(path)
+
+	config, err := LoadConfig(path)
+
+	a.Nil(err)
+	a.Equal(Limit{Rate: 20, Burst: 40}, config.Default)
+	a.Equal(Limit{Rate: 1, Burst: 5}, config.limit("ExportPartners"))
+	a.Equal(Limit{Rate: 20, Burst: 40}, config.limit("ComparePartners"))
+}
+
+func TestLoadConfigNoBurst(t *testing.T) {
+	path := writeConfig(t, "methods:\n  ExportPartners: {rate: 1}\n")
+	defer os.Remove(path)
+
+	_, err := LoadConfig(path)
+
+	assert.NotNil(t, err)
+}
+
+func TestLoadConfigRepoFile(t *testing.T) {
+	_, err := LoadConfig("../../ratelimits.yaml")
+
+	assert.Nil(t, err)
+}
@@ -0,0 +1,174 @@
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Prefix starts every sealed value, which tells sealed values apart from values stored before their key was
+// marked sensitive.
+const Prefix = "enc:v1:"
+
+// ErrNoKeyfile is returned when a value has to be sealed or opened and no keyfile was loaded.
+var ErrNoKeyfile = errors.New("no keyfile is loaded to seal sensitive values with")
+
+// Keyring seals and opens values with envelope encryption. Each value is encrypted with a data key of its own, which
+// is stored with it encrypted by a key from the keyfile.
+type Keyring struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// LoadKeyfile reads a keyfile of base64 encoded 32 byte keys, one per line. The first key seals new values and every
+// key opens the values sealed with it, so a key is rotated by putting a new one first. Blank lines and lines starting
+// with # are skipped.
+func LoadKeyfile(path string) (*Keyring, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("failed to read keyfile %s", path))
+	}
+
+	k := &Keyring{keys: make(map[string]cipher.AEAD)}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(text)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("bad key on line %d of keyfile %s", line, path))
+		}
+		if len(key) != 32 {
+			return nil, errors.New(fmt.Sprintf("key on line %d of keyfile %s is %d bytes, not 32", line, path, len(key)))
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		id := keyID(key)
+		if k.current == "" {
+			k.current = id
+		}
+		k.keys[id] = aead
+	}
+	if k.current == "" {
+		return nil, errors.New(fmt.Sprintf("keyfile %s has no keys", path))
+	}
+	return k, nil
+}
+
+// IsSealed reports whether the value was sealed by a Keyring.
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+// Seal encrypts the value of a key. The sealed value only opens for the same key.
+func (k *Keyring) Seal(key, value string) (string, error) {
+	if k == nil {
+		return "", ErrNoKeyfile
+	}
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", errors.Wrap(err, "failed to make data key")
+	}
+	wrapped, err := seal(k.keys[k.current], dataKey, nil)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(aead, []byte(value), []byte(key))
+	if err != nil {
+		return "", err
+	}
+	return Prefix + k.current + ":" + base64.RawStdEncoding.EncodeToString(wrapped) + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts the sealed value of a key. Values that are not sealed are returned as they are.
+func (k *Keyring) Open(key, value string) (string, error) {
+	if !IsSealed(value) {
+		return value, nil
+	}
+	if k == nil {
+		return "", ErrNoKeyfile
+	}
+	parts := strings.Split(strings.TrimPrefix(value, Prefix), ":")
+	if len(parts) != 3 {
+		return "", errors.New(fmt.Sprintf("sealed value of key %s is malformed", key))
+	}
+	keyAEAD, ok := k.keys[parts[0]]
+	if !ok {
+		return "", errors.New(fmt.Sprintf("sealed value of key %s needs key %s, which is not in the keyfile", key, parts[0]))
+	}
+	wrapped, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("sealed value of key %s is malformed", key))
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("sealed value of key %s is malformed", key))
+	}
+
+	dataKey, err := open(keyAEAD, wrapped, nil)
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("failed to open data key for key %s", key))
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	plain, err := open(aead, sealed, []byte(key))
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("failed to open sealed value of key %s", key))
+	}
+	return string(plain), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make cipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make cipher")
+	}
+	return aead, nil
+}
+
+//seal encrypts plain with a random nonce, which it puts in front of the result.
+func seal(aead cipher.AEAD, plain, additional []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.Wrap(err, "failed to make nonce")
+	}
+	return aead.Seal(nonce, nonce, plain, additional), nil
+}
+
+func open(aead cipher.AEAD, sealed, additional []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("sealed data is too short")
+	}
+	return aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], additional)
+}
+
+//keyID names a key from the keyfile without giving it away.
+func keyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:4])
+}
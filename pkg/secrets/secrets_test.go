@@ -0,0 +1,106 @@
+package secrets
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newKey() string {
+	key := make([]byte, 32)
+	rand.Read(key)
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func writeKeyfile(t *testing.T, lines ...string) string {
+	file, err := ioutil.TempFile("", "keyfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(strings.Join(lines, "\n"))
+	file.Close()
+	return file.Name()
+}
+
+func loadKeyring(t *testing.T, lines ...string) *Keyring {
+	path := writeKeyfile(t, lines...)
+	defer os.Remove(path)
+	keyring, err := LoadKeyfile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+func TestSealAndOpen(t *testing.T) {
+	a := assert.New(t)
+	keyring := loadKeyring(t, "# current key", newKey())
+
+	sealed, err := keyring.Seal("AS2 Password", "hunter2")
+	a.Nil(err)
+	a.True(IsSealed(sealed))
+	a.NotContains(sealed, "hunter2")
+
+	opened, err := keyring.Open("AS2 Password", sealed)
+	a.Nil(err)
+	a.Equal("hunter2", opened)
+
+	//the same value seals differently every time
+	again, _ := keyring.Seal("AS2 Password", "hunter2")
+	a.NotEqual(sealed, again)
+}
+
+func TestOpenOtherKey(t *testing.T) {
+	a := assert.New(t)
+	keyring := loadKeyring(t, newKey())
+	sealed, _ := keyring.Seal("AS2 Password", "hunter2")
+
+	_, err := keyring.Open("Bank Account", sealed)
+
+	a.NotNil(err)
+}
+
+func TestOpenNotSealed(t *testing.T) {
+	opened, err := (*Keyring)(nil).Open("AS2 Password", "hunter2")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "hunter2", opened)
+}
+
+func TestSealNoKeyfile(t *testing.T) {
+	_, err := (*Keyring)(nil).Seal("AS2 Password", "hunter2")
+
+	assert.Equal(t, ErrNoKeyfile, err)
+}
+
+func TestKeyRotation(t *testing.T) {
+	a := assert.New(t)
+	oldKey, newerKey := newKey(), newKey()
+	sealed, _ := loadKeyring(t, oldKey).Seal("AS2 Password", "hunter2")
+
+	rotated := loadKeyring(t, newerKey, oldKey)
+	opened, err := rotated.Open("AS2 Password", sealed)
+	a.Nil(err)
+	a.Equal("hunter2", opened)
+
+	resealed, _ := rotated.Seal("AS2 Password", "hunter2")
+	_, err = loadKeyring(t, oldKey).Open("AS2 Password", resealed)
+	a.NotNil(err)
+}
+
+func TestLoadKeyfileBad(t *testing.T) {
+	for _, lines := range [][]string{{}, {"# no keys"}, {"not base64!"}, {base64.StdEncoding.EncodeToString([]byte("short"))}} {
+		path := writeKeyfile(t, lines...)
+		_, err := LoadKeyfile(path)
+		os.Remove(path)
+		assert.NotNil(t, err, lines)
+	}
+
+	_, err := LoadKeyfile("./does-not-exist")
+	assert.NotNil(t, err)
+}
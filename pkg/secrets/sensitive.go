@@ -0,0 +1,99 @@
+package secrets
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"github.com/pkg/errors"
+)
+
+// Redacted stands in for sensitive values in logs and in replies to callers who cannot see them.
+const Redacted = "[REDACTED]"
+
+// SensitiveKeyFinder finds the keys whose values are sensitive. The db querier is one.
+type SensitiveKeyFinder interface {
+	FindSensitiveKeys() ([]string, error)
+}
+
+// SensitiveKeys remembers which keys are sensitive, finding them again once they are older than the ttl. When they
+// cannot be found every key is treated as sensitive.
+type SensitiveKeys struct {
+	finder  SensitiveKeyFinder
+	ttl     time.Duration
+	lookups metrics.Counter
+
+	mu     sync.Mutex
+	keys   map[string]bool
+	found  time.Time
+	failed bool
+}
+
+func NewSensitiveKeys(finder SensitiveKeyFinder, ttl time.Duration) *SensitiveKeys {
+	return &SensitiveKeys{finder: finder, ttl: ttl, lookups: discard.NewCounter()}
+}
+
+// Instrument counts each lookup with a result label of hit, when the keys were remembered, or miss, when they had to be
+// found. It returns s.
+func (s *SensitiveKeys) Instrument(lookups metrics.Counter) *SensitiveKeys {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lookups = lookups
+	return s
+}
+
+// IsSensitive reports whether the values of the key are sensitive.
+func (s *SensitiveKeys) IsSensitive(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.keys != nil && time.Since(s.found) <= s.ttl {
+		s.lookups.With("result", "hit").Add(1)
+	} else {
+		s.lookups.With("result", "miss").Add(1)
+		keys, err := s.finder.FindSensitiveKeys()
+		s.failed = err != nil
+		if err == nil {
+			s.keys = make(map[string]bool)
+			for _, k := range keys {
+				s.keys[k] = true
+			}
+			s.found = time.Now()
+		}
+	}
+	return s.failed || s.keys[key]
+}
+
+// Redact returns a copy of the attributes with the sensitive values replaced by Redacted.
+func (s *SensitiveKeys) Redact(attributes map[string]string) map[string]string {
+	if attributes == nil {
+		return nil
+	}
+	redacted := make(map[string]string)
+	for key, value := range attributes {
+		if s.IsSensitive(key) {
+			value = Redacted
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
+
+// RedactError returns err with the sensitive values of the attributes taken out of its message, for errors that
+// repeat the values they were given.
+func (s *SensitiveKeys) RedactError(err error, attributes map[string]string) error {
+	if err == nil {
+		return nil
+	}
+	message := err.Error()
+	for key, value := range attributes {
+		if value != "" && s.IsSensitive(key) {
+			message = strings.Replace(message, value, Redacted, -1)
+		}
+	}
+	if message == err.Error() {
+		return err
+	}
+	return errors.New(message)
+}
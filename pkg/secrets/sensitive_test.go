@@ -0,0 +1,97 @@
+package secrets
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type finder struct {
+	keys  []string
+	err   error
+	calls int
+}
+
+func (f *finder) FindSensitiveKeys() ([]string, error) {
+	f.calls++
+	return f.keys, f.err
+}
+
+func TestIsSensitive(t *testing.T) {
+	a := assert.New(t)
+	f := &finder{keys: []string{"AS2 Password"}}
+	sensitive := NewSensitiveKeys(f, time.Minute)
+
+	a.True(sensitive.IsSensitive("AS2 Password"))
+	a.False(sensitive.IsSensitive("Currency"))
+	a.Equal(1, f.calls)
+}
+
+// lookups counts lookups by result
+type lookups struct {
+	counts map[string]float64
+	result string
+}
+
+func (l lookups) With(labelValues ...string) metrics.Counter {
+	return lookups{counts: l.counts, result: labelValues[1]}
+}
+
+func (l lookups) Add(delta float64) {
+	l.counts[l.result] += delta
+}
+
+func TestIsSensitiveCountsLookups(t *testing.T) {
+	a := assert.New(t)
+	counts := make(map[string]float64)
+	sensitive := NewSensitiveKeys(&finder{keys: []string{"AS2 Password"}}, time.Minute).Instrument(lookups{counts: counts})
+
+	sensitive.IsSensitive("AS2 Password")
+	sensitive.IsSensitive("Currency")
+	sensitive.IsSensitive("Type of Payment")
+
+	a.Equal(map[string]float64{"miss": 1, "hit": 2}, counts)
+}
+
+func TestIsSensitiveRefinds(t *testing.T) {
+	a := assert.New(t)
+	f := &finder{keys: []string{"AS2 Password"}}
+	sensitive := NewSensitiveKeys(f, 0)
+
+	sensitive.IsSensitive("Currency")
+	f.keys = []string{"Currency"}
+
+	a.True(sensitive.IsSensitive("Currency"))
+	a.Equal(2, f.calls)
+}
+
+func TestIsSensitiveFailsClosed(t *testing.T) {
+	sensitive := NewSensitiveKeys(&finder{err: errors.New("test error")}, time.Minute)
+
+	assert.True(t, sensitive.IsSensitive("Currency"))
+}
+
+func TestRedact(t *testing.T) {
+	a := assert.New(t)
+	sensitive := NewSensitiveKeys(&finder{keys: []string{"AS2 Password"}}, time.Minute)
+	attributes := map[string]string{"AS2 Password": "hunter2", "Currency": "USD"}
+
+	a.Equal(map[string]string{"AS2 Password": Redacted, "Currency": "USD"}, sensitive.Redact(attributes))
+	a.Equal("hunter2", attributes["AS2 Password"])
+	a.Nil(sensitive.Redact(nil))
+}
+
+func TestRedactError(t *testing.T) {
+	a := assert.New(t)
+	sensitive := NewSensitiveKeys(&finder{keys: []string{"AS2 Password"}}, time.Minute)
+	err := errors.New("could not find Id or Code from key: AS2 Password and value: hunter2")
+
+	redacted := sensitive.RedactError(err, map[string]string{"AS2 Password": "hunter2"})
+	a.Equal("could not find Id or Code from key: AS2 Password and value: "+Redacted, redacted.Error())
+
+	a.Equal(err, sensitive.RedactError(err, map[string]string{"Currency": "USD"}))
+	a.Nil(sensitive.RedactError(nil, map[string]string{"AS2 Password": "hunter2"}))
+}
@@ -1,37 +1,343 @@
 package service
 
 import (
+	"strings"
+	"time"
+
 	"golang.org/x/net/context"
 
 	"github.com/go-kit/kit/log"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/secrets"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/tracing"
 )
 
 // Middleware describes a service (as opposed to endpoint) middleware.
 type Middleware func(PartnerService) PartnerService
 
 // LoggingMiddleware takes a logger as a dependency
-// and returns a ServiceMiddleware.
-func LoggingMiddleware(logger log.Logger) Middleware {
+// and returns a ServiceMiddleware. Values of sensitive keys are redacted.
+func LoggingMiddleware(logger log.Logger, sensitive *secrets.SensitiveKeys) Middleware {
 	return func(next PartnerService) PartnerService {
-		return loggingMiddleware{logger, next}
+		return loggingMiddleware{logger, sensitive, next}
 	}
 }
 
 type loggingMiddleware struct {
-	logger log.Logger
-	next   PartnerService
+	logger    log.Logger
+	sensitive *secrets.SensitiveKeys
+	next      PartnerService
+}
+
+func (mw loggingMiddleware) GetPartnerDataByKeyValue(ctx context.Context, key string, value string, group string, includeInactive bool) (partnerId int32, partnerCode string, attributes map[string]string, revision int32, err error) {
+	defer func() {
+		mw.logger.Log("method", "KeyValue", "id", partnerId, "code", partnerCode, "attributes", mw.sensitive.Redact(attributes), "revision", revision, "err", mw.sensitive.RedactError(err, map[string]string{key: value}))
+	}()
+	return mw.next.GetPartnerDataByKeyValue(ctx, key, value, group, includeInactive)
+}
+
+func (mw loggingMiddleware) GetDataById(ctx context.Context, id int32, code string, group string, includeInactive bool) (partnerId int32, partnerCode string, attributes map[string]string, revision int32, err error) {
+	defer func() {
+		mw.logger.Log("method", "ById", "id", partnerId, "code", partnerCode, "attributes", mw.sensitive.Redact(attributes), "revision", revision, "err", err)
+	}()
+ 	return mw.next.GetDataById(ctx, id, code, group, includeInactive)
+}
+
+func (mw loggingMiddleware) ExportPartners(ctx context.Context, group string, partnerCodes []string) (partners []models.Partner, err error) {
+	defer func() {
+		mw.logger.Log("method", "Export", "group", group, "codes", len(partnerCodes), "partners", len(partners), "err", err)
+	}()
+	return mw.next.ExportPartners(ctx, group, partnerCodes)
+}
+
+func (mw loggingMiddleware) ComparePartners(ctx context.Context, partnerCodes []string, group string) (keys []KeyComparison, err error) {
+	defer func() {
+		mw.logger.Log("method", "Compare", "codes", partnerCodes, "group", group, "keys", len(keys), "err", err)
+	}()
+	return mw.next.ComparePartners(ctx, partnerCodes, group)
+}
+
+func (mw loggingMiddleware) ClonePartner(ctx context.Context, name, code, sourceCode, template string, groups []string, overrides map[string]string) (partnerId int32, partnerCode string, attributes map[string]string, err error) {
+	defer func() {
+		mw.logger.Log("method", "Clone", "source", sourceCode, "template", template, "groups", groups, "id", partnerId, "code", partnerCode, "attributes", mw.sensitive.Redact(attributes), "err", mw.sensitive.RedactError(err, overrides))
+	}()
+	return mw.next.ClonePartner(ctx, name, code, sourceCode, template, groups, overrides)
+}
+
+func (mw loggingMiddleware) SetPartnerStatus(ctx context.Context, partnerCode, status string, expectedRevision int32) (partnerId int32, changedAt time.Time, err error) {
+	defer func() {
+		mw.logger.Log("method", "SetStatus", "code", partnerCode, "status", status, "expectedRevision", expectedRevision, "id", partnerId, "changedAt", changedAt, "err", err)
+	}()
+	return mw.next.SetPartnerStatus(ctx, partnerCode, status, expectedRevision)
+}
+
+func (mw loggingMiddleware) RestorePartner(ctx context.Context, partnerCode string) (restored int32, err error) {
+	defer func() {
+		mw.logger.Log("method", "RestorePartner", "code", partnerCode, "restored", restored, "err", err)
+	}()
+	return mw.next.RestorePartner(ctx, partnerCode)
+}
+
+func (mw loggingMiddleware) RestoreKey(ctx context.Context, key string) (restored int32, err error) {
+	defer func() {
+		mw.logger.Log("method", "RestoreKey", "key", key, "restored", restored, "err", err)
+	}()
+	return mw.next.RestoreKey(ctx, key)
+}
+
+func (mw loggingMiddleware) RestoreGroup(ctx context.Context, group string) (restored int32, err error) {
+	defer func() {
+		mw.logger.Log("method", "RestoreGroup", "group", group, "restored", restored, "err", err)
+	}()
+	return mw.next.RestoreGroup(ctx, group)
+}
+
+func (mw loggingMiddleware) RestorePartnerAttribute(ctx context.Context, partnerCode, key string, expectedRevision int32) (restored int32, err error) {
+	defer func() {
+		mw.logger.Log("method", "RestorePartnerAttribute", "code", partnerCode, "key", key, "expectedRevision", expectedRevision, "restored", restored, "err", err)
+	}()
+	return mw.next.RestorePartnerAttribute(ctx, partnerCode, key, expectedRevision)
+}
+
+func (mw loggingMiddleware) OpenChangeSet(ctx context.Context, name string) (changeSet models.ChangeSet, err error) {
+	defer func() {
+		mw.logger.Log("method", "OpenChangeSet", "name", name, "changeSetId", changeSet.Id.Int32, "err", err)
+	}()
+	return mw.next.OpenChangeSet(ctx, name)
+}
+
+func (mw loggingMiddleware) StageChange(ctx context.Context, changeSetId int32, partnerCode, partnerName string, attributes map[string]string, expectedRevision int32) (changeSet models.ChangeSet, err error) {
+	defer func() {
+		mw.logger.Log("method", "StageChange", "changeSetId", changeSetId, "code", partnerCode, "name", partnerName, "attributes", len(attributes), "expectedRevision", expectedRevision, "err", mw.sensitive.RedactError(err, attributes))
+	}()
+	return mw.next.StageChange(ctx, changeSetId, partnerCode, partnerName, attributes, expectedRevision)
+}
+
+func (mw loggingMiddleware) PreviewChangeSet(ctx context.Context, changeSetId int32, partnerCode string) (partnerId int32, code string, attributes map[string]string, err error) {
+	defer func() {
+		mw.logger.Log("method", "PreviewChangeSet", "changeSetId", changeSetId, "code", partnerCode, "id", partnerId, "err", err)
+	}()
+	return mw.next.PreviewChangeSet(ctx, changeSetId, partnerCode)
+}
+
+func (mw loggingMiddleware) PublishChangeSet(ctx context.Context, changeSetId int32) (changeSet models.ChangeSet, err error) {
+	defer func() {
+		mw.logger.Log("method", "PublishChangeSet", "changeSetId", changeSetId, "partners", len(changeSet.Partners), "err", err)
+	}()
+	return mw.next.PublishChangeSet(ctx, changeSetId)
+}
+
+func (mw loggingMiddleware) DiscardChangeSet(ctx context.Context, changeSetId int32) (changeSet models.ChangeSet, err error) {
+	defer func() {
+		mw.logger.Log("method", "DiscardChangeSet", "changeSetId", changeSetId, "partners", len(changeSet.Partners), "err", err)
+	}()
+	return mw.next.DiscardChangeSet(ctx, changeSetId)
+}
+
+func (mw loggingMiddleware) ListApprovalRequests(ctx context.Context, status string) (requests []models.ApprovalRequest, err error) {
+	defer func() {
+		mw.logger.Log("method", "ListApprovalRequests", "status", status, "requests", len(requests), "err", err)
+	}()
+	return mw.next.ListApprovalRequests(ctx, status)
+}
+
+func (mw loggingMiddleware) ApproveRequest(ctx context.Context, requestId int32) (request models.ApprovalRequest, err error) {
+	defer func() {
+		mw.logger.Log("method", "ApproveRequest", "requestId", requestId, "decidedBy", request.DecidedBy.String, "err", err)
+	}()
+	return mw.next.ApproveRequest(ctx, requestId)
 }
 
-func (mw loggingMiddleware) GetPartnerDataByKeyValue(ctx context.Context, key string, value string, group string) (partnerId int32, partnerCode string, attributes map[string]string, err error) {
+func (mw loggingMiddleware) RejectRequest(ctx context.Context, requestId int32, reason string) (request models.ApprovalRequest, err error) {
 	defer func() {
-		mw.logger.Log("method", "KeyValue", "id", partnerId, "code", partnerCode, "attributes", attributes, "err", err)
+		mw.logger.Log("method", "RejectRequest", "requestId", requestId, "reason", reason, "decidedBy", request.DecidedBy.String, "err", err)
 	}()
-	return mw.next.GetPartnerDataByKeyValue(ctx, key, value, group)
+	return mw.next.RejectRequest(ctx, requestId, reason)
 }
 
-func (mw loggingMiddleware) GetDataById(ctx context.Context, id int32, code string, group string) (partnerId int32, partnerCode string, attributes map[string]string, err error) {
+func (mw loggingMiddleware) ListAccessRecords(ctx context.Context, partnerCode, from, to string) (records []models.AccessRecord, err error) {
 	defer func() {
-		mw.logger.Log("method", "ById", "id", partnerId, "code", partnerCode, "attributes", attributes, "err", err)
+		mw.logger.Log("method", "ListAccessRecords", "code", partnerCode, "from", from, "to", to, "records", len(records), "err", err)
 	}()
- 	return mw.next.GetDataById(ctx, id, code, group)
+	return mw.next.ListAccessRecords(ctx, partnerCode, from, to)
+}
+
+//the keys themselves are never logged, only their ids and prefixes
+func (mw loggingMiddleware) IssueAPIKey(ctx context.Context, owner string, scope []string, expiresAt string) (apiKey models.APIKey, key string, err error) {
+	defer func() {
+		mw.logger.Log("method", "IssueAPIKey", "owner", owner, "scope", strings.Join(scope, ","), "expiresAt", expiresAt, "id", apiKey.Id.Int32, "prefix", apiKey.Prefix.String, "issuedBy", apiKey.IssuedBy.String, "err", err)
+	}()
+	return mw.next.IssueAPIKey(ctx, owner, scope, expiresAt)
+}
+
+func (mw loggingMiddleware) ListAPIKeys(ctx context.Context, owner string) (apiKeys []models.APIKey, err error) {
+	defer func() {
+		mw.logger.Log("method", "ListAPIKeys", "owner", owner, "keys", len(apiKeys), "err", err)
+	}()
+	return mw.next.ListAPIKeys(ctx, owner)
+}
+
+func (mw loggingMiddleware) RotateAPIKey(ctx context.Context, id int32) (apiKey models.APIKey, key string, err error) {
+	defer func() {
+		mw.logger.Log("method", "RotateAPIKey", "id", id, "prefix", apiKey.Prefix.String, "err", err)
+	}()
+	return mw.next.RotateAPIKey(ctx, id)
+}
+
+func (mw loggingMiddleware) RevokeAPIKey(ctx context.Context, id int32) (apiKey models.APIKey, err error) {
+	defer func() {
+		mw.logger.Log("method", "RevokeAPIKey", "id", id, "prefix", apiKey.Prefix.String, "err", err)
+	}()
+	return mw.next.RevokeAPIKey(ctx, id)
+}
+
+// TracingMiddleware returns a service middleware that records a span for each call.
+func TracingMiddleware() Middleware {
+	return func(next PartnerService) PartnerService {
+		return tracingMiddleware{next}
+	}
+}
+
+type tracingMiddleware struct {
+	next PartnerService
+}
+
+var tracer = tracing.Tracer("service")
+
+func (mw tracingMiddleware) GetPartnerDataByKeyValue(ctx context.Context, key string, value string, group string, includeInactive bool) (partnerId int32, partnerCode string, attributes map[string]string, revision int32, err error) {
+	ctx, span := tracer.Start(ctx, "service.GetPartnerDataByKeyValue")
+	defer func() { tracing.End(span, err) }()
+	return mw.next.GetPartnerDataByKeyValue(ctx, key, value, group, includeInactive)
+}
+
+func (mw tracingMiddleware) GetDataById(ctx context.Context, id int32, code string, group string, includeInactive bool) (partnerId int32, partnerCode string, attributes map[string]string, revision int32, err error) {
+	ctx, span := tracer.Start(ctx, "service.GetDataById")
+	defer func() { tracing.End(span, err) }()
+	return mw.next.GetDataById(ctx, id, code, group, includeInactive)
+}
+
+func (mw tracingMiddleware) ExportPartners(ctx context.Context, group string, partnerCodes []string) (partners []models.Partner, err error) {
+	ctx, span := tracer.Start(ctx, "service.ExportPartners")
+	defer func() { tracing.End(span, err) }()
+	return mw.next.ExportPartners(ctx, group, partnerCodes)
+}
+
+func (mw tracingMiddleware) ComparePartners(ctx context.Context, partnerCodes []string, group string) (keys []KeyComparison, err error) {
+	ctx, span := tracer.Start(ctx, "service.ComparePartners")
+	defer func() { tracing.End(span, err) }()
+	return mw.next.ComparePartners(ctx, partnerCodes, group)
+}
+
+func (mw tracingMiddleware) ClonePartner(ctx context.Context, name, code, sourceCode, template string, groups []string, overrides map[string]string) (partnerId int32, partnerCode string, attributes map[string]string, err error) {
+	ctx, span := tracer.Start(ctx, "service.ClonePartner")
+	defer func() { tracing.End(span, err) }()
+	return mw.next.ClonePartner(ctx, name, code, sourceCode, template, groups, overrides)
+}
+
+func (mw tracingMiddleware) SetPartnerStatus(ctx context.Context, partnerCode, status string, expectedRevision int32) (partnerId int32, changedAt time.Time, err error) {
+	ctx, span := tracer.Start(ctx, "service.SetPartnerStatus")
+	defer func() { tracing.End(span, err) }()
+	return mw.next.SetPartnerStatus(ctx, partnerCode, status, expectedRevision)
+}
+
+func (mw tracingMiddleware) RestorePartner(ctx context.Context, partnerCode string) (restored int32, err error) {
+	ctx, span := tracer.Start(ctx, "service.RestorePartner")
+	defer func() { tracing.End(span, err) }()
+	return mw.next.RestorePartner(ctx, partnerCode)
+}
+
+func (mw tracingMiddleware) RestoreKey(ctx context.Context, key string) (restored int32, err error) {
+	ctx, span := tracer.Start(ctx, "service.RestoreKey")
+	defer func() { tracing.End(span, err) }()
+	return mw.next.RestoreKey(ctx, key)
+}
+
+func (mw tracingMiddleware) RestoreGroup(ctx context.Context, group string) (restored int32, err error) {
+	ctx, span := tracer.Start(ctx, "service.RestoreGroup")
+	defer func() { tracing.End(span, err) }()
+	return mw.next.RestoreGroup(ctx, group)
+}
+
+func (mw tracingMiddleware) RestorePartnerAttribute(ctx context.Context, partnerCode, key string, expectedRevision int32) (restored int32, err error) {
+	ctx, span := tracer.Start(ctx, "service.RestorePartnerAttribute")
+	defer func() { tracing.End(span, err) }()
+	return mw.next.RestorePartnerAttribute(ctx, partnerCode, key, expectedRevision)
+}
+
+func (mw tracingMiddleware) OpenChangeSet(ctx context.Context, name string) (changeSet models.ChangeSet, err error) {
+	ctx, span := tracer.Start(ctx, "service.OpenChangeSet")
+	defer func() { tracing.End(span, err) }()
+	return mw.next.OpenChangeSet(ctx, name)
+}
+
+func (mw tracingMiddleware) StageChange(ctx context.Context, changeSetId int32, partnerCode, partnerName string, attributes map[string]string, expectedRevision int32) (changeSet models.ChangeSet, err error) {
+	ctx, span := tracer.Start(ctx, "service.StageChange")
+	defer func() { tracing.End(span, err) }()
+	return mw.next.StageChange(ctx, changeSetId, partnerCode, partnerName, attributes, expectedRevision)
+}
+
+func (mw tracingMiddleware) PreviewChangeSet(ctx context.Context, changeSetId int32, partnerCode string) (partnerId int32, code string, attributes map[string]string, err error) {
+	ctx, span := tracer.Start(ctx, "service.PreviewChangeSet")
+	defer func() { tracing.End(span, err) }()
+	return mw.next.PreviewChangeSet(ctx, changeSetId, partnerCode)
+}
+
+func (mw tracingMiddleware) PublishChangeSet(ctx context.Context, changeSetId int32) (changeSet models.ChangeSet, err error) {
+	ctx, span := tracer.Start(ctx, "service.PublishChangeSet")
+	defer func() { tracing.End(span, err) }()
+	return mw.next.PublishChangeSet(ctx, changeSetId)
+}
+
+func (mw tracingMiddleware) DiscardChangeSet(ctx context.Context, changeSetId int32) (changeSet models.ChangeSet, err error) {
+	ctx, span := tracer.Start(ctx, "service.DiscardChangeSet")
+	defer func() { tracing.End(span, err) }()
+	return mw.next.DiscardChangeSet(ctx, changeSetId)
+}
+
+func (mw tracingMiddleware) ListApprovalRequests(ctx context.Context, status string) (requests []models.ApprovalRequest, err error) {
+	ctx, span := tracer.Start(ctx, "service.ListApprovalRequests")
+	defer func() { tracing.End(span, err) }()
+	return mw.next.ListApprovalRequests(ctx, status)
+}
+
+func (mw tracingMiddleware) ApproveRequest(ctx context.Context, requestId int32) (request models.ApprovalRequest, err error) {
+	ctx, span := tracer.Start(ctx, "service.ApproveRequest")
+	defer func() { tracing.End(span, err) }()
+	return mw.next.ApproveRequest(ctx, requestId)
+}
+
+func (mw tracingMiddleware) RejectRequest(ctx context.Context, requestId int32, reason string) (request models.ApprovalRequest, err error) {
+	ctx, span := tracer.Start(ctx, "service.RejectRequest")
+	defer func() { tracing.End(span, err) }()
+	return mw.next.RejectRequest(ctx, requestId, reason)
+}
+
+func (mw tracingMiddleware) ListAccessRecords(ctx context.Context, partnerCode, from, to string) (records []models.AccessRecord, err error) {
+	ctx, span := tracer.Start(ctx, "service.ListAccessRecords")
+	defer func() { tracing.End(span, err) }()
+	return mw.next.ListAccessRecords(ctx, partnerCode, from, to)
+}
+
+func (mw tracingMiddleware) IssueAPIKey(ctx context.Context, owner string, scope []string, expiresAt string) (apiKey models.APIKey, key string, err error) {
+	ctx, span := tracer.Start(ctx, "service.IssueAPIKey")
+	defer func() { tracing.End(span, err) }()
+	return mw.next.IssueAPIKey(ctx, owner, scope, expiresAt)
+}
+
+func (mw tracingMiddleware) ListAPIKeys(ctx context.Context, owner string) (apiKeys []models.APIKey, err error) {
+	ctx, span := tracer.Start(ctx, "service.ListAPIKeys")
+	defer func() { tracing.End(span, err) }()
+	return mw.next.ListAPIKeys(ctx, owner)
+}
+
+func (mw tracingMiddleware) RotateAPIKey(ctx context.Context, id int32) (apiKey models.APIKey, key string, err error) {
+	ctx, span := tracer.Start(ctx, "service.RotateAPIKey")
+	defer func() { tracing.End(span, err) }()
+	return mw.next.RotateAPIKey(ctx, id)
+}
+
+func (mw tracingMiddleware) RevokeAPIKey(ctx context.Context, id int32) (apiKey models.APIKey, err error) {
+	ctx, span := tracer.Start(ctx, "service.RevokeAPIKey")
+	defer func() { tracing.End(span, err) }()
+	return mw.next.RevokeAPIKey(ctx, id)
 }
@@ -2,25 +2,105 @@ package service
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/go-kit/kit/log"
+	"github.com/jackc/pgx"
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/apikeys"
 	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/identity"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/secrets"
 )
 
-func New(logger log.Logger, q db.PartnerServiceQuerier) PartnerService {
+func New(logger log.Logger, q db.PartnerServiceQuerier, sensitive *secrets.SensitiveKeys) PartnerService {
 	var svc PartnerService
 	{
 		svc = NewPartnerService(q)
-		svc = LoggingMiddleware(logger)(svc)
+		svc = TracingMiddleware()(svc)
+		svc = LoggingMiddleware(logger, sensitive)(svc)
 	}
 	return svc
 }
 
 type PartnerService interface {
-	GetPartnerDataByKeyValue(ctx context.Context, key, value, group string) (int32, string, map[string]string, error)
-	GetDataById(ctx context.Context, partnerId int32, partnerCode string, group string) (int32, string, map[string]string, error)
+	GetPartnerDataByKeyValue(ctx context.Context, key, value, group string, includeInactive bool) (int32, string, map[string]string, int32, error)
+	GetDataById(ctx context.Context, partnerId int32, partnerCode string, group string, includeInactive bool) (int32, string, map[string]string, int32, error)
+	ExportPartners(ctx context.Context, group string, partnerCodes []string) ([]models.Partner, error)
+	ComparePartners(ctx context.Context, partnerCodes []string, group string) ([]KeyComparison, error)
+	ClonePartner(ctx context.Context, name, code, sourceCode, template string, groups []string, overrides map[string]string) (int32, string, map[string]string, error)
+	SetPartnerStatus(ctx context.Context, partnerCode, status string, expectedRevision int32) (int32, time.Time, error)
+	RestorePartner(ctx context.Context, partnerCode string) (int32, error)
+	RestoreKey(ctx context.Context, key string) (int32, error)
+	RestoreGroup(ctx context.Context, group string) (int32, error)
+	RestorePartnerAttribute(ctx context.Context, partnerCode, key string, expectedRevision int32) (int32, error)
+	OpenChangeSet(ctx context.Context, name string) (models.ChangeSet, error)
+	StageChange(ctx context.Context, changeSetId int32, partnerCode, partnerName string, attributes map[string]string, expectedRevision int32) (models.ChangeSet, error)
+	PreviewChangeSet(ctx context.Context, changeSetId int32, partnerCode string) (int32, string, map[string]string, error)
+	PublishChangeSet(ctx context.Context, changeSetId int32) (models.ChangeSet, error)
+	DiscardChangeSet(ctx context.Context, changeSetId int32) (models.ChangeSet, error)
+	ListApprovalRequests(ctx context.Context, status string) ([]models.ApprovalRequest, error)
+	ApproveRequest(ctx context.Context, requestId int32) (models.ApprovalRequest, error)
+	RejectRequest(ctx context.Context, requestId int32, reason string) (models.ApprovalRequest, error)
+	ListAccessRecords(ctx context.Context, partnerCode, from, to string) ([]models.AccessRecord, error)
+	IssueAPIKey(ctx context.Context, owner string, scope []string, expiresAt string) (models.APIKey, string, error)
+	ListAPIKeys(ctx context.Context, owner string) ([]models.APIKey, error)
+	RotateAPIKey(ctx context.Context, id int32) (models.APIKey, string, error)
+	RevokeAPIKey(ctx context.Context, id int32) (models.APIKey, error)
+}
+
+// Partner lifecycle statuses. Every new partner starts out onboarding, however it is created, unless an import or apply
+// gives it its status, and lookups only return active partners unless asked to include the others.
+const (
+	StatusOnboarding = "onboarding"
+	StatusActive     = "active"
+	StatusSuspended  = "suspended"
+	StatusRetired    = "retired"
+)
+
+// IsStatus reports whether status is one of the partner lifecycle statuses.
+func IsStatus(status string) bool {
+	_, ok := statusTransitions[status]
+	return ok
+}
+
+// statusTransitions lists the statuses a partner can move to from each status. Retired is final.
+var statusTransitions = map[string][]string{
+	StatusOnboarding: {StatusActive, StatusRetired},
+	StatusActive:     {StatusSuspended, StatusRetired},
+	StatusSuspended:  {StatusActive, StatusRetired},
+	StatusRetired:    {},
+}
+
+// Statuses of a key in a comparison.
+const (
+	KeyEqual     = "EQUAL"
+	KeyDifferent = "DIFFERENT"
+	KeyMissing   = "MISSING"
+)
+
+// KeyComparison is one row of a ComparePartners matrix. Values is keyed by partner code and leaves out
+// partners that have no value for the key.
+type KeyComparison struct {
+	Key    string
+	Status string
+	Values map[string]string
+}
+
+// IsConflict reports whether the error is from a write that expected a revision the partner is no longer at. The
+// partner should be read again and the write retried against its new revision.
+func IsConflict(err error) bool {
+	return errors.Cause(err) == db.ErrRevisionConflict
+}
+
+// IsUnavailable reports whether the error is from a call that could not reach the database, either because it is
+// down or because the circuit breaker in front of it is open. The call can be sent again later.
+func IsUnavailable(err error) bool {
+	return db.IsUnavailable(err)
 }
 
 // NewPartnerService returns a struct that fulfills the PartnerService interface.
@@ -35,55 +115,752 @@ type partnerService struct {
 	querier db.PartnerServiceQuerier
 }
 
-func (s partnerService) GetPartnerDataByKeyValue(_ context.Context, key, value, group string) (int32, string, map[string]string, error) { //Attribute should be array?
+func (s partnerService) GetPartnerDataByKeyValue(ctx context.Context, key, value, group string, includeInactive bool) (int32, string, map[string]string, int32, error) { //Attribute should be array?
 	attributes := make(map[string]string)
 	if key == "" {
-		return 0, "", attributes, errors.New("key cannot be empty")
+		return 0, "", attributes, 0, errors.New("key cannot be empty")
 	}
 	if value == "" {
-		return 0, "", attributes, errors.New("value cannot be empty")
+		return 0, "", attributes, 0, errors.New("value cannot be empty")
+	}
+	id, code, err := s.queries(ctx).FindPartnerDataFromKeyValue(key, value)
+	if IsUnavailable(err) {
+		return 0, "", attributes, 0, errors.Wrap(err, fmt.Sprintf("could not find Id or Code from key: %s and value: %s", key, value))
+	}
+	if err != nil {
+		return 0, "", attributes, 0, errors.New(fmt.Sprintf("could not find Id or Code from key: %s and value: %s", key, value))
 	}
-	id, code, err := s.querier.FindPartnerDataFromKeyValue(key, value)
+	if err = s.checkActive(ctx, id, code, includeInactive); err != nil {
+		return 0, "", attributes, 0, err
+	}
+	//The revision is read before the attributes, so a write in between makes it older than them rather than newer.
+	//A write sent back with it then conflicts instead of overwriting something the caller has not seen.
+	revision, err := s.queries(ctx).FindPartnerRevision(id)
 	if err != nil {
-		return 0, "", attributes, errors.New(fmt.Sprintf("could not find Id or Code from key: %s and value: %s", key, value))
+		return 0, "", attributes, 0, errors.Wrap(err, fmt.Sprintf("could not find revision of partner %s", code))
 	}
 	//If a group is given to the GetPartnerDataByKeyValue function return only the partner attributes for that group.
 	if group == "" {
-		attributes, err = s.querier.FindAllAttributesForPartner(id)
+		attributes, err = s.queries(ctx).FindAllAttributesForPartner(id)
 	} else {
-		attributes, err = s.querier.FindPartnerAttribute(id, group)
+		attributes, err = s.queries(ctx).FindPartnerAttribute(id, group)
 	}
 
-	return id, code, attributes, err
+	return id, code, attributes, revision, err
 }
 
-func (s partnerService) GetDataById(_ context.Context, partnerId int32, partnerCode, group string) (int32, string, map[string]string, error) {
+func (s partnerService) GetDataById(ctx context.Context, partnerId int32, partnerCode, group string, includeInactive bool) (int32, string, map[string]string, int32, error) {
 	attributes := make(map[string]string)
 	if partnerId <= 0 && partnerCode == "" {
-		return 0, "", attributes, errors.New("partnerId must be greater than 0")
+		return 0, "", attributes, 0, errors.New("partnerId must be greater than 0")
 	}
 	if partnerId == 0 && partnerCode == "" {
-		return 0, "", attributes, errors.New("partnerId and partnerCode cannot both be empty")
+		return 0, "", attributes, 0, errors.New("partnerId and partnerCode cannot both be empty")
 	}
 	//If both partnerId and partnerCode are non-nil, check that the two correspond to the same row in the DB.
 	if partnerId != 0 && partnerCode != "" {
-		areEqual, _ := s.querier.CheckPartnerIDEqualsPartnerCode(partnerId, partnerCode)
+		areEqual, _ := s.queries(ctx).CheckPartnerIDEqualsPartnerCode(partnerId, partnerCode)
 		if !areEqual {
-			return 0, "", attributes, errors.New("partnerId and partnerCode correspond to different values.")
+			return 0, "", attributes, 0, errors.New("partnerId and partnerCode correspond to different values.")
 		}
 	}
-	id, code, err := s.querier.FindPartnerDataByID(partnerId, partnerCode)
+	id, code, err := s.queries(ctx).FindPartnerDataByID(partnerId, partnerCode)
 
+	var revision int32
 	if err != nil {
 		err = errors.Wrap(err, fmt.Sprintf("partnerId %d not found", id))
+	} else if err = s.checkActive(ctx, id, code, includeInactive); err != nil {
+		return 0, "", attributes, 0, err
+	} else if revision, err = s.queries(ctx).FindPartnerRevision(id); err != nil {
+		return 0, "", attributes, 0, errors.Wrap(err, fmt.Sprintf("could not find revision of partner %s", code))
 	} else {
 		//If a group is given to the GetDataById function return only the partner attributes for that group.
 		if group == "" {
-			attributes, err = s.querier.FindAllAttributesForPartner(id)
+			attributes, err = s.queries(ctx).FindAllAttributesForPartner(id)
+
+		} else {
+			attributes, err = s.queries(ctx).FindPartnerAttribute(id, group)
+		}
+	}
+	return id, code, attributes, revision, err
+}
+
+func (s partnerService) ExportPartners(ctx context.Context, group string, partnerCodes []string) ([]models.Partner, error) {
+	partners, err := s.queries(ctx).FindPartners(partnerCodes, group)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not export partners")
+	}
+	//Asking for specific partners that don't exist is an error rather than a silently shorter export.
+	if err = checkPartnersFound(partners, partnerCodes); err != nil {
+		return nil, err
+	}
+	return partners, nil
+}
+
+func (s partnerService) ComparePartners(ctx context.Context, partnerCodes []string, group string) ([]KeyComparison, error) {
+	seen := make(map[string]bool)
+	for _, code := range partnerCodes {
+		if code == "" {
+			return nil, errors.New("partnerCode cannot be empty")
+		}
+		if seen[code] {
+			return nil, errors.New(fmt.Sprintf("partnerCode %s is given more than once", code))
+		}
+		seen[code] = true
+	}
+	if len(partnerCodes) < 2 {
+		return nil, errors.New("at least two partnerCodes are needed to compare")
+	}
+	partners, err := s.queries(ctx).FindPartners(partnerCodes, group)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not compare partners")
+	}
+	if err = checkPartnersFound(partners, partnerCodes); err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	values := make(map[string]map[string]string)
+	for _, p := range partners {
+		for key, value := range p.Attributes {
+			if values[key] == nil {
+				values[key] = make(map[string]string)
+				keys = append(keys, key)
+			}
+			values[key][p.Code.String] = value
+		}
+	}
+	sort.Strings(keys)
 
+	comparisons := make([]KeyComparison, 0, len(keys))
+	for _, key := range keys {
+		comparisons = append(comparisons, KeyComparison{Key: key, Status: compareValues(values[key], len(partners)), Values: values[key]})
+	}
+	return comparisons, nil
+}
+
+func (s partnerService) ClonePartner(ctx context.Context, name, code, sourceCode, template string, groups []string, overrides map[string]string) (int32, string, map[string]string, error) {
+	attributes := make(map[string]string)
+	if name == "" || code == "" {
+		return 0, "", attributes, errors.New("name and code of the new partner cannot be empty")
+	}
+	if (sourceCode == "") == (template == "") {
+		return 0, "", attributes, errors.New("exactly one of sourceCode and template must be given")
+	}
+
+	//No groups means copying every key, which is the same as the empty group.
+	if len(groups) == 0 {
+		groups = []string{""}
+	}
+	for _, group := range groups {
+		var copied map[string]string
+		if sourceCode != "" {
+			partners, err := s.queries(ctx).FindPartners([]string{sourceCode}, group)
+			if err != nil {
+				return 0, "", make(map[string]string), errors.Wrap(err, "could not clone partner")
+			}
+			if len(partners) == 0 {
+				return 0, "", make(map[string]string), errors.New(fmt.Sprintf("partnerCode %s not found", sourceCode))
+			}
+			copied = partners[0].Attributes
 		} else {
-			attributes, err = s.querier.FindPartnerAttribute(id, group)
+			var err error
+			copied, err = s.queries(ctx).FindTemplateAttributes(template, group)
+			if err != nil {
+				return 0, "", make(map[string]string), errors.Wrap(err, fmt.Sprintf("could not clone template %s", template))
+			}
+		}
+		for key, value := range copied {
+			attributes[key] = value
+		}
+	}
+
+	//Identifier keys are never copied, they can only be set through overrides.
+	identifiers, err := s.queries(ctx).FindIdentifierKeys()
+	if err != nil {
+		return 0, "", make(map[string]string), errors.Wrap(err, "could not clone partner")
+	}
+	for _, key := range identifiers {
+		delete(attributes, key)
+	}
+	for key, value := range overrides {
+		attributes[key] = value
+	}
+
+	//Copied values need approval as much as overrides do, so protected groups have to be left out of the clone and
+	//staged in a change set.
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if err = s.checkUnprotected(ctx, keys); err != nil {
+		return 0, "", make(map[string]string), err
+	}
+
+	id, err := s.queries(ctx).CreatePartner(models.Partner{
+		Name:       pgx.NullString{String: name, Valid: true},
+		Code:       pgx.NullString{String: code, Valid: true},
+		Attributes: attributes,
+	})
+	if err != nil {
+		return 0, "", make(map[string]string), errors.Wrap(err, fmt.Sprintf("could not create partner %s", code))
+	}
+	return id, code, attributes, nil
+}
+
+// SetPartnerStatus moves the partner to the status. An expectedRevision of 0 changes the status whatever revision the
+// partner is at.
+func (s partnerService) SetPartnerStatus(ctx context.Context, partnerCode, status string, expectedRevision int32) (int32, time.Time, error) {
+	if partnerCode == "" {
+		return 0, time.Time{}, errors.New("partnerCode cannot be empty")
+	}
+	if expectedRevision < 0 {
+		return 0, time.Time{}, errors.New("expectedRevision cannot be negative")
+	}
+	if _, ok := statusTransitions[status]; !ok {
+		return 0, time.Time{}, errors.New(fmt.Sprintf("unknown status: %s", status))
+	}
+	id, _, err := s.queries(ctx).FindPartnerDataByID(0, partnerCode)
+	if err != nil {
+		return 0, time.Time{}, errors.Wrap(err, fmt.Sprintf("partnerCode %s not found", partnerCode))
+	}
+	current, err := s.queries(ctx).FindPartnerStatus(id)
+	if err != nil {
+		return 0, time.Time{}, errors.Wrap(err, fmt.Sprintf("could not find status of partner %s", partnerCode))
+	}
+	if current == status {
+		return 0, time.Time{}, errors.New(fmt.Sprintf("partner %s is already %s", partnerCode, status))
+	}
+	allowed := false
+	for _, next := range statusTransitions[current] {
+		if next == status {
+			allowed = true
+		}
+	}
+	if !allowed {
+		return 0, time.Time{}, errors.New(fmt.Sprintf("partner %s cannot go from %s to %s", partnerCode, current, status))
+	}
+
+	changedAt, err := s.queries(ctx).UpdatePartnerStatus(id, current, status, expectedRevision)
+	if err != nil {
+		return 0, time.Time{}, errors.Wrap(err, fmt.Sprintf("could not set status of partner %s", partnerCode))
+	}
+	return id, changedAt, nil
+}
+
+// RestorePartner brings back a deleted partner with the attributes it had when it was deleted. The count
+// includes the partner itself.
+func (s partnerService) RestorePartner(ctx context.Context, partnerCode string) (int32, error) {
+	if partnerCode == "" {
+		return 0, errors.New("partnerCode cannot be empty")
+	}
+	err := s.checkRestoreUnprotected(ctx, "partners", partnerCode)
+	if err != nil {
+		return 0, err
+	}
+	restored, err := s.queries(ctx).RestorePartner(partnerCode)
+	if err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("could not restore partner %s", partnerCode))
+	}
+	return int32(restored), nil
+}
+
+func (s partnerService) RestoreKey(ctx context.Context, key string) (int32, error) {
+	if key == "" {
+		return 0, errors.New("key cannot be empty")
+	}
+	err := s.checkRestoreUnprotected(ctx, "keys", key)
+	if err != nil {
+		return 0, err
+	}
+	restored, err := s.queries(ctx).RestoreKey(key)
+	if err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("could not restore key %s", key))
+	}
+	return int32(restored), nil
+}
+
+func (s partnerService) RestoreGroup(ctx context.Context, group string) (int32, error) {
+	if group == "" {
+		return 0, errors.New("group cannot be empty")
+	}
+	err := s.checkRestoreUnprotected(ctx, "groups", group)
+	if err != nil {
+		return 0, err
+	}
+	restored, err := s.queries(ctx).RestoreGroup(group)
+	if err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("could not restore group %s", group))
+	}
+	return int32(restored), nil
+}
+
+func (s partnerService) RestorePartnerAttribute(ctx context.Context, partnerCode, key string, expectedRevision int32) (int32, error) {
+	if partnerCode == "" || key == "" {
+		return 0, errors.New("partnerCode and key cannot be empty")
+	}
+	if expectedRevision < 0 {
+		return 0, errors.New("expectedRevision cannot be negative")
+	}
+	if err := s.checkUnprotected(ctx, []string{key}); err != nil {
+		return 0, err
+	}
+	restored, err := s.queries(ctx).RestorePartnerAttribute(partnerCode, key, expectedRevision)
+	if err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("could not restore key %s for partner %s", key, partnerCode))
+	}
+	return int32(restored), nil
+}
+
+func (s partnerService) OpenChangeSet(ctx context.Context, name string) (models.ChangeSet, error) {
+	if name == "" {
+		return models.ChangeSet{}, errors.New("name cannot be empty")
+	}
+	id, err := s.queries(ctx).CreateChangeSet(name)
+	if err != nil {
+		return models.ChangeSet{}, errors.Wrap(err, fmt.Sprintf("could not open change set %s", name))
+	}
+	return s.queries(ctx).FindChangeSet(id)
+}
+
+// StageChange adds a create or an update of one partner to a draft change set. The name can be left empty to
+// keep the name of an existing partner, but a partner that does not exist yet needs one. An expectedRevision other
+// than 0 is checked when the change set is published, not when the change is staged.
+func (s partnerService) StageChange(ctx context.Context, changeSetId int32, partnerCode, partnerName string, attributes map[string]string, expectedRevision int32) (models.ChangeSet, error) {
+	if partnerCode == "" {
+		return models.ChangeSet{}, errors.New("partnerCode cannot be empty")
+	}
+	if expectedRevision < 0 {
+		return models.ChangeSet{}, errors.New("expectedRevision cannot be negative")
+	}
+	if partnerName == "" && len(attributes) == 0 {
+		return models.ChangeSet{}, errors.New("partnerName and attributes cannot both be empty")
+	}
+	changeSet, err := s.findDraft(ctx, changeSetId)
+	if err != nil {
+		return models.ChangeSet{}, err
+	}
+	if partnerName == "" {
+		staged, ok := findStaged(changeSet, partnerCode)
+		if !ok || !staged.Name.Valid {
+			if _, _, err = s.queries(ctx).FindPartnerDataByID(0, partnerCode); err != nil {
+				return models.ChangeSet{}, errors.New(fmt.Sprintf("partnerCode %s not found, partnerName is needed to create it", partnerCode))
+			}
+		}
+	}
+
+	err = s.queries(ctx).StageChange(changeSetId, models.Partner{
+		Name:       pgx.NullString{String: partnerName, Valid: partnerName != ""},
+		Code:       pgx.NullString{String: partnerCode, Valid: true},
+		Revision:   pgx.NullInt32{Int32: expectedRevision, Valid: expectedRevision > 0},
+		Attributes: attributes,
+	})
+	if err != nil {
+		return models.ChangeSet{}, errors.Wrap(err, fmt.Sprintf("could not stage partner %s", partnerCode))
+	}
+	return s.queries(ctx).FindChangeSet(changeSetId)
+}
+
+// PreviewChangeSet returns a partner staged in the change set as it would look once published. The id is 0
+// for a partner the change set creates.
+func (s partnerService) PreviewChangeSet(ctx context.Context, changeSetId int32, partnerCode string) (int32, string, map[string]string, error) {
+	attributes := make(map[string]string)
+	if changeSetId <= 0 {
+		return 0, "", attributes, errors.New("changeSetId must be greater than 0")
+	}
+	changeSet, err := s.queries(ctx).FindChangeSet(changeSetId)
+	if err != nil {
+		return 0, "", attributes, errors.Wrap(err, fmt.Sprintf("changeSetId %d not found", changeSetId))
+	}
+	staged, ok := findStaged(changeSet, partnerCode)
+	if !ok {
+		return 0, "", attributes, errors.New(fmt.Sprintf("partnerCode %s is not staged in changeSetId %d", partnerCode, changeSetId))
+	}
+
+	id, _, err := s.queries(ctx).FindPartnerDataByID(0, partnerCode)
+	if err == nil {
+		live, err := s.queries(ctx).FindAllAttributesForPartner(id)
+		if err != nil {
+			return 0, "", attributes, errors.Wrap(err, fmt.Sprintf("could not find attributes of partner %s", partnerCode))
+		}
+		for key, value := range live {
+			attributes[key] = value
+		}
+	} else {
+		id = 0
+	}
+	for key, value := range staged.Attributes {
+		attributes[key] = value
+	}
+	return id, partnerCode, attributes, nil
+}
+
+// PublishChangeSet applies the change set, unless it touches a group with an approval policy. Then the change set
+// is left pending until a different approver approves it.
+func (s partnerService) PublishChangeSet(ctx context.Context, changeSetId int32) (models.ChangeSet, error) {
+	changeSet, err := s.findDraft(ctx, changeSetId)
+	if err != nil {
+		return models.ChangeSet{}, err
+	}
+	policies, err := s.queries(ctx).FindApprovalPolicies(stagedKeys(changeSet))
+	if err != nil {
+		return models.ChangeSet{}, errors.Wrap(err, fmt.Sprintf("could not find approval policies for changeSetId %d", changeSetId))
+	}
+	if len(policies) > 0 {
+		caller, ok := identity.FromContext(ctx)
+		if !ok {
+			return models.ChangeSet{}, errors.New(fmt.Sprintf("changeSetId %d needs approval and approval cannot be requested without a caller", changeSetId))
+		}
+		if _, err = s.queries(ctx).RequestApproval(changeSetId, caller, sortedGroups(policies)); err != nil {
+			return models.ChangeSet{}, errors.Wrap(err, fmt.Sprintf("could not request approval for changeSetId %d", changeSetId))
+		}
+		return s.queries(ctx).FindChangeSet(changeSetId)
+	}
+	if err := s.queries(ctx).PublishChangeSet(changeSetId); err != nil {
+		return models.ChangeSet{}, errors.Wrap(err, fmt.Sprintf("could not publish changeSetId %d", changeSetId))
+	}
+	return s.queries(ctx).FindChangeSet(changeSetId)
+}
+
+func (s partnerService) DiscardChangeSet(ctx context.Context, changeSetId int32) (models.ChangeSet, error) {
+	if _, err := s.findDraft(ctx, changeSetId); err != nil {
+		return models.ChangeSet{}, err
+	}
+	if err := s.queries(ctx).DiscardChangeSet(changeSetId); err != nil {
+		return models.ChangeSet{}, errors.Wrap(err, fmt.Sprintf("could not discard changeSetId %d", changeSetId))
+	}
+	return s.queries(ctx).FindChangeSet(changeSetId)
+}
+
+func (s partnerService) ListApprovalRequests(ctx context.Context, status string) ([]models.ApprovalRequest, error) {
+	switch status {
+	case "", models.ApprovalPending, models.ApprovalApproved, models.ApprovalRejected:
+	default:
+		return nil, errors.New(fmt.Sprintf("unknown approval status: %s", status))
+	}
+	requests, err := s.queries(ctx).FindApprovalRequests(status)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list approval requests")
+	}
+	return requests, nil
+}
+
+// ApproveRequest publishes the change set behind a pending request. The caller cannot be the user who made the
+// request and must be an approver of every protected group the change set touches.
+func (s partnerService) ApproveRequest(ctx context.Context, requestId int32) (models.ApprovalRequest, error) {
+	caller, err := s.checkApprover(ctx, requestId)
+	if err != nil {
+		return models.ApprovalRequest{}, err
+	}
+	if err = s.queries(ctx).ApproveRequest(requestId, caller); err != nil {
+		return models.ApprovalRequest{}, errors.Wrap(err, fmt.Sprintf("could not approve requestId %d", requestId))
+	}
+	return s.queries(ctx).FindApprovalRequest(requestId)
+}
+
+// RejectRequest returns the change set behind a pending request to draft so it can be fixed and published again.
+// It has the same rules about the caller as ApproveRequest.
+func (s partnerService) RejectRequest(ctx context.Context, requestId int32, reason string) (models.ApprovalRequest, error) {
+	caller, err := s.checkApprover(ctx, requestId)
+	if err != nil {
+		return models.ApprovalRequest{}, err
+	}
+	if err = s.queries(ctx).RejectRequest(requestId, caller, reason); err != nil {
+		return models.ApprovalRequest{}, errors.Wrap(err, fmt.Sprintf("could not reject requestId %d", requestId))
+	}
+	return s.queries(ctx).FindApprovalRequest(requestId)
+}
+
+// ListAccessRecords returns the reads of a partner's sensitive values from one RFC 3339 time up to another, oldest
+// first. An empty from starts at the first read and an empty to ends now.
+func (s partnerService) ListAccessRecords(ctx context.Context, partnerCode, from, to string) ([]models.AccessRecord, error) {
+	if partnerCode == "" {
+		return nil, errors.New("partnerCode cannot be empty")
+	}
+	var fromTime time.Time
+	toTime := time.Now()
+	var err error
+	if from != "" {
+		if fromTime, err = time.Parse(time.RFC3339, from); err != nil {
+			return nil, errors.New(fmt.Sprintf("from is not an RFC 3339 time: %s", from))
+		}
+	}
+	if to != "" {
+		if toTime, err = time.Parse(time.RFC3339, to); err != nil {
+			return nil, errors.New(fmt.Sprintf("to is not an RFC 3339 time: %s", to))
+		}
+	}
+	if !fromTime.Before(toTime) {
+		return nil, errors.New("from must be before to")
+	}
+	records, err := s.queries(ctx).FindAccessRecords(partnerCode, fromTime, toTime)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("could not list access records of partner %s", partnerCode))
+	}
+	return records, nil
+}
+
+// IssueAPIKey makes a key for the owner that authenticates with the roles in the scope, until the RFC 3339 time it
+// expires at, or for good if that is empty. The scope can only hold roles the caller has, and the caller is recorded
+// as the issuer. The key itself is returned only here and by RotateAPIKey.
+func (s partnerService) IssueAPIKey(ctx context.Context, owner string, scope []string, expiresAt string) (models.APIKey, string, error) {
+	if owner == "" {
+		return models.APIKey{}, "", errors.New("owner cannot be empty")
+	}
+	if len(scope) == 0 {
+		return models.APIKey{}, "", errors.New("scope cannot be empty")
+	}
+	issuer, ok := identity.FromContext(ctx)
+	if !ok {
+		return models.APIKey{}, "", errors.New("api keys cannot be issued without a caller")
+	}
+	roles := identity.RolesFromContext(ctx)
+	for _, role := range scope {
+		if !contains(roles, role) {
+			return models.APIKey{}, "", errors.New(fmt.Sprintf("%s cannot issue a key with role %s, which they do not have", issuer, role))
+		}
+	}
+	var expires pgx.NullTime
+	if expiresAt != "" {
+		t, err := time.Parse(time.RFC3339, expiresAt)
+		if err != nil {
+			return models.APIKey{}, "", errors.New(fmt.Sprintf("expiresAt is not an RFC 3339 time: %s", expiresAt))
+		}
+		if !t.After(time.Now()) {
+			return models.APIKey{}, "", errors.New(fmt.Sprintf("expiresAt is in the past: %s", expiresAt))
+		}
+		expires = pgx.NullTime{Time: t, Valid: true}
+	}
+
+	key, prefix, hash, err := apikeys.Generate()
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+	apiKey, err := s.queries(ctx).CreateAPIKey(models.APIKey{
+		Prefix:    pgx.NullString{String: prefix, Valid: true},
+		Owner:     pgx.NullString{String: owner, Valid: true},
+		Scope:     scope,
+		IssuedBy:  pgx.NullString{String: issuer, Valid: true},
+		ExpiresAt: expires,
+	}, hash)
+	if err != nil {
+		return models.APIKey{}, "", errors.Wrap(err, fmt.Sprintf("could not issue api key for %s", owner))
+	}
+	return apiKey, key, nil
+}
+
+// ListAPIKeys returns the keys of an owner, or of every owner when it is empty, including revoked and expired ones.
+func (s partnerService) ListAPIKeys(ctx context.Context, owner string) ([]models.APIKey, error) {
+	apiKeys, err := s.queries(ctx).FindAPIKeys(owner)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("could not list api keys of %s", owner))
+	}
+	return apiKeys, nil
+}
+
+// RotateAPIKey gives a key a new secret, keeping its owner, scope and expiry. The old secret stops working at once.
+func (s partnerService) RotateAPIKey(ctx context.Context, id int32) (models.APIKey, string, error) {
+	if id <= 0 {
+		return models.APIKey{}, "", errors.New("id must be given")
+	}
+	key, prefix, hash, err := apikeys.Generate()
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+	apiKey, err := s.queries(ctx).RotateAPIKey(id, prefix, hash)
+	if err != nil {
+		return models.APIKey{}, "", errors.Wrap(err, fmt.Sprintf("could not rotate api key %d", id))
+	}
+	return apiKey, key, nil
+}
+
+// RevokeAPIKey stops a key from working for good.
+func (s partnerService) RevokeAPIKey(ctx context.Context, id int32) (models.APIKey, error) {
+	if id <= 0 {
+		return models.APIKey{}, errors.New("id must be given")
+	}
+	apiKey, err := s.queries(ctx).RevokeAPIKey(id)
+	if err != nil {
+		return models.APIKey{}, errors.Wrap(err, fmt.Sprintf("could not revoke api key %d", id))
+	}
+	return apiKey, nil
+}
+
+//queries returns the querier, with each query traced as part of the call in ctx.
+func (s partnerService) queries(ctx context.Context) db.PartnerServiceQuerier {
+	return db.NewTracingQuerier(ctx, s.querier)
+}
+
+//checkApprover returns the caller if they can decide the pending request.
+func (s partnerService) checkApprover(ctx context.Context, requestId int32) (string, error) {
+	caller, ok := identity.FromContext(ctx)
+	if !ok {
+		return "", errors.New("requests cannot be decided without a caller")
+	}
+	if requestId <= 0 {
+		return "", errors.New("requestId must be greater than 0")
+	}
+	request, err := s.queries(ctx).FindApprovalRequest(requestId)
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("requestId %d not found", requestId))
+	}
+	if request.Status.String != models.ApprovalPending {
+		return "", errors.New(fmt.Sprintf("requestId %d is already %s", requestId, request.Status.String))
+	}
+	if request.RequestedBy.String == caller {
+		return "", errors.New(fmt.Sprintf("requestId %d was made by %s and needs a different user to decide it", requestId, caller))
+	}
+
+	//The policies are looked up again rather than trusting the groups on the request, so a policy added since
+	//the request was made still applies.
+	changeSet, err := s.queries(ctx).FindChangeSet(request.ChangeSetId.Int32)
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("changeSetId %d not found", request.ChangeSetId.Int32))
+	}
+	policies, err := s.queries(ctx).FindApprovalPolicies(stagedKeys(changeSet))
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("could not find approval policies for changeSetId %d", changeSet.Id.Int32))
+	}
+	for _, group := range sortedGroups(policies) {
+		if !contains(policies[group], caller) {
+			return "", errors.New(fmt.Sprintf("%s is not an approver for group %s", caller, group))
+		}
+	}
+	return caller, nil
+}
+
+//checkUnprotected returns an error if any of the keys are in a group with an approval policy, for changes that
+//can only be made through a change set.
+func (s partnerService) checkUnprotected(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	policies, err := s.queries(ctx).FindApprovalPolicies(keys)
+	if err != nil {
+		return errors.Wrap(err, "could not find approval policies")
+	}
+	if len(policies) > 0 {
+		return errors.New(fmt.Sprintf("keys in %s need approval, stage them in a change set instead", strings.Join(sortedGroups(policies), ", ")))
+	}
+	return nil
+}
+
+//checkRestoreUnprotected returns an error if a restore by name in partners, keys or groups would bring values back
+//into a group with an approval policy. A restore cannot be approved, so the values have to be staged in a change set.
+func (s partnerService) checkRestoreUnprotected(ctx context.Context, table, name string) error {
+	policies, err := s.queries(ctx).FindRestoreApprovalPolicies(table, name)
+	if err != nil {
+		return errors.Wrap(err, "could not find approval policies")
+	}
+	if len(policies) > 0 {
+		return errors.New(fmt.Sprintf("keys in %s need approval, stage them in a change set instead", strings.Join(sortedGroups(policies), ", ")))
+	}
+	return nil
+}
+
+//findDraft returns the change set, or an error if it does not exist or is no longer a draft.
+func (s partnerService) findDraft(ctx context.Context, changeSetId int32) (models.ChangeSet, error) {
+	if changeSetId <= 0 {
+		return models.ChangeSet{}, errors.New("changeSetId must be greater than 0")
+	}
+	changeSet, err := s.queries(ctx).FindChangeSet(changeSetId)
+	if err != nil {
+		return models.ChangeSet{}, errors.Wrap(err, fmt.Sprintf("changeSetId %d not found", changeSetId))
+	}
+	if changeSet.Status.String != models.ChangeSetDraft {
+		return models.ChangeSet{}, errors.New(fmt.Sprintf("changeSetId %d is already %s", changeSetId, changeSet.Status.String))
+	}
+	return changeSet, nil
+}
+
+//checkActive returns an error if the partner is not active, unless inactive partners were asked for.
+func (s partnerService) checkActive(ctx context.Context, id int32, code string, includeInactive bool) error {
+	if includeInactive {
+		return nil
+	}
+	status, err := s.queries(ctx).FindPartnerStatus(id)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("could not find status of partnerId %d", id))
+	}
+	if status != StatusActive {
+		return errors.New(fmt.Sprintf("partner %s is %s, set includeInactive to find it", code, status))
+	}
+	return nil
+}
+
+//compareValues works out the status of a key from the values the partners have for it.
+func compareValues(values map[string]string, partnerCount int) string {
+	if len(values) < partnerCount {
+		return KeyMissing
+	}
+	var first string
+	for _, value := range values {
+		first = value
+		break
+	}
+	for _, value := range values {
+		if value != first {
+			return KeyDifferent
+		}
+	}
+	return KeyEqual
+}
+
+//findStaged returns the partner staged in the change set with the code.
+func findStaged(changeSet models.ChangeSet, partnerCode string) (models.Partner, bool) {
+	for _, p := range changeSet.Partners {
+		if p.Code.String == partnerCode {
+			return p, true
+		}
+	}
+	return models.Partner{}, false
+}
+
+//stagedKeys returns every key the change set sets on any partner, sorted.
+func stagedKeys(changeSet models.ChangeSet) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, p := range changeSet.Partners {
+		for key := range p.Attributes {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedGroups(policies map[string][]string) []string {
+	groups := make([]string, 0, len(policies))
+	for group := range policies {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+//checkPartnersFound returns an error naming the first of the codes that is not one of the partners.
+func checkPartnersFound(partners []models.Partner, partnerCodes []string) error {
+	found := make(map[string]bool)
+	for _, p := range partners {
+		found[p.Code.String] = true
+	}
+	for _, code := range partnerCodes {
+		if !found[code] {
+			return errors.New(fmt.Sprintf("partnerCode %s not found", code))
 		}
 	}
-	return id, code, attributes, err
+	return nil
 }
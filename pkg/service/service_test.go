@@ -1,13 +1,22 @@
 package service
 
 import (
+	"bytes"
 	"testing"
+	"time"
 
+	"github.com/go-kit/kit/log"
+	"github.com/jackc/pgx"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"golang.org/x/net/context"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/apikeys"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/identity"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/secrets"
 )
 
 var ctx context.Context
@@ -43,6 +52,236 @@ func (m *mockQuerier) CheckPartnerIDEqualsPartnerCode(partnerId int32, partnerCo
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *mockQuerier) FindPartners(partnerCodes []string, group string) ([]models.Partner, error) {
+	args := m.Called(partnerCodes, group)
+	typePartners, _ := args.Get(0).([]models.Partner)
+	return typePartners, args.Error(1)
+}
+
+func (m *mockQuerier) SavePartners(partners []models.Partner) error {
+	args := m.Called(partners)
+	return args.Error(0)
+}
+
+func (m *mockQuerier) ApplyPartners(partners, deletes []models.Partner) error {
+	args := m.Called(partners, deletes)
+	return args.Error(0)
+}
+
+func (m *mockQuerier) FindIdentifierKeys() ([]string, error) {
+	args := m.Called()
+	typeKeys, _ := args.Get(0).([]string)
+	return typeKeys, args.Error(1)
+}
+
+func (m *mockQuerier) FindTemplateAttributes(name string, group string) (map[string]string, error) {
+	args := m.Called(name, group)
+	typeMapStringString, _ := args.Get(0).(map[string]string)
+	return typeMapStringString, args.Error(1)
+}
+
+func (m *mockQuerier) CreatePartner(partner models.Partner) (int32, error) {
+	args := m.Called(partner)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *mockQuerier) FindPartnerStatus(partnerId int32) (string, error) {
+	args := m.Called(partnerId)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockQuerier) FindPartnerRevision(partnerId int32) (int32, error) {
+	args := m.Called(partnerId)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *mockQuerier) UpdatePartnerStatus(partnerId int32, from string, to string, expectedRevision int32) (time.Time, error) {
+	args := m.Called(partnerId, from, to, expectedRevision)
+	typeTime, _ := args.Get(0).(time.Time)
+	return typeTime, args.Error(1)
+}
+
+func (m *mockQuerier) RestorePartner(partnerCode string) (int64, error) {
+	args := m.Called(partnerCode)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockQuerier) RestoreKey(key string) (int64, error) {
+	args := m.Called(key)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockQuerier) RestoreGroup(group string) (int64, error) {
+	args := m.Called(group)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockQuerier) RestorePartnerAttribute(partnerCode string, key string, expectedRevision int32) (int64, error) {
+	args := m.Called(partnerCode, key, expectedRevision)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockQuerier) PurgeDeleted(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockQuerier) CreateChangeSet(name string) (int32, error) {
+	args := m.Called(name)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *mockQuerier) FindChangeSet(changeSetId int32) (models.ChangeSet, error) {
+	args := m.Called(changeSetId)
+	typeChangeSet, _ := args.Get(0).(models.ChangeSet)
+	return typeChangeSet, args.Error(1)
+}
+
+func (m *mockQuerier) StageChange(changeSetId int32, partner models.Partner) error {
+	args := m.Called(changeSetId, partner)
+	return args.Error(0)
+}
+
+func (m *mockQuerier) PublishChangeSet(changeSetId int32) error {
+	args := m.Called(changeSetId)
+	return args.Error(0)
+}
+
+func (m *mockQuerier) DiscardChangeSet(changeSetId int32) error {
+	args := m.Called(changeSetId)
+	return args.Error(0)
+}
+
+func (m *mockQuerier) FindApprovalPolicies(keys []string) (map[string][]string, error) {
+	args := m.Called(keys)
+	typePolicies, _ := args.Get(0).(map[string][]string)
+	return typePolicies, args.Error(1)
+}
+
+func (m *mockQuerier) FindRestoreApprovalPolicies(table, name string) (map[string][]string, error) {
+	args := m.Called(table, name)
+	typePolicies, _ := args.Get(0).(map[string][]string)
+	return typePolicies, args.Error(1)
+}
+
+func (m *mockQuerier) RequestApproval(changeSetId int32, requestedBy string, groups []string) (int32, error) {
+	args := m.Called(changeSetId, requestedBy, groups)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *mockQuerier) FindApprovalRequests(status string) ([]models.ApprovalRequest, error) {
+	args := m.Called(status)
+	typeRequests, _ := args.Get(0).([]models.ApprovalRequest)
+	return typeRequests, args.Error(1)
+}
+
+func (m *mockQuerier) FindApprovalRequest(requestId int32) (models.ApprovalRequest, error) {
+	args := m.Called(requestId)
+	typeRequest, _ := args.Get(0).(models.ApprovalRequest)
+	return typeRequest, args.Error(1)
+}
+
+func (m *mockQuerier) ApproveRequest(requestId int32, approver string) error {
+	args := m.Called(requestId, approver)
+	return args.Error(0)
+}
+
+func (m *mockQuerier) RejectRequest(requestId int32, approver string, reason string) error {
+	args := m.Called(requestId, approver, reason)
+	return args.Error(0)
+}
+
+func (m *mockQuerier) FindKeyGroups(keys []string) (map[string][]string, error) {
+	args := m.Called(keys)
+	typeMap, _ := args.Get(0).(map[string][]string)
+	return typeMap, args.Error(1)
+}
+
+func (m *mockQuerier) FindSensitiveKeys() ([]string, error) {
+	args := m.Called()
+	typeKeys, _ := args.Get(0).([]string)
+	return typeKeys, args.Error(1)
+}
+
+func (m *mockQuerier) ClaimIdempotencyKey(key string, method string, requestHash string, since time.Time) (models.IdempotentResponse, bool, error) {
+	args := m.Called(key, method, requestHash, since)
+	typeResponse, _ := args.Get(0).(models.IdempotentResponse)
+	return typeResponse, args.Bool(1), args.Error(2)
+}
+
+func (m *mockQuerier) SaveIdempotentResponse(key string, method string, response string) error {
+	args := m.Called(key, method, response)
+	return args.Error(0)
+}
+
+func (m *mockQuerier) ReleaseIdempotencyKey(key string, method string) error {
+	args := m.Called(key, method)
+	return args.Error(0)
+}
+
+func (m *mockQuerier) PurgeIdempotencyKeys(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockQuerier) RecordAccess(records []models.AccessRecord) error {
+	args := m.Called(records)
+	return args.Error(0)
+}
+
+func (m *mockQuerier) FindAccessRecords(partnerCode string, from time.Time, to time.Time) ([]models.AccessRecord, error) {
+	args := m.Called(partnerCode, from, to)
+	typeRecords, _ := args.Get(0).([]models.AccessRecord)
+	return typeRecords, args.Error(1)
+}
+
+func (m *mockQuerier) CreateAPIKey(key models.APIKey, hash string) (models.APIKey, error) {
+	args := m.Called(key, hash)
+	return args.Get(0).(models.APIKey), args.Error(1)
+}
+
+func (m *mockQuerier) FindAPIKeys(owner string) ([]models.APIKey, error) {
+	args := m.Called(owner)
+	typeKeys, _ := args.Get(0).([]models.APIKey)
+	return typeKeys, args.Error(1)
+}
+
+func (m *mockQuerier) FindAPIKeyByHash(hash string) (models.APIKey, error) {
+	args := m.Called(hash)
+	return args.Get(0).(models.APIKey), args.Error(1)
+}
+
+func (m *mockQuerier) RotateAPIKey(id int32, prefix, hash string) (models.APIKey, error) {
+	args := m.Called(id, prefix, hash)
+	return args.Get(0).(models.APIKey), args.Error(1)
+}
+
+func (m *mockQuerier) RevokeAPIKey(id int32) (models.APIKey, error) {
+	args := m.Called(id)
+	return args.Get(0).(models.APIKey), args.Error(1)
+}
+
+func (m *mockQuerier) TouchAPIKey(id int32, usedAt time.Time) error {
+	args := m.Called(id, usedAt)
+	return args.Error(0)
+}
+func (m *mockQuerier) Ping() error {
+	args := m.Called()
+	return args.Error(0)
+}
+func (m *mockQuerier) FindSchemaVersion() (int32, error) {
+	args := m.Called()
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func newPartner(name, code string, attributes map[string]string) models.Partner {
+	return models.Partner{
+		Name:       pgx.NullString{String: name, Valid: true},
+		Code:       pgx.NullString{String: code, Valid: true},
+		Attributes: attributes,
+	}
+}
+
 // ServiceMethodsSuite allows us to attach setup and breakdown functions to multiple tests
 type ServiceMethodsSuite struct {
 	suite.Suite
@@ -67,6 +306,8 @@ func (suite *ServiceMethodsSuite) SetupTest() {
 	mq.On("FindPartnerAttribute", int32(1), "Money").Return(wantedMap, nil)
 	mq.On("FindPartnerDataByID", int32(1), "KOH").Return(int32(1), "KOH", nil)
 	mq.On("CheckPartnerIDEqualsPartnerCode", int32(1), "KOH").Return(true, nil)
+	mq.On("FindPartnerStatus", int32(1)).Return("active", nil)
+	mq.On("FindPartnerRevision", int32(1)).Return(int32(3), nil)
 	//cases for when things are missing/bad inputs...
 	mq.On("FindPartnerDataFromKeyValue", "", "USD").Return(int32(0), "", errors.New("error finding partner data from key value because empty key"))
 	mq.On("FindPartnerDataFromKeyValue", "Currency", "").Return(int32(0), "", errors.New("error finding partner data from key value because empty value"))
@@ -94,6 +335,138 @@ func (suite *ServiceMethodsSuite) SetupTest() {
 	mq.On("CheckPartnerIDEqualsPartnerCode", int32(-1), "KOH").Return(false, errors.New("error checking if partnerId matches partnerCode because bad/negative id"))
 	mq.On("CheckPartnerIDEqualsPartnerCode", int32(0), "").Return(false, errors.New("error checking if partnerId matches partnerCode because both empty"))
 
+	kohls := models.Partner{
+		Id:         pgx.NullInt32{Int32: 1, Valid: true},
+		Name:       pgx.NullString{String: "Kohls", Valid: true},
+		Code:       pgx.NullString{String: "KOH", Valid: true},
+		Attributes: wantedMap,
+	}
+	mq.On("FindPartners", []string(nil), "").Return([]models.Partner{kohls}, nil)
+	mq.On("FindPartners", []string{"KOH"}, "Money").Return([]models.Partner{kohls}, nil)
+	mq.On("FindPartners", []string{"KOH", "asdfjkl"}, "").Return([]models.Partner{kohls}, nil)
+	mq.On("FindPartners", []string(nil), "asdfjkl").Return(nil, errors.New("error finding partners because bad group"))
+
+	jcPenny := models.Partner{
+		Id:         pgx.NullInt32{Int32: 2, Valid: true},
+		Name:       pgx.NullString{String: "JC Penny", Valid: true},
+		Code:       pgx.NullString{String: "JCP", Valid: true},
+		Attributes: map[string]string{"Currency": "USD", "Type of Payment": "Debit", "Qualifier": "ZZ"},
+	}
+	mq.On("FindPartners", []string{"KOH", "JCP"}, "").Return([]models.Partner{kohls, jcPenny}, nil)
+	mq.On("FindPartners", []string{"KOH", "JCP"}, "asdfjkl").Return(nil, errors.New("error finding partners because bad group"))
+
+	dicks := models.Partner{
+		Id:         pgx.NullInt32{Int32: 5, Valid: true},
+		Name:       pgx.NullString{String: "Dicks", Valid: true},
+		Code:       pgx.NullString{String: "DIC", Valid: true},
+		Attributes: map[string]string{"Currency": "USD", "ISAID": "DICKS1"},
+	}
+	dicksMoney := dicks
+	dicksMoney.Attributes = map[string]string{"Currency": "USD"}
+	mq.On("FindPartners", []string{"DIC"}, "").Return([]models.Partner{dicks}, nil)
+	mq.On("FindPartners", []string{"DIC"}, "Money").Return([]models.Partner{dicksMoney}, nil)
+	dicksEDI := dicks
+	dicksEDI.Attributes = map[string]string{"Qualifier": "ZZ"}
+	mq.On("FindPartners", []string{"DIC"}, "EDI").Return([]models.Partner{dicksEDI}, nil)
+	mq.On("FindPartners", []string{"asdfjkl"}, "").Return([]models.Partner{}, nil)
+	mq.On("FindIdentifierKeys").Return([]string{"ISAID"}, nil)
+
+	changedAt := time.Date(2017, 8, 1, 12, 0, 0, 0, time.UTC)
+	mq.On("FindPartnerDataFromKeyValue", "ISAID", "DICKS1").Return(int32(5), "DIC", nil)
+	mq.On("FindPartnerDataByID", int32(5), "DIC").Return(int32(5), "DIC", nil)
+	mq.On("FindPartnerDataByID", int32(0), "DIC").Return(int32(5), "DIC", nil)
+	mq.On("FindPartnerDataByID", int32(0), "asdfjkl").Return(int32(0), "", errors.New("error finding partner data from id or code because bad code"))
+	mq.On("CheckPartnerIDEqualsPartnerCode", int32(5), "DIC").Return(true, nil)
+	mq.On("FindAllAttributesForPartner", int32(5)).Return(dicks.Attributes, nil)
+	mq.On("FindPartnerStatus", int32(5)).Return("suspended", nil)
+	mq.On("FindPartnerRevision", int32(5)).Return(int32(1), nil)
+	mq.On("UpdatePartnerStatus", int32(1), "active", "suspended", int32(0)).Return(changedAt, nil)
+	mq.On("UpdatePartnerStatus", int32(5), "suspended", "active", int32(0)).Return(changedAt, nil)
+	mq.On("UpdatePartnerStatus", int32(1), "active", "suspended", int32(2)).Return(time.Time{}, errors.Wrap(db.ErrRevisionConflict, "partnerId: 1 is not at revision 2"))
+	mq.On("FindTemplateAttributes", "Standard US EDI retailer", "").Return(map[string]string{"Qualifier": "ZZ", "850": "Received"}, nil)
+	mq.On("FindTemplateAttributes", "asdfjkl", "").Return(nil, errors.New("error finding template because bad name"))
+	mq.On("CreatePartner", newPartner("Dicks East", "DIE", map[string]string{"Qualifier": "ZZ"})).Return(int32(7), nil)
+	mq.On("CreatePartner", newPartner("Dicks West", "DIW", map[string]string{"Qualifier": "ZZ", "ISAID": "DICKSWEST"})).Return(int32(8), nil)
+	mq.On("CreatePartner", newPartner("Target", "TAR", map[string]string{"Qualifier": "ZZ", "850": "Received"})).Return(int32(9), nil)
+	mq.On("CreatePartner", newPartner("Kohls", "KOH", map[string]string{"Qualifier": "ZZ"})).Return(int32(0), errors.New("error creating partner because code taken"))
+	mq.On("RestorePartner", "MUS").Return(int64(3), nil)
+	mq.On("RestorePartner", "KOH").Return(int64(0), errors.New("error restoring partner because code taken"))
+	mq.On("RestoreKey", "Currency").Return(int64(1), nil)
+	mq.On("RestoreGroup", "asdfjkl").Return(int64(0), errors.New("error restoring group because none deleted"))
+	mq.On("RestorePartnerAttribute", "KOH", "Qualifier", int32(0)).Return(int64(1), nil)
+	mq.On("FindRestoreApprovalPolicies", "partners", "MUS").Return(map[string][]string{}, nil)
+	mq.On("FindRestoreApprovalPolicies", "partners", "KOH").Return(map[string][]string{}, nil)
+	mq.On("FindRestoreApprovalPolicies", "partners", "CIN").Return(map[string][]string{"Money": {"controller", "finance-lead"}}, nil)
+	mq.On("FindRestoreApprovalPolicies", "keys", "Currency").Return(map[string][]string{}, nil)
+	mq.On("FindRestoreApprovalPolicies", "keys", "Price").Return(map[string][]string{"Money": {"controller", "finance-lead"}}, nil)
+	mq.On("FindRestoreApprovalPolicies", "groups", "asdfjkl").Return(map[string][]string{}, nil)
+	mq.On("FindRestoreApprovalPolicies", "groups", "Money").Return(map[string][]string{"Money": {"controller", "finance-lead"}}, nil)
+
+	draft := models.ChangeSet{
+		Id:       pgx.NullInt32{Int32: 3, Valid: true},
+		Name:     pgx.NullString{String: "Kohls to CAD", Valid: true},
+		Status:   pgx.NullString{String: models.ChangeSetDraft, Valid: true},
+		Partners: []models.Partner{{Code: pgx.NullString{String: "KOH", Valid: true}, Attributes: map[string]string{"Currency": "CAD"}}},
+	}
+	published := draft
+	published.Status = pgx.NullString{String: models.ChangeSetPublished, Valid: true}
+	mq.On("CreateChangeSet", "Kohls to CAD").Return(int32(3), nil)
+	mq.On("FindChangeSet", int32(3)).Return(draft, nil)
+	mq.On("FindChangeSet", int32(4)).Return(published, nil)
+	mq.On("FindChangeSet", int32(99)).Return(nil, errors.New("error finding change set because bad id"))
+	mq.On("StageChange", int32(3), models.Partner{Code: pgx.NullString{String: "KOH", Valid: true}, Attributes: map[string]string{"Currency": "CAD"}}).Return(nil)
+	mq.On("StageChange", int32(3), newPartner("Mustang", "MUS", map[string]string{"Currency": "USD"})).Return(nil)
+	mq.On("PublishChangeSet", int32(3)).Return(nil)
+	mq.On("DiscardChangeSet", int32(3)).Return(nil)
+	unprotected := draft
+	unprotected.Partners = []models.Partner{{Code: pgx.NullString{String: "KOH", Valid: true}, Attributes: map[string]string{"Qualifier": "ZZ"}}}
+	mq.On("FindChangeSet", int32(5)).Return(unprotected, nil)
+	mq.On("PublishChangeSet", int32(5)).Return(nil)
+
+	mq.On("FindApprovalPolicies", []string{"Currency"}).Return(map[string][]string{"Money": {"controller", "finance-lead"}}, nil)
+	mq.On("FindApprovalPolicies", []string{"ISAID"}).Return(map[string][]string{}, nil)
+	mq.On("FindApprovalPolicies", []string{"Qualifier"}).Return(map[string][]string{}, nil)
+	mq.On("FindApprovalPolicies", []string{"ISAID", "Qualifier"}).Return(map[string][]string{}, nil)
+	mq.On("FindApprovalPolicies", []string{"850", "Qualifier"}).Return(map[string][]string{}, nil)
+	mq.On("RequestApproval", int32(3), "jdoe", []string{"Money"}).Return(int32(10), nil)
+	pendingRequest := models.ApprovalRequest{
+		Id:          pgx.NullInt32{Int32: 10, Valid: true},
+		ChangeSetId: pgx.NullInt32{Int32: 3, Valid: true},
+		Groups:      []string{"Money"},
+		Status:      pgx.NullString{String: models.ApprovalPending, Valid: true},
+		RequestedBy: pgx.NullString{String: "jdoe", Valid: true},
+	}
+	approvedRequest := pendingRequest
+	approvedRequest.Status = pgx.NullString{String: models.ApprovalApproved, Valid: true}
+	mq.On("FindApprovalRequest", int32(10)).Return(pendingRequest, nil)
+	mq.On("FindApprovalRequest", int32(11)).Return(approvedRequest, nil)
+	mq.On("FindApprovalRequests", "pending").Return([]models.ApprovalRequest{pendingRequest}, nil)
+
+	accessFrom := time.Date(2017, 8, 1, 0, 0, 0, 0, time.UTC)
+	accessTo := time.Date(2017, 9, 1, 0, 0, 0, 0, time.UTC)
+	accessRecord := models.AccessRecord{
+		Caller:      pgx.NullString{String: "jdoe", Valid: true},
+		PartnerCode: pgx.NullString{String: "KOH", Valid: true},
+		Keys:        []string{"AS2 Password"},
+	}
+	mq.On("FindAccessRecords", "KOH", accessFrom, accessTo).Return([]models.AccessRecord{accessRecord}, nil)
+
+	apiKey := models.APIKey{
+		Id:     pgx.NullInt32{Int32: 7, Valid: true},
+		Prefix: pgx.NullString{String: "psk_0a1b2c3d", Valid: true},
+		Owner:  pgx.NullString{String: "onboarding-pipeline", Valid: true},
+		Scope:  []string{"edi"},
+	}
+	//keys are only created with their issuer recorded
+	issuedByJdoe := mock.MatchedBy(func(k models.APIKey) bool { return k.IssuedBy.String == "jdoe" })
+	mq.On("CreateAPIKey", issuedByJdoe, mock.AnythingOfType("string")).Return(apiKey, nil)
+	mq.On("FindAPIKeys", "onboarding-pipeline").Return([]models.APIKey{apiKey}, nil)
+	mq.On("RotateAPIKey", int32(7), mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(apiKey, nil)
+	mq.On("RotateAPIKey", int32(8), mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(models.APIKey{}, errors.New("no api key with id: 8 that is not revoked"))
+	mq.On("RevokeAPIKey", int32(7)).Return(apiKey, nil)
+	mq.On("ApproveRequest", int32(10), "controller").Return(nil)
+	mq.On("RejectRequest", int32(10), "controller", "wrong currency").Return(nil)
+
 	service = NewPartnerService(mq)
 }
 
@@ -103,7 +476,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerDataFromKeyValueHappy() {
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
-	partnerId, partnerCode, attributes, err := service.GetPartnerDataByKeyValue(ctx, "Currency", "USD", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetPartnerDataByKeyValue(ctx, "Currency", "USD", "Money", false)
 	a.Nil(err)
 	a.Equal(int32(1), partnerId)
 	a.Equal("KOH", partnerCode)
@@ -112,7 +485,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerDataFromKeyValueHappy() {
 
 func (suite *ServiceMethodsSuite) TestFindPartnerDataFromKeyValueNilKey() {
 	a := assert.New(suite.T())
-	partnerId, partnerCode, attributes, err := service.GetPartnerDataByKeyValue(ctx, "", "USD", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetPartnerDataByKeyValue(ctx, "", "USD", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -121,7 +494,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerDataFromKeyValueNilKey() {
 
 func (suite *ServiceMethodsSuite) TestFindPartnerDataFromKeyValueNilValue() {
 	a := assert.New(suite.T())
-	partnerId, partnerCode, attributes, err := service.GetPartnerDataByKeyValue(ctx, "Currency", "", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetPartnerDataByKeyValue(ctx, "Currency", "", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -130,7 +503,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerDataFromKeyValueNilValue() {
 
 func (suite *ServiceMethodsSuite) TestFindPartnerDataFromKeyValueBadKey() {
 	a := assert.New(suite.T())
-	partnerId, partnerCode, attributes, err := service.GetPartnerDataByKeyValue(ctx, "asdfjkl", "USD", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetPartnerDataByKeyValue(ctx, "asdfjkl", "USD", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -139,7 +512,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerDataFromKeyValueBadKey() {
 
 func (suite *ServiceMethodsSuite) TestFindPartnerDataFromKeyValueBadValue() {
 	a := assert.New(suite.T())
-	partnerId, partnerCode, attributes, err := service.GetPartnerDataByKeyValue(ctx, "Currency", "asdfjkl", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetPartnerDataByKeyValue(ctx, "Currency", "asdfjkl", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -152,7 +525,7 @@ func (suite *ServiceMethodsSuite) TestFindAllAttributesForPartnerKVHappy() {
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
-	partnerId, partnerCode, attributes, err := service.GetPartnerDataByKeyValue(ctx, "Currency", "USD", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetPartnerDataByKeyValue(ctx, "Currency", "USD", "Money", false)
 	a.Nil(err)
 	a.Equal(int32(1), partnerId)
 	a.Equal("KOH", partnerCode)
@@ -164,7 +537,7 @@ func (suite *ServiceMethodsSuite) TestFindAllAttributesForPartnerNilKey() {
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
-	partnerId, partnerCode, attributes, err := service.GetPartnerDataByKeyValue(ctx, "", "USD", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetPartnerDataByKeyValue(ctx, "", "USD", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -176,7 +549,7 @@ func (suite *ServiceMethodsSuite) TestFindAllAttributesForPartnerNilValue() {
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
-	partnerId, partnerCode, attributes, err := service.GetPartnerDataByKeyValue(ctx, "Currency", "", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetPartnerDataByKeyValue(ctx, "Currency", "", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -185,7 +558,7 @@ func (suite *ServiceMethodsSuite) TestFindAllAttributesForPartnerNilValue() {
 
 func (suite *ServiceMethodsSuite) TestFindAllAttributesForPartnerBadKey() {
 	a := assert.New(suite.T())
-	partnerId, partnerCode, attributes, err := service.GetPartnerDataByKeyValue(ctx, "asdfjkl", "USD", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetPartnerDataByKeyValue(ctx, "asdfjkl", "USD", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -194,7 +567,7 @@ func (suite *ServiceMethodsSuite) TestFindAllAttributesForPartnerBadKey() {
 
 func (suite *ServiceMethodsSuite) TestFindAllAttributesForPartnerBadValue() {
 	a := assert.New(suite.T())
-	partnerId, partnerCode, attributes, err := service.GetPartnerDataByKeyValue(ctx, "Currency", "asdfjkl", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetPartnerDataByKeyValue(ctx, "Currency", "asdfjkl", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -208,7 +581,7 @@ func (suite *ServiceMethodsSuite) TestFindAllAttributesForPartnerIDHappy() {
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(1), "KOH", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(1), "KOH", "Money", false)
 	a.Nil(err)
 	a.Equal(int32(1), partnerId)
 	a.Equal("KOH", partnerCode)
@@ -217,7 +590,7 @@ func (suite *ServiceMethodsSuite) TestFindAllAttributesForPartnerIDHappy() {
 
 func (suite *ServiceMethodsSuite) TestFindAllAttributesForPartnerNilIdAndNilCode() {
 	a := assert.New(suite.T())
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(0), "", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(0), "", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -229,7 +602,7 @@ func (suite *ServiceMethodsSuite) TestFindAllAttributesForPartnerIDNilId() {
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(0), "KOH", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(0), "KOH", "Money", false)
 	a.Nil(err)
 	a.Equal(int32(1), partnerId)
 	a.Equal("KOH", partnerCode)
@@ -241,7 +614,7 @@ func (suite *ServiceMethodsSuite) TestFindAllAttributesForPartnerIDNilCode() {
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(1), "", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(1), "", "Money", false)
 	a.Nil(err)
 	a.Equal(int32(1), partnerId)
 	a.Equal("KOH", partnerCode)
@@ -250,7 +623,7 @@ func (suite *ServiceMethodsSuite) TestFindAllAttributesForPartnerIDNilCode() {
 
 func (suite *ServiceMethodsSuite) TestFindAllAttributesForPartnerNegativeId() {
 	a := assert.New(suite.T())
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(-1), "KOH", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(-1), "KOH", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -259,7 +632,7 @@ func (suite *ServiceMethodsSuite) TestFindAllAttributesForPartnerNegativeId() {
 
 func (suite *ServiceMethodsSuite) TestFindAllAttributesForPartnerBadId() {
 	a := assert.New(suite.T())
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(-1), "KOH", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(-1), "KOH", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -268,7 +641,7 @@ func (suite *ServiceMethodsSuite) TestFindAllAttributesForPartnerBadId() {
 
 func (suite *ServiceMethodsSuite) TestFindAllAttributesForPartnerBadCode() {
 	a := assert.New(suite.T())
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(1), "asdfjkl", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(1), "asdfjkl", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -281,7 +654,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerAttributeHappyKV() {
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
-	partnerId, partnerCode, attributes, err := service.GetPartnerDataByKeyValue(ctx, "Currency", "USD", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetPartnerDataByKeyValue(ctx, "Currency", "USD", "Money", false)
 	a.Nil(err)
 	a.Equal(int32(1), partnerId)
 	a.Equal("KOH", partnerCode)
@@ -293,7 +666,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerAttributeNilKey() {
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
-	partnerId, partnerCode, attributes, err := service.GetPartnerDataByKeyValue(ctx, "", "USD", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetPartnerDataByKeyValue(ctx, "", "USD", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -305,7 +678,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerAttributeNilValue() {
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
-	partnerId, partnerCode, attributes, err := service.GetPartnerDataByKeyValue(ctx, "Currency", "", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetPartnerDataByKeyValue(ctx, "Currency", "", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -317,7 +690,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerAttributeNilGroup() {
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
-	partnerId, partnerCode, attributes, err := service.GetPartnerDataByKeyValue(ctx, "Currency", "USD", "")
+	partnerId, partnerCode, attributes, _, err := service.GetPartnerDataByKeyValue(ctx, "Currency", "USD", "", false)
 	a.Nil(err)
 	a.Equal(int32(1), partnerId)
 	a.Equal("KOH", partnerCode)
@@ -326,7 +699,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerAttributeNilGroup() {
 
 func (suite *ServiceMethodsSuite) TestFindPartnerAttributeBadKey() {
 	a := assert.New(suite.T())
-	partnerId, partnerCode, attributes, err := service.GetPartnerDataByKeyValue(ctx, "asdfjkl", "USD", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetPartnerDataByKeyValue(ctx, "asdfjkl", "USD", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -335,7 +708,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerAttributeBadKey() {
 
 func (suite *ServiceMethodsSuite) TestFindPartnerAttributeBadValue() {
 	a := assert.New(suite.T())
-	partnerId, partnerCode, attributes, err := service.GetPartnerDataByKeyValue(ctx, "Currency", "asdfjkl", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetPartnerDataByKeyValue(ctx, "Currency", "asdfjkl", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -347,7 +720,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerAttributeBadGroup() {
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
-	partnerId, partnerCode, attributes, err := service.GetPartnerDataByKeyValue(ctx, "Currency", "USD", "asdfjkl")
+	partnerId, partnerCode, attributes, _, err := service.GetPartnerDataByKeyValue(ctx, "Currency", "USD", "asdfjkl", false)
 	a.NotNil(err)
 	a.Equal(int32(1), partnerId)
 	a.Equal("KOH", partnerCode)
@@ -360,7 +733,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerAttributeIDHappy() {
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(1), "KOH", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(1), "KOH", "Money", false)
 	a.Nil(err)
 	a.Equal(int32(1), partnerId)
 	a.Equal("KOH", partnerCode)
@@ -372,7 +745,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerAttributeNilIdAndNilCode() {
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(0), "", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(0), "", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -384,7 +757,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerAttributeNilId() {
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(0), "KOH", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(0), "KOH", "Money", false)
 	a.Nil(err)
 	a.Equal(int32(1), partnerId)
 	a.Equal("KOH", partnerCode)
@@ -396,7 +769,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerAttributeNilCode() {
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(1), "", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(1), "", "Money", false)
 	a.Nil(err)
 	a.Equal(int32(1), partnerId)
 	a.Equal("KOH", partnerCode)
@@ -408,7 +781,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerAttributeIDNilGroup() {
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(1), "KOH", "")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(1), "KOH", "", false)
 	a.Nil(err)
 	a.Equal(int32(1), partnerId)
 	a.Equal("KOH", partnerCode)
@@ -417,7 +790,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerAttributeIDNilGroup() {
 
 func (suite *ServiceMethodsSuite) TestFindPartnerAttributeNegativeId() {
 	a := assert.New(suite.T())
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(-1), "KOH", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(-1), "KOH", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -426,7 +799,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerAttributeNegativeId() {
 
 func (suite *ServiceMethodsSuite) TestFindPartnerAttributeBadId() {
 	a := assert.New(suite.T())
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(-1), "KOH", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(-1), "KOH", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -435,7 +808,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerAttributeBadId() {
 
 func (suite *ServiceMethodsSuite) TestFindPartnerAttributeBadCode() {
 	a := assert.New(suite.T())
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(1), "asdfjkl", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(1), "asdfjkl", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -447,7 +820,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerAttributeIDBadGroup() {
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(1), "KOH", "asdfjkl")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(1), "KOH", "asdfjkl", false)
 	a.NotNil(err)
 	a.Equal(int32(1), partnerId)
 	a.Equal("KOH", partnerCode)
@@ -460,11 +833,12 @@ func (suite *ServiceMethodsSuite) TestFindPartnerDataByIDHappy() {
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(1), "KOH", "Money")
+	partnerId, partnerCode, attributes, revision, err := service.GetDataById(ctx, int32(1), "KOH", "Money", false)
 	a.Nil(err)
 	a.Equal(int32(1), partnerId)
 	a.Equal("KOH", partnerCode)
 	a.Equal(wantedMap, attributes)
+	a.Equal(int32(3), revision)
 }
 
 func (suite *ServiceMethodsSuite) TestFindPartnerDataByIDNilId() {
@@ -472,7 +846,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerDataByIDNilId() {
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(0), "KOH", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(0), "KOH", "Money", false)
 	a.Nil(err)
 	a.Equal(int32(1), partnerId)
 	a.Equal("KOH", partnerCode)
@@ -484,7 +858,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerDataByIDNilCode() {
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(1), "", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(1), "", "Money", false)
 	a.Nil(err)
 	a.Equal(int32(1), partnerId)
 	a.Equal("KOH", partnerCode)
@@ -493,7 +867,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerDataByIDNilCode() {
 
 func (suite *ServiceMethodsSuite) TestFindPartnerDataByNilIdAndNilCode() {
 	a := assert.New(suite.T())
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(0), "", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(0), "", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -502,7 +876,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerDataByNilIdAndNilCode() {
 
 func (suite *ServiceMethodsSuite) TestFindPartnerDataByNegativeId() {
 	a := assert.New(suite.T())
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(-1), "KOH", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(-1), "KOH", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -511,7 +885,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerDataByNegativeId() {
 
 func (suite *ServiceMethodsSuite) TestFindPartnerDataByIDBadId() {
 	a := assert.New(suite.T())
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(-1), "KOH", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(-1), "KOH", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -520,7 +894,7 @@ func (suite *ServiceMethodsSuite) TestFindPartnerDataByIDBadId() {
 
 func (suite *ServiceMethodsSuite) TestFindPartnerDataByIDBadCode() {
 	a := assert.New(suite.T())
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(1), "asdfjkl", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(1), "asdfjkl", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -533,7 +907,7 @@ func (suite *ServiceMethodsSuite) TestCheckPartnerIDEqualsPartnerCodeHappy() {
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(1), "KOH", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(1), "KOH", "Money", false)
 	a.Nil(err)
 	a.Equal(int32(1), partnerId)
 	a.Equal("KOH", partnerCode)
@@ -545,7 +919,7 @@ func (suite *ServiceMethodsSuite) TestCheckPartnerIDEqualsPartnerCodeNilId() {
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(0), "KOH", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(0), "KOH", "Money", false)
 	a.Nil(err)
 	a.Equal(int32(1), partnerId)
 	a.Equal("KOH", partnerCode)
@@ -557,7 +931,7 @@ func (suite *ServiceMethodsSuite) TestCheckPartnerIDEqualsPartnerCodeNilCode() {
 	wantedMap := make(map[string]string)
 	wantedMap["Currency"] = "USD"
 	wantedMap["Type of Payment"] = "Credit"
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(1), "", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(1), "", "Money", false)
 	a.Nil(err)
 	a.Equal(int32(1), partnerId)
 	a.Equal("KOH", partnerCode)
@@ -566,7 +940,7 @@ func (suite *ServiceMethodsSuite) TestCheckPartnerIDEqualsPartnerCodeNilCode() {
 
 func (suite *ServiceMethodsSuite) TestCheckPartnerIDEqualsPartnerCodeNilIdAndNilCode() {
 	a := assert.New(suite.T())
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(0), "", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(0), "", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -575,7 +949,7 @@ func (suite *ServiceMethodsSuite) TestCheckPartnerIDEqualsPartnerCodeNilIdAndNil
 
 func (suite *ServiceMethodsSuite) TestCheckPartnerIDEqualsPartnerCodeNegativeId() {
 	a := assert.New(suite.T())
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(-1), "KOH", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(-1), "KOH", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -584,7 +958,7 @@ func (suite *ServiceMethodsSuite) TestCheckPartnerIDEqualsPartnerCodeNegativeId(
 
 func (suite *ServiceMethodsSuite) TestCheckPartnerIDEqualsPartnerCodeBadId() {
 	a := assert.New(suite.T())
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(-1), "KOH", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(-1), "KOH", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
@@ -593,9 +967,610 @@ func (suite *ServiceMethodsSuite) TestCheckPartnerIDEqualsPartnerCodeBadId() {
 
 func (suite *ServiceMethodsSuite) TestCheckPartnerIDEqualsPartnerCodeBadCode() {
 	a := assert.New(suite.T())
-	partnerId, partnerCode, attributes, err := service.GetDataById(ctx, int32(1), "asdfjkl", "Money")
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(1), "asdfjkl", "Money", false)
 	a.NotNil(err)
 	a.Equal(int32(0), partnerId)
 	a.Equal("", partnerCode)
 	a.Equal(make(map[string]string), attributes)
 }
+
+//test ExportPartners
+func (suite *ServiceMethodsSuite) TestExportPartnersHappy() {
+	a := assert.New(suite.T())
+	partners, err := service.ExportPartners(ctx, "", nil)
+	a.Nil(err)
+	a.Len(partners, 1)
+	a.Equal("KOH", partners[0].Code.String)
+	a.Equal(map[string]string{"Currency": "USD", "Type of Payment": "Credit"}, partners[0].Attributes)
+}
+
+func (suite *ServiceMethodsSuite) TestExportPartnersByCodeAndGroup() {
+	a := assert.New(suite.T())
+	partners, err := service.ExportPartners(ctx, "Money", []string{"KOH"})
+	a.Nil(err)
+	a.Len(partners, 1)
+	a.Equal("KOH", partners[0].Code.String)
+}
+
+func (suite *ServiceMethodsSuite) TestExportPartnersMissingCode() {
+	a := assert.New(suite.T())
+	partners, err := service.ExportPartners(ctx, "", []string{"KOH", "asdfjkl"})
+	a.NotNil(err)
+	a.Nil(partners)
+}
+
+func (suite *ServiceMethodsSuite) TestExportPartnersBadGroup() {
+	a := assert.New(suite.T())
+	partners, err := service.ExportPartners(ctx, "asdfjkl", nil)
+	a.NotNil(err)
+	a.Nil(partners)
+}
+
+//test ComparePartners
+func (suite *ServiceMethodsSuite) TestComparePartnersHappy() {
+	a := assert.New(suite.T())
+	keys, err := service.ComparePartners(ctx, []string{"KOH", "JCP"}, "")
+	a.Nil(err)
+	a.Equal([]KeyComparison{
+		{Key: "Currency", Status: KeyEqual, Values: map[string]string{"KOH": "USD", "JCP": "USD"}},
+		{Key: "Qualifier", Status: KeyMissing, Values: map[string]string{"JCP": "ZZ"}},
+		{Key: "Type of Payment", Status: KeyDifferent, Values: map[string]string{"KOH": "Credit", "JCP": "Debit"}},
+	}, keys)
+}
+
+func (suite *ServiceMethodsSuite) TestComparePartnersOneCode() {
+	a := assert.New(suite.T())
+	keys, err := service.ComparePartners(ctx, []string{"KOH"}, "")
+	a.NotNil(err)
+	a.Nil(keys)
+}
+
+func (suite *ServiceMethodsSuite) TestComparePartnersDuplicateCode() {
+	a := assert.New(suite.T())
+	keys, err := service.ComparePartners(ctx, []string{"KOH", "KOH"}, "")
+	a.NotNil(err)
+	a.Nil(keys)
+}
+
+func (suite *ServiceMethodsSuite) TestComparePartnersMissingCode() {
+	a := assert.New(suite.T())
+	keys, err := service.ComparePartners(ctx, []string{"KOH", "asdfjkl"}, "")
+	a.NotNil(err)
+	a.Nil(keys)
+}
+
+func (suite *ServiceMethodsSuite) TestComparePartnersBadGroup() {
+	a := assert.New(suite.T())
+	keys, err := service.ComparePartners(ctx, []string{"KOH", "JCP"}, "asdfjkl")
+	a.NotNil(err)
+	a.Nil(keys)
+}
+
+//test ClonePartner
+func (suite *ServiceMethodsSuite) TestClonePartnerByGroupHappy() {
+	a := assert.New(suite.T())
+	partnerId, partnerCode, attributes, err := service.ClonePartner(ctx, "Dicks East", "DIE", "DIC", "", []string{"EDI"}, nil)
+	a.Nil(err)
+	a.Equal(int32(7), partnerId)
+	a.Equal("DIE", partnerCode)
+	a.Equal(map[string]string{"Qualifier": "ZZ"}, attributes)
+}
+
+func (suite *ServiceMethodsSuite) TestClonePartnerOverridesIdentifier() {
+	a := assert.New(suite.T())
+	partnerId, partnerCode, attributes, err := service.ClonePartner(ctx, "Dicks West", "DIW", "DIC", "", []string{"EDI"}, map[string]string{"ISAID": "DICKSWEST"})
+	a.Nil(err)
+	a.Equal(int32(8), partnerId)
+	a.Equal("DIW", partnerCode)
+	a.Equal(map[string]string{"Qualifier": "ZZ", "ISAID": "DICKSWEST"}, attributes)
+}
+
+func (suite *ServiceMethodsSuite) TestClonePartnerFromTemplate() {
+	a := assert.New(suite.T())
+	partnerId, partnerCode, attributes, err := service.ClonePartner(ctx, "Target", "TAR", "", "Standard US EDI retailer", nil, nil)
+	a.Nil(err)
+	a.Equal(int32(9), partnerId)
+	a.Equal("TAR", partnerCode)
+	a.Equal(map[string]string{"Qualifier": "ZZ", "850": "Received"}, attributes)
+}
+
+func (suite *ServiceMethodsSuite) TestClonePartnerSourceAndTemplate() {
+	a := assert.New(suite.T())
+	partnerId, partnerCode, attributes, err := service.ClonePartner(ctx, "Target", "TAR", "DIC", "Standard US EDI retailer", nil, nil)
+	a.NotNil(err)
+	a.Equal(int32(0), partnerId)
+	a.Equal("", partnerCode)
+	a.Equal(make(map[string]string), attributes)
+}
+
+func (suite *ServiceMethodsSuite) TestClonePartnerNilName() {
+	a := assert.New(suite.T())
+	partnerId, _, _, err := service.ClonePartner(ctx, "", "TAR", "DIC", "", nil, nil)
+	a.NotNil(err)
+	a.Equal(int32(0), partnerId)
+}
+
+func (suite *ServiceMethodsSuite) TestClonePartnerCodeTaken() {
+	a := assert.New(suite.T())
+	partnerId, partnerCode, attributes, err := service.ClonePartner(ctx, "Kohls", "KOH", "DIC", "", []string{"EDI"}, nil)
+	a.NotNil(err)
+	a.Equal(int32(0), partnerId)
+	a.Equal("", partnerCode)
+	a.Equal(make(map[string]string), attributes)
+}
+
+func (suite *ServiceMethodsSuite) TestClonePartnerBadSource() {
+	a := assert.New(suite.T())
+	partnerId, _, _, err := service.ClonePartner(ctx, "Target", "TAR", "asdfjkl", "", nil, nil)
+	a.NotNil(err)
+	a.Equal(int32(0), partnerId)
+}
+
+func (suite *ServiceMethodsSuite) TestClonePartnerBadTemplate() {
+	a := assert.New(suite.T())
+	partnerId, _, _, err := service.ClonePartner(ctx, "Target", "TAR", "", "asdfjkl", nil, nil)
+	a.NotNil(err)
+	a.Equal(int32(0), partnerId)
+}
+
+//test lookups of partners that are not active
+func (suite *ServiceMethodsSuite) TestFindPartnerDataFromKeyValueInactive() {
+	a := assert.New(suite.T())
+	partnerId, partnerCode, attributes, _, err := service.GetPartnerDataByKeyValue(ctx, "ISAID", "DICKS1", "", false)
+	a.NotNil(err)
+	a.Equal(int32(0), partnerId)
+	a.Equal("", partnerCode)
+	a.Equal(make(map[string]string), attributes)
+}
+
+func (suite *ServiceMethodsSuite) TestFindPartnerDataFromKeyValueIncludeInactive() {
+	a := assert.New(suite.T())
+	partnerId, partnerCode, attributes, _, err := service.GetPartnerDataByKeyValue(ctx, "ISAID", "DICKS1", "", true)
+	a.Nil(err)
+	a.Equal(int32(5), partnerId)
+	a.Equal("DIC", partnerCode)
+	a.Equal(map[string]string{"Currency": "USD", "ISAID": "DICKS1"}, attributes)
+}
+
+func (suite *ServiceMethodsSuite) TestGetDataByIdInactive() {
+	a := assert.New(suite.T())
+	partnerId, partnerCode, attributes, _, err := service.GetDataById(ctx, int32(5), "DIC", "", false)
+	a.NotNil(err)
+	a.Equal(int32(0), partnerId)
+	a.Equal("", partnerCode)
+	a.Equal(make(map[string]string), attributes)
+}
+
+func (suite *ServiceMethodsSuite) TestGetDataByIdIncludeInactive() {
+	a := assert.New(suite.T())
+	partnerId, partnerCode, _, _, err := service.GetDataById(ctx, int32(5), "DIC", "", true)
+	a.Nil(err)
+	a.Equal(int32(5), partnerId)
+	a.Equal("DIC", partnerCode)
+}
+
+//test SetPartnerStatus
+func (suite *ServiceMethodsSuite) TestSetPartnerStatusSuspend() {
+	a := assert.New(suite.T())
+	partnerId, changedAt, err := service.SetPartnerStatus(ctx, "KOH", StatusSuspended, 0)
+	a.Nil(err)
+	a.Equal(int32(1), partnerId)
+	a.Equal(time.Date(2017, 8, 1, 12, 0, 0, 0, time.UTC), changedAt)
+}
+
+func (suite *ServiceMethodsSuite) TestSetPartnerStatusReactivate() {
+	a := assert.New(suite.T())
+	partnerId, _, err := service.SetPartnerStatus(ctx, "DIC", StatusActive, 0)
+	a.Nil(err)
+	a.Equal(int32(5), partnerId)
+}
+
+func (suite *ServiceMethodsSuite) TestSetPartnerStatusNotAllowed() {
+	a := assert.New(suite.T())
+	partnerId, changedAt, err := service.SetPartnerStatus(ctx, "KOH", StatusOnboarding, 0)
+	a.NotNil(err)
+	a.Equal(int32(0), partnerId)
+	a.True(changedAt.IsZero())
+}
+
+func (suite *ServiceMethodsSuite) TestSetPartnerStatusSame() {
+	a := assert.New(suite.T())
+	partnerId, _, err := service.SetPartnerStatus(ctx, "KOH", StatusActive, 0)
+	a.NotNil(err)
+	a.Equal(int32(0), partnerId)
+}
+
+func (suite *ServiceMethodsSuite) TestSetPartnerStatusUnknown() {
+	a := assert.New(suite.T())
+	partnerId, _, err := service.SetPartnerStatus(ctx, "KOH", "paused", 0)
+	a.NotNil(err)
+	a.Equal(int32(0), partnerId)
+}
+
+func (suite *ServiceMethodsSuite) TestSetPartnerStatusBadCode() {
+	a := assert.New(suite.T())
+	partnerId, _, err := service.SetPartnerStatus(ctx, "asdfjkl", StatusSuspended, 0)
+	a.NotNil(err)
+	a.Equal(int32(0), partnerId)
+}
+
+func (suite *ServiceMethodsSuite) TestSetPartnerStatusRevisionConflict() {
+	a := assert.New(suite.T())
+	partnerId, _, err := service.SetPartnerStatus(ctx, "KOH", StatusSuspended, 2)
+	a.NotNil(err)
+	a.True(IsConflict(err))
+	a.Equal(int32(0), partnerId)
+}
+
+func (suite *ServiceMethodsSuite) TestSetPartnerStatusNegativeRevision() {
+	a := assert.New(suite.T())
+	_, _, err := service.SetPartnerStatus(ctx, "KOH", StatusSuspended, -1)
+	a.NotNil(err)
+	a.False(IsConflict(err))
+}
+
+//test the Restore methods
+func (suite *ServiceMethodsSuite) TestRestorePartner() {
+	a := assert.New(suite.T())
+	restored, err := service.RestorePartner(ctx, "MUS")
+	a.Nil(err)
+	a.Equal(int32(3), restored)
+}
+
+func (suite *ServiceMethodsSuite) TestRestorePartnerCodeTaken() {
+	a := assert.New(suite.T())
+	restored, err := service.RestorePartner(ctx, "KOH")
+	a.NotNil(err)
+	a.Equal(int32(0), restored)
+}
+
+func (suite *ServiceMethodsSuite) TestRestorePartnerEmpty() {
+	a := assert.New(suite.T())
+	restored, err := service.RestorePartner(ctx, "")
+	a.NotNil(err)
+	a.Equal(int32(0), restored)
+}
+
+func (suite *ServiceMethodsSuite) TestRestoreKey() {
+	a := assert.New(suite.T())
+	restored, err := service.RestoreKey(ctx, "Currency")
+	a.Nil(err)
+	a.Equal(int32(1), restored)
+}
+
+func (suite *ServiceMethodsSuite) TestRestoreGroupNoneDeleted() {
+	a := assert.New(suite.T())
+	restored, err := service.RestoreGroup(ctx, "asdfjkl")
+	a.NotNil(err)
+	a.Equal(int32(0), restored)
+}
+
+func (suite *ServiceMethodsSuite) TestRestorePartnerProtected() {
+	a := assert.New(suite.T())
+	restored, err := service.RestorePartner(ctx, "CIN")
+	a.NotNil(err)
+	a.Contains(err.Error(), "Money")
+	a.Equal(int32(0), restored)
+}
+
+func (suite *ServiceMethodsSuite) TestRestoreKeyProtected() {
+	a := assert.New(suite.T())
+	restored, err := service.RestoreKey(ctx, "Price")
+	a.NotNil(err)
+	a.Contains(err.Error(), "Money")
+	a.Equal(int32(0), restored)
+}
+
+func (suite *ServiceMethodsSuite) TestRestoreGroupProtected() {
+	a := assert.New(suite.T())
+	restored, err := service.RestoreGroup(ctx, "Money")
+	a.NotNil(err)
+	a.Contains(err.Error(), "Money")
+	a.Equal(int32(0), restored)
+}
+
+func (suite *ServiceMethodsSuite) TestRestorePartnerAttribute() {
+	a := assert.New(suite.T())
+	restored, err := service.RestorePartnerAttribute(ctx, "KOH", "Qualifier", 0)
+	a.Nil(err)
+	a.Equal(int32(1), restored)
+}
+
+func (suite *ServiceMethodsSuite) TestRestorePartnerAttributeEmptyKey() {
+	a := assert.New(suite.T())
+	restored, err := service.RestorePartnerAttribute(ctx, "KOH", "", 0)
+	a.NotNil(err)
+	a.Equal(int32(0), restored)
+}
+
+//test change sets
+func (suite *ServiceMethodsSuite) TestOpenChangeSet() {
+	a := assert.New(suite.T())
+	changeSet, err := service.OpenChangeSet(ctx, "Kohls to CAD")
+	a.Nil(err)
+	a.Equal(int32(3), changeSet.Id.Int32)
+	a.Equal(models.ChangeSetDraft, changeSet.Status.String)
+}
+
+func (suite *ServiceMethodsSuite) TestOpenChangeSetEmptyName() {
+	a := assert.New(suite.T())
+	_, err := service.OpenChangeSet(ctx, "")
+	a.NotNil(err)
+}
+
+func (suite *ServiceMethodsSuite) TestStageChangeUpdate() {
+	a := assert.New(suite.T())
+	changeSet, err := service.StageChange(ctx, int32(3), "KOH", "", map[string]string{"Currency": "CAD"}, 0)
+	a.Nil(err)
+	a.Equal(1, len(changeSet.Partners))
+}
+
+func (suite *ServiceMethodsSuite) TestStageChangeCreate() {
+	a := assert.New(suite.T())
+	_, err := service.StageChange(ctx, int32(3), "MUS", "Mustang", map[string]string{"Currency": "USD"}, 0)
+	a.Nil(err)
+}
+
+func (suite *ServiceMethodsSuite) TestStageChangeCreateNeedsName() {
+	a := assert.New(suite.T())
+	_, err := service.StageChange(ctx, int32(3), "asdfjkl", "", map[string]string{"Currency": "USD"}, 0)
+	a.NotNil(err)
+}
+
+func (suite *ServiceMethodsSuite) TestStageChangePublished() {
+	a := assert.New(suite.T())
+	_, err := service.StageChange(ctx, int32(4), "KOH", "", map[string]string{"Currency": "CAD"}, 0)
+	a.NotNil(err)
+}
+
+func (suite *ServiceMethodsSuite) TestPreviewChangeSet() {
+	a := assert.New(suite.T())
+	partnerId, partnerCode, attributes, err := service.PreviewChangeSet(ctx, int32(3), "KOH")
+	a.Nil(err)
+	a.Equal(int32(1), partnerId)
+	a.Equal("KOH", partnerCode)
+	a.Equal(map[string]string{"Currency": "CAD", "Type of Payment": "Credit"}, attributes)
+}
+
+func (suite *ServiceMethodsSuite) TestPreviewChangeSetNotStaged() {
+	a := assert.New(suite.T())
+	partnerId, _, _, err := service.PreviewChangeSet(ctx, int32(3), "DIC")
+	a.NotNil(err)
+	a.Equal(int32(0), partnerId)
+}
+
+func (suite *ServiceMethodsSuite) TestPreviewChangeSetBadId() {
+	a := assert.New(suite.T())
+	_, _, _, err := service.PreviewChangeSet(ctx, int32(99), "KOH")
+	a.NotNil(err)
+}
+
+func (suite *ServiceMethodsSuite) TestGetDataByIdDoesNotSeeDraft() {
+	a := assert.New(suite.T())
+	_, err := service.StageChange(ctx, int32(3), "KOH", "", map[string]string{"Currency": "CAD"}, 0)
+	a.Nil(err)
+	_, _, attributes, _, err := service.GetDataById(ctx, int32(1), "KOH", "", false)
+	a.Nil(err)
+	a.Equal("USD", attributes["Currency"])
+}
+
+func (suite *ServiceMethodsSuite) TestPublishChangeSet() {
+	a := assert.New(suite.T())
+	_, err := service.PublishChangeSet(ctx, int32(5))
+	a.Nil(err)
+}
+
+func (suite *ServiceMethodsSuite) TestPublishChangeSetProtectedRequestsApproval() {
+	a := assert.New(suite.T())
+	_, err := service.PublishChangeSet(identity.NewContext(ctx, "jdoe"), int32(3))
+	a.Nil(err)
+}
+
+func (suite *ServiceMethodsSuite) TestPublishChangeSetProtectedNoCaller() {
+	a := assert.New(suite.T())
+	_, err := service.PublishChangeSet(ctx, int32(3))
+	a.NotNil(err)
+}
+
+func (suite *ServiceMethodsSuite) TestPublishChangeSetAlreadyPublished() {
+	a := assert.New(suite.T())
+	_, err := service.PublishChangeSet(ctx, int32(4))
+	a.NotNil(err)
+}
+
+func (suite *ServiceMethodsSuite) TestDiscardChangeSet() {
+	a := assert.New(suite.T())
+	_, err := service.DiscardChangeSet(ctx, int32(3))
+	a.Nil(err)
+}
+
+func (suite *ServiceMethodsSuite) TestDiscardChangeSetZeroId() {
+	a := assert.New(suite.T())
+	_, err := service.DiscardChangeSet(ctx, int32(0))
+	a.NotNil(err)
+}
+
+//test approvals
+func (suite *ServiceMethodsSuite) TestClonePartnerOverridesProtected() {
+	a := assert.New(suite.T())
+	partnerId, _, _, err := service.ClonePartner(ctx, "Dicks West", "DIW", "DIC", "", nil, map[string]string{"Currency": "CAD"})
+	a.NotNil(err)
+	a.Equal(int32(0), partnerId)
+}
+
+func (suite *ServiceMethodsSuite) TestClonePartnerCopiesProtected() {
+	a := assert.New(suite.T())
+	partnerId, _, _, err := service.ClonePartner(ctx, "Dicks East", "DIE", "DIC", "", []string{"Money"}, nil)
+	a.EqualError(err, "keys in Money need approval, stage them in a change set instead")
+	a.Equal(int32(0), partnerId)
+
+	partnerId, _, _, err = service.ClonePartner(ctx, "Dicks West", "DIW", "DIC", "", nil, nil)
+	a.NotNil(err)
+	a.Equal(int32(0), partnerId)
+}
+
+func (suite *ServiceMethodsSuite) TestListApprovalRequests() {
+	a := assert.New(suite.T())
+	requests, err := service.ListApprovalRequests(ctx, models.ApprovalPending)
+	a.Nil(err)
+	a.Equal(1, len(requests))
+}
+
+func (suite *ServiceMethodsSuite) TestListApprovalRequestsBadStatus() {
+	a := assert.New(suite.T())
+	_, err := service.ListApprovalRequests(ctx, "asdfjkl")
+	a.NotNil(err)
+}
+
+func (suite *ServiceMethodsSuite) TestApproveRequest() {
+	a := assert.New(suite.T())
+	_, err := service.ApproveRequest(identity.NewContext(ctx, "controller"), int32(10))
+	a.Nil(err)
+}
+
+func (suite *ServiceMethodsSuite) TestApproveRequestByRequester() {
+	a := assert.New(suite.T())
+	_, err := service.ApproveRequest(identity.NewContext(ctx, "jdoe"), int32(10))
+	a.NotNil(err)
+}
+
+func (suite *ServiceMethodsSuite) TestApproveRequestNotApprover() {
+	a := assert.New(suite.T())
+	_, err := service.ApproveRequest(identity.NewContext(ctx, "intern"), int32(10))
+	a.NotNil(err)
+}
+
+func (suite *ServiceMethodsSuite) TestApproveRequestNoCaller() {
+	a := assert.New(suite.T())
+	_, err := service.ApproveRequest(ctx, int32(10))
+	a.NotNil(err)
+}
+
+func (suite *ServiceMethodsSuite) TestApproveRequestAlreadyApproved() {
+	a := assert.New(suite.T())
+	_, err := service.ApproveRequest(identity.NewContext(ctx, "controller"), int32(11))
+	a.NotNil(err)
+}
+
+func (suite *ServiceMethodsSuite) TestRejectRequest() {
+	a := assert.New(suite.T())
+	_, err := service.RejectRequest(identity.NewContext(ctx, "controller"), int32(10), "wrong currency")
+	a.Nil(err)
+}
+
+func (suite *ServiceMethodsSuite) TestListAccessRecords() {
+	a := assert.New(suite.T())
+	records, err := service.ListAccessRecords(ctx, "KOH", "2017-08-01T00:00:00Z", "2017-09-01T00:00:00Z")
+	a.Nil(err)
+	a.Equal(1, len(records))
+	a.Equal([]string{"AS2 Password"}, records[0].Keys)
+}
+
+func (suite *ServiceMethodsSuite) TestListAccessRecordsNoPartnerCode() {
+	a := assert.New(suite.T())
+	_, err := service.ListAccessRecords(ctx, "", "2017-08-01T00:00:00Z", "2017-09-01T00:00:00Z")
+	a.NotNil(err)
+}
+
+func (suite *ServiceMethodsSuite) TestListAccessRecordsBadTime() {
+	a := assert.New(suite.T())
+	_, err := service.ListAccessRecords(ctx, "KOH", "August", "")
+	a.NotNil(err)
+}
+
+func (suite *ServiceMethodsSuite) TestListAccessRecordsFromAfterTo() {
+	a := assert.New(suite.T())
+	_, err := service.ListAccessRecords(ctx, "KOH", "2017-09-01T00:00:00Z", "2017-08-01T00:00:00Z")
+	a.NotNil(err)
+}
+
+//issuerCtx is a caller with the roles to issue keys scoped to edi.
+func issuerCtx() context.Context {
+	return identity.NewRolesContext(identity.NewContext(ctx, "jdoe"), []string{"admin", "edi"})
+}
+
+func (suite *ServiceMethodsSuite) TestIssueAPIKey() {
+	a := assert.New(suite.T())
+	apiKey, key, err := service.IssueAPIKey(issuerCtx(), "onboarding-pipeline", []string{"edi"}, time.Now().Add(time.Hour).Format(time.RFC3339))
+	a.Nil(err)
+	a.Equal(int32(7), apiKey.Id.Int32)
+	a.True(apikeys.LooksLikeKey(key))
+}
+
+func (suite *ServiceMethodsSuite) TestIssueAPIKeyBadInput() {
+	a := assert.New(suite.T())
+	_, _, err := service.IssueAPIKey(issuerCtx(), "", []string{"edi"}, "")
+	a.NotNil(err)
+	_, _, err = service.IssueAPIKey(issuerCtx(), "onboarding-pipeline", nil, "")
+	a.NotNil(err)
+	_, _, err = service.IssueAPIKey(issuerCtx(), "onboarding-pipeline", []string{"edi"}, "tomorrow")
+	a.NotNil(err)
+	_, key, err := service.IssueAPIKey(issuerCtx(), "onboarding-pipeline", []string{"edi"}, "2017-08-01T00:00:00Z")
+	a.NotNil(err)
+	a.Equal("", key)
+}
+
+func (suite *ServiceMethodsSuite) TestIssueAPIKeyBeyondIssuerRoles() {
+	a := assert.New(suite.T())
+	_, key, err := service.IssueAPIKey(issuerCtx(), "onboarding-pipeline", []string{"edi", "finance"}, "")
+	a.EqualError(err, "jdoe cannot issue a key with role finance, which they do not have")
+	a.Equal("", key)
+
+	_, _, err = service.IssueAPIKey(ctx, "onboarding-pipeline", []string{"edi"}, "")
+	a.EqualError(err, "api keys cannot be issued without a caller")
+}
+
+func (suite *ServiceMethodsSuite) TestListAPIKeys() {
+	a := assert.New(suite.T())
+	apiKeys, err := service.ListAPIKeys(ctx, "onboarding-pipeline")
+	a.Nil(err)
+	a.Equal(1, len(apiKeys))
+}
+
+func (suite *ServiceMethodsSuite) TestRotateAPIKey() {
+	a := assert.New(suite.T())
+	_, key, err := service.RotateAPIKey(ctx, int32(7))
+	a.Nil(err)
+	a.True(apikeys.LooksLikeKey(key))
+}
+
+func (suite *ServiceMethodsSuite) TestRotateAPIKeyRevoked() {
+	a := assert.New(suite.T())
+	_, key, err := service.RotateAPIKey(ctx, int32(8))
+	a.NotNil(err)
+	a.Equal("", key)
+}
+
+func (suite *ServiceMethodsSuite) TestRevokeAPIKey() {
+	a := assert.New(suite.T())
+	apiKey, err := service.RevokeAPIKey(ctx, int32(7))
+	a.Nil(err)
+	a.Equal(int32(7), apiKey.Id.Int32)
+	_, err = service.RevokeAPIKey(ctx, int32(0))
+	a.NotNil(err)
+}
+
+//test the logging middleware keeps sensitive values out of the logs
+func TestLoggingMiddlewareRedactsSensitive(t *testing.T) {
+	a := assert.New(t)
+	mq := new(mockQuerier)
+	mq.On("FindSensitiveKeys").Return([]string{"AS2 Password"}, nil)
+	mq.On("FindPartnerDataByID", int32(1), "").Return(int32(1), "KOH", nil)
+	mq.On("FindPartnerStatus", int32(1)).Return("active", nil)
+	mq.On("FindPartnerRevision", int32(1)).Return(int32(3), nil)
+	mq.On("FindAllAttributesForPartner", int32(1)).Return(map[string]string{"AS2 Password": "hunter2", "Currency": "USD"}, nil)
+	mq.On("FindPartnerDataFromKeyValue", "AS2 Password", "hunter3").Return(int32(0), "", errors.New("no partner"))
+	var buf bytes.Buffer
+	svc := New(log.NewJSONLogger(&buf), mq, secrets.NewSensitiveKeys(mq, time.Minute))
+
+	_, _, attributes, _, err := svc.GetDataById(context.Background(), 1, "", "", false)
+	a.Nil(err)
+	a.Equal("hunter2", attributes["AS2 Password"])
+	_, _, _, _, err = svc.GetPartnerDataByKeyValue(context.Background(), "AS2 Password", "hunter3", "", false)
+	a.NotNil(err)
+
+	a.Contains(buf.String(), "USD")
+	a.Contains(buf.String(), secrets.Redacted)
+	a.NotContains(buf.String(), "hunter2")
+	a.NotContains(buf.String(), "hunter3")
+}
@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Propagator carries trace context between the http gateway and the gRPC server, in traceparent and tracestate as
+// the W3C Trace Context spec has them.
+var Propagator propagation.TextMapPropagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// Tracer returns the tracer for a package of the service. Its spans are dropped until Start is called.
+func Tracer(pkg string) trace.Tracer {
+	return otel.Tracer("jaxf-github.fanatics.corp/apparel/partner-service/pkg/" + pkg)
+}
+
+// Start sends spans to the file at path, or to stdout if path is -, as JSON. The returned func sends the spans that
+// are left and closes the file.
+func Start(path string) (func(context.Context) error, error) {
+	var out io.Writer = os.Stdout
+	var file *os.File
+	if path != "-" {
+		var err error
+		if file, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644); err != nil {
+			return nil, errors.Wrap(err, "failed to open trace file")
+		}
+		out = file
+	}
+	provider, err := NewProvider(out)
+	if err != nil {
+		return nil, err
+	}
+	otel.SetTracerProvider(provider)
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if file != nil {
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}, nil
+}
+
+// NewProvider returns a provider that writes the spans of the service to out as JSON, in batches.
+func NewProvider(out io.Writer) (*sdktrace.TracerProvider, error) {
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(out))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create trace exporter")
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", "partner-service"))),
+	), nil
+}
+
+// End ends a span, marking it as failed if err is not nil. The message is left out, since errors can repeat the
+// values of sensitive keys; use Fail and span.End instead to give a redacted one.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.SetStatus(codes.Error, "")
+	}
+	span.End()
+}
+
+// Fail marks a span as failed with a message that is safe to keep.
+func Fail(span trace.Span, message string) {
+	span.SetStatus(codes.Error, message)
+}
@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStart(t *testing.T) {
+	a := assert.New(t)
+	dir, err := ioutil.TempDir("", "traces")
+	a.Nil(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "traces.json")
+
+	stop, err := Start(path)
+	a.Nil(err)
+	_, span := Tracer("tracing").Start(context.Background(), "service.GetDataById")
+	span.End()
+	a.Nil(stop(context.Background()))
+
+	written, err := ioutil.ReadFile(path)
+	a.Nil(err)
+	a.True(strings.Contains(string(written), `"Name":"service.GetDataById"`))
+}
+
+func TestStartBadPath(t *testing.T) {
+	_, err := Start(filepath.Join("no", "such", "dir", "traces.json"))
+
+	assert.NotNil(t, err)
+}
+
+func TestEnd(t *testing.T) {
+	a := assert.New(t)
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("tracing")
+
+	_, span := tracer.Start(context.Background(), "ok")
+	End(span, nil)
+	_, span = tracer.Start(context.Background(), "failed")
+	End(span, errors.New("could not find Id or Code from key: AS2 Password and value: hunter2"))
+
+	spans := recorder.Ended()
+	a.Len(spans, 2)
+	a.Equal(codes.Unset, spans[0].Status().Code)
+	a.Equal(codes.Error, spans[1].Status().Code)
+	a.Equal("", spans[1].Status().Description)
+}
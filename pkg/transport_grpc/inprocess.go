@@ -0,0 +1,91 @@
+package transport_grpc
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// inProcessNetwork is the network of the address of calls that come through an InProcessListener.
+const inProcessNetwork = "in-process"
+
+// inProcessAddr is the address of both ends of a connection through an InProcessListener.
+type inProcessAddr struct{}
+
+func (inProcessAddr) Network() string { return inProcessNetwork }
+func (inProcessAddr) String() string  { return inProcessNetwork }
+
+// IsInProcess says whether addr is the address of a call that came through an InProcessListener, which only the
+// http gateway in this process can make.
+func IsInProcess(addr net.Addr) bool {
+	return addr != nil && addr.Network() == inProcessNetwork
+}
+
+// errListenerClosed is returned by Accept and Dial once an InProcessListener is closed.
+var errListenerClosed = errors.New("in-process listener closed")
+
+// InProcessListener is a listener for the gRPC server whose connections are made by Dial in the same process, so that
+// the http gateway can call the server without going over the network or through tls.
+type InProcessListener struct {
+	conns  chan net.Conn
+	closed chan struct{}
+	once   sync.Once
+}
+
+// NewInProcessListener returns a listener to serve the gRPC server on and Dial the gateway's connection with.
+func NewInProcessListener() *InProcessListener {
+	return &InProcessListener{conns: make(chan net.Conn), closed: make(chan struct{})}
+}
+
+// Accept waits for Dial and returns the server's end of the connection.
+func (l *InProcessListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, errListenerClosed
+	}
+}
+
+// Close stops Accept and Dial. Connections already made are left open.
+func (l *InProcessListener) Close() error {
+	l.once.Do(func() { close(l.closed) })
+	return nil
+}
+
+// Addr returns the in-process address.
+func (l *InProcessListener) Addr() net.Addr {
+	return inProcessAddr{}
+}
+
+// Dial connects to the server, for grpc.WithDialer. The address is ignored.
+func (l *InProcessListener) Dial(_ string, timeout time.Duration) (net.Conn, error) {
+	client, server := net.Pipe()
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	err := errListenerClosed
+	select {
+	case l.conns <- inProcessConn{server}:
+		return inProcessConn{client}, nil
+	case <-l.closed:
+	case <-expired:
+		err = errors.New("timed out waiting for the gRPC server to accept")
+	}
+	client.Close()
+	server.Close()
+	return nil, err
+}
+
+// inProcessConn is one end of a pipe, addressed as in-process.
+type inProcessConn struct {
+	net.Conn
+}
+
+func (inProcessConn) LocalAddr() net.Addr  { return inProcessAddr{} }
+func (inProcessConn) RemoteAddr() net.Addr { return inProcessAddr{} }
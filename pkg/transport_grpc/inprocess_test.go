@@ -0,0 +1,37 @@
+package transport_grpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that both ends of a dialed connection are addressed as in process
+func TestInProcessListener(t *testing.T) {
+	a := assert.New(t)
+	l := NewInProcessListener()
+	accepted := make(chan error)
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			a.True(IsInProcess(conn.RemoteAddr()))
+			conn.Write([]byte("ok"))
+		}
+		accepted <- err
+	}()
+
+	conn, err := l.Dial("", time.Second)
+	a.Nil(err)
+	b := make([]byte, 2)
+	conn.Read(b)
+	a.Equal("ok", string(b))
+	a.Nil(<-accepted)
+	a.True(IsInProcess(conn.LocalAddr()))
+
+	l.Close()
+	_, err = l.Accept()
+	a.NotNil(err)
+	_, err = l.Dial("", time.Second)
+	a.NotNil(err)
+}
@@ -0,0 +1,74 @@
+package transport_grpc
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/tracing"
+)
+
+var tracer = tracing.Tracer("transport_grpc")
+
+// TracingUnaryInterceptor records a span for each call, as part of the trace whose context was sent in the call's
+// metadata. The http gateway sends the context of the http request's span.
+func TracingUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	ctx, span := startSpan(ctx, info.FullMethod)
+	defer func() { endSpan(span, err) }()
+	return handler(ctx, req)
+}
+
+// TracingStreamInterceptor is TracingUnaryInterceptor for streaming calls.
+func TracingStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	ctx, span := startSpan(stream.Context(), info.FullMethod)
+	defer func() { endSpan(span, err) }()
+	return handler(srv, tracedStream{ServerStream: stream, ctx: ctx})
+}
+
+func startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = tracing.Propagator.Extract(ctx, metadataCarrier(md))
+	}
+	return tracer.Start(ctx, "grpc"+method, trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("rpc.system", "grpc"), attribute.String("rpc.method", method)))
+}
+
+func endSpan(span trace.Span, err error) {
+	span.SetAttributes(attribute.String("rpc.grpc.status_code", grpc.Code(err).String()))
+	tracing.End(span, err)
+}
+
+// tracedStream is a stream whose context carries its span.
+type tracedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s tracedStream) Context() context.Context {
+	return s.ctx
+}
+
+// metadataCarrier lets trace context be read from and written to metadata.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := c[strings.ToLower(key)]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	c[strings.ToLower(key)] = []string{value}
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -3,19 +3,42 @@ package transport_grpc
 import (
 	"context"
 	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+	kitjwt "github.com/go-kit/kit/auth/jwt"
 	"github.com/go-kit/kit/log"
 	grpctransport "github.com/go-kit/kit/transport/grpc"
 	"github.com/pkg/errors"
 	// oldcontext is necessary because transport_grpc still uses the experimental context rather than stdlib context
 	oldcontext "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/endpoints"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/identity"
 	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/pb"
 )
 
-func MakeGRPCServer(endpoints endpoints.Endpoints, logger log.Logger) pb.PartnerServiceServer {
+// MakeGRPCServer serves the endpoints over gRPC. The options are added to the ones every handler has, such as
+// CallerFromPeer when clients authenticate with certificates.
+func MakeGRPCServer(endpoints endpoints.Endpoints, logger log.Logger, extra ...grpctransport.ServerOption) pb.PartnerServiceServer {
 	options := []grpctransport.ServerOption{
 		grpctransport.ServerErrorLogger(logger),
+		grpctransport.ServerBefore(kitjwt.GRPCToContext()),
+		grpctransport.ServerBefore(RevisionFromMetadata),
+		grpctransport.ServerBefore(IdempotencyKeyFromMetadata),
+		grpctransport.ServerBefore(RequestIDFromMetadata),
+		grpctransport.ServerBefore(APIKeyFromMetadata),
+		grpctransport.ServerBefore(TransportFromMetadata),
+		grpctransport.ServerBefore(AddrFromPeer),
 	}
+	options = append(options, extra...)
 
 	return &grpcServer{
 		keyValue: grpctransport.NewServer(
@@ -30,12 +53,159 @@ func MakeGRPCServer(endpoints endpoints.Endpoints, logger log.Logger) pb.Partner
 			EncodeGRPCResponse,
 			options...,
 		),
+		exportPartners: grpctransport.NewServer(
+			endpoints.ExportPartnersEndpoint,
+			DecodeGRPCExportRequest,
+			EncodeGRPCExportResponse,
+			options...,
+		),
+		comparePartners: grpctransport.NewServer(
+			endpoints.ComparePartnersEndpoint,
+			DecodeGRPCCompareRequest,
+			EncodeGRPCCompareResponse,
+			options...,
+		),
+		clonePartner: grpctransport.NewServer(
+			endpoints.ClonePartnerEndpoint,
+			DecodeGRPCCloneRequest,
+			EncodeGRPCResponse,
+			options...,
+		),
+		setPartnerStatus: grpctransport.NewServer(
+			endpoints.SetPartnerStatusEndpoint,
+			DecodeGRPCStatusRequest,
+			EncodeGRPCStatusResponse,
+			options...,
+		),
+		restorePartner: grpctransport.NewServer(
+			endpoints.RestorePartnerEndpoint,
+			DecodeGRPCRestoreRequest,
+			EncodeGRPCRestoreResponse,
+			options...,
+		),
+		restoreKey: grpctransport.NewServer(
+			endpoints.RestoreKeyEndpoint,
+			DecodeGRPCRestoreRequest,
+			EncodeGRPCRestoreResponse,
+			options...,
+		),
+		restoreGroup: grpctransport.NewServer(
+			endpoints.RestoreGroupEndpoint,
+			DecodeGRPCRestoreRequest,
+			EncodeGRPCRestoreResponse,
+			options...,
+		),
+		restorePartnerAttribute: grpctransport.NewServer(
+			endpoints.RestorePartnerAttributeEndpoint,
+			DecodeGRPCRestoreRequest,
+			EncodeGRPCRestoreResponse,
+			options...,
+		),
+		openChangeSet: grpctransport.NewServer(
+			endpoints.OpenChangeSetEndpoint,
+			DecodeGRPCOpenChangeSetRequest,
+			EncodeGRPCChangeSetResponse,
+			options...,
+		),
+		stageChange: grpctransport.NewServer(
+			endpoints.StageChangeEndpoint,
+			DecodeGRPCStageChangeRequest,
+			EncodeGRPCChangeSetResponse,
+			options...,
+		),
+		previewChangeSet: grpctransport.NewServer(
+			endpoints.PreviewChangeSetEndpoint,
+			DecodeGRPCPreviewRequest,
+			EncodeGRPCResponse,
+			options...,
+		),
+		publishChangeSet: grpctransport.NewServer(
+			endpoints.PublishChangeSetEndpoint,
+			DecodeGRPCChangeSetRequest,
+			EncodeGRPCChangeSetResponse,
+			options...,
+		),
+		discardChangeSet: grpctransport.NewServer(
+			endpoints.DiscardChangeSetEndpoint,
+			DecodeGRPCChangeSetRequest,
+			EncodeGRPCChangeSetResponse,
+			options...,
+		),
+		listApprovalRequests: grpctransport.NewServer(
+			endpoints.ListApprovalRequestsEndpoint,
+			DecodeGRPCListApprovalRequestsRequest,
+			EncodeGRPCApprovalRequestsResponse,
+			options...,
+		),
+		approveRequest: grpctransport.NewServer(
+			endpoints.ApproveRequestEndpoint,
+			DecodeGRPCApprovalDecisionRequest,
+			EncodeGRPCApprovalResponse,
+			options...,
+		),
+		rejectRequest: grpctransport.NewServer(
+			endpoints.RejectRequestEndpoint,
+			DecodeGRPCApprovalDecisionRequest,
+			EncodeGRPCApprovalResponse,
+			options...,
+		),
+		listAccessRecords: grpctransport.NewServer(
+			endpoints.ListAccessRecordsEndpoint,
+			DecodeGRPCAccessLogRequest,
+			EncodeGRPCAccessLogResponse,
+			options...,
+		),
+		issueAPIKey: grpctransport.NewServer(
+			endpoints.IssueAPIKeyEndpoint,
+			DecodeGRPCIssueAPIKeyRequest,
+			EncodeGRPCAPIKeyResponse,
+			options...,
+		),
+		listAPIKeys: grpctransport.NewServer(
+			endpoints.ListAPIKeysEndpoint,
+			DecodeGRPCListAPIKeysRequest,
+			EncodeGRPCAPIKeysResponse,
+			options...,
+		),
+		rotateAPIKey: grpctransport.NewServer(
+			endpoints.RotateAPIKeyEndpoint,
+			DecodeGRPCAPIKeyRequest,
+			EncodeGRPCAPIKeyResponse,
+			options...,
+		),
+		revokeAPIKey: grpctransport.NewServer(
+			endpoints.RevokeAPIKeyEndpoint,
+			DecodeGRPCAPIKeyRequest,
+			EncodeGRPCAPIKeyResponse,
+			options...,
+		),
 	}
 }
 
 type grpcServer struct {
-	keyValue grpctransport.Handler
-	dataById grpctransport.Handler
+	keyValue                grpctransport.Handler
+	dataById                grpctransport.Handler
+	exportPartners          grpctransport.Handler
+	comparePartners         grpctransport.Handler
+	clonePartner            grpctransport.Handler
+	setPartnerStatus        grpctransport.Handler
+	restorePartner          grpctransport.Handler
+	restoreKey              grpctransport.Handler
+	restoreGroup            grpctransport.Handler
+	restorePartnerAttribute grpctransport.Handler
+	openChangeSet           grpctransport.Handler
+	stageChange             grpctransport.Handler
+	previewChangeSet        grpctransport.Handler
+	publishChangeSet        grpctransport.Handler
+	discardChangeSet        grpctransport.Handler
+	listApprovalRequests    grpctransport.Handler
+	approveRequest          grpctransport.Handler
+	rejectRequest           grpctransport.Handler
+	listAccessRecords       grpctransport.Handler
+	issueAPIKey             grpctransport.Handler
+	listAPIKeys             grpctransport.Handler
+	rotateAPIKey            grpctransport.Handler
+	revokeAPIKey            grpctransport.Handler
 }
 
 func (s *grpcServer) GetPartnerDataByKeyValue(ctx oldcontext.Context, req *pb.KeyValueRequest) (*pb.PartnerDataReply, error) {
@@ -44,8 +214,7 @@ func (s *grpcServer) GetPartnerDataByKeyValue(ctx oldcontext.Context, req *pb.Ke
 
 	if err != nil {
 
-		err = errors.Wrap(err, fmt.Sprintf("Error serving transport_grpc in KeyValue"))
-		return nil, err
+		return nil, grpcError(ctx, err, "KeyValue")
 	}
 	return rep.(*pb.PartnerDataReply), nil
 }
@@ -53,26 +222,479 @@ func (s *grpcServer) GetPartnerDataByKeyValue(ctx oldcontext.Context, req *pb.Ke
 func (s *grpcServer) GetDataById(ctx oldcontext.Context, req *pb.IdRequest) (*pb.PartnerDataReply, error) {
 	_, rep, err := s.dataById.ServeGRPC(ctx, req)
 	if err != nil {
-		err = errors.Wrap(err, "error serving transport_grpc in DataById")
-		return nil, err
+		return nil, grpcError(ctx, err, "DataById")
+	}
+	return rep.(*pb.PartnerDataReply), nil
+}
+
+// ExportPartners runs the export endpoint once and then streams the partners back one message at a time.
+func (s *grpcServer) ExportPartners(req *pb.ExportRequest, stream pb.PartnerService_ExportPartnersServer) error {
+	_, rep, err := s.exportPartners.ServeGRPC(stream.Context(), req)
+	if err != nil {
+		return grpcError(stream.Context(), err, "ExportPartners")
+	}
+	for _, partner := range rep.([]*pb.Partner) {
+		if err := stream.Send(partner); err != nil {
+			return errors.Wrap(err, "error sending partner in ExportPartners")
+		}
+	}
+	return nil
+}
+
+func (s *grpcServer) ComparePartners(ctx oldcontext.Context, req *pb.CompareRequest) (*pb.CompareReply, error) {
+	_, rep, err := s.comparePartners.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(ctx, err, "ComparePartners")
+	}
+	return rep.(*pb.CompareReply), nil
+}
+
+func (s *grpcServer) ClonePartner(ctx oldcontext.Context, req *pb.CloneRequest) (*pb.PartnerDataReply, error) {
+	_, rep, err := s.clonePartner.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(ctx, err, "ClonePartner")
+	}
+	return rep.(*pb.PartnerDataReply), nil
+}
+
+func (s *grpcServer) SetPartnerStatus(ctx oldcontext.Context, req *pb.StatusRequest) (*pb.StatusReply, error) {
+	_, rep, err := s.setPartnerStatus.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(ctx, err, "SetPartnerStatus")
+	}
+	return rep.(*pb.StatusReply), nil
+}
+
+func (s *grpcServer) RestorePartner(ctx oldcontext.Context, req *pb.RestoreRequest) (*pb.RestoreReply, error) {
+	_, rep, err := s.restorePartner.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(ctx, err, "RestorePartner")
+	}
+	return rep.(*pb.RestoreReply), nil
+}
+
+func (s *grpcServer) RestoreKey(ctx oldcontext.Context, req *pb.RestoreRequest) (*pb.RestoreReply, error) {
+	_, rep, err := s.restoreKey.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(ctx, err, "RestoreKey")
+	}
+	return rep.(*pb.RestoreReply), nil
+}
+
+func (s *grpcServer) RestoreGroup(ctx oldcontext.Context, req *pb.RestoreRequest) (*pb.RestoreReply, error) {
+	_, rep, err := s.restoreGroup.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(ctx, err, "RestoreGroup")
+	}
+	return rep.(*pb.RestoreReply), nil
+}
+
+func (s *grpcServer) RestorePartnerAttribute(ctx oldcontext.Context, req *pb.RestoreRequest) (*pb.RestoreReply, error) {
+	_, rep, err := s.restorePartnerAttribute.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(ctx, err, "RestorePartnerAttribute")
+	}
+	return rep.(*pb.RestoreReply), nil
+}
+
+func (s *grpcServer) OpenChangeSet(ctx oldcontext.Context, req *pb.OpenChangeSetRequest) (*pb.ChangeSetReply, error) {
+	_, rep, err := s.openChangeSet.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(ctx, err, "OpenChangeSet")
+	}
+	return rep.(*pb.ChangeSetReply), nil
+}
+
+func (s *grpcServer) StageChange(ctx oldcontext.Context, req *pb.StageChangeRequest) (*pb.ChangeSetReply, error) {
+	_, rep, err := s.stageChange.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(ctx, err, "StageChange")
+	}
+	return rep.(*pb.ChangeSetReply), nil
+}
+
+func (s *grpcServer) PreviewChangeSet(ctx oldcontext.Context, req *pb.PreviewRequest) (*pb.PartnerDataReply, error) {
+	_, rep, err := s.previewChangeSet.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(ctx, err, "PreviewChangeSet")
 	}
 	return rep.(*pb.PartnerDataReply), nil
 }
 
+func (s *grpcServer) PublishChangeSet(ctx oldcontext.Context, req *pb.ChangeSetRequest) (*pb.ChangeSetReply, error) {
+	_, rep, err := s.publishChangeSet.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(ctx, err, "PublishChangeSet")
+	}
+	return rep.(*pb.ChangeSetReply), nil
+}
+
+func (s *grpcServer) DiscardChangeSet(ctx oldcontext.Context, req *pb.ChangeSetRequest) (*pb.ChangeSetReply, error) {
+	_, rep, err := s.discardChangeSet.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(ctx, err, "DiscardChangeSet")
+	}
+	return rep.(*pb.ChangeSetReply), nil
+}
+
+func (s *grpcServer) ListApprovalRequests(ctx oldcontext.Context, req *pb.ListApprovalRequestsRequest) (*pb.ApprovalRequestsReply, error) {
+	_, rep, err := s.listApprovalRequests.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(ctx, err, "ListApprovalRequests")
+	}
+	return rep.(*pb.ApprovalRequestsReply), nil
+}
+
+func (s *grpcServer) ApproveRequest(ctx oldcontext.Context, req *pb.ApprovalDecisionRequest) (*pb.ApprovalReply, error) {
+	_, rep, err := s.approveRequest.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(ctx, err, "ApproveRequest")
+	}
+	return rep.(*pb.ApprovalReply), nil
+}
+
+func (s *grpcServer) RejectRequest(ctx oldcontext.Context, req *pb.ApprovalDecisionRequest) (*pb.ApprovalReply, error) {
+	_, rep, err := s.rejectRequest.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(ctx, err, "RejectRequest")
+	}
+	return rep.(*pb.ApprovalReply), nil
+}
+
+func (s *grpcServer) ListAccessRecords(ctx oldcontext.Context, req *pb.AccessLogRequest) (*pb.AccessLogReply, error) {
+	_, rep, err := s.listAccessRecords.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(ctx, err, "ListAccessRecords")
+	}
+	return rep.(*pb.AccessLogReply), nil
+}
+
+func (s *grpcServer) IssueApiKey(ctx oldcontext.Context, req *pb.IssueApiKeyRequest) (*pb.ApiKeyReply, error) {
+	_, rep, err := s.issueAPIKey.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(ctx, err, "IssueApiKey")
+	}
+	return rep.(*pb.ApiKeyReply), nil
+}
+
+func (s *grpcServer) ListApiKeys(ctx oldcontext.Context, req *pb.ListApiKeysRequest) (*pb.ApiKeysReply, error) {
+	_, rep, err := s.listAPIKeys.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(ctx, err, "ListApiKeys")
+	}
+	return rep.(*pb.ApiKeysReply), nil
+}
+
+func (s *grpcServer) RotateApiKey(ctx oldcontext.Context, req *pb.ApiKeyRequest) (*pb.ApiKeyReply, error) {
+	_, rep, err := s.rotateAPIKey.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(ctx, err, "RotateApiKey")
+	}
+	return rep.(*pb.ApiKeyReply), nil
+}
+
+func (s *grpcServer) RevokeApiKey(ctx oldcontext.Context, req *pb.ApiKeyRequest) (*pb.ApiKeyReply, error) {
+	_, rep, err := s.revokeAPIKey.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(ctx, err, "RevokeApiKey")
+	}
+	return rep.(*pb.ApiKeyReply), nil
+}
+
 func DecodeGRPCKeyValueRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
 	req := grpcReq.(*pb.KeyValueRequest)
 
-	return endpoints.KeyValueRequest{Key: req.Key, Value: req.Value, Group: req.Group}, nil
+	return endpoints.KeyValueRequest{Key: req.Key, Value: req.Value, Group: req.Group, IncludeInactive: req.IncludeInactive}, nil
 }
 
 func DecodeGRPCDataByIdRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
 	req := grpcReq.(*pb.IdRequest)
-	return endpoints.IdRequest{PartnerId: req.PartnerId, PartnerCode: req.PartnerCode, Group: req.Group}, nil
+	return endpoints.IdRequest{PartnerId: req.PartnerId, PartnerCode: req.PartnerCode, Group: req.Group, IncludeInactive: req.IncludeInactive}, nil
+}
+
+func DecodeGRPCExportRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.ExportRequest)
+	return endpoints.ExportRequest{Group: req.Group, PartnerCodes: req.PartnerCodes}, nil
+}
+
+func DecodeGRPCCompareRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.CompareRequest)
+	return endpoints.CompareRequest{PartnerCodes: req.PartnerCodes, Group: req.Group}, nil
+}
+
+func DecodeGRPCCloneRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.CloneRequest)
+	return endpoints.CloneRequest{Name: req.Name, Code: req.Code, SourceCode: req.SourceCode, Template: req.Template, Groups: req.Groups, Overrides: req.Overrides}, nil
+}
+
+func DecodeGRPCStatusRequest(ctx context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.StatusRequest)
+	return endpoints.StatusRequest{PartnerCode: req.PartnerCode, Status: req.Status, ExpectedRevision: expectedRevision(ctx, req.ExpectedRevision)}, nil
+}
+
+// DecodeGRPCRestoreRequest is shared by the Restore methods, each of which reads only the fields it needs.
+func DecodeGRPCRestoreRequest(ctx context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.RestoreRequest)
+	return endpoints.RestoreRequest{PartnerCode: req.PartnerCode, Key: req.Key, Group: req.Group, ExpectedRevision: expectedRevision(ctx, req.ExpectedRevision)}, nil
+}
+
+func DecodeGRPCOpenChangeSetRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.OpenChangeSetRequest)
+	return endpoints.OpenChangeSetRequest{Name: req.Name}, nil
+}
+
+func DecodeGRPCStageChangeRequest(ctx context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.StageChangeRequest)
+	return endpoints.StageChangeRequest{ChangeSetId: req.ChangeSetId, PartnerCode: req.PartnerCode, PartnerName: req.PartnerName, Attributes: req.Attributes, ExpectedRevision: expectedRevision(ctx, req.ExpectedRevision)}, nil
+}
+
+func DecodeGRPCPreviewRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.PreviewRequest)
+	return endpoints.PreviewRequest{ChangeSetId: req.ChangeSetId, PartnerCode: req.PartnerCode}, nil
+}
+
+func DecodeGRPCChangeSetRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.ChangeSetRequest)
+	return endpoints.ChangeSetRequest{ChangeSetId: req.ChangeSetId}, nil
+}
+
+func DecodeGRPCListApprovalRequestsRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.ListApprovalRequestsRequest)
+	return endpoints.ListApprovalRequestsRequest{Status: req.Status}, nil
+}
+
+func DecodeGRPCApprovalDecisionRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.ApprovalDecisionRequest)
+	return endpoints.ApprovalDecisionRequest{RequestId: req.RequestId, Reason: req.Reason}, nil
+}
+
+func DecodeGRPCAccessLogRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.AccessLogRequest)
+	return endpoints.AccessLogRequest{PartnerCode: req.PartnerCode, From: req.From, To: req.To}, nil
+}
+
+func DecodeGRPCIssueAPIKeyRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.IssueApiKeyRequest)
+	return endpoints.IssueAPIKeyRequest{Owner: req.Owner, Scope: req.Scope, ExpiresAt: req.ExpiresAt}, nil
+}
+
+func DecodeGRPCListAPIKeysRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.ListApiKeysRequest)
+	return endpoints.ListAPIKeysRequest{Owner: req.Owner}, nil
+}
+
+func DecodeGRPCAPIKeyRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.ApiKeyRequest)
+	return endpoints.APIKeyRequest{Id: req.Id}, nil
 }
 
 func EncodeGRPCResponse(_ context.Context, response interface{}) (interface{}, error) {
 	resp := response.(endpoints.PartnerDataReply)
-	return &pb.PartnerDataReply{PartnerId: resp.PartnerId, PartnerCode: resp.PartnerCode, Attributes: resp.Attributes, Error: resp.Error}, nil
+	return &pb.PartnerDataReply{PartnerId: resp.PartnerId, PartnerCode: resp.PartnerCode, Attributes: resp.Attributes, Revision: resp.Revision, Error: resp.Error}, nil
+}
+
+// EncodeGRPCExportResponse returns the error in the reply as a real error, since a stream of partners has nowhere else to carry it.
+func EncodeGRPCExportResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(endpoints.ExportReply)
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	partners := make([]*pb.Partner, 0, len(resp.Partners))
+	for _, p := range resp.Partners {
+		partners = append(partners, p.Gen(p.Attributes))
+	}
+	return partners, nil
+}
+
+func EncodeGRPCCompareResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(endpoints.CompareReply)
+	keys := make([]*pb.KeyComparison, 0, len(resp.Keys))
+	for _, k := range resp.Keys {
+		keys = append(keys, &pb.KeyComparison{Key: k.Key, Status: pb.KeyStatus(pb.KeyStatus_value[k.Status]), Values: k.Values})
+	}
+	return &pb.CompareReply{PartnerCodes: resp.PartnerCodes, Keys: keys, Error: resp.Error}, nil
+}
+
+// EncodeGRPCStatusResponse leaves statusChangedAt empty when the status was not changed.
+func EncodeGRPCStatusResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(endpoints.StatusReply)
+	rep := &pb.StatusReply{PartnerId: resp.PartnerId, PartnerCode: resp.PartnerCode, Status: resp.Status, Error: resp.Error}
+	if !resp.StatusChangedAt.IsZero() {
+		rep.StatusChangedAt = resp.StatusChangedAt.Format(time.RFC3339)
+	}
+	return rep, nil
+}
+
+func EncodeGRPCRestoreResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(endpoints.RestoreReply)
+	return &pb.RestoreReply{Restored: resp.Restored, Error: resp.Error}, nil
+}
+
+func EncodeGRPCChangeSetResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(endpoints.ChangeSetReply)
+	rep := resp.ChangeSet.Gen()
+	rep.Error = resp.Error
+	return rep, nil
+}
+
+func EncodeGRPCApprovalRequestsResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(endpoints.ApprovalRequestsReply)
+	requests := make([]*pb.ApprovalRequest, 0, len(resp.Requests))
+	for _, r := range resp.Requests {
+		requests = append(requests, r.Gen())
+	}
+	return &pb.ApprovalRequestsReply{Requests: requests, Error: resp.Error}, nil
+}
+
+func EncodeGRPCAccessLogResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(endpoints.AccessLogReply)
+	records := make([]*pb.AccessRecord, 0, len(resp.Records))
+	for _, r := range resp.Records {
+		records = append(records, r.Gen())
+	}
+	return &pb.AccessLogReply{Records: records, Error: resp.Error}, nil
+}
+
+// EncodeGRPCAPIKeyResponse leaves the key out of the reply when there is an error.
+func EncodeGRPCAPIKeyResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(endpoints.APIKeyReply)
+	if resp.Error != "" {
+		return &pb.ApiKeyReply{Error: resp.Error}, nil
+	}
+	return &pb.ApiKeyReply{ApiKey: resp.APIKey.Gen(), Key: resp.Key}, nil
+}
+
+func EncodeGRPCAPIKeysResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(endpoints.APIKeysReply)
+	apiKeys := make([]*pb.ApiKey, 0, len(resp.APIKeys))
+	for _, k := range resp.APIKeys {
+		apiKeys = append(apiKeys, k.Gen())
+	}
+	return &pb.ApiKeysReply{ApiKeys: apiKeys, Error: resp.Error}, nil
+}
+
+// EncodeGRPCApprovalResponse leaves the request out of the reply when there is an error.
+func EncodeGRPCApprovalResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(endpoints.ApprovalReply)
+	if resp.Error != "" {
+		return &pb.ApprovalReply{Error: resp.Error}, nil
+	}
+	return &pb.ApprovalReply{Request: resp.Request.Gen()}, nil
+}
+
+// CallerFromPeer puts the subject of a verified client certificate into the context as the caller. Calls from the
+// http gateway, which come in process, are made for the caller in their x-client-subject metadata instead, and for no
+// caller if there is none. A subject that names an API key is not taken.
+func CallerFromPeer(ctx context.Context, md metadata.MD) context.Context {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	var subject string
+	if IsInProcess(p.Addr) {
+		if subjects := md["x-client-subject"]; len(subjects) > 0 {
+			subject = subjects[0]
+		}
+	} else if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.VerifiedChains) > 0 && len(info.State.VerifiedChains[0]) > 0 {
+		subject = identity.CertificateSubject(info.State.VerifiedChains[0][0])
+	}
+	if subject == "" || identity.IsAPIKeyCaller(subject) {
+		return ctx
+	}
+	return identity.NewContext(ctx, subject)
+}
+
+// IdempotencyKeyFromMetadata puts the idempotency-key metadata sent with a call into the context. The http gateway
+// sends it for an Idempotency-Key header.
+func IdempotencyKeyFromMetadata(ctx context.Context, md metadata.MD) context.Context {
+	if keys := md["idempotency-key"]; len(keys) > 0 {
+		return endpoints.NewIdempotencyContext(ctx, keys[0])
+	}
+	return ctx
+}
+
+// APIKeyFromMetadata puts the x-api-key metadata sent with a call into the context, for APIKeyMiddleware.
+func APIKeyFromMetadata(ctx context.Context, md metadata.MD) context.Context {
+	if keys := md["x-api-key"]; len(keys) > 0 {
+		return endpoints.NewAPIKeyContext(ctx, keys[0])
+	}
+	return ctx
+}
+
+// AddrFromPeer puts the IP address a call came from into the context. For calls from the http gateway, which come in
+// process, that is the address the gateway added last to the x-forwarded-for metadata, which is the one it saw the
+// request come from. Other callers could send any address there, so theirs is taken from the connection.
+func AddrFromPeer(ctx context.Context, md metadata.MD) context.Context {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ctx
+	}
+	if forwarded := md["x-forwarded-for"]; len(forwarded) > 0 && IsInProcess(p.Addr) {
+		addrs := strings.Split(forwarded[len(forwarded)-1], ",")
+		return identity.NewAddrContext(ctx, strings.TrimSpace(addrs[len(addrs)-1]))
+	}
+	addr := p.Addr.String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return identity.NewAddrContext(ctx, addr)
+}
+
+// TransportFromMetadata puts the transport a call came in on into the context: http for calls the gateway sends with
+// x-transport metadata, and grpc for the rest. The metadata is only taken from the gateway, which calls in process.
+func TransportFromMetadata(ctx context.Context, md metadata.MD) context.Context {
+	p, ok := peer.FromContext(ctx)
+	if transports := md["x-transport"]; ok && IsInProcess(p.Addr) && len(transports) > 0 && transports[0] == "http" {
+		return identity.NewTransportContext(ctx, "http")
+	}
+	return identity.NewTransportContext(ctx, "grpc")
+}
+
+// RequestIDFromMetadata puts the x-request-id metadata sent with a call into the context, or a new ID if there is none.
+func RequestIDFromMetadata(ctx context.Context, md metadata.MD) context.Context {
+	if ids := md["x-request-id"]; len(ids) > 0 && ids[0] != "" {
+		return identity.NewRequestIDContext(ctx, ids[0])
+	}
+	return identity.NewRequestIDContext(ctx, identity.NewRequestID())
+}
+
+// expectedRevisionKey is the context key for the revision sent as metadata.
+type expectedRevisionKey struct{}
+
+// RevisionFromMetadata puts the expected-revision metadata sent with a call into the context, for writes whose request
+// does not give an expectedRevision. The http gateway sends it for an If-Match header.
+func RevisionFromMetadata(ctx context.Context, md metadata.MD) context.Context {
+	if revisions := md["expected-revision"]; len(revisions) > 0 {
+		if revision, err := strconv.ParseInt(revisions[0], 10, 32); err == nil {
+			return context.WithValue(ctx, expectedRevisionKey{}, int32(revision))
+		}
+	}
+	return ctx
+}
+
+// expectedRevision returns the revision given in the request, or else the one sent as metadata.
+func expectedRevision(ctx context.Context, revision int32) int32 {
+	if revision != 0 {
+		return revision
+	}
+	fromMetadata, _ := ctx.Value(expectedRevisionKey{}).(int32)
+	return fromMetadata
+}
+
+// grpcError wraps an error from serving a call, except for errors the caller can act on which become a status with
+// the code from endpoints.StatusCode. The http gateway answers Aborted with 409 Conflict, InvalidArgument with
+// 400 Bad Request, Unauthenticated with 401 Unauthorized, PermissionDenied with 403 Forbidden and Unavailable with
+// 503 Service Unavailable.
+func grpcError(ctx context.Context, err error, method string) error {
+	if retryAfter, ok := endpoints.RetryAfter(err); ok {
+		//the gateway sends the header on as Grpc-Metadata-Retry-After, which becomes Retry-After
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(seconds)))
+	}
+	if code := endpoints.StatusCode(err); code != codes.Unknown {
+		return status.Error(code, err.Error())
+	}
+	return errors.Wrap(err, fmt.Sprintf("error serving transport_grpc in %s", method))
 }
 
 // This helper function is required to translate Go error types to a string.
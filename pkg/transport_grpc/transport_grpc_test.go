@@ -1,13 +1,30 @@
 package transport_grpc
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
 	"testing"
+	"time"
 
+	"github.com/jackc/pgx"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/db/models"
 	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/endpoints"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/identity"
 	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/pb"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/service"
 )
 
 // Test err2str
@@ -31,9 +48,10 @@ func TestErr2StrNil(t *testing.T) {
 func TestDecodeGRPCKeyValueRequest(t *testing.T) { //Do we need separate tests for group and no group?
 	ctx := context.Background()
 	hr := &pb.KeyValueRequest{
-		Key:   "Currency",
-		Value: "USD",
-		Group: "Money",
+		Key:             "Currency",
+		Value:           "USD",
+		Group:           "Money",
+		IncludeInactive: true,
 	}
 
 	decReq, err := DecodeGRPCKeyValueRequest(ctx, hr)
@@ -41,6 +59,7 @@ func TestDecodeGRPCKeyValueRequest(t *testing.T) { //Do we need separate tests f
 	assert.Equal(t, "Currency", decReq.(endpoints.KeyValueRequest).Key)
 	assert.Equal(t, "USD", decReq.(endpoints.KeyValueRequest).Value)
 	assert.Equal(t, "Money", decReq.(endpoints.KeyValueRequest).Group)
+	assert.Equal(t, true, decReq.(endpoints.KeyValueRequest).IncludeInactive)
 
 	assert.Nil(t, err)
 }
@@ -103,12 +122,14 @@ func TestEncodeGRPCResponses(t *testing.T) {
 		PartnerId:   1,
 		PartnerCode: "KOH",
 		Attributes:  map[string]string{"Currency": "USD", "Type of Payment": "Credit"},
+		Revision:    3,
 		Error:       "",
 	}
 
 	encRep, err := EncodeGRPCResponse(ctx, *hr)
 
 	assert.Equal(t, int32(1), encRep.(*pb.PartnerDataReply).PartnerId)
+	assert.Equal(t, int32(3), encRep.(*pb.PartnerDataReply).Revision)
 	assert.Equal(t, "KOH", encRep.(*pb.PartnerDataReply).PartnerCode)
 	assert.Equal(t, map[string]string{"Currency": "USD", "Type of Payment": "Credit"}, encRep.(*pb.PartnerDataReply).Attributes)
 	assert.Equal(t, "", encRep.(*pb.PartnerDataReply).Error)
@@ -133,3 +154,519 @@ func TestEncodeGRPCResponseErr(t *testing.T) {
 	assert.Equal(t, "test error", encRep.(*pb.PartnerDataReply).Error)
 	assert.Nil(t, err)
 }
+
+// Test export decode and encode functions
+func TestDecodeGRPCExportRequest(t *testing.T) {
+	ctx := context.Background()
+	hr := &pb.ExportRequest{
+		Group:        "Money",
+		PartnerCodes: []string{"KOH", "JCP"},
+	}
+
+	decReq, err := DecodeGRPCExportRequest(ctx, hr)
+
+	assert.Equal(t, "Money", decReq.(endpoints.ExportRequest).Group)
+	assert.Equal(t, []string{"KOH", "JCP"}, decReq.(endpoints.ExportRequest).PartnerCodes)
+	assert.Nil(t, err)
+}
+
+func TestEncodeGRPCExportResponse(t *testing.T) {
+	ctx := context.Background()
+	hr := &endpoints.ExportReply{
+		Partners: []models.Partner{{
+			Id:         pgx.NullInt32{Int32: 1, Valid: true},
+			Name:       pgx.NullString{String: "Kohls", Valid: true},
+			Code:       pgx.NullString{String: "KOH", Valid: true},
+			Attributes: map[string]string{"Currency": "USD"},
+		}},
+	}
+
+	encRep, err := EncodeGRPCExportResponse(ctx, *hr)
+
+	assert.Nil(t, err)
+	assert.Len(t, encRep.([]*pb.Partner), 1)
+	assert.Equal(t, &pb.Partner{Id: 1, Name: "Kohls", Code: "KOH", Attributes: map[string]string{"Currency": "USD"}}, encRep.([]*pb.Partner)[0])
+}
+
+func TestEncodeGRPCExportResponseErr(t *testing.T) {
+	ctx := context.Background()
+	hr := &endpoints.ExportReply{
+		Error: "test error",
+	}
+
+	encRep, err := EncodeGRPCExportResponse(ctx, *hr)
+
+	assert.Nil(t, encRep)
+	assert.Equal(t, "test error", err.Error())
+}
+
+// Test compare decode and encode functions
+func TestDecodeGRPCCompareRequest(t *testing.T) {
+	ctx := context.Background()
+	hr := &pb.CompareRequest{
+		PartnerCodes: []string{"KOH", "JCP"},
+		Group:        "Money",
+	}
+
+	decReq, err := DecodeGRPCCompareRequest(ctx, hr)
+
+	assert.Equal(t, []string{"KOH", "JCP"}, decReq.(endpoints.CompareRequest).PartnerCodes)
+	assert.Equal(t, "Money", decReq.(endpoints.CompareRequest).Group)
+	assert.Nil(t, err)
+}
+
+func TestEncodeGRPCCompareResponse(t *testing.T) {
+	ctx := context.Background()
+	hr := &endpoints.CompareReply{
+		PartnerCodes: []string{"KOH", "JCP"},
+		Keys: []service.KeyComparison{
+			{Key: "Currency", Status: service.KeyEqual, Values: map[string]string{"KOH": "USD", "JCP": "USD"}},
+			{Key: "Qualifier", Status: service.KeyMissing, Values: map[string]string{"JCP": "ZZ"}},
+		},
+	}
+
+	encRep, err := EncodeGRPCCompareResponse(ctx, *hr)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"KOH", "JCP"}, encRep.(*pb.CompareReply).PartnerCodes)
+	assert.Equal(t, []*pb.KeyComparison{
+		{Key: "Currency", Status: pb.KeyStatus_EQUAL, Values: map[string]string{"KOH": "USD", "JCP": "USD"}},
+		{Key: "Qualifier", Status: pb.KeyStatus_MISSING, Values: map[string]string{"JCP": "ZZ"}},
+	}, encRep.(*pb.CompareReply).Keys)
+	assert.Equal(t, "", encRep.(*pb.CompareReply).Error)
+}
+
+// Test clone decode function, the reply is encoded by EncodeGRPCResponse
+func TestDecodeGRPCCloneRequest(t *testing.T) {
+	ctx := context.Background()
+	hr := &pb.CloneRequest{
+		Name:       "Dicks East",
+		Code:       "DIE",
+		SourceCode: "DIC",
+		Groups:     []string{"Money", "EDI"},
+		Overrides:  map[string]string{"ISAID": "DICKSEAST"},
+	}
+
+	decReq, err := DecodeGRPCCloneRequest(ctx, hr)
+
+	assert.Equal(t, endpoints.CloneRequest{
+		Name:       "Dicks East",
+		Code:       "DIE",
+		SourceCode: "DIC",
+		Groups:     []string{"Money", "EDI"},
+		Overrides:  map[string]string{"ISAID": "DICKSEAST"},
+	}, decReq)
+	assert.Nil(t, err)
+}
+
+// Test status decode and encode functions
+func TestDecodeGRPCStatusRequest(t *testing.T) {
+	ctx := context.Background()
+	hr := &pb.StatusRequest{
+		PartnerCode: "KOH",
+		Status:      "suspended",
+	}
+
+	decReq, err := DecodeGRPCStatusRequest(ctx, hr)
+
+	assert.Equal(t, endpoints.StatusRequest{PartnerCode: "KOH", Status: "suspended"}, decReq)
+	assert.Nil(t, err)
+}
+
+func TestDecodeGRPCStatusRequestRevisionFromMetadata(t *testing.T) {
+	ctx := RevisionFromMetadata(context.Background(), metadata.Pairs("expected-revision", "4"))
+	hr := &pb.StatusRequest{
+		PartnerCode: "KOH",
+		Status:      "suspended",
+	}
+
+	decReq, err := DecodeGRPCStatusRequest(ctx, hr)
+
+	assert.Equal(t, endpoints.StatusRequest{PartnerCode: "KOH", Status: "suspended", ExpectedRevision: 4}, decReq)
+	assert.Nil(t, err)
+
+	hr.ExpectedRevision = 2
+	decReq, err = DecodeGRPCStatusRequest(ctx, hr)
+
+	assert.Equal(t, int32(2), decReq.(endpoints.StatusRequest).ExpectedRevision)
+	assert.Nil(t, err)
+}
+
+func TestEncodeGRPCStatusResponse(t *testing.T) {
+	ctx := context.Background()
+	hr := &endpoints.StatusReply{
+		PartnerId:       1,
+		PartnerCode:     "KOH",
+		Status:          "suspended",
+		StatusChangedAt: time.Date(2017, 8, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	encRep, err := EncodeGRPCStatusResponse(ctx, *hr)
+
+	assert.Nil(t, err)
+	assert.Equal(t, &pb.StatusReply{PartnerId: 1, PartnerCode: "KOH", Status: "suspended", StatusChangedAt: "2017-08-01T12:00:00Z"}, encRep)
+}
+
+func TestEncodeGRPCStatusResponseErr(t *testing.T) {
+	ctx := context.Background()
+	hr := &endpoints.StatusReply{
+		PartnerCode: "KOH",
+		Status:      "active",
+		Error:       "test error",
+	}
+
+	encRep, err := EncodeGRPCStatusResponse(ctx, *hr)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "", encRep.(*pb.StatusReply).StatusChangedAt)
+	assert.Equal(t, "test error", encRep.(*pb.StatusReply).Error)
+}
+
+// Test restore decode and encode functions
+func TestDecodeGRPCRestoreRequest(t *testing.T) {
+	ctx := context.Background()
+	hr := &pb.RestoreRequest{
+		PartnerCode: "KOH",
+		Key:         "Qualifier",
+	}
+
+	decReq, err := DecodeGRPCRestoreRequest(ctx, hr)
+
+	assert.Equal(t, endpoints.RestoreRequest{PartnerCode: "KOH", Key: "Qualifier"}, decReq)
+	assert.Nil(t, err)
+}
+
+func TestEncodeGRPCRestoreResponse(t *testing.T) {
+	ctx := context.Background()
+	hr := &endpoints.RestoreReply{
+		Restored: 3,
+	}
+
+	encRep, err := EncodeGRPCRestoreResponse(ctx, *hr)
+
+	assert.Nil(t, err)
+	assert.Equal(t, &pb.RestoreReply{Restored: 3}, encRep)
+}
+
+// Test change set decode and encode functions
+func TestDecodeGRPCStageChangeRequest(t *testing.T) {
+	ctx := context.Background()
+	hr := &pb.StageChangeRequest{
+		ChangeSetId: 3,
+		PartnerCode: "DIC",
+		PartnerName: "Dicks",
+		Attributes:  map[string]string{"Currency": "USD"},
+	}
+
+	decReq, err := DecodeGRPCStageChangeRequest(ctx, hr)
+
+	assert.Equal(t, endpoints.StageChangeRequest{ChangeSetId: 3, PartnerCode: "DIC", PartnerName: "Dicks", Attributes: map[string]string{"Currency": "USD"}}, decReq)
+	assert.Nil(t, err)
+}
+
+func TestEncodeGRPCChangeSetResponse(t *testing.T) {
+	ctx := context.Background()
+	hr := &endpoints.ChangeSetReply{
+		ChangeSet: models.ChangeSet{
+			Id:       pgx.NullInt32{Int32: 3, Valid: true},
+			Name:     pgx.NullString{String: "Dicks", Valid: true},
+			Status:   pgx.NullString{String: models.ChangeSetDraft, Valid: true},
+			Partners: []models.Partner{{Code: pgx.NullString{String: "DIC", Valid: true}, Attributes: map[string]string{"Currency": "USD"}}},
+		},
+	}
+
+	encRep, err := EncodeGRPCChangeSetResponse(ctx, *hr)
+
+	assert.Nil(t, err)
+	assert.Equal(t, &pb.ChangeSetReply{
+		ChangeSetId: 3,
+		Name:        "Dicks",
+		Status:      "draft",
+		Partners:    []*pb.Partner{{Code: "DIC", Attributes: map[string]string{"Currency": "USD"}}},
+	}, encRep)
+}
+
+func TestEncodeGRPCChangeSetResponseErr(t *testing.T) {
+	ctx := context.Background()
+	hr := &endpoints.ChangeSetReply{
+		Error: "test error",
+	}
+
+	encRep, err := EncodeGRPCChangeSetResponse(ctx, *hr)
+
+	assert.Nil(t, err)
+	assert.Equal(t, &pb.ChangeSetReply{Partners: []*pb.Partner{}, Error: "test error"}, encRep)
+}
+
+// Test approval decode and encode functions
+func TestDecodeGRPCApprovalDecisionRequest(t *testing.T) {
+	ctx := context.Background()
+	hr := &pb.ApprovalDecisionRequest{
+		RequestId: 10,
+		Reason:    "wrong currency",
+	}
+
+	decReq, err := DecodeGRPCApprovalDecisionRequest(ctx, hr)
+
+	assert.Equal(t, endpoints.ApprovalDecisionRequest{RequestId: 10, Reason: "wrong currency"}, decReq)
+	assert.Nil(t, err)
+}
+
+func TestEncodeGRPCApprovalResponse(t *testing.T) {
+	ctx := context.Background()
+	hr := &endpoints.ApprovalReply{
+		Request: models.ApprovalRequest{
+			Id:          pgx.NullInt32{Int32: 10, Valid: true},
+			ChangeSetId: pgx.NullInt32{Int32: 3, Valid: true},
+			Groups:      []string{"Money"},
+			Status:      pgx.NullString{String: models.ApprovalApproved, Valid: true},
+			RequestedBy: pgx.NullString{String: "jdoe", Valid: true},
+			RequestedAt: pgx.NullTime{Time: time.Date(2017, 8, 1, 12, 0, 0, 0, time.UTC), Valid: true},
+			DecidedBy:   pgx.NullString{String: "controller", Valid: true},
+		},
+	}
+
+	encRep, err := EncodeGRPCApprovalResponse(ctx, *hr)
+
+	assert.Nil(t, err)
+	assert.Equal(t, &pb.ApprovalReply{Request: &pb.ApprovalRequest{
+		Id:          10,
+		ChangeSetId: 3,
+		Groups:      []string{"Money"},
+		Status:      "approved",
+		RequestedBy: "jdoe",
+		RequestedAt: "2017-08-01T12:00:00Z",
+		DecidedBy:   "controller",
+	}}, encRep)
+}
+
+func TestEncodeGRPCApprovalResponseErr(t *testing.T) {
+	ctx := context.Background()
+	hr := &endpoints.ApprovalReply{
+		Error: "test error",
+	}
+
+	encRep, err := EncodeGRPCApprovalResponse(ctx, *hr)
+
+	assert.Nil(t, err)
+	assert.Equal(t, &pb.ApprovalReply{Error: "test error"}, encRep)
+}
+
+// Test reading the caller from the client certificate
+func peerContext(subject string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: subject}}
+	info := credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}}
+	return peer.NewContext(context.Background(), &peer.Peer{AuthInfo: info})
+}
+
+func TestCallerFromPeer(t *testing.T) {
+	ctx := CallerFromPeer(peerContext("onboarding-pipeline"), metadata.Pairs("x-client-subject", "jdoe"))
+
+	caller, ok := identity.FromContext(ctx)
+
+	assert.True(t, ok)
+	assert.Equal(t, "onboarding-pipeline", caller)
+}
+
+func TestCallerFromPeerGateway(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: inProcessAddr{}})
+	ctx = CallerFromPeer(ctx, metadata.Pairs("x-client-subject", "jdoe"))
+
+	caller, ok := identity.FromContext(ctx)
+
+	assert.True(t, ok)
+	assert.Equal(t, "jdoe", caller)
+}
+
+func TestCallerFromPeerAPIKeySubject(t *testing.T) {
+	_, ok := identity.FromContext(CallerFromPeer(peerContext("apikey:7"), metadata.MD{}))
+	assert.False(t, ok)
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: inProcessAddr{}})
+	_, ok = identity.FromContext(CallerFromPeer(ctx, metadata.Pairs("x-client-subject", "apikey:7")))
+	assert.False(t, ok)
+}
+
+func TestCallerFromPeerGatewayNoClientCert(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: inProcessAddr{}})
+	ctx = CallerFromPeer(ctx, metadata.MD{})
+
+	_, ok := identity.FromContext(ctx)
+
+	assert.False(t, ok)
+}
+
+func TestCallerFromPeerNoCert(t *testing.T) {
+	ctx := CallerFromPeer(context.Background(), metadata.Pairs("x-client-subject", "jdoe"))
+
+	_, ok := identity.FromContext(ctx)
+
+	assert.False(t, ok)
+}
+
+// Test reading the request ID from metadata
+func TestRequestIDFromMetadata(t *testing.T) {
+	ctx := RequestIDFromMetadata(context.Background(), metadata.Pairs("x-request-id", "abc123"))
+
+	assert.Equal(t, "abc123", identity.RequestIDFromContext(ctx))
+}
+
+func TestRequestIDFromMetadataGenerated(t *testing.T) {
+	ctx := RequestIDFromMetadata(context.Background(), metadata.MD{})
+
+	assert.NotEqual(t, "", identity.RequestIDFromContext(ctx))
+}
+
+// Test reading the address a call came from
+func TestAddrFromPeer(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 51234}})
+
+	assert.Equal(t, "10.0.0.7", identity.AddrFromContext(AddrFromPeer(ctx, metadata.MD{})))
+}
+
+func TestTransportFromMetadata(t *testing.T) {
+	gateway := peer.NewContext(context.Background(), &peer.Peer{Addr: inProcessAddr{}})
+	client := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 51234}})
+
+	assert.Equal(t, "http", identity.TransportFromContext(TransportFromMetadata(gateway, metadata.Pairs("x-transport", "http"))))
+	assert.Equal(t, "grpc", identity.TransportFromContext(TransportFromMetadata(gateway, metadata.MD{})))
+	assert.Equal(t, "grpc", identity.TransportFromContext(TransportFromMetadata(client, metadata.Pairs("x-transport", "http"))))
+}
+
+// Test joining the trace the gateway sent the context of
+func TestTracingUnaryInterceptor(t *testing.T) {
+	md := metadata.Pairs("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+	var traceID string
+
+	TracingUnaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/pb.PartnerService/GetDataById"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		traceID = trace.SpanContextFromContext(ctx).TraceID().String()
+		return nil, nil
+	})
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+}
+
+func TestAddrFromPeerGateway(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: inProcessAddr{}})
+
+	ctx = AddrFromPeer(ctx, metadata.Pairs("x-forwarded-for", "192.168.1.1, 10.0.0.7"))
+
+	assert.Equal(t, "10.0.0.7", identity.AddrFromContext(ctx))
+}
+
+func TestAddrFromPeerIgnoresForwardedFromClients(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 51234}})
+
+	ctx = AddrFromPeer(ctx, metadata.Pairs("x-forwarded-for", "192.168.1.1"))
+
+	assert.Equal(t, "10.0.0.7", identity.AddrFromContext(ctx))
+}
+
+// Test reading the api key from metadata
+func TestAPIKeyFromMetadata(t *testing.T) {
+	a := assert.New(t)
+	store := apiKeyStore{}
+	ctx := APIKeyFromMetadata(context.Background(), metadata.Pairs("x-api-key", "hunter2"))
+
+	_, err := endpoints.APIKeyMiddleware(store)(func(ctx context.Context, request interface{}) (interface{}, error) {
+		return nil, nil
+	})(ctx, nil)
+
+	a.True(endpoints.IsUnauthenticated(err))
+}
+
+// apiKeyStore knows no keys.
+type apiKeyStore struct{}
+
+func (apiKeyStore) FindAPIKeyByHash(string) (models.APIKey, error) {
+	return models.APIKey{}, nil
+}
+
+func (apiKeyStore) TouchAPIKey(int32, time.Time) error {
+	return nil
+}
+
+func TestEncodeGRPCAPIKeyResponse(t *testing.T) {
+	a := assert.New(t)
+	apiKey := models.APIKey{
+		Id:     pgx.NullInt32{Int32: 7, Valid: true},
+		Prefix: pgx.NullString{String: "psk_0a1b2c3d", Valid: true},
+		Owner:  pgx.NullString{String: "onboarding-pipeline", Valid: true},
+		Scope:  []string{"edi"},
+	}
+
+	res, err := EncodeGRPCAPIKeyResponse(context.Background(), endpoints.APIKeyReply{APIKey: apiKey, Key: "psk_0a1b2c3d_secret"})
+
+	a.Nil(err)
+	a.Equal(&pb.ApiKeyReply{ApiKey: &pb.ApiKey{Id: 7, Prefix: "psk_0a1b2c3d", Owner: "onboarding-pipeline", Scope: []string{"edi"}}, Key: "psk_0a1b2c3d_secret"}, res)
+}
+
+func TestEncodeGRPCAPIKeyResponseErr(t *testing.T) {
+	res, err := EncodeGRPCAPIKeyResponse(context.Background(), endpoints.APIKeyReply{Key: "psk_0a1b2c3d_secret", Error: "could not rotate api key 7"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, &pb.ApiKeyReply{Error: "could not rotate api key 7"}, res)
+}
+
+// Test reading the expected revision from metadata
+func TestRevisionFromMetadataBadValue(t *testing.T) {
+	ctx := RevisionFromMetadata(context.Background(), metadata.Pairs("expected-revision", "abc"))
+
+	assert.Equal(t, int32(0), expectedRevision(ctx, 0))
+}
+
+// Test turning a revision conflict into a status
+func TestGRPCErrorConflict(t *testing.T) {
+	err := grpcError(context.Background(), errors.Wrap(db.ErrRevisionConflict, "partnerId: 1 is not at revision 2"), "SetPartnerStatus")
+
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.Aborted, st.Code())
+}
+
+func TestGRPCErrorOther(t *testing.T) {
+	err := grpcError(context.Background(), errors.New("test error"), "SetPartnerStatus")
+
+	_, ok := status.FromError(err)
+	assert.False(t, ok)
+}
+
+func TestGRPCErrorIdempotencyKey(t *testing.T) {
+	st, ok := status.FromError(grpcError(context.Background(), endpoints.ErrIdempotencyKeyInProgress, "ClonePartner"))
+	assert.True(t, ok)
+	assert.Equal(t, codes.Aborted, st.Code())
+
+	st, ok = status.FromError(grpcError(context.Background(), endpoints.ErrIdempotencyKeyReused, "ClonePartner"))
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+}
+
+func TestGRPCErrorUnauthenticated(t *testing.T) {
+	st, ok := status.FromError(grpcError(context.Background(), errors.Wrap(endpoints.ErrUnauthenticated, "a bearer token is required"), "KeyValue"))
+
+	assert.True(t, ok)
+	assert.Equal(t, codes.Unauthenticated, st.Code())
+}
+
+func TestGRPCErrorPermissionDenied(t *testing.T) {
+	st, ok := status.FromError(grpcError(context.Background(), errors.Wrap(endpoints.ErrPermissionDenied, "cannot read group Money"), "KeyValue"))
+
+	assert.True(t, ok)
+	assert.Equal(t, codes.PermissionDenied, st.Code())
+}
+
+func TestGRPCErrorUnavailable(t *testing.T) {
+	st, ok := status.FromError(grpcError(context.Background(), errors.Wrap(db.ErrUnavailable, "circuit breaker is open"), "KeyValue"))
+
+	assert.True(t, ok)
+	assert.Equal(t, codes.Unavailable, st.Code())
+}
+
+func TestGRPCErrorRateLimited(t *testing.T) {
+	err := grpcError(context.Background(), endpoints.RateLimitError{Method: "GetDataById", Client: "jdoe", RetryAfter: 1500 * time.Millisecond}, "DataById")
+
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+}
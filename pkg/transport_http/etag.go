@@ -0,0 +1,117 @@
+package transport_http
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	oldcontext "golang.org/x/net/context"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/pb"
+)
+
+// FormatETag returns the entity tag for a partner revision.
+func FormatETag(revision int32) string {
+	return fmt.Sprintf("%q", strconv.Itoa(int(revision)))
+}
+
+// ParseETag returns the revision in an entity tag made by FormatETag.
+func ParseETag(etag string) (int32, bool) {
+	etag = strings.TrimSpace(etag)
+	if len(etag) < 2 || etag[0] != '"' || etag[len(etag)-1] != '"' {
+		return 0, false
+	}
+	revision, err := strconv.ParseInt(etag[1:len(etag)-1], 10, 32)
+	if err != nil || revision <= 0 {
+		return 0, false
+	}
+	return int32(revision), true
+}
+
+// setETag is a gateway option that sends the revision in a partner reply as its ETag.
+func setETag(_ oldcontext.Context, w http.ResponseWriter, resp proto.Message) error {
+	if reply, ok := resp.(*pb.PartnerDataReply); ok && reply.Revision > 0 && reply.Error == "" {
+		w.Header().Set("ETag", FormatETag(reply.Revision))
+	}
+	return nil
+}
+
+// ConditionalRequests maps the conditional request headers onto the gateway. An If-Match ETag is sent to the service
+// as the revision a write expects, and a read whose reply has an ETag matching If-None-Match is answered with
+// 304 Not Modified.
+func ConditionalRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if match := r.Header.Get("If-Match"); match != "" && match != "*" {
+			revision, ok := ParseETag(match)
+			if !ok {
+				http.Error(w, fmt.Sprintf("If-Match must be a single ETag from this service, not %s", match), http.StatusPreconditionFailed)
+				return
+			}
+			r.Header.Set("Grpc-Metadata-Expected-Revision", strconv.Itoa(int(revision)))
+		}
+
+		noneMatch := r.Header.Get("If-None-Match")
+		if noneMatch == "" || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		//The reply is held back until its ETag is known, since the gateway only sets it as the body is written.
+		buf := &bufferedResponse{header: make(http.Header)}
+		next.ServeHTTP(buf, r)
+		for key, values := range buf.header {
+			w.Header()[key] = values
+		}
+		if buf.status == 0 {
+			buf.status = http.StatusOK
+		}
+		if etag := buf.header.Get("ETag"); buf.status == http.StatusOK && etag != "" && etagListContains(noneMatch, etag) {
+			w.Header().Del("Content-Type")
+			w.Header().Del("Content-Length")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.WriteHeader(buf.status)
+		w.Write(buf.body.Bytes())
+	})
+}
+
+// etagListContains reports whether the If-None-Match list has the ETag, comparing weakly as If-None-Match does.
+func etagListContains(list, etag string) bool {
+	if strings.TrimSpace(list) == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(list, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedResponse is a ResponseWriter that keeps the reply in memory. Flush does nothing so streamed replies are
+// buffered whole as well.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	b.WriteHeader(http.StatusOK)
+	return b.body.Write(p)
+}
+
+func (b *bufferedResponse) Flush() {}
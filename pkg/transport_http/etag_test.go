@@ -0,0 +1,101 @@
+package transport_http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/pb"
+)
+
+// Test ETag formatting and parsing
+func TestFormatETag(t *testing.T) {
+	assert.Equal(t, `"3"`, FormatETag(3))
+}
+
+func TestParseETag(t *testing.T) {
+	revision, ok := ParseETag(`"3"`)
+
+	assert.True(t, ok)
+	assert.Equal(t, int32(3), revision)
+}
+
+func TestParseETagBad(t *testing.T) {
+	for _, etag := range []string{`3`, `W/"3"`, `"0"`, `"abc"`, `"3", "4"`} {
+		_, ok := ParseETag(etag)
+		assert.False(t, ok, etag)
+	}
+}
+
+// Test the gateway option
+func TestSetETag(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := setETag(context.Background(), w, &pb.PartnerDataReply{PartnerId: 1, Revision: 3})
+
+	assert.Nil(t, err)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+}
+
+func TestSetETagErrorReply(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := setETag(context.Background(), w, &pb.PartnerDataReply{Revision: 3, Error: "test error"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "", w.Header().Get("ETag"))
+}
+
+// Test the conditional request headers
+func TestConditionalRequestsIfMatch(t *testing.T) {
+	var sent string
+	handler := ConditionalRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header.Get("Grpc-Metadata-Expected-Revision")
+	}))
+	r := httptest.NewRequest(http.MethodPut, "/api/v1/partners/KOH/status", nil)
+	r.Header.Set("If-Match", `"3"`)
+
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Equal(t, "3", sent)
+}
+
+func TestConditionalRequestsIfMatchBad(t *testing.T) {
+	called := false
+	handler := ConditionalRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	r := httptest.NewRequest(http.MethodPut, "/api/v1/partners/KOH/status", nil)
+	r.Header.Set("If-Match", `W/"3"`)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, r)
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+}
+
+func TestConditionalRequestsIfNoneMatch(t *testing.T) {
+	handler := ConditionalRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"3"`)
+		w.Write([]byte(`{"partnerId":1}`))
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/partners/1", nil)
+	r.Header.Set("If-None-Match", `"2", W/"3"`)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, "", w.Body.String())
+
+	r.Header.Set("If-None-Match", `"2"`)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	assert.Equal(t, `{"partnerId":1}`, w.Body.String())
+}
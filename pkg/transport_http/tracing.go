@@ -0,0 +1,71 @@
+package transport_http
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/tracing"
+)
+
+var tracer = tracing.Tracer("transport_http")
+
+// Tracing records a span for each request, as part of the trace whose context was sent in its traceparent header if
+// there is one, and sends the context of the span on to the service as metadata, so that the gRPC call the gateway
+// makes is part of the same trace.
+func Tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := tracing.Propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, "http "+r.Method+" "+r.URL.Path, trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attribute.String("http.method", r.Method), attribute.String("http.target", r.URL.Path)))
+		defer span.End()
+		tracing.Propagator.Inject(ctx, metadataHeaders(r.Header))
+
+		traced := &tracedResponse{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(traced, r.WithContext(ctx))
+		span.SetAttributes(attribute.Int("http.status_code", traced.status))
+		if traced.status >= http.StatusInternalServerError {
+			tracing.Fail(span, http.StatusText(traced.status))
+		}
+	})
+}
+
+// metadataHeaders are the headers the gateway sends on as metadata, which start with Grpc-Metadata-.
+type metadataHeaders http.Header
+
+func (h metadataHeaders) Get(key string) string {
+	return http.Header(h).Get("Grpc-Metadata-" + key)
+}
+
+func (h metadataHeaders) Set(key, value string) {
+	http.Header(h).Set("Grpc-Metadata-"+key, value)
+}
+
+func (h metadataHeaders) Keys() []string {
+	var keys []string
+	for key := range h {
+		if strings.HasPrefix(key, "Grpc-Metadata-") {
+			keys = append(keys, strings.TrimPrefix(key, "Grpc-Metadata-"))
+		}
+	}
+	return keys
+}
+
+type tracedResponse struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *tracedResponse) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+//Flush lets the gateway stream replies through the wrapper.
+func (w *tracedResponse) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
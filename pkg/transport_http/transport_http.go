@@ -1,34 +1,210 @@
 package transport_http
 
 import (
+	"context"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-kit/kit/log"
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
 	"github.com/pkg/errors"
 	oldcontext "golang.org/x/net/context"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/identity"
+	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/openapi"
 	"jaxf-github.fanatics.corp/apparel/partner-service/pkg/pb"
 )
 
-func MakeHTTPHandler(host string, dopts []grpc.DialOption, logger log.Logger) (http.Handler, error) {
-	// mux for the reverse proxy
-	gwmux := runtime.NewServeMux()
+// SpecPath and ExplorerPath are where the gateway serves its OpenAPI spec and the page for exploring it.
+const (
+	SpecPath     = "/api/v1/openapi.json"
+	ExplorerPath = "/api/v1/docs"
+)
+
+// MakeHTTPHandler serves the REST gateway, which calls the gRPC server over conn. The conn is expected to be made with
+// the in-process listener the server also serves on, so that calls do not go over the network.
+func MakeHTTPHandler(conn *grpc.ClientConn, logger log.Logger) (http.Handler, error) {
+	// mux for the reverse proxy, which sends partner revisions as ETags
+	gwmux := runtime.NewServeMux(runtime.WithForwardResponseOption(setETag))
 
 	// standard mux
 	m := http.NewServeMux()
 
 	ctx := oldcontext.Background()
 
-	if err := pb.RegisterPartnerServiceHandlerFromEndpoint(ctx, gwmux, host, dopts); err != nil {
-		return nil, errors.Wrap(err, "failed to register handler from endpoint")
+	if err := pb.RegisterPartnerServiceHandler(ctx, gwmux, conn); err != nil {
+		return nil, errors.Wrap(err, "failed to register handler")
 	}
 
-	// serve swagger at the /swagger endpoint
-	m.Handle("/swagger/", http.StripPrefix("/swagger/", http.FileServer(http.Dir("./swagger"))))
+	// the OpenAPI spec of the gateway, and a page for trying it out, which /swagger/ used to be
+	m.Handle(SpecPath, openapi.SpecHandler(pb.OpenAPI))
+	m.Handle(ExplorerPath, openapi.ExplorerHandler(SpecPath))
+	m.Handle("/swagger/", http.RedirectHandler(ExplorerPath, http.StatusMovedPermanently))
 
 	// otherwise redirect to reverse proxy
-	m.Handle("/", gwmux)
+	m.Handle("/", Tracing(Transport(RequestIDs(ClientCertIdentity(APIKeys(IdempotencyKeys(RetryAfter(ConditionalRequests(gwmux)))))))))
 
 	return m, nil
 }
+
+// GRPCHandler sends gRPC calls, which are HTTP/2 with an application/grpc Content-Type, to a gRPC server and everything
+// else to the next handler, so that both can be served on one port. Without tls, HTTP/2 is taken in the clear for the
+// gRPC clients.
+type GRPCHandler struct {
+	grpcServer http.Handler
+	handler    http.Handler
+
+	mu       sync.Mutex
+	draining bool
+	calls    sync.WaitGroup
+}
+
+// GRPC returns a handler that serves gRPC calls with grpcServer and everything else with next.
+func GRPC(grpcServer http.Handler, next http.Handler) *GRPCHandler {
+	h := &GRPCHandler{grpcServer: grpcServer}
+	h.handler = h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			h.serveGRPC(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}), &http2.Server{})
+	return h
+}
+
+func (h *GRPCHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.handler.ServeHTTP(w, r)
+}
+
+func (h *GRPCHandler) serveGRPC(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	if h.draining {
+		h.mu.Unlock()
+		//a trailers-only reply, which clients take as Unavailable and retry elsewhere
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Status", strconv.Itoa(int(codes.Unavailable)))
+		w.Header().Set("Grpc-Message", "server is shutting down")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	h.calls.Add(1)
+	h.mu.Unlock()
+	defer h.calls.Done()
+
+	//the http server's read and write timeouts are for requests, and would cut off streams such as ExportPartners,
+	//which have no such limits on a port of their own
+	rc := http.NewResponseController(w)
+	rc.SetReadDeadline(time.Time{})
+	rc.SetWriteDeadline(time.Time{})
+	h.grpcServer.ServeHTTP(w, r)
+}
+
+// Drain refuses new gRPC calls and waits for the ones in flight to finish, or for ctx to be done. The http server's
+// Shutdown does not wait for them, as their connections were taken over for HTTP/2, and a grpc.Server cannot
+// GracefulStop calls it was handed through ServeHTTP, so they are drained here before the server is stopped.
+func (h *GRPCHandler) Drain(ctx context.Context) error {
+	h.mu.Lock()
+	h.draining = true
+	h.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		h.calls.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// IdempotencyKeys sends the Idempotency-Key header on to the service as metadata, which the gateway only does for
+// headers that start with Grpc-Metadata-.
+func IdempotencyKeys(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key := r.Header.Get("Idempotency-Key"); key != "" {
+			r.Header.Set("Grpc-Metadata-Idempotency-Key", key)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Transport tells the service that a call came in over http, so that it is counted as such.
+func Transport(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set("Grpc-Metadata-X-Transport", "http")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// APIKeys sends the X-Api-Key header on to the service as metadata.
+func APIKeys(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key := r.Header.Get("X-Api-Key"); key != "" {
+			r.Header.Set("Grpc-Metadata-X-Api-Key", key)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RetryAfter answers calls that are over their rate limit with 429 Too Many Requests and a Retry-After header. The
+// service sends the seconds to wait as retry-after metadata, which the gateway passes on as Grpc-Metadata-Retry-After.
+func RetryAfter(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&retryAfterResponse{ResponseWriter: w}, r)
+	})
+}
+
+type retryAfterResponse struct {
+	http.ResponseWriter
+}
+
+func (w *retryAfterResponse) WriteHeader(status int) {
+	if retryAfter := w.Header().Get("Grpc-Metadata-Retry-After"); retryAfter != "" && status >= http.StatusBadRequest {
+		w.Header().Set("Retry-After", retryAfter)
+		status = http.StatusTooManyRequests
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+//Flush lets the gateway stream replies through the wrapper.
+func (w *retryAfterResponse) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// RequestIDs sends the X-Request-Id header on to the service as metadata, making up an ID for requests sent without
+// one. The ID is sent back in the X-Request-Id header of the response.
+func RequestIDs(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get("X-Request-Id")
+		if requestID == "" {
+			requestID = identity.NewRequestID()
+		}
+		r.Header.Set("Grpc-Metadata-X-Request-Id", requestID)
+		w.Header().Set("X-Request-Id", requestID)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ClientCertIdentity sends the subject of a verified client certificate on to the service as x-client-subject
+// metadata, which the service takes as the caller of calls from the gateway. A subject sent by the client itself
+// is dropped.
+func ClientCertIdentity(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Del("Grpc-Metadata-X-Client-Subject")
+		if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+			r.Header.Set("Grpc-Metadata-X-Client-Subject", identity.CertificateSubject(r.TLS.VerifiedChains[0][0]))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,319 @@
+package transport_http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// Test forwarding idempotency keys
+func TestIdempotencyKeys(t *testing.T) {
+	var sent string
+	handler := IdempotencyKeys(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header.Get("Grpc-Metadata-Idempotency-Key")
+	}))
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/partners/clone", nil)
+	r.Header.Set("Idempotency-Key", "abc")
+
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Equal(t, "abc", sent)
+}
+
+// Test forwarding the client certificate subject
+func TestClientCertIdentity(t *testing.T) {
+	var sent string
+	handler := ClientCertIdentity(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header.Get("Grpc-Metadata-X-Client-Subject")
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/partners/1", nil)
+	r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "onboarding-pipeline"}}}}}
+	r.Header.Set("Grpc-Metadata-X-Client-Subject", "jdoe")
+
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Equal(t, "onboarding-pipeline", sent)
+}
+
+func TestClientCertIdentityDropsSentSubject(t *testing.T) {
+	var sent string
+	handler := ClientCertIdentity(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header.Get("Grpc-Metadata-X-Client-Subject")
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/partners/1", nil)
+	r.Header.Set("Grpc-Metadata-X-Client-Subject", "jdoe")
+
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Equal(t, "", sent)
+}
+
+// Test forwarding request IDs
+func TestRequestIDs(t *testing.T) {
+	var sent string
+	handler := RequestIDs(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header.Get("Grpc-Metadata-X-Request-Id")
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/partners/1", nil)
+	r.Header.Set("X-Request-Id", "abc123")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, "abc123", sent)
+	assert.Equal(t, "abc123", w.Header().Get("X-Request-Id"))
+}
+
+func TestRequestIDsGenerated(t *testing.T) {
+	var sent string
+	handler := RequestIDs(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header.Get("Grpc-Metadata-X-Request-Id")
+	}))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/partners/1", nil))
+
+	assert.NotEqual(t, "", sent)
+	assert.Equal(t, sent, w.Header().Get("X-Request-Id"))
+}
+
+func TestTransport(t *testing.T) {
+	var sent string
+	handler := Transport(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header.Get("Grpc-Metadata-X-Transport")
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/partners/1", nil))
+
+	assert.Equal(t, "http", sent)
+}
+
+func TestTracing(t *testing.T) {
+	var sent string
+	handler := Tracing(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header.Get("Grpc-Metadata-Traceparent")
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/partners/1", nil)
+	r.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Contains(t, sent, "4bf92f3577b34da6a3ce929d0e0e4736")
+}
+
+// Test forwarding api keys
+func TestAPIKeys(t *testing.T) {
+	var sent string
+	handler := APIKeys(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header.Get("Grpc-Metadata-X-Api-Key")
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/partners/1", nil)
+	r.Header.Set("X-Api-Key", "psk_0a1b2c3d_secret")
+
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Equal(t, "psk_0a1b2c3d_secret", sent)
+}
+
+// Test answering calls over their rate limit with 429
+func TestRetryAfter(t *testing.T) {
+	handler := RetryAfter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Grpc-Metadata-Retry-After", "2")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/partners/1", nil))
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+}
+
+func TestRetryAfterOtherStatus(t *testing.T) {
+	handler := RetryAfter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/partners/1", nil))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "", w.Header().Get("Retry-After"))
+}
+
+// Test sending gRPC calls to the gRPC server on a shared port
+func TestGRPC(t *testing.T) {
+	var served string
+	handler := GRPC(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served = "grpc"
+	}), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served = "http"
+	}))
+	r := httptest.NewRequest(http.MethodPost, "/pb.PartnerService/GetDataById", nil)
+	r.ProtoMajor = 2
+	r.Header.Set("Content-Type", "application/grpc+proto")
+
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	assert.Equal(t, "grpc", served)
+
+	r = httptest.NewRequest(http.MethodPost, "/api/v1/partners/clone", nil)
+	r.Header.Set("Content-Type", "application/json")
+
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	assert.Equal(t, "http", served)
+}
+
+//blockingHealth answers Check calls once release is closed, saying on started when each comes in.
+type blockingHealth struct {
+	healthpb.HealthServer
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h blockingHealth) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	h.started <- struct{}{}
+	<-h.release
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+// Test draining gRPC calls on a shared port, after which the server stops without the panic GracefulStop gives
+func TestGRPCDrain(t *testing.T) {
+	a := assert.New(t)
+	health := blockingHealth{started: make(chan struct{}, 1), release: make(chan struct{})}
+	grpcServer := grpc.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, health)
+	handler := GRPC(grpcServer, http.NotFoundHandler())
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	conn, err := grpc.Dial(strings.TrimPrefix(server.URL, "http://"), grpc.WithInsecure())
+	if !a.Nil(err) {
+		return
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	inFlight := make(chan error, 1)
+	go func() {
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		inFlight <- err
+	}()
+	<-health.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	a.Equal(context.DeadlineExceeded, handler.Drain(ctx))
+
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	st, _ := status.FromError(err)
+	a.Equal(codes.Unavailable, st.Code())
+
+	close(health.release)
+	a.Nil(<-inFlight)
+	a.Nil(handler.Drain(context.Background()))
+	grpcServer.Stop()
+}
+
+// Test that gRPC calls on a shared port are not cut off by the http server's timeouts, as long streams would be
+func TestGRPCNoTimeouts(t *testing.T) {
+	a := assert.New(t)
+	health := blockingHealth{started: make(chan struct{}, 1), release: make(chan struct{})}
+	grpcServer := grpc.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, health)
+	defer grpcServer.Stop()
+	server := httptest.NewUnstartedServer(GRPC(grpcServer, http.NotFoundHandler()))
+	server.Config.ReadTimeout = 50 * time.Millisecond
+	server.Config.WriteTimeout = 50 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	conn, err := grpc.Dial(strings.TrimPrefix(server.URL, "http://"), grpc.WithInsecure())
+	if !a.Nil(err) {
+		return
+	}
+	defer conn.Close()
+
+	go func() {
+		<-health.started
+		time.Sleep(200 * time.Millisecond)
+		close(health.release)
+	}()
+	reply, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if a.Nil(err) {
+		a.Equal(healthpb.HealthCheckResponse_SERVING, reply.Status)
+	}
+}
+
+// Test serving the spec, whose references all have to lead somewhere
+func TestSpec(t *testing.T) {
+	a := assert.New(t)
+	conn, err := grpc.Dial("localhost:0", grpc.WithInsecure())
+	if !a.Nil(err) {
+		return
+	}
+	defer conn.Close()
+	h, err := MakeHTTPHandler(conn, log.NewNopLogger())
+	if !a.Nil(err) {
+		return
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, SpecPath, nil))
+	a.Equal(http.StatusOK, w.Code)
+	var spec map[string]interface{}
+	if !a.Nil(json.Unmarshal(w.Body.Bytes(), &spec)) {
+		return
+	}
+	a.Equal("3.0.3", spec["openapi"])
+	a.NotEmpty(spec["paths"])
+	for _, ref := range refs(spec) {
+		var node interface{} = spec
+		for _, key := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			m, _ := node.(map[string]interface{})
+			node = m[key]
+		}
+		a.NotNil(node, ref)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, ExplorerPath, nil))
+	a.Equal(http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger/", nil))
+	a.Equal(http.StatusMovedPermanently, w.Code)
+	a.Equal(ExplorerPath, w.Header().Get("Location"))
+}
+
+// refs returns every $ref in a document.
+func refs(node interface{}) []string {
+	var found []string
+	switch node := node.(type) {
+	case map[string]interface{}:
+		for key, value := range node {
+			if ref, ok := value.(string); ok && key == "$ref" {
+				found = append(found, ref)
+			}
+			found = append(found, refs(value)...)
+		}
+	case []interface{}:
+		for _, value := range node {
+			found = append(found, refs(value)...)
+		}
+	}
+	return found
+}
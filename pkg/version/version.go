@@ -0,0 +1,34 @@
+package version
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Version, Commit and BuildTime are set when the binary is linked, for example with
+// -ldflags "-X jaxf-github.fanatics.corp/apparel/partner-service/pkg/version.Commit=$(git rev-parse HEAD)".
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// Info is the build the binary came from.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"buildTime"`
+}
+
+// Get returns the build the binary came from.
+func Get() Info {
+	return Info{Version: Version, Commit: Commit, BuildTime: BuildTime}
+}
+
+// Handler serves the build the binary came from as JSON.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Get())
+	})
+}
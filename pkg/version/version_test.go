@@ -0,0 +1,23 @@
+package version
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	a := assert.New(t)
+	Version, Commit, BuildTime = "42", "8202b10", "2017-08-01T12:00:00Z"
+	defer func() { Version, Commit, BuildTime = "dev", "unknown", "unknown" }()
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/version", nil))
+
+	var info Info
+	a.Nil(json.Unmarshal(w.Body.Bytes(), &info))
+	a.Equal(Info{Version: "42", Commit: "8202b10", BuildTime: "2017-08-01T12:00:00Z"}, info)
+	a.Equal("application/json", w.Header().Get("Content-Type"))
+}
@@ -0,0 +1,93 @@
+package watch
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// KeyPair is a certificate and its key, loaded from files and loaded again when they change, for tls.Config to get
+// the certificate from on each handshake.
+type KeyPair struct {
+	cert, key *File
+
+	mtx   sync.RWMutex
+	pair *tls.Certificate
+	leaf *x509.Certificate
+}
+
+// LoadKeyPair loads the PEM certificate at certPath and its key at keyPath.
+func LoadKeyPair(certPath, keyPath string) (*KeyPair, error) {
+	k := &KeyPair{cert: NewFile(certPath), key: NewFile(keyPath)}
+	if _, err := k.reload(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// reload reads both files and, if either changed and they are a valid pair, replaces the certificate. It returns
+// whether it did.
+func (k *KeyPair) reload() (replaced bool, err error) {
+	defer func() {
+		//one of the files can be rewritten before the other, so both are read again next time
+		if err != nil {
+			k.cert.sum, k.key.sum = nil, nil
+		}
+	}()
+	certPEM, certChanged, err := k.cert.Read()
+	if err != nil {
+		return false, err
+	}
+	keyPEM, keyChanged, err := k.key.Read()
+	if err != nil {
+		return false, err
+	}
+	if !certChanged && !keyChanged {
+		return false, nil
+	}
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to create certificate")
+	}
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return false, errors.Wrap(err, "failed to parse certificate")
+	}
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+	k.pair, k.leaf = &pair, leaf
+	return true, nil
+}
+
+// GetCertificate is for tls.Config, to serve the current certificate.
+func (k *KeyPair) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	k.mtx.RLock()
+	defer k.mtx.RUnlock()
+	return k.pair, nil
+}
+
+// Leaf returns the current certificate.
+func (k *KeyPair) Leaf() *x509.Certificate {
+	k.mtx.RLock()
+	defer k.mtx.RUnlock()
+	return k.leaf
+}
+
+// Watch loads the files again every interval until ctx is done, calling changed when the certificate is replaced and
+// failed when it cannot be, in which case the old one is still served.
+func (k *KeyPair) Watch(ctx context.Context, interval time.Duration, changed func(*x509.Certificate), failed func(error)) {
+	Every(ctx, interval, func() {
+		ok, err := k.reload()
+		if err != nil {
+			failed(err)
+			return
+		}
+		if ok {
+			changed(k.Leaf())
+		}
+	})
+}
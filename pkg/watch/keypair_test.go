@@ -0,0 +1,80 @@
+package watch
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//selfSigned returns a self signed certificate for name and its key, both PEM encoded
+func selfSigned(t *testing.T, name string) ([]byte, []byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), der
+}
+
+func TestKeyPairReload(t *testing.T) {
+	a := assert.New(t)
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	certPath, keyPath := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	cert1, key1, der1 := selfSigned(t, "one")
+	ioutil.WriteFile(certPath, cert1, 0600)
+	ioutil.WriteFile(keyPath, key1, 0600)
+
+	k, err := LoadKeyPair(certPath, keyPath)
+	a.Nil(err)
+	a.Equal("one", k.Leaf().Subject.CommonName)
+
+	//the cert is rewritten before its key, so the old pair is served until both are
+	cert2, key2, der2 := selfSigned(t, "two")
+	ioutil.WriteFile(certPath, cert2, 0600)
+	replaced, err := k.reload()
+	a.False(replaced)
+	a.NotNil(err)
+	pair, _ := k.GetCertificate(&tls.ClientHelloInfo{})
+	a.Equal(der1, pair.Certificate[0])
+
+	ioutil.WriteFile(keyPath, key2, 0600)
+	replaced, err = k.reload()
+	a.True(replaced)
+	a.Nil(err)
+	pair, _ = k.GetCertificate(&tls.ClientHelloInfo{})
+	a.Equal(der2, pair.Certificate[0])
+	a.Equal("two", k.Leaf().Subject.CommonName)
+
+	replaced, err = k.reload()
+	a.False(replaced)
+	a.Nil(err)
+}
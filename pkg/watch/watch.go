@@ -0,0 +1,100 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// File is a file whose contents are checked for changes by reading it again. Polling, rather than waiting on file
+// system events, also sees secrets that are mounted as symlinks to files that are swapped out.
+type File struct {
+	path string
+	sum  []byte
+}
+
+// NewFile returns a File for path. Its first Read counts as a change.
+func NewFile(path string) *File {
+	return &File{path: path}
+}
+
+// Read returns the contents of the file and whether they changed since the last Read. A file that cannot be read is
+// not a change, so that one being replaced is picked up once it is back.
+func (f *File) Read() ([]byte, bool, error) {
+	b, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to read "+f.path)
+	}
+	sum := sha256.Sum256(b)
+	if f.sum != nil && bytes.Equal(f.sum, sum[:]) {
+		return b, false, nil
+	}
+	f.sum = sum[:]
+	return b, true, nil
+}
+
+// Every calls check every interval until ctx is done.
+func Every(ctx context.Context, interval time.Duration, check func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			check()
+		}
+	}
+}
+
+// Secret is a secret read from a file, such as one named by a *_FILE env var, with surrounding whitespace trimmed.
+type Secret struct {
+	file *File
+
+	mtx   sync.RWMutex
+	value string
+}
+
+// LoadSecret reads the secret in the file at path.
+func LoadSecret(path string) (*Secret, error) {
+	s := &Secret{file: NewFile(path)}
+	b, _, err := s.file.Read()
+	if err != nil {
+		return nil, err
+	}
+	s.value = strings.TrimSpace(string(b))
+	return s, nil
+}
+
+// Value returns the secret as it was last read.
+func (s *Secret) Value() string {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.value
+}
+
+// Watch reads the file again every interval until ctx is done, calling changed with the new secret when it changes
+// and failed when the file cannot be read.
+func (s *Secret) Watch(ctx context.Context, interval time.Duration, changed func(string), failed func(error)) {
+	Every(ctx, interval, func() {
+		b, ok, err := s.file.Read()
+		if err != nil {
+			failed(err)
+			return
+		}
+		if !ok {
+			return
+		}
+		value := strings.TrimSpace(string(b))
+		s.mtx.Lock()
+		s.value = value
+		s.mtx.Unlock()
+		changed(value)
+	})
+}
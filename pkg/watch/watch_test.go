@@ -0,0 +1,63 @@
+package watch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//tempDir returns a temp dir and a func that removes it
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func TestFileRead(t *testing.T) {
+	a := assert.New(t)
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "password")
+	ioutil.WriteFile(path, []byte("one\n"), 0600)
+	f := NewFile(path)
+
+	b, changed, err := f.Read()
+	a.Nil(err)
+	a.True(changed)
+	a.Equal("one\n", string(b))
+
+	_, changed, _ = f.Read()
+	a.False(changed)
+
+	//a secret being swapped out can be missing for a moment
+	os.Remove(path)
+	_, changed, err = f.Read()
+	a.NotNil(err)
+	a.False(changed)
+
+	ioutil.WriteFile(path, []byte("two\n"), 0600)
+	b, changed, err = f.Read()
+	a.Nil(err)
+	a.True(changed)
+	a.Equal("two\n", string(b))
+}
+
+func TestLoadSecret(t *testing.T) {
+	a := assert.New(t)
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "password")
+	ioutil.WriteFile(path, []byte("  hunter2\n"), 0600)
+
+	s, err := LoadSecret(path)
+
+	a.Nil(err)
+	a.Equal("hunter2", s.Value())
+	_, err = LoadSecret(filepath.Join(dir, "missing"))
+	a.NotNil(err)
+}